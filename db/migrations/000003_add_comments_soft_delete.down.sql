@@ -0,0 +1,3 @@
+alter table comments
+    drop column if exists updated_at,
+    drop column if exists is_deleted;
\ No newline at end of file
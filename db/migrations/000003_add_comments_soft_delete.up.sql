@@ -0,0 +1,3 @@
+alter table comments
+    add column if not exists is_deleted boolean not null default false,
+    add column if not exists updated_at timestamp;
\ No newline at end of file
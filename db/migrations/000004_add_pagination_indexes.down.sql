@@ -0,0 +1,3 @@
+drop index if exists comments_parent_comment_id_created_at_id_idx;
+drop index if exists comments_post_id_created_at_id_idx;
+drop index if exists posts_created_at_id_idx;
\ No newline at end of file
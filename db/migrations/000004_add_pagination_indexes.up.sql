@@ -0,0 +1,4 @@
+create index if not exists posts_created_at_id_idx on posts (created_at, id);
+create index if not exists comments_post_id_created_at_id_idx on comments (post_id, created_at, id)
+    where parent_comment_id is null;
+create index if not exists comments_parent_comment_id_created_at_id_idx on comments (parent_comment_id, created_at, id);
\ No newline at end of file
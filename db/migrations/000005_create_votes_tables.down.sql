@@ -0,0 +1,10 @@
+drop table if exists comment_votes;
+drop table if exists post_votes;
+
+alter table comments
+    drop column if exists downvotes,
+    drop column if exists upvotes;
+
+alter table posts
+    drop column if exists downvotes,
+    drop column if exists upvotes;
\ No newline at end of file
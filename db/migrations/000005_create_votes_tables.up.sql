@@ -0,0 +1,23 @@
+alter table posts
+    add column if not exists upvotes integer not null default 0,
+    add column if not exists downvotes integer not null default 0;
+
+alter table comments
+    add column if not exists upvotes integer not null default 0,
+    add column if not exists downvotes integer not null default 0;
+
+create table if not exists post_votes (
+    post_id uuid not null references posts(id) on delete cascade,
+    voter varchar(100) not null,
+    value smallint not null check(value in (-1, 1)),
+    created_at timestamp default now(),
+    primary key (post_id, voter)
+);
+
+create table if not exists comment_votes (
+    comment_id uuid not null references comments(id) on delete cascade,
+    voter varchar(100) not null,
+    value smallint not null check(value in (-1, 1)),
+    created_at timestamp default now(),
+    primary key (comment_id, voter)
+);
\ No newline at end of file
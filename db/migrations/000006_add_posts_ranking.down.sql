@@ -0,0 +1,8 @@
+drop index if exists posts_controversy_created_at_id_idx;
+drop index if exists posts_hot_score_created_at_id_idx;
+drop index if exists posts_score_created_at_id_idx;
+
+alter table posts
+    drop column if exists controversy,
+    drop column if exists hot_score,
+    drop column if exists score;
\ No newline at end of file
@@ -0,0 +1,16 @@
+alter table posts
+    add column if not exists score integer generated always as (upvotes - downvotes) stored,
+    add column if not exists hot_score double precision generated always as (
+        sign((upvotes - downvotes)::double precision) * log(greatest(abs(upvotes - downvotes), 1)::double precision)
+            + (extract(epoch from created_at)::double precision - 1134028003) / 45000
+    ) stored,
+    add column if not exists controversy double precision generated always as (
+        case when upvotes <= 0 or downvotes <= 0 then 0
+            else power((upvotes + downvotes)::double precision,
+                least(upvotes, downvotes)::double precision / greatest(upvotes, downvotes))
+        end
+    ) stored;
+
+create index if not exists posts_score_created_at_id_idx on posts (score, created_at, id);
+create index if not exists posts_hot_score_created_at_id_idx on posts (hot_score, created_at, id);
+create index if not exists posts_controversy_created_at_id_idx on posts (controversy, created_at, id);
\ No newline at end of file
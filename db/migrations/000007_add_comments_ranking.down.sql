@@ -0,0 +1,8 @@
+drop index if exists comments_parent_comment_id_best_created_at_id_idx;
+drop index if exists comments_parent_comment_id_score_created_at_id_idx;
+drop index if exists comments_post_id_best_created_at_id_idx;
+drop index if exists comments_post_id_score_created_at_id_idx;
+
+alter table comments
+    drop column if exists best,
+    drop column if exists score;
\ No newline at end of file
@@ -0,0 +1,20 @@
+alter table comments
+    add column if not exists score integer generated always as (upvotes - downvotes) stored,
+    add column if not exists best double precision generated always as (
+        case when upvotes + downvotes <= 0 then 0
+            else (upvotes::double precision / (upvotes + downvotes) + 1.281551565545 ^ 2 / (2 * (upvotes + downvotes))
+                - 1.281551565545 * sqrt((upvotes::double precision / (upvotes + downvotes)
+                    * (1 - upvotes::double precision / (upvotes + downvotes))
+                    + 1.281551565545 ^ 2 / (4 * (upvotes + downvotes))) / (upvotes + downvotes)))
+                / (1 + 1.281551565545 ^ 2 / (upvotes + downvotes))
+        end
+    ) stored;
+
+create index if not exists comments_post_id_score_created_at_id_idx on comments (post_id, score, created_at, id)
+    where parent_comment_id is null;
+create index if not exists comments_post_id_best_created_at_id_idx on comments (post_id, best, created_at, id)
+    where parent_comment_id is null;
+create index if not exists comments_parent_comment_id_score_created_at_id_idx
+    on comments (parent_comment_id, score, created_at, id);
+create index if not exists comments_parent_comment_id_best_created_at_id_idx
+    on comments (parent_comment_id, best, created_at, id);
\ No newline at end of file
@@ -0,0 +1,6 @@
+create table if not exists users (
+    id uuid primary key default gen_random_uuid(),
+    username varchar(100) not null unique,
+    password_hash text not null,
+    created_at timestamp default now()
+);
\ No newline at end of file
@@ -0,0 +1 @@
+drop index if exists comments_post_id_all_created_at_id_idx;
\ No newline at end of file
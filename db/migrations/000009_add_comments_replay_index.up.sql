@@ -0,0 +1 @@
+create index if not exists comments_post_id_all_created_at_id_idx on comments (post_id, created_at, id);
\ No newline at end of file
@@ -0,0 +1,13 @@
+create table if not exists notifications (
+    id uuid primary key default gen_random_uuid(),
+    recipient varchar(100) not null,
+    type varchar(20) not null,
+    actor varchar(100) not null,
+    post_id uuid not null references posts(id) on delete cascade,
+    comment_id uuid not null references comments(id) on delete cascade,
+    is_read boolean not null default false,
+    created_at timestamp default now()
+);
+
+create index if not exists notifications_recipient_created_at_id_idx on notifications (recipient, created_at, id);
+create index if not exists notifications_recipient_unread_idx on notifications (recipient) where not is_read;
\ No newline at end of file
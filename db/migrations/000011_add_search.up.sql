@@ -0,0 +1,14 @@
+alter table posts add column if not exists search tsvector generated always as (
+    setweight(to_tsvector('russian', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('russian', coalesce(content, '')), 'B') ||
+    setweight(to_tsvector('english', coalesce(content, '')), 'B')
+) stored;
+
+alter table comments add column if not exists search tsvector generated always as (
+    setweight(to_tsvector('russian', coalesce(content, '')), 'B') ||
+    setweight(to_tsvector('english', coalesce(content, '')), 'B')
+) stored;
+
+create index if not exists posts_search_idx on posts using gin (search);
+create index if not exists comments_search_idx on comments using gin (search);
\ No newline at end of file
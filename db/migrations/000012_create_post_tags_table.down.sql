@@ -0,0 +1,2 @@
+drop index if exists post_tags_tag_post_id_idx;
+drop table if exists post_tags;
\ No newline at end of file
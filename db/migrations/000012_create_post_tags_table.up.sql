@@ -0,0 +1,7 @@
+create table if not exists post_tags (
+    post_id uuid not null references posts(id) on delete cascade,
+    tag varchar(32) not null,
+    primary key (post_id, tag)
+);
+
+create index if not exists post_tags_tag_post_id_idx on post_tags (tag, post_id);
\ No newline at end of file
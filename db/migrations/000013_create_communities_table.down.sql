@@ -0,0 +1,6 @@
+drop index if exists posts_community_id_created_at_id_idx;
+
+alter table posts drop column if exists community_id;
+
+drop table if exists community_members;
+drop table if exists communities;
\ No newline at end of file
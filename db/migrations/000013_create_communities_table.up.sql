@@ -0,0 +1,22 @@
+create table if not exists communities (
+    id uuid primary key default gen_random_uuid(),
+    slug varchar(32) not null unique,
+    title varchar(100) not null,
+    description text not null default '',
+    owner varchar(100) not null,
+    post_policy varchar(20) not null,
+    comment_policy varchar(20) not null,
+    member_count integer not null default 0,
+    created_at timestamp default now()
+);
+
+create table if not exists community_members (
+    community_id uuid not null references communities(id) on delete cascade,
+    username varchar(100) not null,
+    joined_at timestamp default now(),
+    primary key (community_id, username)
+);
+
+alter table posts add column if not exists community_id uuid references communities(id) on delete set null;
+
+create index if not exists posts_community_id_created_at_id_idx on posts (community_id, created_at, id);
\ No newline at end of file
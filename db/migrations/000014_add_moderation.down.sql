@@ -0,0 +1,9 @@
+drop trigger if exists moderation_log_append_only on moderation_log;
+drop function if exists forbid_moderation_log_change();
+
+drop table if exists moderation_log;
+drop table if exists community_moderators;
+
+alter table comments drop column if exists is_removed;
+alter table posts drop column if exists is_removed;
+alter table users drop column if exists role;
\ No newline at end of file
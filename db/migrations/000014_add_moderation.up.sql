@@ -0,0 +1,39 @@
+alter table users add column if not exists role varchar(20) not null default 'USER';
+
+alter table posts add column if not exists is_removed boolean not null default false;
+alter table comments add column if not exists is_removed boolean not null default false;
+
+create table if not exists community_moderators (
+    community_id uuid not null references communities(id) on delete cascade,
+    username varchar(100) not null,
+    primary key (community_id, username)
+);
+
+-- журнал не ссылается на посты и комментарии внешними ключами, чтобы записи оставались после их удаления
+create table if not exists moderation_log (
+    id uuid primary key default gen_random_uuid(),
+    actor varchar(100) not null,
+    action varchar(20) not null,
+    post_id uuid,
+    comment_id uuid,
+    target_user varchar(100),
+    role varchar(20),
+    community_id uuid,
+    reason text not null default '',
+    created_at timestamp default now()
+);
+
+create index if not exists moderation_log_created_at_id_idx on moderation_log (created_at, id);
+create index if not exists moderation_log_community_id_created_at_id_idx
+    on moderation_log (community_id, created_at, id);
+
+-- журнал только дополняется: изменение и удаление записей запрещены
+create or replace function forbid_moderation_log_change() returns trigger as $$
+begin
+    raise exception 'moderation_log is append-only';
+end;
+$$ language plpgsql;
+
+create or replace trigger moderation_log_append_only
+    before update or delete on moderation_log
+    for each statement execute function forbid_moderation_log_change();
\ No newline at end of file
@@ -0,0 +1,4 @@
+drop index if exists moderation_log_comment_id_idx;
+drop index if exists moderation_log_post_id_idx;
+
+drop table if exists reports;
\ No newline at end of file
@@ -0,0 +1,23 @@
+create table if not exists reports (
+    id uuid primary key default gen_random_uuid(),
+    target_type varchar(20) not null,
+    target_id uuid not null,
+    post_id uuid not null references posts(id) on delete cascade,
+    reporter varchar(100) not null,
+    reason varchar(20) not null,
+    note text not null default '',
+    status varchar(20) not null default 'OPEN',
+    resolved_by varchar(100),
+    resolved_at timestamp,
+    created_at timestamp default now(),
+    unique (target_type, target_id, reporter)
+);
+
+create index if not exists reports_status_created_at_id_idx on reports (status, created_at, id);
+
+-- последнее действие с контентом в журнале нужно, чтобы при отклонении жалоб восстановить только контент,
+-- скрытый по жалобам автоматически
+create index if not exists moderation_log_post_id_idx on moderation_log (post_id, created_at, id)
+    where comment_id is null;
+create index if not exists moderation_log_comment_id_idx on moderation_log (comment_id, created_at, id)
+    where comment_id is not null;
\ No newline at end of file
@@ -0,0 +1 @@
+drop table if exists rate_limits;
\ No newline at end of file
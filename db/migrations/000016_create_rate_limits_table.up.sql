@@ -0,0 +1,8 @@
+-- корзины ограничителя частоты запросов: потерять их при сбое не страшно, поэтому таблица не пишется в WAL
+create unlogged table if not exists rate_limits (
+    key varchar(300) primary key,
+    tokens double precision not null,
+    burst integer not null,
+    rate double precision not null, -- сколько токенов восполняется за секунду
+    updated_at timestamp not null default now()
+);
\ No newline at end of file
@@ -0,0 +1,7 @@
+drop index if exists comments_author_content_hash_idx;
+drop index if exists posts_author_content_hash_idx;
+
+alter table comments drop column if exists content_hash;
+alter table comments drop column if exists is_shadowed;
+alter table posts drop column if exists content_hash;
+alter table posts drop column if exists is_shadowed;
\ No newline at end of file
@@ -0,0 +1,10 @@
+-- скрытый фильтром контента пост или комментарий виден только автору
+alter table posts add column if not exists is_shadowed boolean not null default false;
+alter table comments add column if not exists is_shadowed boolean not null default false;
+
+-- хэш нормализованного текста для поиска повторов одного автора
+alter table posts add column if not exists content_hash varchar(64) not null default '';
+alter table comments add column if not exists content_hash varchar(64) not null default '';
+
+create index if not exists posts_author_content_hash_idx on posts (author, content_hash, created_at);
+create index if not exists comments_author_content_hash_idx on comments (author, content_hash, created_at);
\ No newline at end of file
@@ -2,19 +2,17 @@ package app
 
 import (
 	"context"
-	"net/http"
+	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
-	"github.com/99designs/gqlgen/graphql/handler"
-	"github.com/99designs/gqlgen/graphql/handler/transport"
-	"github.com/99designs/gqlgen/graphql/playground"
-	"github.com/gin-gonic/gin"
-	"github.com/gorilla/websocket"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
-	"github.com/nedokyrill/posts-service/graphql"
+	"github.com/nedokyrill/posts-service/internal/auth"
 	"github.com/nedokyrill/posts-service/internal/resolvers"
 	"github.com/nedokyrill/posts-service/internal/service"
 	"github.com/nedokyrill/posts-service/internal/storage"
@@ -24,7 +22,6 @@ import (
 	"github.com/nedokyrill/posts-service/pkg/db"
 	"github.com/nedokyrill/posts-service/pkg/logger"
 	"github.com/nedokyrill/posts-service/pkg/server"
-	"github.com/nedokyrill/posts-service/pkg/utils"
 )
 
 func Run() {
@@ -40,11 +37,30 @@ func Run() {
 	// Init REPO layer
 	var postStore storage.PostStorage
 	var commStore storage.CommentStorage
+	var voteStore storage.VoteStorage
+	var userStore storage.UserStorage
+	var notifStore storage.NotificationStorage
+	var communityStore storage.CommunityStorage
+	var modStore storage.ModerationStorage
+	var reportStore storage.ReportStorage
+	var rateLimitStore storage.RateLimitStorage
+	var pool *pgxpool.Pool // nil при хранении в памяти
 
 	if os.Getenv("IN_MEM_STORAGE") == "true" {
 		logger.Logger.Info("using memory storage")
-		postStore = mem.NewPostStorageMem()
-		commStore = mem.NewCommentsStorageMem()
+		posts := mem.NewPostStorageMem()
+		comms := mem.NewCommentsStorageMem(posts)
+		users, communities := mem.NewUserStorageMem(), mem.NewCommunityStorageMem()
+		postStore = posts
+		commStore = comms
+		voteStore = mem.NewVoteStorageMem(posts, comms)
+		userStore = users
+		notifStore = mem.NewNotificationStorageMem()
+		communityStore = communities
+		moderation := mem.NewModerationStorageMem(posts, comms, users, communities)
+		modStore = moderation
+		reportStore = mem.NewReportStorageMem(moderation)
+		rateLimitStore = mem.NewRateLimitStorageMem()
 	} else {
 		logger.Logger.Info("using postgres storage")
 		ctx, cancel := context.WithTimeout(context.Background(), consts.PgxTimeout)
@@ -55,38 +71,99 @@ func Run() {
 			logger.Logger.Fatal("error connecting to database, exiting...")
 		}
 		defer conn.Close()
+		pool = conn
 
 		postStore = postgres.NewPostStorePgx(conn)
 		commStore = postgres.NewCommentsStorePgx(conn)
+		voteStore = postgres.NewVoteStorePgx(conn)
+		userStore = postgres.NewUserStorePgx(conn)
+		notifStore = postgres.NewNotificationStorePgx(conn)
+		communityStore = postgres.NewCommunityStorePgx(conn)
+		modStore = postgres.NewModerationStorePgx(conn)
+		reportStore = postgres.NewReportStorePgx(conn)
+		rateLimitStore = postgres.NewRateLimitStorePgx(conn)
 	}
 
+	// Init AUTH
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		logger.Logger.Fatal("JWT_SECRET is not set, exiting...")
+	}
+	tokens := auth.NewTokenManager(secret, consts.AccessTokenTTL, consts.RefreshTokenTTL)
+
 	// Init SERVICE layer
-	postServ := service.NewPostService(postStore)
-	commServ := service.NewCommentService(commStore, postStore)
-	viewerServ := service.NewViewerService()
+	filters, err := newContentFilters(postStore, commStore)
+	if err != nil {
+		logger.Logger.Fatal(fmt.Sprintf("%v, exiting...", err))
+	}
+	postServ := service.NewPostService(postStore, communityStore, userStore, filters)
+	communityServ := service.NewCommunityService(communityStore)
+	commServ := service.NewCommentService(commStore, postStore, communityStore, userStore, filters)
+	overflow, err := service.ParseOverflowPolicy(os.Getenv("VIEWER_OVERFLOW_POLICY"))
+	if err != nil {
+		logger.Logger.Fatal(fmt.Sprintf("%v, exiting...", err))
+	}
+	localViewers := service.NewViewerService(consts.MaxSubscriptionsPerUser, consts.ViewerBufferSize, overflow)
+
+	localInbox := service.NewInboxService(consts.MaxSubscriptionsPerUser, consts.ViewerBufferSize)
+
+	// с postgres события и уведомления рассылаются через LISTEN/NOTIFY, чтобы их получали подписчики всех инстансов
+	var viewerServ service.ViewerService = localViewers
+	var inboxServ service.InboxService = localInbox
+	listenCtx, stopListen := context.WithCancel(context.Background())
+	defer stopListen()
+	if pool != nil {
+		pgViewers := service.NewViewerServicePg(pool, commStore, localViewers)
+		go pgViewers.Listen(listenCtx)
+		viewerServ = pgViewers
+
+		pgInbox := service.NewInboxServicePg(pool, localInbox)
+		go pgInbox.Listen(listenCtx)
+		inboxServ = pgInbox
+	}
+	notifServ := service.NewNotificationService(notifStore, commStore, postStore, inboxServ)
+	voteServ := service.NewVoteService(voteStore, postStore, commStore)
+	userServ := service.NewUserService(userStore, tokens)
+	modServ := service.NewModerationService(modStore, postStore, commStore, communityStore, userStore)
+	promoteCtx, cancelPromote := context.WithTimeout(context.Background(), consts.PgxTimeout)
+	err = modServ.PromoteAdmins(promoteCtx, envList("ADMIN_USERNAMES"))
+	cancelPromote()
+	if err != nil {
+		logger.Logger.Fatal(fmt.Sprintf("%v, exiting...", err))
+	}
+	hideThreshold, err := envInt("REPORT_HIDE_THRESHOLD", consts.ReportHideThreshold)
+	if err != nil {
+		logger.Logger.Fatal(fmt.Sprintf("%v, exiting...", err))
+	}
+	reportServ := service.NewReportService(reportStore, postStore, commStore, communityStore, userStore, hideThreshold)
+	rateLimits, err := service.ParseRateLimits(consts.DefaultRateLimits, os.Getenv("RATE_LIMITS"))
+	if err != nil {
+		logger.Logger.Fatal(fmt.Sprintf("%v, exiting...", err))
+	}
+	rateLimitServ := service.NewRateLimitService(rateLimitStore, rateLimits)
 
 	// Init ROUTER n start SERVER
-	hand := handler.New(graphql.NewExecutableSchema(graphql.Config{Resolvers: &resolvers.Resolver{
+	router := newRouter(&resolvers.Resolver{
 		PostService:    postServ,
 		CommentService: commServ,
 		ViewerService:  viewerServ,
-	}}))
-	hand.AddTransport(transport.POST{})    // поддержка post
-	hand.AddTransport(transport.GET{})     // поддержка get
-	hand.AddTransport(transport.Websocket{ // поддержка вебсокетов
-		Upgrader: websocket.Upgrader{
-			CheckOrigin: func(r *http.Request) bool {
-				return true
-			},
-		},
-	})
-
-	router := utils.NewGinRouter()
+		VoteService:    voteServ,
+		UserService:    userServ,
 
-	// Init ENDPOINTS
-	router.POST("/query", gin.WrapH(hand))
-	router.GET("/query", gin.WrapH(hand))
-	router.GET("/", gin.WrapH(playground.Handler("graphQL playground", "/query")))
+		NotificationService: notifServ,
+		InboxService:        inboxServ,
+		CommunityService:    communityServ,
+		ModerationService:   modServ,
+		ReportService:       reportServ,
+	}, tokens, rateLimitServ, websocketConfig{
+		allowedOrigins: envList("WS_ALLOWED_ORIGINS"),
+		initTimeout:    consts.WebsocketInitTimeout,
+		pingInterval:   consts.WebsocketPingInterval,
+	})
+	// без доверенных прокси IP клиента для ограничителя частоты - адрес соединения, X-Forwarded-For не учитывается
+	if err = router.SetTrustedProxies(envList("TRUSTED_PROXIES")); err != nil {
+		logger.Logger.Fatal(fmt.Sprintf("invalid TRUSTED_PROXIES: %v, exiting...", err))
+	}
 
 	srv := server.NewAPIServer(router)
 
@@ -105,3 +182,52 @@ func Run() {
 			"error", err)
 	}
 }
+
+// фильтры контента постов и комментариев из переменных окружения
+func newContentFilters(postStore storage.PostStorage,
+	commStore storage.CommentStorage) (*service.ContentFilterChain, error) {
+	mode, err := service.ParseFilterMode(os.Getenv("CONTENT_FILTER_MODE"))
+	if err != nil {
+		return nil, err
+	}
+
+	maxPostLinks, err := envInt("MAX_POST_LINKS", consts.MaxPostLinks)
+	if err != nil {
+		return nil, err
+	}
+	maxCommentLinks, err := envInt("MAX_COMMENT_LINKS", consts.MaxCommentLinks)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.NewContentFilterChain(mode,
+		service.NewBannedWordsFilter(envList("BANNED_WORDS")),
+		service.NewLinkFilter(maxPostLinks, maxCommentLinks),
+		service.NewDuplicateFilter(postStore, commStore, consts.DuplicateWindow, consts.DuplicateMinLen),
+	), nil
+}
+
+// значения переменной окружения, перечисленные через запятую
+func envList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// неотрицательное целое из переменной окружения, если она не задана - def
+func envInt(key string, def int) (int, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return def, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s: %q", key, value)
+	}
+	return n, nil
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/nedokyrill/posts-service/internal/service"
+	"github.com/nedokyrill/posts-service/pkg/utils"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// rateLimiter - расширение gqlgen, ограничивающее частоту мутаций и подписок. Токен списывается за каждое
+// корневое поле операции: под разными псевдонимами одна мутация выполняется в запросе несколько раз
+type rateLimiter struct {
+	limits service.RateLimitService
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationInterceptor
+} = rateLimiter{}
+
+func (rateLimiter) ExtensionName() string {
+	return "RateLimiter"
+}
+
+func (rateLimiter) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (l rateLimiter) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	opCtx := graphql.GetOperationContext(ctx)
+
+	var rootType string
+	switch opCtx.Operation.Operation {
+	case ast.Mutation:
+		rootType = "Mutation"
+	case ast.Subscription:
+		rootType = "Subscription"
+	default:
+		return next(ctx)
+	}
+
+	for _, field := range graphql.CollectFields(opCtx, opCtx.Operation.SelectionSet, []string{rootType}) {
+		if strings.HasPrefix(field.Name, "__") {
+			continue
+		}
+
+		if err := l.limits.Allow(ctx, field.Name); err != nil {
+			var gqlErr utils.GqlError
+			if errors.As(err, &gqlErr) {
+				err = &gqlerror.Error{Message: gqlErr.Msg, Extensions: gqlErr.Extensions()}
+			}
+			return graphql.OneShot(&graphql.Response{Errors: gqlerror.List{gqlerror.WrapIfUnwrapped(err)}})
+		}
+	}
+	return next(ctx)
+}
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	start := time.Now()
+	srv := newLimitedTestServer(t, websocketConfig{initTimeout: time.Second, pingInterval: time.Minute},
+		map[string]models.RateLimit{
+			"CreatePost": {Burst: 1, Per: time.Hour},
+			"AddComment": {Burst: 1, Per: time.Hour},
+			"*":          {Burst: 3, Per: time.Hour},
+		})
+	token, postID := srv.newPost()
+
+	// корзины восполняются, пока идет тест (bcrypt в Register под -race медленный), поэтому ожидание может быть
+	// меньше полного на время, прошедшее с начала теста
+	requireLimited := func(res gqlResponse, retryAfter time.Duration) {
+		require.Len(t, res.Errors, 1)
+		assert.Equal(t, consts.RateLimitedType, res.Errors[0].Extensions["type"])
+		got, ok := res.Errors[0].Extensions["retryAfter"].(float64)
+		require.True(t, ok)
+		assert.LessOrEqual(t, got, retryAfter.Seconds())
+		assert.Greater(t, got, (retryAfter-time.Since(start)).Seconds()-1)
+	}
+
+	t.Run("limit repeated mutation", func(t *testing.T) {
+		requireLimited(srv.do(token, `mutation { CreatePost(title: "test", content: "test") { id } }`), time.Hour)
+
+		// у другого пользователя своя корзина, но с того же адреса его запросы списываются и из корзины IP
+		other := srv.register("other_user")
+		requireLimited(srv.do(other, `mutation { CreatePost(title: "test", content: "test") { id } }`), time.Hour)
+	})
+
+	t.Run("count aliased mutations", func(t *testing.T) {
+		requireLimited(srv.do(token, fmt.Sprintf(`mutation {
+			first: AddComment(postId: "%[1]s", content: "first") { id }
+			second: AddComment(postId: "%[1]s", content: "second") { id }
+		}`, postID)), time.Hour)
+
+		// операция отклоняется целиком
+		data := srv.query("", fmt.Sprintf(`{ GetPostById(id: "%s") { commentCount } }`, postID))
+		assert.Equal(t, float64(0), data["GetPostById"].(map[string]any)["commentCount"])
+	})
+
+	t.Run("limit anonymous clients by ip", func(t *testing.T) {
+		// test_user и other_user уже зарегистрировались с этого адреса
+		srv.register("third_user")
+		requireLimited(srv.do("", `mutation { Register(username: "fourth_user", password: "password") {
+			accessToken } }`), time.Hour/3)
+	})
+
+	t.Run("skip queries", func(t *testing.T) {
+		for range 5 {
+			srv.query("", `{ GetAllPosts { totalCount } }`)
+		}
+	})
+}
+
+func TestRateLimiter_Subscription(t *testing.T) {
+	srv := newLimitedTestServer(t, websocketConfig{initTimeout: time.Second, pingInterval: time.Minute},
+		map[string]models.RateLimit{"SubOnPost": {Burst: 1, Per: time.Minute}})
+	token, postID := srv.newPost()
+
+	conn := srv.dial("graphql-transport-ws")
+	send(t, conn, wsMessage{Type: "connection_init", Payload: initPayload(token)})
+	readUntil(t, conn, "connection_ack")
+
+	send(t, conn, wsMessage{ID: "1", Type: "subscribe", Payload: subscriptionPayload(t, postID)})
+	send(t, conn, wsMessage{ID: "2", Type: "subscribe", Payload: subscriptionPayload(t, postID)})
+
+	// подписки запускаются параллельно, поэтому отклонена может быть любая из двух
+	limited := readUntil(t, conn, "next")
+	var res gqlResponse
+	require.NoError(t, json.Unmarshal(limited.Payload, &res))
+	require.Len(t, res.Errors, 1)
+	assert.Equal(t, consts.RateLimitedType, res.Errors[0].Extensions["type"])
+	assert.Equal(t, limited.ID, readUntil(t, conn, "complete").ID)
+}
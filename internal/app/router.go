@@ -0,0 +1,68 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/99designs/gqlgen/graphql/playground"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/nedokyrill/posts-service/graphql"
+	"github.com/nedokyrill/posts-service/internal/auth"
+	"github.com/nedokyrill/posts-service/internal/loaders"
+	"github.com/nedokyrill/posts-service/internal/resolvers"
+	"github.com/nedokyrill/posts-service/internal/service"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+	"github.com/nedokyrill/posts-service/pkg/logger"
+	"github.com/nedokyrill/posts-service/pkg/server"
+	"github.com/nedokyrill/posts-service/pkg/utils"
+)
+
+// настройки вебсокет-транспорта подписок
+type websocketConfig struct {
+	allowedOrigins []string
+	initTimeout    time.Duration // сколько ждать connection_init после открытия соединения
+	pingInterval   time.Duration // интервал keep-alive (graphql-ws) и ping/pong (graphql-transport-ws)
+}
+
+func newRouter(resolver *resolvers.Resolver, tokens *auth.TokenManager, limits service.RateLimitService,
+	ws websocketConfig) *gin.Engine {
+	hand := handler.New(graphql.NewExecutableSchema(graphql.Config{Resolvers: resolver}))
+	hand.AddTransport(transport.SSE{ // подписки через Server-Sent Events, должен идти до POST
+		KeepAlivePingInterval: consts.SSEKeepAliveInterval,
+	})
+	hand.AddTransport(transport.POST{}) // поддержка post
+	hand.AddTransport(transport.GET{})  // поддержка get
+	// поддержка вебсокетов, протокол выбирается по Sec-WebSocket-Protocol: graphql-transport-ws или graphql-ws
+	// (и он же, если клиент протокол не передал)
+	hand.AddTransport(transport.Websocket{
+		Upgrader: websocket.Upgrader{
+			CheckOrigin: auth.CheckOrigin(ws.allowedOrigins),
+		},
+		InitFunc:              auth.WebsocketInitFunc(tokens), // токен для подписок передается в connection_init
+		InitTimeout:           ws.initTimeout,
+		KeepAlivePingInterval: ws.pingInterval, // graphql-ws: сервер шлет ka
+		PingPongInterval:      ws.pingInterval, // graphql-transport-ws: сервер шлет ping и закрывает соединение без pong
+		ErrorFunc: func(_ context.Context, err error) {
+			logger.Logger.Warnf("websocket error: %v", err)
+		},
+		CloseFunc: func(_ context.Context, closeCode int) {
+			logger.Logger.Infof("websocket closed with code %d", closeCode)
+		},
+	})
+	// ограничение частоты мутаций и подписок
+	hand.Use(rateLimiter{limits: limits})
+	// батчинг загрузки комментариев и ответов
+	hand.AroundResponses(loaders.Middleware(resolver.CommentService, resolver.VoteService, resolver.CommunityService))
+
+	router := utils.NewGinRouter()
+
+	// Init ENDPOINTS
+	router.POST("/query", auth.ClientIP(), auth.Middleware(tokens), server.SSEMiddleware(), gin.WrapH(hand))
+	router.GET("/query", auth.ClientIP(), auth.Middleware(tokens), gin.WrapH(hand))
+	router.GET("/", gin.WrapH(playground.Handler("graphQL playground", "/query")))
+
+	return router
+}
@@ -0,0 +1,431 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/nedokyrill/posts-service/internal/auth"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/internal/resolvers"
+	"github.com/nedokyrill/posts-service/internal/service"
+	"github.com/nedokyrill/posts-service/internal/storage/mem"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+	"github.com/nedokyrill/posts-service/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	// инициализация логгера в тестовой среде
+	log, _ := zap.NewDevelopment()
+	logger.Logger = log.Sugar()
+	gin.SetMode(gin.TestMode)
+	m.Run()
+}
+
+// wsMessage - сообщение протоколов graphql-ws и graphql-transport-ws
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+type testServer struct {
+	*httptest.Server
+	t *testing.T
+}
+
+// сервер приложения с хранением в памяти
+func newTestServer(t *testing.T, ws websocketConfig) *testServer {
+	return newLimitedTestServer(t, ws, nil)
+}
+
+// сервер приложения с хранением в памяти и ограничениями частоты операций
+func newLimitedTestServer(t *testing.T, ws websocketConfig, limits map[string]models.RateLimit) *testServer {
+	posts := mem.NewPostStorageMem()
+	comms := mem.NewCommentsStorageMem(posts)
+	users, communities := mem.NewUserStorageMem(), mem.NewCommunityStorageMem()
+	moderation := mem.NewModerationStorageMem(posts, comms, users, communities)
+	tokens := auth.NewTokenManager("secret", time.Minute, time.Hour)
+	inbox := service.NewInboxService(consts.MaxSubscriptionsPerUser, consts.ViewerBufferSize)
+
+	router := newRouter(&resolvers.Resolver{
+		PostService:    service.NewPostService(posts, communities, users, nil),
+		CommentService: service.NewCommentService(comms, posts, communities, users, nil),
+		ViewerService:  service.NewViewerService(consts.MaxSubscriptionsPerUser, consts.ViewerBufferSize, service.OverflowDropOldest),
+		VoteService:    service.NewVoteService(mem.NewVoteStorageMem(posts, comms), posts, comms),
+		UserService:    service.NewUserService(users, tokens),
+
+		NotificationService: service.NewNotificationService(mem.NewNotificationStorageMem(), comms, posts, inbox),
+		InboxService:        inbox,
+		CommunityService:    service.NewCommunityService(communities),
+		ModerationService:   service.NewModerationService(moderation, posts, comms, communities, users),
+		ReportService: service.NewReportService(mem.NewReportStorageMem(moderation), posts, comms, communities, users,
+			consts.ReportHideThreshold),
+	}, tokens, service.NewRateLimitService(mem.NewRateLimitStorageMem(), limits), ws)
+
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	return &testServer{Server: srv, t: t}
+}
+
+type gqlResponse struct {
+	Data   map[string]any `json:"data"`
+	Errors []struct {
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+// выполняет запрос и возвращает ответ вместе с ошибками
+func (s *testServer) do(token, query string) gqlResponse {
+	body, err := json.Marshal(map[string]string{"query": query})
+	require.NoError(s.t, err)
+
+	req, err := http.NewRequest(http.MethodPost, s.URL+"/query", bytes.NewReader(body))
+	require.NoError(s.t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(s.t, err)
+	defer resp.Body.Close()
+
+	var res gqlResponse
+	require.NoError(s.t, json.NewDecoder(resp.Body).Decode(&res))
+	return res
+}
+
+// выполняет запрос и возвращает data ответа
+func (s *testServer) query(token, query string) map[string]any {
+	res := s.do(token, query)
+	require.Empty(s.t, res.Errors)
+	return res.Data
+}
+
+// регистрирует пользователя и возвращает его access-токен
+func (s *testServer) register(username string) string {
+	data := s.query("", fmt.Sprintf(`mutation { Register(username: "%s", password: "password") { accessToken } }`,
+		username))
+	return data["Register"].(map[string]any)["accessToken"].(string)
+}
+
+// регистрирует пользователя и создает пост, возвращает токен и id поста
+func (s *testServer) newPost() (string, string) {
+	token := s.register("test_user")
+
+	data := s.query(token, `mutation { CreatePost(title: "test", content: "test", isCommentAllowed: true) { id } }`)
+	return token, data["CreatePost"].(map[string]any)["id"].(string)
+}
+
+func (s *testServer) addComment(token, postID, content string) {
+	s.query(token, fmt.Sprintf(`mutation { AddComment(postId: "%s", content: "%s") { id } }`, postID, content))
+}
+
+func (s *testServer) dial(subprotocol string) *websocket.Conn {
+	dialer := websocket.Dialer{Subprotocols: []string{subprotocol}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(s.URL, "http")+"/query", nil)
+	require.NoError(s.t, err)
+	s.t.Cleanup(func() { _ = conn.Close() })
+
+	assert.Equal(s.t, subprotocol, conn.Subprotocol())
+	return conn
+}
+
+func send(t *testing.T, conn *websocket.Conn, msg wsMessage) {
+	require.NoError(t, conn.WriteJSON(msg))
+}
+
+// читает сообщения до сообщения с типом msgType, пропуская keep-alive и отвечая на ping
+func readUntil(t *testing.T, conn *websocket.Conn, msgType string) wsMessage {
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	for {
+		var msg wsMessage
+		require.NoError(t, conn.ReadJSON(&msg))
+
+		switch {
+		case msg.Type == msgType:
+			return msg
+		case msg.Type == "ping":
+			send(t, conn, wsMessage{Type: "pong"})
+		case msg.Type != "ka" && msg.Type != "pong":
+			require.Failf(t, "unexpected message", "%+v", msg)
+		}
+	}
+}
+
+func commentContent(t *testing.T, msg wsMessage) string {
+	var payload struct {
+		Data struct {
+			SubOnPost struct {
+				Content string `json:"content"`
+			} `json:"SubOnPost"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(msg.Payload, &payload))
+	return payload.Data.SubOnPost.Content
+}
+
+func subscriptionPayload(t *testing.T, postID string) json.RawMessage {
+	// since раньше создания комментариев: комментарий, добавленный до регистрации подписки, придет из истории
+	query := fmt.Sprintf(`subscription { SubOnPost(postId: "%s", since: "%s") { ... on Comment { content } } }`,
+		postID, time.Now().Add(-time.Minute).Format(time.RFC3339Nano))
+
+	payload, err := json.Marshal(map[string]string{"query": query})
+	require.NoError(t, err)
+	return payload
+}
+
+func initPayload(token string) json.RawMessage {
+	return json.RawMessage(fmt.Sprintf(`{"Authorization": "Bearer %s"}`, token))
+}
+
+func TestWebsocket_GraphqlTransportWS(t *testing.T) {
+	srv := newTestServer(t, websocketConfig{initTimeout: time.Second, pingInterval: time.Minute})
+	token, postID := srv.newPost()
+
+	conn := srv.dial("graphql-transport-ws")
+	send(t, conn, wsMessage{Type: "connection_init", Payload: initPayload(token)})
+	readUntil(t, conn, "connection_ack")
+
+	send(t, conn, wsMessage{ID: "1", Type: "subscribe", Payload: subscriptionPayload(t, postID)})
+
+	srv.addComment(token, postID, "first")
+	msg := readUntil(t, conn, "next")
+	assert.Equal(t, "1", msg.ID)
+	assert.Equal(t, "first", commentContent(t, msg))
+
+	// к этому моменту подписка точно зарегистрирована, комментарий приходит как новое событие
+	srv.addComment(token, postID, "second")
+	assert.Equal(t, "second", commentContent(t, readUntil(t, conn, "next")))
+
+	send(t, conn, wsMessage{ID: "1", Type: "complete"})
+	send(t, conn, wsMessage{Type: "ping"})
+	readUntil(t, conn, "pong")
+}
+
+func TestWebsocket_GraphqlWS(t *testing.T) {
+	srv := newTestServer(t, websocketConfig{initTimeout: time.Second, pingInterval: time.Minute})
+	token, postID := srv.newPost()
+
+	conn := srv.dial("graphql-ws")
+	send(t, conn, wsMessage{Type: "connection_init", Payload: initPayload(token)})
+	readUntil(t, conn, "connection_ack")
+
+	send(t, conn, wsMessage{ID: "1", Type: "start", Payload: subscriptionPayload(t, postID)})
+
+	srv.addComment(token, postID, "first")
+	msg := readUntil(t, conn, "data")
+	assert.Equal(t, "1", msg.ID)
+	assert.Equal(t, "first", commentContent(t, msg))
+
+	srv.addComment(token, postID, "second")
+	assert.Equal(t, "second", commentContent(t, readUntil(t, conn, "data")))
+
+	send(t, conn, wsMessage{ID: "1", Type: "stop"})
+	readUntil(t, conn, "complete")
+}
+
+func TestWebsocket_CommentsStatus(t *testing.T) {
+	srv := newTestServer(t, websocketConfig{initTimeout: time.Second, pingInterval: time.Minute})
+	token, postID := srv.newPost()
+
+	conn := srv.dial("graphql-transport-ws")
+	send(t, conn, wsMessage{Type: "connection_init", Payload: initPayload(token)})
+	readUntil(t, conn, "connection_ack")
+
+	query := fmt.Sprintf(`subscription { SubOnPost(postId: "%s", since: "%s") {
+		... on Comment { content }
+		... on CommentsStatus { isCommentsAllowed } } }`, postID, time.Now().Add(-time.Minute).Format(time.RFC3339Nano))
+	payload, err := json.Marshal(map[string]string{"query": query})
+	require.NoError(t, err)
+	send(t, conn, wsMessage{ID: "1", Type: "subscribe", Payload: payload})
+
+	// после комментария подписка точно зарегистрирована
+	srv.addComment(token, postID, "first")
+	assert.Equal(t, "first", commentContent(t, readUntil(t, conn, "next")))
+
+	isCommentsAllowed := func(msg wsMessage) bool {
+		var payload struct {
+			Data struct {
+				SubOnPost struct {
+					IsCommentsAllowed bool `json:"isCommentsAllowed"`
+				} `json:"SubOnPost"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(msg.Payload, &payload))
+		return payload.Data.SubOnPost.IsCommentsAllowed
+	}
+	updatePost := fmt.Sprintf(`mutation { UpdatePost(id: "%s", title: "test", content: "test",
+		isCommentAllowed: false) { isCommentsAllowed } }`, postID)
+
+	// закрытие комментариев через UpdatePost приходит подписчикам, как и через SetCommentsAllowed
+	data := srv.query(token, updatePost)
+	assert.Equal(t, false, data["UpdatePost"].(map[string]any)["isCommentsAllowed"])
+	assert.False(t, isCommentsAllowed(readUntil(t, conn, "next")))
+
+	// без изменения флага события нет: следующим приходит открытие комментариев
+	srv.query(token, updatePost)
+	srv.query(token, fmt.Sprintf(`mutation { SetCommentsAllowed(postId: "%s", allowed: true) { id } }`, postID))
+	assert.True(t, isCommentsAllowed(readUntil(t, conn, "next")))
+}
+
+func TestWebsocket_PostActivity(t *testing.T) {
+	srv := newTestServer(t, websocketConfig{initTimeout: time.Second, pingInterval: time.Minute})
+	token, postID := srv.newPost()
+
+	activity := srv.dial("graphql-transport-ws")
+	send(t, activity, wsMessage{Type: "connection_init", Payload: initPayload(token)})
+	readUntil(t, activity, "connection_ack")
+
+	query := fmt.Sprintf(`subscription { PostActivity(postId: "%s") { commentCount viewers } }`, postID)
+	payload, err := json.Marshal(map[string]string{"query": query})
+	require.NoError(t, err)
+	send(t, activity, wsMessage{ID: "1", Type: "subscribe", Payload: payload})
+
+	next := func() string {
+		var res struct {
+			Data struct {
+				PostActivity struct {
+					CommentCount int32 `json:"commentCount"`
+					Viewers      int32 `json:"viewers"`
+				} `json:"PostActivity"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(readUntil(t, activity, "next").Payload, &res))
+		return fmt.Sprintf("comments=%d viewers=%d", res.Data.PostActivity.CommentCount, res.Data.PostActivity.Viewers)
+	}
+	assert.Equal(t, "comments=0 viewers=0", next())
+
+	reader := srv.dial("graphql-transport-ws")
+	send(t, reader, wsMessage{Type: "connection_init", Payload: initPayload(token)})
+	readUntil(t, reader, "connection_ack")
+	send(t, reader, wsMessage{ID: "1", Type: "subscribe", Payload: subscriptionPayload(t, postID)})
+	assert.Equal(t, "comments=0 viewers=1", next())
+
+	srv.addComment(token, postID, "first")
+	assert.Equal(t, "comments=1 viewers=1", next())
+
+	data := srv.query("", fmt.Sprintf(`{ GetPostById(id: "%s") { commentCount } }`, postID))
+	assert.Equal(t, float64(1), data["GetPostById"].(map[string]any)["commentCount"])
+
+	send(t, reader, wsMessage{ID: "1", Type: "complete"})
+	assert.Equal(t, "comments=1 viewers=0", next())
+}
+
+func TestWebsocket_NotificationAdded(t *testing.T) {
+	srv := newTestServer(t, websocketConfig{initTimeout: time.Second, pingInterval: time.Minute})
+	token, postID := srv.newPost()
+	replier := srv.register("replier")
+
+	conn := srv.dial("graphql-transport-ws")
+	send(t, conn, wsMessage{Type: "connection_init", Payload: initPayload(token)})
+	readUntil(t, conn, "connection_ack")
+
+	payload, err := json.Marshal(map[string]string{"query": `subscription { NotificationAdded { id type actor } }`})
+	require.NoError(t, err)
+	send(t, conn, wsMessage{ID: "1", Type: "subscribe", Payload: payload})
+
+	received := make(chan wsMessage, 10)
+	go func() {
+		for {
+			var msg wsMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			if msg.Type == "next" {
+				received <- msg
+			}
+		}
+	}()
+
+	// подписка регистрируется асинхронно, поэтому комментируем, пока не придет уведомление
+	comments := 0
+	var msg wsMessage
+	require.Eventually(t, func() bool {
+		srv.addComment(replier, postID, "comment")
+		comments++
+		select {
+		case msg = <-received:
+			return true
+		case <-time.After(50 * time.Millisecond):
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+
+	var res struct {
+		Data struct {
+			NotificationAdded struct {
+				ID    string `json:"id"`
+				Type  string `json:"type"`
+				Actor string `json:"actor"`
+			} `json:"NotificationAdded"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(msg.Payload, &res))
+	assert.Equal(t, "COMMENT", res.Data.NotificationAdded.Type)
+	assert.Equal(t, "replier", res.Data.NotificationAdded.Actor)
+
+	data := srv.query(token, `{ MyNotifications(first: 10) { unreadCount edges { node { id isRead } } } }`)
+	inbox := data["MyNotifications"].(map[string]any)
+	assert.Equal(t, float64(comments), inbox["unreadCount"])
+
+	data = srv.query(token, fmt.Sprintf(`mutation { MarkNotificationsRead(ids: ["%s"]) }`,
+		res.Data.NotificationAdded.ID))
+	assert.Equal(t, float64(1), data["MarkNotificationsRead"])
+}
+
+func TestWebsocket_Connection(t *testing.T) {
+	t.Run("close when connection_init has no token", func(t *testing.T) {
+		srv := newTestServer(t, websocketConfig{initTimeout: time.Second, pingInterval: time.Minute})
+
+		conn := srv.dial("graphql-transport-ws")
+		send(t, conn, wsMessage{Type: "connection_init"})
+
+		_, _, err := conn.ReadMessage()
+		assert.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure), err)
+	})
+
+	t.Run("close when connection_init is not sent in time", func(t *testing.T) {
+		srv := newTestServer(t, websocketConfig{initTimeout: 50 * time.Millisecond, pingInterval: time.Minute})
+
+		conn := srv.dial("graphql-transport-ws")
+
+		_, _, err := conn.ReadMessage()
+		assert.True(t, websocket.IsCloseError(err, websocket.CloseProtocolError), err)
+	})
+
+	t.Run("server pings graphql-transport-ws clients", func(t *testing.T) {
+		srv := newTestServer(t, websocketConfig{initTimeout: time.Second, pingInterval: 50 * time.Millisecond})
+		token, _ := srv.newPost()
+
+		conn := srv.dial("graphql-transport-ws")
+		send(t, conn, wsMessage{Type: "connection_init", Payload: initPayload(token)})
+		readUntil(t, conn, "connection_ack")
+
+		var msg wsMessage
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		require.NoError(t, conn.ReadJSON(&msg))
+		assert.Equal(t, "ping", msg.Type)
+	})
+
+	t.Run("reject foreign origin", func(t *testing.T) {
+		srv := newTestServer(t, websocketConfig{allowedOrigins: []string{"https://app.example.com"}})
+
+		header := http.Header{"Origin": []string{"https://evil.example.com"}}
+		dialer := websocket.Dialer{Subprotocols: []string{"graphql-transport-ws"}}
+		_, resp, err := dialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/query", header)
+		require.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+}
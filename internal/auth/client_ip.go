@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+type clientIPKey struct{}
+
+// ClientIP кладет в контекст запроса IP клиента. За прокси он берется из X-Forwarded-For, только если прокси
+// перечислены в доверенных у роутера, иначе клиент мог бы подставить любой адрес
+func ClientIP() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(WithClientIP(c.Request.Context(), c.ClientIP()))
+		c.Next()
+	}
+}
+
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// ClientIPFrom возвращает IP клиента запроса, пустую строку - если он неизвестен
+func ClientIPFrom(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
@@ -0,0 +1,25 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type ctxKey struct{}
+
+// Identity - аутентифицированный пользователь, от имени которого выполняется запрос
+type Identity struct {
+	UserID   uuid.UUID
+	Username string
+}
+
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, ctxKey{}, identity)
+}
+
+// IdentityFrom возвращает пользователя запроса, false - запрос анонимный
+func IdentityFrom(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(ctxKey{}).(Identity)
+	return identity, ok
+}
@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware кладет в контекст запроса пользователя из заголовка "Authorization: Bearer <access token>".
+// Запрос без заголовка проходит как анонимный, а с неверным или истекшим токеном отклоняется
+func Middleware(tokens *TokenManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if header == "" {
+			c.Next()
+			return
+		}
+
+		token, ok := bearerToken(header)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authorization header must be Bearer token"})
+			return
+		}
+
+		identity, err := tokens.ParseAccess(token)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired access token"})
+			return
+		}
+
+		c.Request = c.Request.WithContext(WithIdentity(c.Request.Context(), identity))
+		c.Next()
+	}
+}
+
+func bearerToken(header string) (string, bool) {
+	return strings.CutPrefix(header, "Bearer ")
+}
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	accessTokenType  = "access"
+	refreshTokenType = "refresh"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+type claims struct {
+	Username string `json:"username"`
+	Type     string `json:"typ"` // access или refresh, чтобы refresh-токен нельзя было передать вместо access
+	jwt.RegisteredClaims
+}
+
+// TokenManager выпускает и проверяет подписанные (HS256) access и refresh токены
+type TokenManager struct {
+	secret     []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+func NewTokenManager(secret string, accessTTL, refreshTTL time.Duration) *TokenManager {
+	return &TokenManager{
+		secret:     []byte(secret),
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+	}
+}
+
+// Issue выпускает пару access и refresh токенов для пользователя
+func (m *TokenManager) Issue(identity Identity) (string, string, error) {
+	access, err := m.sign(identity, accessTokenType, m.accessTTL)
+	if err != nil {
+		return "", "", err
+	}
+
+	refresh, err := m.sign(identity, refreshTokenType, m.refreshTTL)
+	if err != nil {
+		return "", "", err
+	}
+	return access, refresh, nil
+}
+
+func (m *TokenManager) ParseAccess(token string) (Identity, error) {
+	return m.parse(token, accessTokenType)
+}
+
+func (m *TokenManager) ParseRefresh(token string) (Identity, error) {
+	return m.parse(token, refreshTokenType)
+}
+
+func (m *TokenManager) sign(identity Identity, tokenType string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
+		Username: identity.Username,
+		Type:     tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   identity.UserID.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			ID:        uuid.NewString(),
+		},
+	})
+	return token.SignedString(m.secret)
+}
+
+func (m *TokenManager) parse(token string, tokenType string) (Identity, error) {
+	var c claims
+	_, err := jwt.ParseWithClaims(token, &c, func(*jwt.Token) (any, error) {
+		return m.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if c.Type != tokenType {
+		return Identity{}, fmt.Errorf("%w: expected %s token, got %q", ErrInvalidToken, tokenType, c.Type)
+	}
+
+	userID, err := uuid.Parse(c.Subject)
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return Identity{UserID: userID, Username: c.Username}, nil
+}
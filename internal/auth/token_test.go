@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenManager(t *testing.T) {
+	tokens := NewTokenManager("secret", time.Minute, time.Hour)
+	identity := Identity{UserID: uuid.New(), Username: "test_user"}
+
+	t.Run("parse issued tokens", func(t *testing.T) {
+		access, refresh, err := tokens.Issue(identity)
+		require.NoError(t, err)
+
+		parsed, err := tokens.ParseAccess(access)
+		require.NoError(t, err)
+		assert.Equal(t, identity, parsed)
+
+		parsed, err = tokens.ParseRefresh(refresh)
+		require.NoError(t, err)
+		assert.Equal(t, identity, parsed)
+	})
+
+	t.Run("reject token of another type", func(t *testing.T) {
+		access, refresh, err := tokens.Issue(identity)
+		require.NoError(t, err)
+
+		_, err = tokens.ParseAccess(refresh)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+
+		_, err = tokens.ParseRefresh(access)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("reject token signed with another secret", func(t *testing.T) {
+		access, _, err := NewTokenManager("another_secret", time.Minute, time.Hour).Issue(identity)
+		require.NoError(t, err)
+
+		_, err = tokens.ParseAccess(access)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("reject expired token", func(t *testing.T) {
+		access, _, err := NewTokenManager("secret", -time.Minute, time.Hour).Issue(identity)
+		require.NoError(t, err)
+
+		_, err = tokens.ParseAccess(access)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tokens := NewTokenManager("secret", time.Minute, time.Hour)
+	identity := Identity{UserID: uuid.New(), Username: "test_user"}
+	access, refresh, err := tokens.Issue(identity)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.GET("/", Middleware(tokens), func(c *gin.Context) {
+		if identity, ok := IdentityFrom(c.Request.Context()); ok {
+			c.String(http.StatusOK, identity.Username)
+			return
+		}
+		c.String(http.StatusOK, "anonymous")
+	})
+
+	do := func(header string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("anonymous without header", func(t *testing.T) {
+		rec := do("")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "anonymous", rec.Body.String())
+	})
+
+	t.Run("identity from access token", func(t *testing.T) {
+		rec := do("Bearer " + access)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "test_user", rec.Body.String())
+	})
+
+	t.Run("reject refresh token", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, do("Bearer "+refresh).Code)
+	})
+
+	t.Run("reject non bearer header", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, do("Basic dXNlcjpwYXNz").Code)
+	})
+}
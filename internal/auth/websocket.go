@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+)
+
+// WebsocketInitFunc проверяет access-токен из payload сообщения connection_init
+// ({"Authorization": "Bearer <token>"}) и кладет пользователя в контекст подписок соединения.
+// Браузер не может передать заголовок при открытии вебсокета, поэтому токен передается в payload,
+// но если заголовок все же был, middleware уже положил пользователя в контекст
+func WebsocketInitFunc(tokens *TokenManager) transport.WebsocketInitFunc {
+	return func(ctx context.Context, payload transport.InitPayload) (context.Context, *transport.InitPayload, error) {
+		header := payload.Authorization()
+		if header == "" {
+			if _, ok := IdentityFrom(ctx); ok {
+				return ctx, nil, nil
+			}
+			return ctx, nil, errors.New("authorization token is required in connection_init payload")
+		}
+
+		token, ok := bearerToken(header)
+		if !ok {
+			token = header
+		}
+
+		identity, err := tokens.ParseAccess(token)
+		if err != nil {
+			return ctx, nil, errors.New("invalid or expired access token")
+		}
+		return WithIdentity(ctx, identity), nil, nil
+	}
+}
+
+// CheckOrigin разрешает вебсокет-соединения с того же хоста и из origin'ов списка allowed ("*" - любые).
+// Запросы без заголовка Origin приходят не из браузера и пропускаются, их защищает токен
+func CheckOrigin(allowed []string) func(r *http.Request) bool {
+	allowAny := slices.Contains(allowed, "*")
+
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" || allowAny {
+			return true
+		}
+
+		for _, o := range allowed {
+			if strings.EqualFold(strings.TrimSuffix(o, "/"), origin) {
+				return true
+			}
+		}
+
+		u, err := url.Parse(origin)
+		if err != nil {
+			return false
+		}
+		return strings.EqualFold(u.Host, r.Host)
+	}
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebsocketInitFunc(t *testing.T) {
+	tokens := NewTokenManager("secret", time.Minute, time.Hour)
+	identity := Identity{UserID: uuid.New(), Username: "test_user"}
+	access, refresh, err := tokens.Issue(identity)
+	require.NoError(t, err)
+
+	initFunc := WebsocketInitFunc(tokens)
+
+	t.Run("identity from payload token", func(t *testing.T) {
+		for _, header := range []string{"Bearer " + access, access} {
+			ctx, _, err := initFunc(context.Background(), transport.InitPayload{"Authorization": header})
+			require.NoError(t, err)
+
+			got, ok := IdentityFrom(ctx)
+			require.True(t, ok)
+			assert.Equal(t, identity, got)
+		}
+	})
+
+	t.Run("identity from upgrade request header", func(t *testing.T) {
+		_, _, err := initFunc(WithIdentity(context.Background(), identity), nil)
+		assert.NoError(t, err)
+	})
+
+	t.Run("reject without token", func(t *testing.T) {
+		_, _, err := initFunc(context.Background(), transport.InitPayload{})
+		assert.Error(t, err)
+	})
+
+	t.Run("reject refresh token", func(t *testing.T) {
+		_, _, err := initFunc(context.Background(), transport.InitPayload{"authorization": "Bearer " + refresh})
+		assert.Error(t, err)
+	})
+}
+
+func TestCheckOrigin(t *testing.T) {
+	request := func(origin string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "http://api.example.com/query", nil)
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		return r
+	}
+
+	checkOrigin := CheckOrigin([]string{"https://app.example.com"})
+
+	assert.True(t, checkOrigin(request("")))
+	assert.True(t, checkOrigin(request("https://app.example.com")))
+	assert.True(t, checkOrigin(request("http://api.example.com")))
+	assert.False(t, checkOrigin(request("https://evil.example.com")))
+
+	assert.True(t, CheckOrigin([]string{"*"})(request("https://evil.example.com")))
+}
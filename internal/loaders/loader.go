@@ -0,0 +1,86 @@
+package loaders
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// BatchFunc загружает значения сразу для нескольких ключей, отсутствующим ключам соответствует нулевое значение
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Loader собирает ключи, запрошенные резолверами за короткое время ожидания, и загружает их одним вызовом fetch.
+// Результаты кешируются на все время жизни загрузчика, поэтому загрузчик создается на один graphql-ответ
+type Loader[K comparable, V any] struct {
+	fetch    BatchFunc[K, V]
+	wait     time.Duration
+	maxBatch int
+
+	mu    sync.Mutex
+	cache map[K]*batch[K, V]
+	batch *batch[K, V] // текущая еще не отправленная пачка
+}
+
+type batch[K comparable, V any] struct {
+	keys    []K
+	results map[K]V
+	err     error
+	done    chan struct{}
+}
+
+func NewLoader[K comparable, V any](fetch BatchFunc[K, V], wait time.Duration, maxBatch int) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch:    fetch,
+		wait:     wait,
+		maxBatch: maxBatch,
+		cache:    make(map[K]*batch[K, V]),
+	}
+}
+
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	b, ok := l.cache[key]
+	if !ok {
+		if l.batch == nil {
+			l.batch = &batch[K, V]{done: make(chan struct{})}
+			current := l.batch
+			time.AfterFunc(l.wait, func() { l.dispatch(ctx, current) })
+		}
+
+		b = l.batch
+		b.keys = append(b.keys, key)
+		l.cache[key] = b
+
+		if len(b.keys) >= l.maxBatch { // пачка заполнена, не ждем таймера
+			l.batch = nil
+			go b.load(ctx, l.fetch)
+		}
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-b.done:
+		return b.results[key], b.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// отправка пачки по таймеру
+func (l *Loader[K, V]) dispatch(ctx context.Context, b *batch[K, V]) {
+	l.mu.Lock()
+	if l.batch != b { // пачка уже отправлена по заполнению
+		l.mu.Unlock()
+		return
+	}
+	l.batch = nil
+	l.mu.Unlock()
+
+	b.load(ctx, l.fetch)
+}
+
+func (b *batch[K, V]) load(ctx context.Context, fetch BatchFunc[K, V]) {
+	b.results, b.err = fetch(ctx, b.keys)
+	close(b.done)
+}
@@ -0,0 +1,109 @@
+package loaders
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// загрузчик, возвращающий удвоенные ключи и запоминающий пришедшие пачки
+func newDoubleLoader(maxBatch int, err error) (*Loader[int, int], *[][]int) {
+	var mu sync.Mutex
+	batches := make([][]int, 0)
+
+	loader := NewLoader(func(_ context.Context, keys []int) (map[int]int, error) {
+		mu.Lock()
+		batches = append(batches, keys)
+		mu.Unlock()
+
+		if err != nil {
+			return nil, err
+		}
+		res := make(map[int]int, len(keys))
+		for _, key := range keys {
+			res[key] = key * 2
+		}
+		return res, nil
+	}, 5*time.Millisecond, maxBatch)
+
+	return loader, &batches
+}
+
+func loadAll(t *testing.T, loader *Loader[int, int], keys []int) []int {
+	res := make([]int, len(keys))
+	errs := make([]error, len(keys))
+
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res[i], errs[i] = loader.Load(context.Background(), key)
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	return res
+}
+
+func TestLoader_Load(t *testing.T) {
+	t.Run("batches concurrent loads", func(t *testing.T) {
+		loader, batches := newDoubleLoader(100, nil)
+
+		res := loadAll(t, loader, []int{1, 2, 3, 4})
+
+		assert.Equal(t, []int{2, 4, 6, 8}, res)
+		require.Len(t, *batches, 1)
+		assert.ElementsMatch(t, []int{1, 2, 3, 4}, (*batches)[0])
+	})
+
+	t.Run("caches loaded keys", func(t *testing.T) {
+		loader, batches := newDoubleLoader(100, nil)
+
+		loadAll(t, loader, []int{1, 1, 2})
+		res := loadAll(t, loader, []int{1, 2})
+
+		assert.Equal(t, []int{2, 4}, res)
+		require.Len(t, *batches, 1)
+		assert.ElementsMatch(t, []int{1, 2}, (*batches)[0])
+	})
+
+	t.Run("splits batches by max size", func(t *testing.T) {
+		loader, batches := newDoubleLoader(2, nil)
+
+		res := loadAll(t, loader, []int{1, 2, 3, 4, 5})
+
+		assert.Equal(t, []int{2, 4, 6, 8, 10}, res)
+		assert.Len(t, *batches, 3)
+		for _, batch := range *batches {
+			assert.LessOrEqual(t, len(batch), 2)
+		}
+	})
+
+	t.Run("returns batch error to every caller", func(t *testing.T) {
+		loader, _ := newDoubleLoader(100, assert.AnError)
+
+		_, err := loader.Load(context.Background(), 1)
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+
+	t.Run("stops waiting when context is canceled", func(t *testing.T) {
+		loader := NewLoader(func(ctx context.Context, keys []int) (map[int]int, error) {
+			time.Sleep(time.Second)
+			return nil, nil
+		}, time.Millisecond, 100)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := loader.Load(ctx, 1)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,146 @@
+package loaders
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/internal/service"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+)
+
+type ctxKey struct{}
+
+type CommentLoader = Loader[uuid.UUID, *models.CommentConnection]
+type VoteLoader = Loader[uuid.UUID, int32]
+type CountLoader = Loader[uuid.UUID, int32]
+type CommunityLoader = Loader[uuid.UUID, *models.Community]
+type MembershipLoader = Loader[uuid.UUID, bool]
+
+// Loaders - загрузчики одного graphql-ответа. Аргументы полей (сортировка и пагинация у comments/replies, голосующий
+// у myVote) могут отличаться, поэтому в одну пачку попадают только запросы с одинаковыми аргументами
+type Loaders struct {
+	commServ      service.CommentService
+	voteServ      service.VoteService
+	communityServ service.CommunityService
+
+	mu           sync.Mutex
+	comments     map[string]*CommentLoader
+	replies      map[string]*CommentLoader
+	postVotes    map[string]*VoteLoader
+	commentVotes map[string]*VoteLoader
+	counts       map[string]*CountLoader
+	communities  map[string]*CommunityLoader
+	memberships  map[string]*MembershipLoader
+}
+
+func New(commServ service.CommentService, voteServ service.VoteService,
+	communityServ service.CommunityService) *Loaders {
+	return &Loaders{
+		commServ:      commServ,
+		voteServ:      voteServ,
+		communityServ: communityServ,
+		comments:      make(map[string]*CommentLoader),
+		replies:       make(map[string]*CommentLoader),
+		postVotes:     make(map[string]*VoteLoader),
+		commentVotes:  make(map[string]*VoteLoader),
+		counts:        make(map[string]*CountLoader),
+		communities:   make(map[string]*CommunityLoader),
+		memberships:   make(map[string]*MembershipLoader),
+	}
+}
+
+// Middleware создает новые загрузчики для каждого ответа: для запросов и мутаций он один,
+// а у подписки на каждое событие свой, чтобы не отдавать закешированные при прошлом событии данные
+func Middleware(commServ service.CommentService, voteServ service.VoteService,
+	communityServ service.CommunityService) graphql.ResponseMiddleware {
+	return func(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+		return next(context.WithValue(ctx, ctxKey{}, New(commServ, voteServ, communityServ)))
+	}
+}
+
+func For(ctx context.Context) *Loaders {
+	return ctx.Value(ctxKey{}).(*Loaders)
+}
+
+// CommentsByPostID - загрузчик страниц комментариев к постам
+func (l *Loaders) CommentsByPostID(sort models.CommentSort, page models.PageRequest) *CommentLoader {
+	return loaderFor(l, l.comments, string(sort)+pageKey(page), func(ctx context.Context,
+		ids []uuid.UUID) (map[uuid.UUID]*models.CommentConnection, error) {
+		return l.commServ.GetCommentsByPostIDs(ctx, ids, sort, page)
+	})
+}
+
+// CommentCounts - загрузчик количества всех комментариев к постам, аргументов нет, поэтому загрузчик один
+func (l *Loaders) CommentCounts() *CountLoader {
+	return loaderFor(l, l.counts, "", func(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]int32, error) {
+		return l.commServ.GetCommentCounts(ctx, ids)
+	})
+}
+
+// RepliesByCommentID - загрузчик страниц ответов на комментарии
+func (l *Loaders) RepliesByCommentID(sort models.CommentSort, page models.PageRequest) *CommentLoader {
+	return loaderFor(l, l.replies, string(sort)+pageKey(page), func(ctx context.Context,
+		ids []uuid.UUID) (map[uuid.UUID]*models.CommentConnection, error) {
+		return l.commServ.GetRepliesByCommentIDs(ctx, ids, sort, page)
+	})
+}
+
+// PostVotes - загрузчик голосов пользователя voter за посты
+func (l *Loaders) PostVotes(voter string) *VoteLoader {
+	return loaderFor(l, l.postVotes, voter, func(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]int32, error) {
+		return l.voteServ.GetPostVotes(ctx, ids, voter)
+	})
+}
+
+// CommentVotes - загрузчик голосов пользователя voter за комментарии
+func (l *Loaders) CommentVotes(voter string) *VoteLoader {
+	return loaderFor(l, l.commentVotes, voter, func(ctx context.Context,
+		ids []uuid.UUID) (map[uuid.UUID]int32, error) {
+		return l.voteServ.GetCommentVotes(ctx, ids, voter)
+	})
+}
+
+// Communities - загрузчик сообществ, в которых опубликованы посты
+func (l *Loaders) Communities() *CommunityLoader {
+	return loaderFor(l, l.communities, "", func(ctx context.Context,
+		ids []uuid.UUID) (map[uuid.UUID]*models.Community, error) {
+		return l.communityServ.GetCommunitiesByIDs(ctx, ids)
+	})
+}
+
+// CommunityMemberships - загрузчик членства пользователя username в сообществах
+func (l *Loaders) CommunityMemberships(username string) *MembershipLoader {
+	return loaderFor(l, l.memberships, username, func(ctx context.Context,
+		ids []uuid.UUID) (map[uuid.UUID]bool, error) {
+		return l.communityServ.GetMemberships(ctx, ids, username)
+	})
+}
+
+func loaderFor[V any](l *Loaders, loaders map[string]*Loader[uuid.UUID, V], key string,
+	fetch BatchFunc[uuid.UUID, V]) *Loader[uuid.UUID, V] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	loader, ok := loaders[key]
+	if !ok {
+		loader = NewLoader(fetch, consts.LoaderWait, consts.LoaderMaxBatch)
+		loaders[key] = loader
+	}
+	return loader
+}
+
+// ключ аргументов пагинации, у заданного аргумента есть префикс ":", чтобы отличать его от отсутствующего
+func pageKey(page models.PageRequest) string {
+	return fmt.Sprintf("|%s|%s|%s|%s", arg(page.First), arg(page.After), arg(page.Last), arg(page.Before))
+}
+
+func arg[T any](v *T) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprintf(":%v", *v)
+}
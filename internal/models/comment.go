@@ -13,12 +13,51 @@ type Comment struct {
 	PostID          uuid.UUID  `json:"postId"`
 	ParentCommentID *uuid.UUID `json:"parentCommentId,omitempty"`
 	//Replies         []*Comment `json:"replies,omitempty"`
-	CreatedAt *time.Time `json:"createdAt"`
+	IsDeleted  bool       `json:"isDeleted"`
+	IsRemoved  bool       `json:"isRemoved"`  // скрыт модератором
+	IsShadowed bool       `json:"isShadowed"` // скрыт фильтром контента, виден только автору
+	Upvotes    int32      `json:"upvotes"`
+	Downvotes  int32      `json:"downvotes"`
+	CreatedAt  *time.Time `json:"createdAt"`
+	UpdatedAt  *time.Time `json:"updatedAt,omitempty"`
+
+	// ключ сортировки BEST, в postgres это генерируемый столбец best
+	Best float64 `json:"-"`
+}
+
+func (c *Comment) Score() int32 {
+	return c.Upvotes - c.Downvotes
+}
+
+// UpdateRanking пересчитывает ключи сортировки после изменения голосов
+func (c *Comment) UpdateRanking() {
+	c.Best = WilsonScore(c.Upvotes, c.Downvotes)
+}
+
+// SortKey - значение ключа сортировки комментария при сортировке sort
+func (c *Comment) SortKey(sort CommentSort) float64 {
+	switch sort {
+	case CommentSortTop:
+		return float64(c.Score())
+	case CommentSortBest:
+		return c.Best
+	default:
+		return 0
+	}
+}
+
+// Desc - идут ли комментарии от больших значений ключей к меньшим (все сортировки, кроме OLD)
+func (s CommentSort) Desc() bool {
+	return s != CommentSortOld
 }
 
 type CommentRequest struct {
-	Author          string
 	Content         string
 	PostID          uuid.UUID
 	ParentCommentID *uuid.UUID
 }
+
+type CommentEditRequest struct {
+	ID      uuid.UUID
+	Content string
+}
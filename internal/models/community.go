@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Community - сообщество (хаб), объединяющее посты
+type Community struct {
+	ID            uuid.UUID     `json:"id"`
+	Slug          string        `json:"slug"`
+	Title         string        `json:"title"`
+	Description   string        `json:"description"`
+	Owner         string        `json:"owner"`
+	PostPolicy    PostPolicy    `json:"postPolicy"`
+	CommentPolicy CommentPolicy `json:"commentPolicy"`
+	MemberCount   int32         `json:"memberCount"`
+	CreatedAt     *time.Time    `json:"createdAt,omitempty"`
+}
+
+type CommunityRequest struct {
+	Slug          string
+	Title         string
+	Description   string
+	PostPolicy    PostPolicy
+	CommentPolicy CommentPolicy
+}
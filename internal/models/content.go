@@ -0,0 +1,24 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// ContentHash - хэш текста поста или комментария для поиска повторов: регистр, ё и пробелы не учитываются
+func ContentHash(text string) string {
+	normalized := strings.Join(strings.Fields(strings.ReplaceAll(strings.ToLower(text), "ё", "е")), " ")
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// VisibleTo - виден ли комментарий пользователю viewer: скрытый фильтром контента видит только автор
+func (c *Comment) VisibleTo(viewer string) bool {
+	return !c.IsShadowed || (viewer != "" && c.Author == viewer)
+}
+
+// VisibleTo - виден ли пост пользователю viewer: скрытый фильтром контента видит только автор
+func (p *Post) VisibleTo(viewer string) bool {
+	return !p.IsShadowed || (viewer != "" && p.Author == viewer)
+}
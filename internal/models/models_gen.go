@@ -2,11 +2,869 @@
 
 package models
 
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+type AuthPayload struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	User         *User  `json:"user"`
+}
+
+type CommentConnection struct {
+	Edges      []*CommentEdge `json:"edges"`
+	PageInfo   *PageInfo      `json:"pageInfo"`
+	TotalCount int32          `json:"totalCount"`
+}
+
+type CommentEdge struct {
+	Cursor string   `json:"cursor"`
+	Node   *Comment `json:"node"`
+}
+
+type CommentSearchResult struct {
+	Comment *Comment `json:"comment"`
+	Rank    float64  `json:"rank"`
+	Snippet string   `json:"snippet"`
+}
+
+type CommentTreeNode struct {
+	Comment *Comment    `json:"comment"`
+	Depth   int32       `json:"depth"`
+	Path    []uuid.UUID `json:"path"`
+}
+
+type ModerationActionConnection struct {
+	Edges    []*ModerationActionEdge `json:"edges"`
+	PageInfo *PageInfo               `json:"pageInfo"`
+}
+
+type ModerationActionEdge struct {
+	Cursor string            `json:"cursor"`
+	Node   *ModerationAction `json:"node"`
+}
+
 type Mutation struct {
 }
 
+type NotificationConnection struct {
+	Edges       []*NotificationEdge `json:"edges"`
+	PageInfo    *PageInfo           `json:"pageInfo"`
+	UnreadCount int32               `json:"unreadCount"`
+}
+
+type NotificationEdge struct {
+	Cursor string        `json:"cursor"`
+	Node   *Notification `json:"node"`
+}
+
+type PageInfo struct {
+	HasNextPage     bool    `json:"hasNextPage"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	StartCursor     *string `json:"startCursor,omitempty"`
+	EndCursor       *string `json:"endCursor,omitempty"`
+}
+
+type PostConnection struct {
+	Edges      []*PostEdge `json:"edges"`
+	PageInfo   *PageInfo   `json:"pageInfo"`
+	TotalCount int32       `json:"totalCount"`
+}
+
+type PostEdge struct {
+	Cursor string `json:"cursor"`
+	Node   *Post  `json:"node"`
+}
+
+type PostSearchConnection struct {
+	Edges      []*PostSearchEdge `json:"edges"`
+	PageInfo   *PageInfo         `json:"pageInfo"`
+	TotalCount int32             `json:"totalCount"`
+}
+
+type PostSearchEdge struct {
+	Cursor string            `json:"cursor"`
+	Node   *PostSearchResult `json:"node"`
+}
+
+type PostSearchResult struct {
+	Post    *Post   `json:"post"`
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
 type Query struct {
 }
 
+type ReportConnection struct {
+	Edges    []*ReportEdge `json:"edges"`
+	PageInfo *PageInfo     `json:"pageInfo"`
+}
+
+type ReportEdge struct {
+	Cursor string  `json:"cursor"`
+	Node   *Report `json:"node"`
+}
+
 type Subscription struct {
 }
+
+type Tag struct {
+	Name      string `json:"name"`
+	PostCount int32  `json:"postCount"`
+}
+
+type CommentPolicy string
+
+const (
+	CommentPolicyOpen   CommentPolicy = "OPEN"
+	CommentPolicyClosed CommentPolicy = "CLOSED"
+)
+
+var AllCommentPolicy = []CommentPolicy{
+	CommentPolicyOpen,
+	CommentPolicyClosed,
+}
+
+func (e CommentPolicy) IsValid() bool {
+	switch e {
+	case CommentPolicyOpen, CommentPolicyClosed:
+		return true
+	}
+	return false
+}
+
+func (e CommentPolicy) String() string {
+	return string(e)
+}
+
+func (e *CommentPolicy) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = CommentPolicy(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid CommentPolicy", str)
+	}
+	return nil
+}
+
+func (e CommentPolicy) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *CommentPolicy) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e CommentPolicy) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type CommentSort string
+
+const (
+	CommentSortNew  CommentSort = "NEW"
+	CommentSortOld  CommentSort = "OLD"
+	CommentSortTop  CommentSort = "TOP"
+	CommentSortBest CommentSort = "BEST"
+)
+
+var AllCommentSort = []CommentSort{
+	CommentSortNew,
+	CommentSortOld,
+	CommentSortTop,
+	CommentSortBest,
+}
+
+func (e CommentSort) IsValid() bool {
+	switch e {
+	case CommentSortNew, CommentSortOld, CommentSortTop, CommentSortBest:
+		return true
+	}
+	return false
+}
+
+func (e CommentSort) String() string {
+	return string(e)
+}
+
+func (e *CommentSort) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = CommentSort(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid CommentSort", str)
+	}
+	return nil
+}
+
+func (e CommentSort) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *CommentSort) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e CommentSort) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ModerationActionType string
+
+const (
+	ModerationActionTypeRemovePost      ModerationActionType = "REMOVE_POST"
+	ModerationActionTypeRemoveComment   ModerationActionType = "REMOVE_COMMENT"
+	ModerationActionTypeSetRole         ModerationActionType = "SET_ROLE"
+	ModerationActionTypeAddModerator    ModerationActionType = "ADD_MODERATOR"
+	ModerationActionTypeRemoveModerator ModerationActionType = "REMOVE_MODERATOR"
+	ModerationActionTypeAutoHidePost    ModerationActionType = "AUTO_HIDE_POST"
+	ModerationActionTypeAutoHideComment ModerationActionType = "AUTO_HIDE_COMMENT"
+	ModerationActionTypeRestorePost     ModerationActionType = "RESTORE_POST"
+	ModerationActionTypeRestoreComment  ModerationActionType = "RESTORE_COMMENT"
+)
+
+var AllModerationActionType = []ModerationActionType{
+	ModerationActionTypeRemovePost,
+	ModerationActionTypeRemoveComment,
+	ModerationActionTypeSetRole,
+	ModerationActionTypeAddModerator,
+	ModerationActionTypeRemoveModerator,
+	ModerationActionTypeAutoHidePost,
+	ModerationActionTypeAutoHideComment,
+	ModerationActionTypeRestorePost,
+	ModerationActionTypeRestoreComment,
+}
+
+func (e ModerationActionType) IsValid() bool {
+	switch e {
+	case ModerationActionTypeRemovePost, ModerationActionTypeRemoveComment, ModerationActionTypeSetRole, ModerationActionTypeAddModerator, ModerationActionTypeRemoveModerator, ModerationActionTypeAutoHidePost, ModerationActionTypeAutoHideComment, ModerationActionTypeRestorePost, ModerationActionTypeRestoreComment:
+		return true
+	}
+	return false
+}
+
+func (e ModerationActionType) String() string {
+	return string(e)
+}
+
+func (e *ModerationActionType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ModerationActionType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ModerationActionType", str)
+	}
+	return nil
+}
+
+func (e ModerationActionType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ModerationActionType) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ModerationActionType) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type NotificationType string
+
+const (
+	NotificationTypeReply   NotificationType = "REPLY"
+	NotificationTypeComment NotificationType = "COMMENT"
+)
+
+var AllNotificationType = []NotificationType{
+	NotificationTypeReply,
+	NotificationTypeComment,
+}
+
+func (e NotificationType) IsValid() bool {
+	switch e {
+	case NotificationTypeReply, NotificationTypeComment:
+		return true
+	}
+	return false
+}
+
+func (e NotificationType) String() string {
+	return string(e)
+}
+
+func (e *NotificationType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = NotificationType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid NotificationType", str)
+	}
+	return nil
+}
+
+func (e NotificationType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *NotificationType) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e NotificationType) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type PostPolicy string
+
+const (
+	PostPolicyOpen    PostPolicy = "OPEN"
+	PostPolicyMembers PostPolicy = "MEMBERS"
+)
+
+var AllPostPolicy = []PostPolicy{
+	PostPolicyOpen,
+	PostPolicyMembers,
+}
+
+func (e PostPolicy) IsValid() bool {
+	switch e {
+	case PostPolicyOpen, PostPolicyMembers:
+		return true
+	}
+	return false
+}
+
+func (e PostPolicy) String() string {
+	return string(e)
+}
+
+func (e *PostPolicy) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PostPolicy(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PostPolicy", str)
+	}
+	return nil
+}
+
+func (e PostPolicy) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *PostPolicy) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e PostPolicy) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type PostSort string
+
+const (
+	PostSortNew           PostSort = "NEW"
+	PostSortTop           PostSort = "TOP"
+	PostSortHot           PostSort = "HOT"
+	PostSortControversial PostSort = "CONTROVERSIAL"
+)
+
+var AllPostSort = []PostSort{
+	PostSortNew,
+	PostSortTop,
+	PostSortHot,
+	PostSortControversial,
+}
+
+func (e PostSort) IsValid() bool {
+	switch e {
+	case PostSortNew, PostSortTop, PostSortHot, PostSortControversial:
+		return true
+	}
+	return false
+}
+
+func (e PostSort) String() string {
+	return string(e)
+}
+
+func (e *PostSort) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PostSort(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PostSort", str)
+	}
+	return nil
+}
+
+func (e PostSort) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *PostSort) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e PostSort) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ReportAction string
+
+const (
+	ReportActionDismiss ReportAction = "DISMISS"
+	ReportActionRemove  ReportAction = "REMOVE"
+)
+
+var AllReportAction = []ReportAction{
+	ReportActionDismiss,
+	ReportActionRemove,
+}
+
+func (e ReportAction) IsValid() bool {
+	switch e {
+	case ReportActionDismiss, ReportActionRemove:
+		return true
+	}
+	return false
+}
+
+func (e ReportAction) String() string {
+	return string(e)
+}
+
+func (e *ReportAction) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ReportAction(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ReportAction", str)
+	}
+	return nil
+}
+
+func (e ReportAction) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ReportAction) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ReportAction) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ReportReason string
+
+const (
+	ReportReasonSpam    ReportReason = "SPAM"
+	ReportReasonAbuse   ReportReason = "ABUSE"
+	ReportReasonIllegal ReportReason = "ILLEGAL"
+	ReportReasonOther   ReportReason = "OTHER"
+)
+
+var AllReportReason = []ReportReason{
+	ReportReasonSpam,
+	ReportReasonAbuse,
+	ReportReasonIllegal,
+	ReportReasonOther,
+}
+
+func (e ReportReason) IsValid() bool {
+	switch e {
+	case ReportReasonSpam, ReportReasonAbuse, ReportReasonIllegal, ReportReasonOther:
+		return true
+	}
+	return false
+}
+
+func (e ReportReason) String() string {
+	return string(e)
+}
+
+func (e *ReportReason) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ReportReason(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ReportReason", str)
+	}
+	return nil
+}
+
+func (e ReportReason) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ReportReason) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ReportReason) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ReportStatus string
+
+const (
+	ReportStatusOpen      ReportStatus = "OPEN"
+	ReportStatusDismissed ReportStatus = "DISMISSED"
+	ReportStatusRemoved   ReportStatus = "REMOVED"
+)
+
+var AllReportStatus = []ReportStatus{
+	ReportStatusOpen,
+	ReportStatusDismissed,
+	ReportStatusRemoved,
+}
+
+func (e ReportStatus) IsValid() bool {
+	switch e {
+	case ReportStatusOpen, ReportStatusDismissed, ReportStatusRemoved:
+		return true
+	}
+	return false
+}
+
+func (e ReportStatus) String() string {
+	return string(e)
+}
+
+func (e *ReportStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ReportStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ReportStatus", str)
+	}
+	return nil
+}
+
+func (e ReportStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ReportStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ReportStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ReportTargetType string
+
+const (
+	ReportTargetTypePost    ReportTargetType = "POST"
+	ReportTargetTypeComment ReportTargetType = "COMMENT"
+)
+
+var AllReportTargetType = []ReportTargetType{
+	ReportTargetTypePost,
+	ReportTargetTypeComment,
+}
+
+func (e ReportTargetType) IsValid() bool {
+	switch e {
+	case ReportTargetTypePost, ReportTargetTypeComment:
+		return true
+	}
+	return false
+}
+
+func (e ReportTargetType) String() string {
+	return string(e)
+}
+
+func (e *ReportTargetType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ReportTargetType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ReportTargetType", str)
+	}
+	return nil
+}
+
+func (e ReportTargetType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ReportTargetType) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ReportTargetType) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type Role string
+
+const (
+	RoleUser      Role = "USER"
+	RoleModerator Role = "MODERATOR"
+	RoleAdmin     Role = "ADMIN"
+)
+
+var AllRole = []Role{
+	RoleUser,
+	RoleModerator,
+	RoleAdmin,
+}
+
+func (e Role) IsValid() bool {
+	switch e {
+	case RoleUser, RoleModerator, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+func (e Role) String() string {
+	return string(e)
+}
+
+func (e *Role) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Role(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Role", str)
+	}
+	return nil
+}
+
+func (e Role) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *Role) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e Role) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type TagMatch string
+
+const (
+	TagMatchAny TagMatch = "ANY"
+	TagMatchAll TagMatch = "ALL"
+)
+
+var AllTagMatch = []TagMatch{
+	TagMatchAny,
+	TagMatchAll,
+}
+
+func (e TagMatch) IsValid() bool {
+	switch e {
+	case TagMatchAny, TagMatchAll:
+		return true
+	}
+	return false
+}
+
+func (e TagMatch) String() string {
+	return string(e)
+}
+
+func (e *TagMatch) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = TagMatch(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid TagMatch", str)
+	}
+	return nil
+}
+
+func (e TagMatch) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *TagMatch) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e TagMatch) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type TimeWindow string
+
+const (
+	TimeWindowDay   TimeWindow = "DAY"
+	TimeWindowWeek  TimeWindow = "WEEK"
+	TimeWindowMonth TimeWindow = "MONTH"
+	TimeWindowAll   TimeWindow = "ALL"
+)
+
+var AllTimeWindow = []TimeWindow{
+	TimeWindowDay,
+	TimeWindowWeek,
+	TimeWindowMonth,
+	TimeWindowAll,
+}
+
+func (e TimeWindow) IsValid() bool {
+	switch e {
+	case TimeWindowDay, TimeWindowWeek, TimeWindowMonth, TimeWindowAll:
+		return true
+	}
+	return false
+}
+
+func (e TimeWindow) String() string {
+	return string(e)
+}
+
+func (e *TimeWindow) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = TimeWindow(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid TimeWindow", str)
+	}
+	return nil
+}
+
+func (e TimeWindow) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *TimeWindow) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e TimeWindow) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ModerationAction - запись журнала действий модераторов
+type ModerationAction struct {
+	ID          uuid.UUID            `json:"id"`
+	Actor       string               `json:"actor"`
+	Action      ModerationActionType `json:"action"`
+	PostID      *uuid.UUID           `json:"postId,omitempty"`
+	CommentID   *uuid.UUID           `json:"commentId,omitempty"`
+	TargetUser  *string              `json:"targetUser,omitempty"`
+	Role        *Role                `json:"role,omitempty"`
+	CommunityID *uuid.UUID           `json:"communityId,omitempty"`
+	Reason      string               `json:"reason"`
+	CreatedAt   *time.Time           `json:"createdAt,omitempty"`
+}
+
+// IsAutoHide - скрыт ли контент автоматически по жалобам
+func (t ModerationActionType) IsAutoHide() bool {
+	return t == ModerationActionTypeAutoHidePost || t == ModerationActionTypeAutoHideComment
+}
+
+// CanModerate - может ли пользователь с ролью скрывать посты и комментарии везде
+func (r Role) CanModerate() bool {
+	return r == RoleModerator || r == RoleAdmin
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification - уведомление пользователя о комментарии к его посту или ответе на его комментарий
+type Notification struct {
+	ID        uuid.UUID        `json:"id"`
+	Recipient string           `json:"recipient"` // имя пользователя, которому адресовано уведомление
+	Type      NotificationType `json:"type"`
+	Actor     string           `json:"actor"`
+	PostID    uuid.UUID        `json:"postId"`
+	CommentID uuid.UUID        `json:"commentId"`
+	IsRead    bool             `json:"isRead"`
+	CreatedAt *time.Time       `json:"createdAt,omitempty"`
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cursor - позиция элемента в выдаче, пагинация идет по тройке (key, created_at, id), где key - значение
+// ключа сортировки (рейтинг и т.п.), при сортировке по времени он всегда равен нулю
+type Cursor struct {
+	Key       float64
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+func (c Cursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatFloat(c.Key, 'g', -1, 64) + "|" +
+		c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()))
+}
+
+// Compare сравнивает курсоры в порядке возрастания (key, created_at, id)
+func (c Cursor) Compare(other Cursor) int {
+	if res := cmp.Compare(c.Key, other.Key); res != 0 {
+		return res
+	}
+	if res := c.CreatedAt.Compare(other.CreatedAt); res != 0 {
+		return res
+	}
+	return bytes.Compare(c.ID[:], other.ID[:])
+}
+
+func DecodeCursor(s string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, err
+	}
+
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != 3 {
+		return Cursor{}, errors.New("invalid cursor format")
+	}
+
+	var c Cursor
+	if c.Key, err = strconv.ParseFloat(parts[0], 64); err != nil {
+		return Cursor{}, err
+	}
+	if c.CreatedAt, err = time.Parse(time.RFC3339Nano, parts[1]); err != nil {
+		return Cursor{}, err
+	}
+	if c.ID, err = uuid.Parse(parts[2]); err != nil {
+		return Cursor{}, err
+	}
+	return c, nil
+}
+
+func PostCursor(post *Post) Cursor {
+	return Cursor{CreatedAt: timeOrZero(post.CreatedAt), ID: post.ID}
+}
+
+// PostSortCursor - курсоры постов для ленты с сортировкой sort
+func PostSortCursor(sort PostSort) func(post *Post) Cursor {
+	return func(post *Post) Cursor {
+		c := PostCursor(post)
+		c.Key = post.SortKey(sort)
+		return c
+	}
+}
+
+func CommentCursor(comment *Comment) Cursor {
+	return Cursor{CreatedAt: timeOrZero(comment.CreatedAt), ID: comment.ID}
+}
+
+// CommentSortCursor - курсоры комментариев при сортировке sort
+func CommentSortCursor(sort CommentSort) func(comment *Comment) Cursor {
+	return func(comment *Comment) Cursor {
+		c := CommentCursor(comment)
+		c.Key = comment.SortKey(sort)
+		return c
+	}
+}
+
+func NotificationCursor(notification *Notification) Cursor {
+	return Cursor{CreatedAt: timeOrZero(notification.CreatedAt), ID: notification.ID}
+}
+
+func ModerationActionCursor(action *ModerationAction) Cursor {
+	return Cursor{CreatedAt: timeOrZero(action.CreatedAt), ID: action.ID}
+}
+
+func ReportCursor(report *Report) Cursor {
+	return Cursor{CreatedAt: timeOrZero(report.CreatedAt), ID: report.ID}
+}
+
+// PostSearchCursor - курсор результата поиска постов, ключ - релевантность
+func PostSearchCursor(result *PostSearchResult) Cursor {
+	c := PostCursor(result.Post)
+	c.Key = result.Rank
+	return c
+}
+
+// PageRequest - аргументы пагинации first/after/last/before из запроса
+type PageRequest struct {
+	First  *int32
+	After  *string
+	Last   *int32
+	Before *string
+}
+
+// Page - параметры выборки страницы для хранилища, After и Before задаются в порядке выдачи
+type Page struct {
+	Limit   int
+	After   *Cursor // элементы строго после курсора
+	Before  *Cursor // элементы строго до курсора
+	FromEnd bool    // выбрать последние Limit элементов (пагинация через last/before)
+}
+
+func timeOrZero(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
@@ -1,24 +1,117 @@
 package models
 
 import (
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type Post struct {
-	ID                uuid.UUID `json:"id,omitempty"`
-	Title             string    `json:"title"`
-	Author            string    `json:"author"`
-	Content           string    `json:"content"`
-	IsCommentsAllowed bool      `json:"isCommentsAllowed"`
+	ID                uuid.UUID  `json:"id,omitempty"`
+	Title             string     `json:"title"`
+	Author            string     `json:"author"`
+	Content           string     `json:"content"`
+	IsCommentsAllowed bool       `json:"isCommentsAllowed"`
+	Tags              []string   `json:"tags"`
+	CommunityID       *uuid.UUID `json:"communityId,omitempty"` // сообщество поста, nil у постов вне сообществ
+	IsRemoved         bool       `json:"isRemoved"`             // скрыт модератором
+	IsShadowed        bool       `json:"isShadowed"`            // скрыт фильтром контента, виден только автору
 	//Comments          []*Comment `json:"comments,omitempty"`
+	Upvotes   int32      `json:"upvotes"`
+	Downvotes int32      `json:"downvotes"`
 	CreatedAt *time.Time `json:"createdAt,omitempty"`
+
+	// ключи сортировки ленты, в postgres это генерируемые столбцы hot_score и controversy
+	HotScore    float64 `json:"-"`
+	Controversy float64 `json:"-"`
+}
+
+func (p *Post) Score() int32 {
+	return p.Upvotes - p.Downvotes
+}
+
+// UpdateRanking пересчитывает ключи сортировки после изменения голосов
+func (p *Post) UpdateRanking() {
+	p.HotScore = HotScore(p.Upvotes, p.Downvotes, timeOrZero(p.CreatedAt))
+	p.Controversy = Controversy(p.Upvotes, p.Downvotes)
+}
+
+// SortKey - значение ключа сортировки поста в ленте с сортировкой sort
+func (p *Post) SortKey(sort PostSort) float64 {
+	switch sort {
+	case PostSortTop:
+		return float64(p.Score())
+	case PostSortHot:
+		return p.HotScore
+	case PostSortControversial:
+		return p.Controversy
+	default:
+		return 0
+	}
+}
+
+// PostFeed - параметры ленты постов для хранилища
+type PostFeed struct {
+	Sort  PostSort
+	Since *time.Time // только посты, созданные не раньше Since (nil - за все время)
+	Tags  []string   // только посты с этими тегами (пустой - без фильтра по тегам)
+	Match TagMatch   // ANY - с любым из тегов Tags, ALL - со всеми
+
+	CommunityID *uuid.UUID // только посты сообщества (nil - все посты)
+
+	IncludeRemoved bool   // показывать ли скрытые модераторами посты (только модераторам)
+	Viewer         string // кто смотрит ленту: скрытые фильтром контента посты видны только автору
+}
+
+// Matches - подходит ли пост под фильтр ленты по тегам, сообществу и скрытию (без учета времени публикации)
+func (f PostFeed) Matches(post *Post) bool {
+	if post.IsRemoved && !f.IncludeRemoved {
+		return false
+	}
+
+	if !post.VisibleTo(f.Viewer) {
+		return false
+	}
+
+	if f.CommunityID != nil && (post.CommunityID == nil || *post.CommunityID != *f.CommunityID) {
+		return false
+	}
+
+	if len(f.Tags) == 0 {
+		return true
+	}
+
+	found := 0
+	for _, tag := range f.Tags {
+		if slices.Contains(post.Tags, tag) {
+			found++
+		}
+	}
+	if f.Match == TagMatchAll {
+		return found == len(f.Tags)
+	}
+	return found > 0
+}
+
+// PostFilter - фильтр ленты из запроса
+type PostFilter struct {
+	Tags        []string
+	Match       TagMatch
+	CommunityID *uuid.UUID
 }
 
 type PostRequest struct {
 	Title            string
-	Author           *string
 	Content          string
-	IsCommentAllowed bool
+	IsCommentAllowed *bool // nil - по политике комментариев сообщества
+	Tags             []string
+	Community        *string // slug сообщества, nil - пост вне сообществ
+}
+
+type PostUpdateRequest struct {
+	ID      uuid.UUID
+	Title   string
+	Content string
+	Tags    []string // nil - теги не меняются
 }
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PostEvent - событие, которое рассылается подписчикам поста
+type PostEvent interface {
+	IsPostEvent()
+}
+
+type CommentsStatus struct {
+	PostID            uuid.UUID  `json:"postId"`
+	IsCommentsAllowed bool       `json:"isCommentsAllowed"`
+	ChangedAt         *time.Time `json:"changedAt,omitempty"`
+}
+
+func (Comment) IsPostEvent()        {}
+func (CommentsStatus) IsPostEvent() {}
+
+// PostActivity - событие подписки на активность поста: сколько человек сейчас читают пост (открыта подписка
+// SubOnPost), количество комментариев отдает резолвер
+type PostActivity struct {
+	PostID  uuid.UUID `json:"postId"`
+	Viewers int32     `json:"viewers"`
+}
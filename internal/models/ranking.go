@@ -0,0 +1,53 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// начало отсчета времени в формуле hot (8 декабря 2005), как в reddit
+const hotEpoch = 1134028003
+
+// квантиль нормального распределения для доверительного интервала Уилсона (уровень 80%), как в reddit
+const wilsonZ = 1.281551565545
+
+// HotScore - рейтинг с поправкой на время публикации: порядок голосов (log10) плюс время публикации,
+// так что 10 голосов "за" весят как 12.5 часов свежести. Совпадает со столбцом hot_score в postgres
+func HotScore(up, down int32, createdAt time.Time) float64 {
+	score := float64(up - down)
+
+	sign := 0.0
+	if score > 0 {
+		sign = 1
+	} else if score < 0 {
+		sign = -1
+	}
+
+	// в postgres время хранится с точностью до микросекунд
+	seconds := float64(createdAt.UnixMicro()) / 1e6
+	return sign*math.Log10(math.Max(math.Abs(score), 1)) + (seconds-hotEpoch)/45000
+}
+
+// Controversy - спорность: чем больше голосов и чем ближе число голосов "за" и "против", тем она выше.
+// Совпадает со столбцом controversy в postgres
+func Controversy(up, down int32) float64 {
+	if up <= 0 || down <= 0 {
+		return 0
+	}
+
+	balance := float64(min(up, down)) / float64(max(up, down))
+	return math.Pow(float64(up+down), balance)
+}
+
+// WilsonScore - нижняя граница доверительного интервала Уилсона для доли голосов "за": комментарий с 10 голосами
+// "за" из 10 оказывается выше комментария с 1 голосом из 1. Совпадает со столбцом best в postgres
+func WilsonScore(up, down int32) float64 {
+	n := float64(up + down)
+	if n <= 0 {
+		return 0
+	}
+
+	p := float64(up) / n
+	z2 := wilsonZ * wilsonZ
+	return (p + z2/(2*n) - wilsonZ*math.Sqrt((p*(1-p)+z2/(4*n))/n)) / (1 + z2/n)
+}
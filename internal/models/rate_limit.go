@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// RateLimit - ограничение частоты вызовов операции (token bucket): подряд можно сделать Burst вызовов,
+// дальше токены восполняются равномерно, Burst штук за Per
+type RateLimit struct {
+	Burst int
+	Per   time.Duration
+}
+
+// Rate - сколько токенов восполняется за секунду
+func (l RateLimit) Rate() float64 {
+	return float64(l.Burst) / l.Per.Seconds()
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Report - жалоба пользователя на пост или комментарий
+type Report struct {
+	ID         uuid.UUID        `json:"id"`
+	TargetType ReportTargetType `json:"targetType"`
+	TargetID   uuid.UUID        `json:"targetId"`
+	PostID     uuid.UUID        `json:"postId"`
+	Reporter   string           `json:"reporter"`
+	Reason     ReportReason     `json:"reason"`
+	Note       string           `json:"note"`
+	Status     ReportStatus     `json:"status"`
+	ResolvedBy *string          `json:"resolvedBy,omitempty"`
+	ResolvedAt *time.Time       `json:"resolvedAt,omitempty"`
+	CreatedAt  *time.Time       `json:"createdAt,omitempty"`
+}
+
+// CommentID - id комментария, на который пожаловались, у жалобы на пост - nil
+func (r Report) CommentID() *uuid.UUID {
+	if r.TargetType == ReportTargetTypeComment {
+		return &r.TargetID
+	}
+	return nil
+}
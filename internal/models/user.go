@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type User struct {
+	ID           uuid.UUID  `json:"id"`
+	Username     string     `json:"username"`
+	PasswordHash string     `json:"-"` // bcrypt-хеш пароля
+	Role         Role       `json:"role"`
+	CreatedAt    *time.Time `json:"createdAt"`
+}
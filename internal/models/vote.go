@@ -0,0 +1,14 @@
+package models
+
+// VoteDelta - на сколько меняются счетчики голосов "за" и "против" при замене голоса prev на next.
+// Голос равен 1 (за), -1 (против) или 0 (голоса нет)
+func VoteDelta(prev, next int) (up, down int) {
+	return count(next, 1) - count(prev, 1), count(next, -1) - count(prev, -1)
+}
+
+func count(vote, value int) int {
+	if vote == value {
+		return 1
+	}
+	return 0
+}
@@ -11,15 +11,23 @@ import (
 
 	"github.com/google/uuid"
 	graphql1 "github.com/nedokyrill/posts-service/graphql"
+	"github.com/nedokyrill/posts-service/internal/auth"
+	"github.com/nedokyrill/posts-service/internal/loaders"
 	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/internal/service"
 	"github.com/nedokyrill/posts-service/pkg/logger"
 	"github.com/nedokyrill/posts-service/pkg/utils"
 	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
 // Replies is the resolver for the replies field.
-func (r *commentResolver) Replies(ctx context.Context, obj *models.Comment) ([]*models.Comment, error) {
-	comments, err := r.CommentService.GetRepliesByComment(ctx, obj.ID)
+func (r *commentResolver) Replies(ctx context.Context, obj *models.Comment, sort models.CommentSort, first *int32, after *string, last *int32, before *string) (*models.CommentConnection, error) {
+	comments, err := loaders.For(ctx).RepliesByCommentID(sort, models.PageRequest{
+		First:  first,
+		After:  after,
+		Last:   last,
+		Before: before,
+	}).Load(ctx, obj.ID)
 	if err != nil {
 		var gqlErr utils.GqlError
 		if errors.As(err, &gqlErr) {
@@ -31,10 +39,28 @@ func (r *commentResolver) Replies(ctx context.Context, obj *models.Comment) ([]*
 	return comments, nil
 }
 
+// MyVote is the resolver for the myVote field.
+func (r *commentResolver) MyVote(ctx context.Context, obj *models.Comment) (int32, error) {
+	identity, ok := auth.IdentityFrom(ctx)
+	if !ok { // у анонимного пользователя голоса нет
+		return 0, nil
+	}
+
+	vote, err := loaders.For(ctx).CommentVotes(identity.Username).Load(ctx, obj.ID)
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return 0, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return 0, err
+	}
+
+	return vote, nil
+}
+
 // AddComment is the resolver for the AddComment field.
-func (r *mutationResolver) AddComment(ctx context.Context, author string, content string, postID uuid.UUID, parentCommentID *uuid.UUID) (*models.Comment, error) {
+func (r *mutationResolver) AddComment(ctx context.Context, content string, postID uuid.UUID, parentCommentID *uuid.UUID) (*models.Comment, error) {
 	comment, err := r.CommentService.CreateComment(ctx, models.CommentRequest{
-		Author:          author,
 		Content:         content,
 		PostID:          postID,
 		ParentCommentID: parentCommentID,
@@ -48,15 +74,96 @@ func (r *mutationResolver) AddComment(ctx context.Context, author string, conten
 		return nil, err
 	}
 
-	go func() { // асинхронный запуск рассылки нотификаций
-		_ = r.ViewerService.NotifyViewers(ctx, postID, *comment)
+	// скрытый фильтром контента комментарий виден только автору, о нем никого не оповещаем
+	if comment.IsShadowed {
+		return comment, nil
+	}
+
+	go func() { // асинхронный запуск рассылки нотификаций (запрос к этому моменту может быть завершен)
+		_ = r.ViewerService.NotifyViewers(context.WithoutCancel(ctx), postID, comment)
 	}()
+	go func() { // уведомление автору поста или комментария, на который ответили (запрос к этому моменту завершен)
+		_ = r.NotificationService.NotifyComment(context.WithoutCancel(ctx), comment)
+	}()
+
+	return comment, nil
+}
+
+// EditComment is the resolver for the EditComment field.
+func (r *mutationResolver) EditComment(ctx context.Context, id uuid.UUID, content string) (*models.Comment, error) {
+	comment, err := r.CommentService.EditComment(ctx, models.CommentEditRequest{
+		ID:      id,
+		Content: content,
+	})
+
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return nil, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+// DeleteComment is the resolver for the DeleteComment field.
+func (r *mutationResolver) DeleteComment(ctx context.Context, id uuid.UUID) (*models.Comment, error) {
+	comment, err := r.CommentService.DeleteComment(ctx, id)
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return nil, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+// VoteComment is the resolver for the VoteComment field.
+func (r *mutationResolver) VoteComment(ctx context.Context, commentID uuid.UUID, value int32) (*models.Comment, error) {
+	comment, err := r.VoteService.VoteComment(ctx, commentID, value)
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return nil, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return nil, err
+	}
 
 	return comment, nil
 }
 
+// CommentCount is the resolver for the commentCount field.
+func (r *postActivityResolver) CommentCount(ctx context.Context, obj *models.PostActivity) (int32, error) {
+	count, err := loaders.For(ctx).CommentCounts().Load(ctx, obj.PostID)
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return 0, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetCommentTree is the resolver for the GetCommentTree field.
+func (r *queryResolver) GetCommentTree(ctx context.Context, postID uuid.UUID, maxDepth *int32, page *int32) ([]*models.CommentTreeNode, error) {
+	nodes, err := r.CommentService.GetCommentTree(ctx, postID, maxDepth, page)
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return nil, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return nil, err
+	}
+
+	return nodes, nil
+}
+
 // SubOnPost is the resolver for the SubOnPost field.
-func (r *subscriptionResolver) SubOnPost(ctx context.Context, postID uuid.UUID) (<-chan *models.Comment, error) {
+func (r *subscriptionResolver) SubOnPost(ctx context.Context, postID uuid.UUID, since *time.Time, lastCommentID *uuid.UUID) (<-chan models.PostEvent, error) {
 	id, ch, err := r.ViewerService.CreateViewer(ctx, postID)
 	if err != nil {
 		var gqlErr utils.GqlError
@@ -66,15 +173,51 @@ func (r *subscriptionResolver) SubOnPost(ctx context.Context, postID uuid.UUID)
 		return nil, err
 	}
 
+	// пропущенные комментарии загружаются уже после подписки, чтобы не потерять созданные в промежутке
+	missed, err := r.CommentService.GetMissedComments(ctx, postID, since, lastCommentID)
+	if err != nil {
+		_ = r.ViewerService.DeleteViewer(ctx, postID, id)
+
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return nil, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return nil, err
+	}
+
 	go func() {
 		<-ctx.Done()
 
 		newCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
-		err = r.ViewerService.DeleteViewer(newCtx, postID, id)
-		if err != nil {
-			logger.Logger.Errorf("Error removing post viewer %s: %s", id, err)
+		if err := r.ViewerService.DeleteViewer(newCtx, postID, id); err != nil {
+			logger.Logger.Errorf("Error removing post viewer %d: %s", id, err)
+		}
+	}()
+
+	if since == nil && lastCommentID == nil {
+		return ch, nil
+	}
+	return service.ReplayEvents(ctx, missed, ch), nil
+}
+
+// PostActivity is the resolver for the PostActivity field.
+func (r *subscriptionResolver) PostActivity(ctx context.Context, postID uuid.UUID) (<-chan *models.PostActivity, error) {
+	id, ch, err := r.ViewerService.WatchActivity(ctx, postID)
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return nil, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return nil, err
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		if err := r.ViewerService.UnwatchActivity(context.Background(), postID, id); err != nil {
+			logger.Logger.Errorf("Error removing post activity viewer %d: %s", id, err)
 		}
 	}()
 
@@ -84,8 +227,12 @@ func (r *subscriptionResolver) SubOnPost(ctx context.Context, postID uuid.UUID)
 // Comment returns graphql1.CommentResolver implementation.
 func (r *Resolver) Comment() graphql1.CommentResolver { return &commentResolver{r} }
 
+// PostActivity returns graphql1.PostActivityResolver implementation.
+func (r *Resolver) PostActivity() graphql1.PostActivityResolver { return &postActivityResolver{r} }
+
 // Subscription returns graphql1.SubscriptionResolver implementation.
 func (r *Resolver) Subscription() graphql1.SubscriptionResolver { return &subscriptionResolver{r} }
 
 type commentResolver struct{ *Resolver }
+type postActivityResolver struct{ *Resolver }
 type subscriptionResolver struct{ *Resolver }
@@ -0,0 +1,144 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.80
+
+import (
+	"context"
+	"errors"
+
+	graphql1 "github.com/nedokyrill/posts-service/graphql"
+	"github.com/nedokyrill/posts-service/internal/auth"
+	"github.com/nedokyrill/posts-service/internal/loaders"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/pkg/utils"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// IsMember is the resolver for the isMember field.
+func (r *communityResolver) IsMember(ctx context.Context, obj *models.Community) (bool, error) {
+	identity, ok := auth.IdentityFrom(ctx)
+	if !ok { // анонимный пользователь ни в одном сообществе не состоит
+		return false, nil
+	}
+
+	isMember, err := loaders.For(ctx).CommunityMemberships(identity.Username).Load(ctx, obj.ID)
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return false, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return false, err
+	}
+
+	return isMember, nil
+}
+
+// Posts is the resolver for the posts field.
+func (r *communityResolver) Posts(ctx context.Context, obj *models.Community, sort models.PostSort, timeWindow models.TimeWindow, tags []string, match models.TagMatch, first *int32, after *string, last *int32, before *string) (*models.PostConnection, error) {
+	posts, err := r.PostService.GetAllPosts(ctx, sort, timeWindow, models.PostFilter{
+		Tags:        tags,
+		Match:       match,
+		CommunityID: &obj.ID,
+	}, models.PageRequest{
+		First:  first,
+		After:  after,
+		Last:   last,
+		Before: before,
+	})
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return nil, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// CreateCommunity is the resolver for the CreateCommunity field.
+func (r *mutationResolver) CreateCommunity(ctx context.Context, slug string, title string, description string, postPolicy models.PostPolicy, commentPolicy models.CommentPolicy) (*models.Community, error) {
+	community, err := r.CommunityService.CreateCommunity(ctx, models.CommunityRequest{
+		Slug:          slug,
+		Title:         title,
+		Description:   description,
+		PostPolicy:    postPolicy,
+		CommentPolicy: commentPolicy,
+	})
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return nil, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return nil, err
+	}
+
+	return community, nil
+}
+
+// JoinCommunity is the resolver for the JoinCommunity field.
+func (r *mutationResolver) JoinCommunity(ctx context.Context, slug string) (*models.Community, error) {
+	community, err := r.CommunityService.JoinCommunity(ctx, slug)
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return nil, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return nil, err
+	}
+
+	return community, nil
+}
+
+// LeaveCommunity is the resolver for the LeaveCommunity field.
+func (r *mutationResolver) LeaveCommunity(ctx context.Context, slug string) (*models.Community, error) {
+	community, err := r.CommunityService.LeaveCommunity(ctx, slug)
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return nil, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return nil, err
+	}
+
+	return community, nil
+}
+
+// Community is the resolver for the community field.
+func (r *postResolver) Community(ctx context.Context, obj *models.Post) (*models.Community, error) {
+	if obj.CommunityID == nil { // пост опубликован вне сообществ
+		return nil, nil
+	}
+
+	community, err := loaders.For(ctx).Communities().Load(ctx, *obj.CommunityID)
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return nil, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return nil, err
+	}
+
+	return community, nil
+}
+
+// Community is the resolver for the Community field.
+func (r *queryResolver) Community(ctx context.Context, slug string) (*models.Community, error) {
+	community, err := r.CommunityService.GetCommunityBySlug(ctx, slug)
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return nil, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return nil, err
+	}
+
+	return community, nil
+}
+
+// Community returns graphql1.CommunityResolver implementation.
+func (r *Resolver) Community() graphql1.CommunityResolver { return &communityResolver{r} }
+
+type communityResolver struct{ *Resolver }
@@ -0,0 +1,129 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.80
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	graphql1 "github.com/nedokyrill/posts-service/graphql"
+	"github.com/nedokyrill/posts-service/internal/loaders"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/pkg/utils"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Community is the resolver for the community field.
+func (r *moderationActionResolver) Community(ctx context.Context, obj *models.ModerationAction) (*models.Community, error) {
+	if obj.CommunityID == nil { // действие не относится к сообществу
+		return nil, nil
+	}
+
+	community, err := loaders.For(ctx).Communities().Load(ctx, *obj.CommunityID)
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return nil, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return nil, err
+	}
+
+	return community, nil
+}
+
+// RemovePost is the resolver for the RemovePost field.
+func (r *mutationResolver) RemovePost(ctx context.Context, id uuid.UUID, reason string) (*models.Post, error) {
+	post, err := r.ModerationService.RemovePost(ctx, id, reason)
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return nil, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return nil, err
+	}
+
+	return post, nil
+}
+
+// RemoveComment is the resolver for the RemoveComment field.
+func (r *mutationResolver) RemoveComment(ctx context.Context, id uuid.UUID, reason string) (*models.Comment, error) {
+	comment, err := r.ModerationService.RemoveComment(ctx, id, reason)
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return nil, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+// SetUserRole is the resolver for the SetUserRole field.
+func (r *mutationResolver) SetUserRole(ctx context.Context, username string, role models.Role) (*models.User, error) {
+	user, err := r.ModerationService.SetUserRole(ctx, username, role)
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return nil, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// AddCommunityModerator is the resolver for the AddCommunityModerator field.
+func (r *mutationResolver) AddCommunityModerator(ctx context.Context, community string, username string) (*models.Community, error) {
+	updated, err := r.ModerationService.AddCommunityModerator(ctx, community, username)
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return nil, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// RemoveCommunityModerator is the resolver for the RemoveCommunityModerator field.
+func (r *mutationResolver) RemoveCommunityModerator(ctx context.Context, community string, username string) (*models.Community, error) {
+	updated, err := r.ModerationService.RemoveCommunityModerator(ctx, community, username)
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return nil, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// ModerationLog is the resolver for the ModerationLog field.
+func (r *queryResolver) ModerationLog(ctx context.Context, community *string, first *int32, after *string) (*models.ModerationActionConnection, error) {
+	log, err := r.ModerationService.GetModerationLog(ctx, community, models.PageRequest{
+		First: first,
+		After: after,
+	})
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return nil, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return nil, err
+	}
+
+	return log, nil
+}
+
+// ModerationAction returns graphql1.ModerationActionResolver implementation.
+func (r *Resolver) ModerationAction() graphql1.ModerationActionResolver {
+	return &moderationActionResolver{r}
+}
+
+type moderationActionResolver struct{ *Resolver }
@@ -0,0 +1,69 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.80
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/pkg/logger"
+	"github.com/nedokyrill/posts-service/pkg/utils"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// MarkNotificationsRead is the resolver for the MarkNotificationsRead field.
+func (r *mutationResolver) MarkNotificationsRead(ctx context.Context, ids []uuid.UUID) (int32, error) {
+	marked, err := r.NotificationService.MarkNotificationsRead(ctx, ids)
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return 0, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return 0, err
+	}
+
+	return marked, nil
+}
+
+// MyNotifications is the resolver for the MyNotifications field.
+func (r *queryResolver) MyNotifications(ctx context.Context, first *int32, after *string) (*models.NotificationConnection, error) {
+	notifications, err := r.NotificationService.GetMyNotifications(ctx, models.PageRequest{
+		First: first,
+		After: after,
+	})
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return nil, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+// NotificationAdded is the resolver for the NotificationAdded field.
+func (r *subscriptionResolver) NotificationAdded(ctx context.Context) (<-chan *models.Notification, error) {
+	id, ch, err := r.InboxService.Subscribe(ctx)
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return nil, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return nil, err
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		if err := r.InboxService.Unsubscribe(context.Background(), id); err != nil {
+			logger.Logger.Errorf("Error removing inbox subscriber %d: %s", id, err)
+		}
+	}()
+
+	return ch, nil
+}
@@ -10,18 +10,21 @@ import (
 
 	"github.com/google/uuid"
 	graphql1 "github.com/nedokyrill/posts-service/graphql"
+	"github.com/nedokyrill/posts-service/internal/auth"
+	"github.com/nedokyrill/posts-service/internal/loaders"
 	"github.com/nedokyrill/posts-service/internal/models"
 	"github.com/nedokyrill/posts-service/pkg/utils"
 	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
 // CreatePost is the resolver for the CreatePost field.
-func (r *mutationResolver) CreatePost(ctx context.Context, title string, author *string, content string, isCommentAllowed bool) (*models.Post, error) {
+func (r *mutationResolver) CreatePost(ctx context.Context, title string, content string, isCommentAllowed *bool, tags []string, community *string) (*models.Post, error) {
 	post, err := r.PostService.CreatePost(ctx, models.PostRequest{
 		Title:            title,
-		Author:           author,
 		Content:          content,
 		IsCommentAllowed: isCommentAllowed,
+		Tags:             tags,
+		Community:        community,
 	})
 
 	if err != nil {
@@ -35,9 +38,85 @@ func (r *mutationResolver) CreatePost(ctx context.Context, title string, author
 	return post, nil
 }
 
+// UpdatePost is the resolver for the UpdatePost field.
+func (r *mutationResolver) UpdatePost(ctx context.Context, id uuid.UUID, title string, content string, isCommentAllowed bool, tags []string) (*models.Post, error) {
+	post, err := r.PostService.UpdatePost(ctx, models.PostUpdateRequest{
+		ID:      id,
+		Title:   title,
+		Content: content,
+		Tags:    tags,
+	})
+
+	// комментарии закрываются и открываются через SetCommentsAllowed, чтобы подписчики узнали об этом
+	if err == nil && post.IsCommentsAllowed != isCommentAllowed {
+		post, err = r.PostService.SetCommentsAllowed(ctx, id, isCommentAllowed)
+		if err == nil {
+			r.notifyCommentsStatus(ctx, post)
+		}
+	}
+
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return nil, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return nil, err
+	}
+
+	return post, nil
+}
+
+// DeletePost is the resolver for the DeletePost field.
+func (r *mutationResolver) DeletePost(ctx context.Context, id uuid.UUID) (bool, error) {
+	err := r.PostService.DeletePost(ctx, id)
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return false, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// SetCommentsAllowed is the resolver for the SetCommentsAllowed field.
+func (r *mutationResolver) SetCommentsAllowed(ctx context.Context, postID uuid.UUID, allowed bool) (*models.Post, error) {
+	post, err := r.PostService.SetCommentsAllowed(ctx, postID, allowed)
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return nil, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return nil, err
+	}
+
+	r.notifyCommentsStatus(ctx, post)
+	return post, nil
+}
+
+// VotePost is the resolver for the VotePost field.
+func (r *mutationResolver) VotePost(ctx context.Context, postID uuid.UUID, value int32) (*models.Post, error) {
+	post, err := r.VoteService.VotePost(ctx, postID, value)
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return nil, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return nil, err
+	}
+
+	return post, nil
+}
+
 // Comments is the resolver for the comments field.
-func (r *postResolver) Comments(ctx context.Context, obj *models.Post, page *int32) ([]*models.Comment, error) {
-	comments, err := r.CommentService.GetCommentsByPostID(ctx, obj.ID, page)
+func (r *postResolver) Comments(ctx context.Context, obj *models.Post, sort models.CommentSort, first *int32, after *string, last *int32, before *string) (*models.CommentConnection, error) {
+	comments, err := loaders.For(ctx).CommentsByPostID(sort, models.PageRequest{
+		First:  first,
+		After:  after,
+		Last:   last,
+		Before: before,
+	}).Load(ctx, obj.ID)
 	if err != nil {
 		var gqlErr utils.GqlError
 		if errors.As(err, &gqlErr) {
@@ -48,9 +127,46 @@ func (r *postResolver) Comments(ctx context.Context, obj *models.Post, page *int
 	return comments, nil
 }
 
+// CommentCount is the resolver for the commentCount field.
+func (r *postResolver) CommentCount(ctx context.Context, obj *models.Post) (int32, error) {
+	count, err := loaders.For(ctx).CommentCounts().Load(ctx, obj.ID)
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return 0, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return 0, err
+	}
+	return count, nil
+}
+
+// MyVote is the resolver for the myVote field.
+func (r *postResolver) MyVote(ctx context.Context, obj *models.Post) (int32, error) {
+	identity, ok := auth.IdentityFrom(ctx)
+	if !ok { // у анонимного пользователя голоса нет
+		return 0, nil
+	}
+
+	vote, err := loaders.For(ctx).PostVotes(identity.Username).Load(ctx, obj.ID)
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return 0, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return 0, err
+	}
+
+	return vote, nil
+}
+
 // GetAllPosts is the resolver for the GetAllPosts field.
-func (r *queryResolver) GetAllPosts(ctx context.Context, page *int32) ([]*models.Post, error) {
-	posts, err := r.PostService.GetAllPosts(ctx, page)
+func (r *queryResolver) GetAllPosts(ctx context.Context, sort models.PostSort, timeWindow models.TimeWindow, tags []string, match models.TagMatch, first *int32, after *string, last *int32, before *string) (*models.PostConnection, error) {
+	posts, err := r.PostService.GetAllPosts(ctx, sort, timeWindow, models.PostFilter{Tags: tags, Match: match}, models.PageRequest{
+		First:  first,
+		After:  after,
+		Last:   last,
+		Before: before,
+	})
 	if err != nil {
 		var gqlErr utils.GqlError
 		if errors.As(err, &gqlErr) {
@@ -75,6 +191,20 @@ func (r *queryResolver) GetPostByID(ctx context.Context, id uuid.UUID) (*models.
 	return post, nil
 }
 
+// PopularTags is the resolver for the PopularTags field.
+func (r *queryResolver) PopularTags(ctx context.Context, limit *int32) ([]*models.Tag, error) {
+	tagList, err := r.PostService.GetPopularTags(ctx, limit)
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return nil, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return nil, err
+	}
+
+	return tagList, nil
+}
+
 // Mutation returns graphql1.MutationResolver implementation.
 func (r *Resolver) Mutation() graphql1.MutationResolver { return &mutationResolver{r} }
 
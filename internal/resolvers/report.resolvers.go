@@ -0,0 +1,65 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.80
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/pkg/utils"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// ReportContent is the resolver for the ReportContent field.
+func (r *mutationResolver) ReportContent(ctx context.Context, targetType models.ReportTargetType, targetID uuid.UUID, reason models.ReportReason, note *string) (*models.Report, error) {
+	report, err := r.ReportService.ReportContent(ctx, targetType, targetID, reason, note)
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return nil, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// ResolveReport is the resolver for the ResolveReport field.
+func (r *mutationResolver) ResolveReport(ctx context.Context, id uuid.UUID, action models.ReportAction) (*models.Report, error) {
+	report, err := r.ReportService.ResolveReport(ctx, id, action)
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return nil, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// ReportQueue is the resolver for the ReportQueue field.
+func (r *queryResolver) ReportQueue(ctx context.Context, status *models.ReportStatus, first *int32, after *string) (*models.ReportConnection, error) {
+	queueStatus := models.ReportStatusOpen // явный null - тоже открытые жалобы
+	if status != nil {
+		queueStatus = *status
+	}
+
+	queue, err := r.ReportService.GetReportQueue(ctx, queueStatus, models.PageRequest{
+		First: first,
+		After: after,
+	})
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return nil, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return nil, err
+	}
+
+	return queue, nil
+}
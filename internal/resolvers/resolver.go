@@ -1,6 +1,12 @@
 package resolvers
 
-import "github.com/nedokyrill/posts-service/internal/service"
+import (
+	"context"
+	"time"
+
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/internal/service"
+)
 
 // This file will not be regenerated automatically.
 //
@@ -10,4 +16,24 @@ type Resolver struct {
 	PostService    service.PostService
 	CommentService service.CommentService
 	ViewerService  service.ViewerService
+	VoteService    service.VoteService
+	UserService    service.UserService
+
+	NotificationService service.NotificationService
+	InboxService        service.InboxService
+	CommunityService    service.CommunityService
+	ModerationService   service.ModerationService
+	ReportService       service.ReportService
+}
+
+// асинхронно уведомляем подписчиков поста о закрытии/открытии комментариев (запрос может быть завершен)
+func (r *Resolver) notifyCommentsStatus(ctx context.Context, post *models.Post) {
+	go func() {
+		now := time.Now()
+		_ = r.ViewerService.NotifyViewers(context.WithoutCancel(ctx), post.ID, &models.CommentsStatus{
+			PostID:            post.ID,
+			IsCommentsAllowed: post.IsCommentsAllowed,
+			ChangedAt:         &now,
+		})
+	}()
 }
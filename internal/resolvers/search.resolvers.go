@@ -0,0 +1,43 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.80
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/pkg/utils"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// SearchPosts is the resolver for the SearchPosts field.
+func (r *queryResolver) SearchPosts(ctx context.Context, query string, first *int32, after *string) (*models.PostSearchConnection, error) {
+	results, err := r.PostService.SearchPosts(ctx, query, models.PageRequest{First: first, After: after})
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return nil, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// SearchComments is the resolver for the SearchComments field.
+func (r *queryResolver) SearchComments(ctx context.Context, query string, postID *uuid.UUID) ([]*models.CommentSearchResult, error) {
+	results, err := r.CommentService.SearchComments(ctx, query, postID)
+	if err != nil {
+		var gqlErr utils.GqlError
+		if errors.As(err, &gqlErr) {
+			return nil, &gqlerror.Error{Extensions: gqlErr.Extensions()}
+		}
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -2,7 +2,12 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/nedokyrill/posts-service/internal/models"
@@ -13,25 +18,28 @@ import (
 )
 
 type CommentServiceImpl struct {
-	commStore storage.CommentStorage
-	postStore storage.PostStorage
+	commStore  storage.CommentStorage
+	postStore  storage.PostStorage
+	moderators moderators
+	filters    *ContentFilterChain
 }
 
-func NewCommentService(commStore storage.CommentStorage, postStore storage.PostStorage) *CommentServiceImpl {
+func NewCommentService(commStore storage.CommentStorage, postStore storage.PostStorage,
+	communityStore storage.CommunityStorage, userStore storage.UserStorage,
+	filters *ContentFilterChain) *CommentServiceImpl {
 	return &CommentServiceImpl{
-		commStore: commStore,
-		postStore: postStore,
+		commStore:  commStore,
+		postStore:  postStore,
+		moderators: moderators{userStore: userStore, communityStore: communityStore},
+		filters:    filters,
 	}
 }
 
 func (s *CommentServiceImpl) CreateComment(ctx context.Context,
 	commReq models.CommentRequest) (*models.Comment, error) {
-	if len(commReq.Author) == 0 {
-		return nil, utils.GqlError{
-			Msg:  "comment must have a author",
-			Type: consts.BadRequestType,
-		}
-
+	identity, err := requireIdentity(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	if len(commReq.Content) > consts.ContentMaxLen {
@@ -42,7 +50,7 @@ func (s *CommentServiceImpl) CreateComment(ctx context.Context,
 	}
 
 	post, err := s.postStore.GetPostByID(ctx, commReq.PostID)
-	if err != nil {
+	if err != nil || post.IsRemoved || !post.VisibleTo(identity.Username) {
 		return nil, utils.GqlError{
 			Msg:  fmt.Sprintf("post with id: %s does not exist", commReq.PostID.String()),
 			Type: consts.BadRequestType,
@@ -57,11 +65,27 @@ func (s *CommentServiceImpl) CreateComment(ctx context.Context,
 
 	}
 
+	if commReq.ParentCommentID != nil {
+		if err = s.requireParentInPost(ctx, *commReq.ParentCommentID, commReq.PostID, identity.Username); err != nil {
+			return nil, err
+		}
+	}
+
+	shadowed, err := s.filters.Apply(ctx, Content{
+		Kind:   ContentKindComment,
+		Author: identity.Username,
+		Text:   commReq.Content,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	newComm, err := s.commStore.CreateComment(ctx, models.Comment{
-		Author:          commReq.Author,
+		Author:          identity.Username,
 		Content:         commReq.Content,
 		PostID:          commReq.PostID,
 		ParentCommentID: commReq.ParentCommentID,
+		IsShadowed:      shadowed,
 	})
 
 	if err != nil {
@@ -75,18 +99,74 @@ func (s *CommentServiceImpl) CreateComment(ctx context.Context,
 	logger.Logger.Info(fmt.Sprintf("create comment with id: %s successfully", newComm.ID.String()))
 	return &newComm, nil
 }
-func (s *CommentServiceImpl) GetCommentsByPostID(ctx context.Context, postID uuid.UUID,
-	page *int32) ([]*models.Comment, error) {
-	if page != nil && *page <= 0 {
+func (s *CommentServiceImpl) GetCommentsByPostIDs(ctx context.Context, postIDs []uuid.UUID, sort models.CommentSort,
+	pageReq models.PageRequest) (map[uuid.UUID]*models.CommentConnection, error) {
+	if err := validateCommentSort(sort); err != nil {
+		return nil, err
+	}
+
+	page, err := parsePageRequest(pageReq)
+	if err != nil {
+		return nil, err
+	}
+
+	viewer := viewerName(ctx)
+	comments, err := s.commStore.GetCommentsByPostIDs(ctx, postIDs, sort, withLookahead(page), viewer)
+	if err != nil {
+		logger.Logger.Error(fmt.Sprintf("error getting comments: %v", err))
 		return nil, utils.GqlError{
-			Msg:  "page must be greater than zero",
-			Type: consts.BadRequestType,
+			Msg:  "error getting comments",
+			Type: consts.InternalServerErrorType,
 		}
 	}
 
-	offset, limit := utils.GetOffsetNLimit(page, consts.PageSize)
+	totals, err := s.commStore.CountCommentsByPostIDs(ctx, postIDs, viewer)
+	if err != nil {
+		logger.Logger.Error(fmt.Sprintf("error counting comments: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error getting comments",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	if err = s.hideRemoved(ctx, slices.Concat(slices.Collect(maps.Values(comments))...)); err != nil {
+		return nil, err
+	}
+
+	logger.Logger.Info(fmt.Sprintf("get comments by %d posts successfully", len(postIDs)))
+	return newCommentConnections(postIDs, comments, sort, page, totals), nil
+}
+
+// количество всех комментариев и ответов к постам, вместе с удаленными (их ветки ответов остаются)
+func (s *CommentServiceImpl) GetCommentCounts(ctx context.Context, postIDs []uuid.UUID) (map[uuid.UUID]int32, error) {
+	counts, err := s.commStore.CountAllCommentsByPostIDs(ctx, postIDs, viewerName(ctx))
+	if err != nil {
+		logger.Logger.Error(fmt.Sprintf("error counting comments: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error counting comments",
+			Type: consts.InternalServerErrorType,
+		}
+	}
 
-	comments, err := s.commStore.GetCommentsByPostID(ctx, postID, offset, limit)
+	res := make(map[uuid.UUID]int32, len(counts))
+	for postID, count := range counts {
+		res[postID] = int32(count)
+	}
+	return res, nil
+}
+func (s *CommentServiceImpl) GetRepliesByCommentIDs(ctx context.Context, commentIDs []uuid.UUID,
+	sort models.CommentSort, pageReq models.PageRequest) (map[uuid.UUID]*models.CommentConnection, error) {
+	if err := validateCommentSort(sort); err != nil {
+		return nil, err
+	}
+
+	page, err := parsePageRequest(pageReq)
+	if err != nil {
+		return nil, err
+	}
+
+	viewer := viewerName(ctx)
+	replies, err := s.commStore.GetRepliesByParentCommentIDs(ctx, commentIDs, sort, withLookahead(page), viewer)
 	if err != nil {
 		logger.Logger.Error(fmt.Sprintf("error getting comments: %v", err))
 		return nil, utils.GqlError{
@@ -95,19 +175,371 @@ func (s *CommentServiceImpl) GetCommentsByPostID(ctx context.Context, postID uui
 		}
 	}
 
-	logger.Logger.Info(fmt.Sprintf("get comments by postId: %s successfully", postID.String()))
+	totals, err := s.commStore.CountRepliesByParentCommentIDs(ctx, commentIDs, viewer)
+	if err != nil {
+		logger.Logger.Error(fmt.Sprintf("error counting comments: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error getting comments",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	if err = s.hideRemoved(ctx, slices.Concat(slices.Collect(maps.Values(replies))...)); err != nil {
+		return nil, err
+	}
+
+	logger.Logger.Info(fmt.Sprintf("get replies by %d comments successfully", len(commentIDs)))
+	return newCommentConnections(commentIDs, replies, sort, page, totals), nil
+}
+func (s *CommentServiceImpl) GetCommentTree(ctx context.Context, postID uuid.UUID, maxDepth *int32,
+	page *int32) ([]*models.CommentTreeNode, error) {
+	depth := consts.MaxCommentTreeDepth
+	if maxDepth != nil {
+		if *maxDepth < 0 || *maxDepth > consts.MaxCommentTreeDepth {
+			return nil, utils.GqlError{
+				Msg:  fmt.Sprintf("max depth must be between 0 and %d", consts.MaxCommentTreeDepth),
+				Type: consts.BadRequestType,
+			}
+		}
+		depth = int(*maxDepth)
+	}
+
+	if err := s.requireVisiblePost(ctx, postID, "error getting comment tree"); err != nil {
+		return nil, err
+	}
+
+	offset, limit := utils.GetOffsetNLimit(page, consts.PageSize)
+
+	nodes, err := s.commStore.GetCommentTree(ctx, postID, depth, offset, limit, viewerName(ctx))
+	if err != nil {
+		logger.Logger.Error(fmt.Sprintf("error getting comment tree: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error getting comment tree",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	comments := make([]*models.Comment, 0, len(nodes))
+	for _, node := range nodes {
+		comments = append(comments, node.Comment)
+	}
+	if err = s.hideRemoved(ctx, comments); err != nil {
+		return nil, err
+	}
+
+	logger.Logger.Info(fmt.Sprintf("get comment tree by postId: %s successfully", postID.String()))
+	return nodes, nil
+}
+func (s *CommentServiceImpl) EditComment(ctx context.Context,
+	commReq models.CommentEditRequest) (*models.Comment, error) {
+	if len(commReq.Content) > consts.ContentMaxLen {
+		return nil, utils.GqlError{
+			Msg:  fmt.Sprintf("comment content should no exceed %v characters", consts.ContentMaxLen),
+			Type: consts.BadRequestType,
+		}
+	}
+
+	comment, err := s.getOwnedComment(ctx, commReq.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	shadowed, err := s.filters.Apply(ctx, Content{
+		Kind:   ContentKindComment,
+		ID:     comment.ID,
+		Author: comment.Author,
+		Text:   commReq.Content,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	updatedComm, err := s.commStore.UpdateComment(ctx, commReq.ID, commReq.Content, shadowed)
+	if err != nil {
+		logger.Logger.Error(fmt.Sprintf("error editing comment: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error editing comment",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	logger.Logger.Info(fmt.Sprintf("edit comment with id: %s successfully", commReq.ID.String()))
+	return &updatedComm, nil
+}
+func (s *CommentServiceImpl) DeleteComment(ctx context.Context, commentID uuid.UUID) (*models.Comment, error) {
+	if _, err := s.getOwnedComment(ctx, commentID); err != nil {
+		return nil, err
+	}
+
+	deletedComm, err := s.commStore.DeleteComment(ctx, commentID)
+	if err != nil {
+		logger.Logger.Error(fmt.Sprintf("error deleting comment: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error deleting comment",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	logger.Logger.Info(fmt.Sprintf("delete comment with id: %s successfully", commentID.String()))
+	return &deletedComm, nil
+}
+
+// комментарии к посту, появившиеся после момента since или после комментария lastCommentID, от старых к новым
+func (s *CommentServiceImpl) GetMissedComments(ctx context.Context, postID uuid.UUID, since *time.Time,
+	lastCommentID *uuid.UUID) ([]*models.Comment, error) {
+	var after models.Cursor
+	switch {
+	case since == nil && lastCommentID == nil:
+		return nil, nil
+	case since != nil && lastCommentID != nil:
+		return nil, utils.GqlError{
+			Msg:  "only one of since and lastCommentId can be set",
+			Type: consts.BadRequestType,
+		}
+	}
+
+	if err := s.requireVisiblePost(ctx, postID, "error getting missed comments"); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case since != nil:
+		// created_at хранится без часового пояса в UTC, а pgx записывает в timestamp время по часам самого значения
+		after = models.Cursor{CreatedAt: since.UTC()}
+	default:
+		last, err := s.commStore.GetCommentByID(ctx, *lastCommentID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, utils.GqlError{
+					Msg:  fmt.Sprintf("comment with id: %s not found", lastCommentID.String()),
+					Type: consts.BadRequestType,
+				}
+			}
+			logger.Logger.Error(fmt.Sprintf("error getting comment: %v", err))
+			return nil, utils.GqlError{
+				Msg:  "error getting missed comments",
+				Type: consts.InternalServerErrorType,
+			}
+		}
+		if last.PostID != postID {
+			return nil, utils.GqlError{
+				Msg:  fmt.Sprintf("comment with id: %s does not belong to post %s", lastCommentID.String(), postID.String()),
+				Type: consts.BadRequestType,
+			}
+		}
+		after = models.CommentCursor(last)
+	}
+
+	// на один больше лимита, чтобы понять, что пропущено слишком много
+	comments, err := s.commStore.GetCommentsAfter(ctx, postID, after, consts.MaxReplayComments+1, viewerName(ctx))
+	if err != nil {
+		logger.Logger.Error(fmt.Sprintf("error getting missed comments: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error getting missed comments",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	if len(comments) > consts.MaxReplayComments {
+		return nil, utils.GqlError{
+			Msg:  fmt.Sprintf("more than %d comments were missed, reload the post", consts.MaxReplayComments),
+			Type: consts.BadRequestType,
+		}
+	}
+
+	if err = s.hideRemoved(ctx, comments); err != nil {
+		return nil, err
+	}
 	return comments, nil
 }
-func (s *CommentServiceImpl) GetRepliesByComment(ctx context.Context, commentID uuid.UUID) ([]*models.Comment, error) {
-	replies, err := s.commStore.GetRepliesByParentCommentID(ctx, commentID)
+func (s *CommentServiceImpl) SearchComments(ctx context.Context, query string,
+	postID *uuid.UUID) ([]*models.CommentSearchResult, error) {
+	query, err := parseSearchQuery(query)
 	if err != nil {
-		logger.Logger.Error(fmt.Sprintf("error getting comments: %v", err))
+		return nil, err
+	}
+
+	results, err := s.commStore.SearchComments(ctx, query, postID, consts.SearchCommentsLimit)
+	if err != nil {
+		logger.Logger.Error(fmt.Sprintf("error searching comments: %v", err))
 		return nil, utils.GqlError{
-			Msg:  "error getting comments",
+			Msg:  "error searching comments",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	logger.Logger.Info("search comments successfully")
+	return results, nil
+}
+
+// проверяем, что комментарий, на который отвечают, есть и относится к тому же посту
+func (s *CommentServiceImpl) requireParentInPost(ctx context.Context, parentID, postID uuid.UUID,
+	username string) error {
+	parent, err := s.commStore.GetCommentByID(ctx, parentID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		logger.Logger.Error(fmt.Sprintf("error getting parent comment: %v", err))
+		return utils.GqlError{
+			Msg:  "error creating comment",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	if err != nil || parent.PostID != postID || !parent.VisibleTo(username) {
+		return utils.GqlError{
+			Msg:  fmt.Sprintf("parent comment with id: %s does not exist in post %s", parentID, postID),
+			Type: consts.BadRequestType,
+		}
+	}
+	return nil
+}
+
+// проверяем, что пользователь видит пост: скрытый модератором пост видят только модераторы, скрытый фильтром
+// контента - только автор, для остальных его нет, как и в GetPostByID
+func (s *CommentServiceImpl) requireVisiblePost(ctx context.Context, postID uuid.UUID, errMsg string) error {
+	notFound := utils.GqlError{
+		Msg:  fmt.Sprintf("post with id: %s does not exist", postID.String()),
+		Type: consts.BadRequestType,
+	}
+
+	post, err := s.postStore.GetPostByID(ctx, postID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return notFound
+		}
+		logger.Logger.Error(fmt.Sprintf("error getting post: %v", err))
+		return utils.GqlError{
+			Msg:  errMsg,
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	if !post.VisibleTo(viewerName(ctx)) {
+		return notFound
+	}
+
+	if post.IsRemoved {
+		canModerate, err := s.moderators.canModerate(ctx, post.CommunityID)
+		if err != nil {
+			logger.Logger.Error(fmt.Sprintf("error checking moderator: %v", err))
+			return utils.GqlError{
+				Msg:  errMsg,
+				Type: consts.InternalServerErrorType,
+			}
+		}
+		if !canModerate {
+			return notFound
+		}
+	}
+	return nil
+}
+
+// получаем комментарий и проверяем, что его изменяет автор
+func (s *CommentServiceImpl) getOwnedComment(ctx context.Context, commentID uuid.UUID) (*models.Comment, error) {
+	identity, err := requireIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := s.commStore.GetCommentByID(ctx, commentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.GqlError{
+				Msg:  fmt.Sprintf("comment with id: %s not found", commentID.String()),
+				Type: consts.BadRequestType,
+			}
+		}
+		logger.Logger.Error(fmt.Sprintf("error getting comment: %v", err))
+		return nil, utils.GqlError{
+			Msg:  fmt.Sprintf("error getting comment with id: %s", commentID.String()),
 			Type: consts.InternalServerErrorType,
 		}
 	}
 
-	logger.Logger.Info(fmt.Sprintf("get comments by commentId: %s successfully", commentID.String()))
-	return replies, nil
+	if comment.IsDeleted {
+		return nil, utils.GqlError{
+			Msg:  fmt.Sprintf("comment with id: %s is deleted", commentID.String()),
+			Type: consts.BadRequestType,
+		}
+	}
+
+	if comment.IsRemoved {
+		return nil, utils.GqlError{
+			Msg:  fmt.Sprintf("comment with id: %s is removed by moderator", commentID.String()),
+			Type: consts.BadRequestType,
+		}
+	}
+
+	if comment.Author != identity.Username {
+		return nil, utils.GqlError{
+			Msg:  fmt.Sprintf("only author of the comment with id: %s can change it", commentID.String()),
+			Type: consts.ForbiddenType,
+		}
+	}
+
+	return comment, nil
+}
+
+// у скрытых модераторами комментариев затираем текст и автора для всех, кроме модераторов поста. Сами
+// комментарии остаются, как и удаленные автором, чтобы не терять ветки ответов. Комментарии - копии из хранилища
+func (s *CommentServiceImpl) hideRemoved(ctx context.Context, comments []*models.Comment) error {
+	canModerate := make(map[uuid.UUID]bool) // может ли пользователь модерировать пост, по id поста
+	for _, comment := range comments {
+		if !comment.IsRemoved {
+			continue
+		}
+
+		allowed, ok := canModerate[comment.PostID]
+		if !ok {
+			post, err := s.postStore.GetPostByID(ctx, comment.PostID)
+			if err == nil {
+				allowed, err = s.moderators.canModerate(ctx, post.CommunityID)
+			}
+			if err != nil {
+				logger.Logger.Error(fmt.Sprintf("error checking comment moderator: %v", err))
+				return utils.GqlError{
+					Msg:  "error getting comments",
+					Type: consts.InternalServerErrorType,
+				}
+			}
+			canModerate[comment.PostID] = allowed
+		}
+
+		if !allowed {
+			comment.Content, comment.Author = "", ""
+		}
+	}
+	return nil
+}
+
+// для каждого запрошенного id собираем свою страницу, у id без комментариев она пустая
+func newCommentConnections(ids []uuid.UUID, comments map[uuid.UUID][]*models.Comment, sort models.CommentSort,
+	page models.Page, totals map[uuid.UUID]int) map[uuid.UUID]*models.CommentConnection {
+	conns := make(map[uuid.UUID]*models.CommentConnection, len(ids))
+	for _, id := range ids {
+		conns[id] = newCommentConnection(comments[id], sort, page, totals[id])
+	}
+	return conns
+}
+
+func newCommentConnection(comments []*models.Comment, sort models.CommentSort, page models.Page,
+	total int) *models.CommentConnection {
+	cursorOf := models.CommentSortCursor(sort)
+	comments, pageInfo := cutPage(comments, page, cursorOf)
+
+	edges := make([]*models.CommentEdge, 0, len(comments))
+	for _, comment := range comments {
+		edges = append(edges, &models.CommentEdge{Cursor: cursorOf(comment).Encode(), Node: comment})
+	}
+
+	return &models.CommentConnection{Edges: edges, PageInfo: pageInfo, TotalCount: int32(total)}
+}
+
+func validateCommentSort(sort models.CommentSort) error {
+	if !sort.IsValid() {
+		return utils.GqlError{
+			Msg:  fmt.Sprintf("invalid comment sort: %s", sort),
+			Type: consts.BadRequestType,
+		}
+	}
+	return nil
 }
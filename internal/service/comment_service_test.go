@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"strings"
 	"testing"
 	"time"
 
@@ -9,6 +11,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/nedokyrill/posts-service/internal/models"
 	store_mock "github.com/nedokyrill/posts-service/internal/storage/mocks"
+	"github.com/nedokyrill/posts-service/pkg/consts"
 	"github.com/nedokyrill/posts-service/pkg/logger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -26,14 +29,15 @@ func TestCommentService_CreateComment(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	ctx := context.Background()
+	author := "test_author"
+	ctx := withUser(author)
 	postStorage := store_mock.NewMockPostStorage(ctrl)
 	commentStorage := store_mock.NewMockCommentStorage(ctrl)
 
-	commentService := NewCommentService(commentStorage, postStorage)
+	commentService := NewCommentService(commentStorage, postStorage,
+		store_mock.NewMockCommunityStorage(ctrl), store_mock.NewMockUserStorage(ctrl), nil)
 
 	postID := uuid.New()
-	author := "test_author"
 	content := "test content"
 
 	t.Run("successfully create root comment", func(t *testing.T) {
@@ -46,7 +50,6 @@ func TestCommentService_CreateComment(t *testing.T) {
 		}
 
 		commentReq := models.CommentRequest{
-			Author:  author,
 			Content: content,
 			PostID:  postID,
 		}
@@ -90,7 +93,6 @@ func TestCommentService_CreateComment(t *testing.T) {
 		}
 
 		commentReq := models.CommentRequest{
-			Author:          author,
 			Content:         content,
 			PostID:          postID,
 			ParentCommentID: &parentCommentID,
@@ -109,6 +111,10 @@ func TestCommentService_CreateComment(t *testing.T) {
 			GetPostByID(ctx, postID).
 			Return(post, nil)
 
+		commentStorage.EXPECT().
+			GetCommentByID(ctx, parentCommentID).
+			Return(&models.Comment{ID: parentCommentID, PostID: postID}, nil)
+
 		commentStorage.EXPECT().
 			CreateComment(ctx, gomock.Any()).
 			DoAndReturn(func(ctx context.Context, comm models.Comment) (models.Comment, error) {
@@ -125,9 +131,54 @@ func TestCommentService_CreateComment(t *testing.T) {
 		assert.Equal(t, &expectedComment, result)
 	})
 
+	t.Run("fail when parent comment belongs to another post", func(t *testing.T) {
+		parentCommentID := uuid.New()
+		commentReq := models.CommentRequest{
+			Content:         content,
+			PostID:          postID,
+			ParentCommentID: &parentCommentID,
+		}
+
+		postStorage.EXPECT().
+			GetPostByID(ctx, postID).
+			Return(&models.Post{ID: postID, Author: "post_author", IsCommentsAllowed: true}, nil)
+
+		commentStorage.EXPECT().
+			GetCommentByID(ctx, parentCommentID).
+			Return(&models.Comment{ID: parentCommentID, PostID: uuid.New()}, nil)
+
+		result, err := commentService.CreateComment(ctx, commentReq)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "does not exist in post")
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail when parent comment not found", func(t *testing.T) {
+		parentCommentID := uuid.New()
+		commentReq := models.CommentRequest{
+			Content:         content,
+			PostID:          postID,
+			ParentCommentID: &parentCommentID,
+		}
+
+		postStorage.EXPECT().
+			GetPostByID(ctx, postID).
+			Return(&models.Post{ID: postID, Author: "post_author", IsCommentsAllowed: true}, nil)
+
+		commentStorage.EXPECT().
+			GetCommentByID(ctx, parentCommentID).
+			Return(nil, sql.ErrNoRows)
+
+		result, err := commentService.CreateComment(ctx, commentReq)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "does not exist in post")
+		assert.Nil(t, result)
+	})
+
 	t.Run("fail when post not found", func(t *testing.T) {
 		commentReq := models.CommentRequest{
-			Author:  author,
 			Content: content,
 			PostID:  postID,
 		}
@@ -152,7 +203,6 @@ func TestCommentService_CreateComment(t *testing.T) {
 		}
 
 		commentReq := models.CommentRequest{
-			Author:  author,
 			Content: content,
 			PostID:  postID,
 		}
@@ -168,6 +218,17 @@ func TestCommentService_CreateComment(t *testing.T) {
 		assert.Nil(t, result)
 	})
 
+	t.Run("fail when user is not authenticated", func(t *testing.T) {
+		result, err := commentService.CreateComment(context.Background(), models.CommentRequest{
+			Content: content,
+			PostID:  postID,
+		})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "authentication required")
+		assert.Nil(t, result)
+	})
+
 	t.Run("fail when storage fails to create comment", func(t *testing.T) {
 		post := &models.Post{
 			ID:                postID,
@@ -178,7 +239,6 @@ func TestCommentService_CreateComment(t *testing.T) {
 		}
 
 		commentReq := models.CommentRequest{
-			Author:  author,
 			Content: content,
 			PostID:  postID,
 		}
@@ -197,3 +257,712 @@ func TestCommentService_CreateComment(t *testing.T) {
 		assert.Nil(t, result)
 	})
 }
+
+func TestCommentService_EditComment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	author := "test_author"
+	ctx := withUser(author)
+	anotherCtx := withUser("another_author")
+	postStorage := store_mock.NewMockPostStorage(ctrl)
+	commentStorage := store_mock.NewMockCommentStorage(ctrl)
+
+	commentService := NewCommentService(commentStorage, postStorage,
+		store_mock.NewMockCommunityStorage(ctrl), store_mock.NewMockUserStorage(ctrl), nil)
+
+	commentID := uuid.New()
+	existingComment := &models.Comment{
+		ID:      commentID,
+		Author:  author,
+		Content: "old content",
+		PostID:  uuid.New(),
+	}
+
+	t.Run("successfully edit comment", func(t *testing.T) {
+		commentStorage.EXPECT().
+			GetCommentByID(ctx, commentID).
+			Return(existingComment, nil)
+
+		commentStorage.EXPECT().
+			UpdateComment(ctx, commentID, "new content", false).
+			Return(models.Comment{ID: commentID, Author: author, Content: "new content"}, nil)
+
+		result, err := commentService.EditComment(ctx, models.CommentEditRequest{
+			ID:      commentID,
+			Content: "new content",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "new content", result.Content)
+	})
+
+	t.Run("fail when user is not the author", func(t *testing.T) {
+		commentStorage.EXPECT().
+			GetCommentByID(anotherCtx, commentID).
+			Return(existingComment, nil)
+
+		result, err := commentService.EditComment(anotherCtx, models.CommentEditRequest{
+			ID:      commentID,
+			Content: "new content",
+		})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "only author of the comment")
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail when comment is deleted", func(t *testing.T) {
+		commentStorage.EXPECT().
+			GetCommentByID(ctx, commentID).
+			Return(&models.Comment{ID: commentID, IsDeleted: true}, nil)
+
+		result, err := commentService.EditComment(ctx, models.CommentEditRequest{
+			ID:      commentID,
+			Content: "new content",
+		})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "is deleted")
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail when content is too long", func(t *testing.T) {
+		result, err := commentService.EditComment(ctx, models.CommentEditRequest{
+			ID:      commentID,
+			Content: strings.Repeat("a", consts.ContentMaxLen+1),
+		})
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail when comment not found", func(t *testing.T) {
+		commentStorage.EXPECT().
+			GetCommentByID(ctx, commentID).
+			Return(nil, sql.ErrNoRows)
+
+		result, err := commentService.EditComment(ctx, models.CommentEditRequest{
+			ID:      commentID,
+			Content: "new content",
+		})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+		assert.Nil(t, result)
+	})
+}
+
+func TestCommentService_DeleteComment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	author := "test_author"
+	ctx := withUser(author)
+	anotherCtx := withUser("another_author")
+	postStorage := store_mock.NewMockPostStorage(ctrl)
+	commentStorage := store_mock.NewMockCommentStorage(ctrl)
+
+	commentService := NewCommentService(commentStorage, postStorage,
+		store_mock.NewMockCommunityStorage(ctrl), store_mock.NewMockUserStorage(ctrl), nil)
+
+	commentID := uuid.New()
+	existingComment := &models.Comment{
+		ID:      commentID,
+		Author:  author,
+		Content: "content",
+		PostID:  uuid.New(),
+	}
+
+	t.Run("successfully delete comment", func(t *testing.T) {
+		commentStorage.EXPECT().
+			GetCommentByID(ctx, commentID).
+			Return(existingComment, nil)
+
+		commentStorage.EXPECT().
+			DeleteComment(ctx, commentID).
+			Return(models.Comment{ID: commentID, IsDeleted: true}, nil)
+
+		result, err := commentService.DeleteComment(ctx, commentID)
+
+		require.NoError(t, err)
+		assert.True(t, result.IsDeleted)
+	})
+
+	t.Run("fail when user is not the author", func(t *testing.T) {
+		commentStorage.EXPECT().
+			GetCommentByID(anotherCtx, commentID).
+			Return(existingComment, nil)
+
+		result, err := commentService.DeleteComment(anotherCtx, commentID)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "only author of the comment")
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail when user is not authenticated", func(t *testing.T) {
+		result, err := commentService.DeleteComment(context.Background(), commentID)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "authentication required")
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail when storage fails to delete comment", func(t *testing.T) {
+		commentStorage.EXPECT().
+			GetCommentByID(ctx, commentID).
+			Return(existingComment, nil)
+
+		commentStorage.EXPECT().
+			DeleteComment(ctx, commentID).
+			Return(models.Comment{}, assert.AnError)
+
+		result, err := commentService.DeleteComment(ctx, commentID)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+	})
+}
+
+func TestCommentService_GetCommentsByPostIDs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	postStorage := store_mock.NewMockPostStorage(ctrl)
+	commentStorage := store_mock.NewMockCommentStorage(ctrl)
+
+	commentService := NewCommentService(commentStorage, postStorage,
+		store_mock.NewMockCommunityStorage(ctrl), store_mock.NewMockUserStorage(ctrl), nil)
+
+	postID := uuid.New()
+	emptyPostID := uuid.New()
+	postIDs := []uuid.UUID{postID, emptyPostID}
+	now := time.Now()
+	comments := []*models.Comment{
+		{ID: uuid.New(), Author: "test_author", Content: "first", PostID: postID, CreatedAt: &now},
+		{ID: uuid.New(), Author: "test_author", Content: "second", PostID: postID, CreatedAt: &now},
+	}
+
+	t.Run("successfully get first pages of comments", func(t *testing.T) {
+		first := int32(1)
+
+		commentStorage.EXPECT().
+			GetCommentsByPostIDs(ctx, postIDs, models.CommentSortNew, models.Page{Limit: 2}, "").
+			Return(map[uuid.UUID][]*models.Comment{postID: comments}, nil)
+
+		commentStorage.EXPECT().
+			CountCommentsByPostIDs(ctx, postIDs, "").
+			Return(map[uuid.UUID]int{postID: 2}, nil)
+
+		result, err := commentService.GetCommentsByPostIDs(ctx, postIDs, models.CommentSortNew,
+			models.PageRequest{First: &first})
+
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+
+		conn := result[postID]
+		require.Len(t, conn.Edges, 1)
+		assert.Equal(t, comments[0], conn.Edges[0].Node)
+		assert.Equal(t, models.CommentCursor(comments[0]).Encode(), conn.Edges[0].Cursor)
+		assert.True(t, conn.PageInfo.HasNextPage)
+		assert.Equal(t, int32(2), conn.TotalCount)
+
+		empty := result[emptyPostID]
+		assert.Empty(t, empty.Edges)
+		assert.False(t, empty.PageInfo.HasNextPage)
+		assert.Equal(t, int32(0), empty.TotalCount)
+	})
+
+	t.Run("fail with invalid page request", func(t *testing.T) {
+		first, last := int32(1), int32(1)
+
+		result, err := commentService.GetCommentsByPostIDs(ctx, postIDs, models.CommentSortNew,
+			models.PageRequest{First: &first, Last: &last})
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail when storage fails to get comments", func(t *testing.T) {
+		commentStorage.EXPECT().
+			GetCommentsByPostIDs(ctx, postIDs, models.CommentSortNew,
+				models.Page{Limit: consts.PageSize + 1}, "").
+			Return(nil, assert.AnError)
+
+		result, err := commentService.GetCommentsByPostIDs(ctx, postIDs, models.CommentSortNew, models.PageRequest{})
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail when sort is invalid", func(t *testing.T) {
+		result, err := commentService.GetCommentsByPostIDs(ctx, postIDs, models.CommentSort("RANDOM"),
+			models.PageRequest{})
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+	})
+}
+
+func TestCommentService_GetCommentCounts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	commentStorage := store_mock.NewMockCommentStorage(ctrl)
+
+	commentService := NewCommentService(commentStorage, store_mock.NewMockPostStorage(ctrl),
+		store_mock.NewMockCommunityStorage(ctrl), store_mock.NewMockUserStorage(ctrl), nil)
+
+	postID := uuid.New()
+	postIDs := []uuid.UUID{postID, uuid.New()}
+
+	t.Run("successfully count comments", func(t *testing.T) {
+		commentStorage.EXPECT().
+			CountAllCommentsByPostIDs(ctx, postIDs, "").
+			Return(map[uuid.UUID]int{postID: 3}, nil)
+
+		counts, err := commentService.GetCommentCounts(ctx, postIDs)
+
+		require.NoError(t, err)
+		assert.Equal(t, map[uuid.UUID]int32{postID: 3}, counts)
+	})
+
+	t.Run("fail when storage fails", func(t *testing.T) {
+		commentStorage.EXPECT().
+			CountAllCommentsByPostIDs(ctx, postIDs, "").
+			Return(nil, assert.AnError)
+
+		counts, err := commentService.GetCommentCounts(ctx, postIDs)
+
+		assert.Error(t, err)
+		assert.Nil(t, counts)
+	})
+}
+
+func TestCommentService_GetRepliesByCommentIDs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	postStorage := store_mock.NewMockPostStorage(ctrl)
+	commentStorage := store_mock.NewMockCommentStorage(ctrl)
+
+	commentService := NewCommentService(commentStorage, postStorage,
+		store_mock.NewMockCommunityStorage(ctrl), store_mock.NewMockUserStorage(ctrl), nil)
+
+	parentID := uuid.New()
+	otherParentID := uuid.New()
+	parentIDs := []uuid.UUID{parentID, otherParentID}
+	now := time.Now()
+	replies := []*models.Comment{
+		{ID: uuid.New(), Author: "test_author", Content: "reply", ParentCommentID: &parentID, CreatedAt: &now},
+	}
+	otherReplies := []*models.Comment{
+		{ID: uuid.New(), Author: "test_author", Content: "other reply", ParentCommentID: &otherParentID, CreatedAt: &now},
+	}
+
+	t.Run("successfully get replies", func(t *testing.T) {
+		commentStorage.EXPECT().
+			GetRepliesByParentCommentIDs(ctx, parentIDs, models.CommentSortOld,
+				models.Page{Limit: consts.PageSize + 1}, "").
+			Return(map[uuid.UUID][]*models.Comment{parentID: replies, otherParentID: otherReplies}, nil)
+
+		commentStorage.EXPECT().
+			CountRepliesByParentCommentIDs(ctx, parentIDs, "").
+			Return(map[uuid.UUID]int{parentID: 1, otherParentID: 1}, nil)
+
+		result, err := commentService.GetRepliesByCommentIDs(ctx, parentIDs, models.CommentSortOld, models.PageRequest{})
+
+		require.NoError(t, err)
+		require.Len(t, result[parentID].Edges, 1)
+		assert.Equal(t, replies[0], result[parentID].Edges[0].Node)
+		assert.False(t, result[parentID].PageInfo.HasNextPage)
+		assert.Equal(t, int32(1), result[parentID].TotalCount)
+
+		require.Len(t, result[otherParentID].Edges, 1)
+		assert.Equal(t, otherReplies[0], result[otherParentID].Edges[0].Node)
+	})
+
+	t.Run("successfully get best replies with sort key in cursor", func(t *testing.T) {
+		best := &models.Comment{ID: uuid.New(), Content: "best reply", ParentCommentID: &parentID, CreatedAt: &now,
+			Upvotes: 5, Best: models.WilsonScore(5, 0)}
+
+		commentStorage.EXPECT().
+			GetRepliesByParentCommentIDs(ctx, parentIDs, models.CommentSortBest,
+				models.Page{Limit: consts.PageSize + 1}, "").
+			Return(map[uuid.UUID][]*models.Comment{parentID: {best}}, nil)
+
+		commentStorage.EXPECT().
+			CountRepliesByParentCommentIDs(ctx, parentIDs, "").
+			Return(map[uuid.UUID]int{parentID: 1}, nil)
+
+		result, err := commentService.GetRepliesByCommentIDs(ctx, parentIDs, models.CommentSortBest, models.PageRequest{})
+
+		require.NoError(t, err)
+		require.Len(t, result[parentID].Edges, 1)
+
+		cursor, err := models.DecodeCursor(result[parentID].Edges[0].Cursor)
+		require.NoError(t, err)
+		assert.Equal(t, best.Best, cursor.Key)
+		assert.Equal(t, best.ID, cursor.ID)
+	})
+
+	t.Run("fail when storage fails to count replies", func(t *testing.T) {
+		commentStorage.EXPECT().
+			GetRepliesByParentCommentIDs(ctx, parentIDs, models.CommentSortOld,
+				models.Page{Limit: consts.PageSize + 1}, "").
+			Return(map[uuid.UUID][]*models.Comment{parentID: replies}, nil)
+
+		commentStorage.EXPECT().
+			CountRepliesByParentCommentIDs(ctx, parentIDs, "").
+			Return(nil, assert.AnError)
+
+		result, err := commentService.GetRepliesByCommentIDs(ctx, parentIDs, models.CommentSortOld, models.PageRequest{})
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+	})
+}
+
+func TestCommentService_GetCommentTree(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	postStorage := store_mock.NewMockPostStorage(ctrl)
+	commentStorage := store_mock.NewMockCommentStorage(ctrl)
+
+	commentService := NewCommentService(commentStorage, postStorage,
+		store_mock.NewMockCommunityStorage(ctrl), store_mock.NewMockUserStorage(ctrl), nil)
+
+	postID := uuid.New()
+	root := &models.Comment{ID: uuid.New(), Author: "test_author", Content: "root", PostID: postID}
+	reply := &models.Comment{ID: uuid.New(), Author: "test_author", Content: "reply", PostID: postID,
+		ParentCommentID: &root.ID}
+	nodes := []*models.CommentTreeNode{
+		{Comment: root, Depth: 0, Path: []uuid.UUID{root.ID}},
+		{Comment: reply, Depth: 1, Path: []uuid.UUID{root.ID, reply.ID}},
+	}
+
+	t.Run("successfully get second page of tree", func(t *testing.T) {
+		maxDepth, page := int32(3), int32(2)
+
+		postStorage.EXPECT().
+			GetPostByID(ctx, postID).
+			Return(&models.Post{ID: postID}, nil)
+
+		commentStorage.EXPECT().
+			GetCommentTree(ctx, postID, 3, consts.PageSize, consts.PageSize, "").
+			Return(nodes, nil)
+
+		result, err := commentService.GetCommentTree(ctx, postID, &maxDepth, &page)
+
+		require.NoError(t, err)
+		assert.Equal(t, nodes, result)
+	})
+
+	t.Run("use max depth by default", func(t *testing.T) {
+		postStorage.EXPECT().
+			GetPostByID(ctx, postID).
+			Return(&models.Post{ID: postID}, nil)
+
+		commentStorage.EXPECT().
+			GetCommentTree(ctx, postID, consts.MaxCommentTreeDepth, 0, consts.PageSize, "").
+			Return(nodes, nil)
+
+		result, err := commentService.GetCommentTree(ctx, postID, nil, nil)
+
+		require.NoError(t, err)
+		assert.Len(t, result, 2)
+	})
+
+	t.Run("fail with invalid max depth", func(t *testing.T) {
+		maxDepth := int32(consts.MaxCommentTreeDepth + 1)
+
+		result, err := commentService.GetCommentTree(ctx, postID, &maxDepth, nil)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "max depth must be between")
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail when post not found", func(t *testing.T) {
+		postStorage.EXPECT().
+			GetPostByID(ctx, postID).
+			Return(nil, sql.ErrNoRows)
+
+		result, err := commentService.GetCommentTree(ctx, postID, nil, nil)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "does not exist")
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail when post removed by moderator", func(t *testing.T) {
+		postStorage.EXPECT().
+			GetPostByID(ctx, postID).
+			Return(&models.Post{ID: postID, IsRemoved: true}, nil)
+
+		result, err := commentService.GetCommentTree(ctx, postID, nil, nil)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "does not exist")
+		assert.Nil(t, result)
+	})
+}
+
+func TestCommentService_GetMissedComments(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	postStorage := store_mock.NewMockPostStorage(ctrl)
+	commentStorage := store_mock.NewMockCommentStorage(ctrl)
+
+	commentService := NewCommentService(commentStorage, postStorage,
+		store_mock.NewMockCommunityStorage(ctrl), store_mock.NewMockUserStorage(ctrl), nil)
+
+	postID := uuid.New()
+	post := &models.Post{ID: postID}
+	now := time.Now().UTC()
+	last := &models.Comment{ID: uuid.New(), PostID: postID, CreatedAt: &now}
+	missed := []*models.Comment{{ID: uuid.New(), PostID: postID, CreatedAt: &now}}
+
+	t.Run("nothing to replay without since and lastCommentId", func(t *testing.T) {
+		result, err := commentService.GetMissedComments(ctx, postID, nil, nil)
+
+		require.NoError(t, err)
+		assert.Nil(t, result)
+	})
+
+	t.Run("successfully get comments since time", func(t *testing.T) {
+		postStorage.EXPECT().
+			GetPostByID(ctx, postID).
+			Return(post, nil)
+
+		commentStorage.EXPECT().
+			GetCommentsAfter(ctx, postID, models.Cursor{CreatedAt: now}, consts.MaxReplayComments+1, "").
+			Return(missed, nil)
+
+		result, err := commentService.GetMissedComments(ctx, postID, &now, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, missed, result)
+	})
+
+	t.Run("convert since to utc", func(t *testing.T) {
+		sinceMoscow := now.In(time.FixedZone("MSK", 3*60*60))
+
+		postStorage.EXPECT().
+			GetPostByID(ctx, postID).
+			Return(post, nil)
+
+		commentStorage.EXPECT().
+			GetCommentsAfter(ctx, postID, gomock.Any(), consts.MaxReplayComments+1, "").
+			DoAndReturn(func(ctx context.Context, postID uuid.UUID, after models.Cursor, limit int,
+				viewer string) ([]*models.Comment, error) {
+				assert.Equal(t, time.UTC, after.CreatedAt.Location())
+				assert.True(t, now.Equal(after.CreatedAt))
+				return missed, nil
+			})
+
+		result, err := commentService.GetMissedComments(ctx, postID, &sinceMoscow, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, missed, result)
+	})
+
+	t.Run("successfully get comments after last comment", func(t *testing.T) {
+		postStorage.EXPECT().
+			GetPostByID(ctx, postID).
+			Return(post, nil)
+
+		commentStorage.EXPECT().
+			GetCommentByID(ctx, last.ID).
+			Return(last, nil)
+
+		commentStorage.EXPECT().
+			GetCommentsAfter(ctx, postID, models.CommentCursor(last), consts.MaxReplayComments+1, "").
+			Return(missed, nil)
+
+		result, err := commentService.GetMissedComments(ctx, postID, nil, &last.ID)
+
+		require.NoError(t, err)
+		assert.Equal(t, missed, result)
+	})
+
+	t.Run("fail when both since and lastCommentId are set", func(t *testing.T) {
+		result, err := commentService.GetMissedComments(ctx, postID, &now, &last.ID)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail when last comment belongs to another post", func(t *testing.T) {
+		postStorage.EXPECT().
+			GetPostByID(ctx, postID).
+			Return(post, nil)
+
+		commentStorage.EXPECT().
+			GetCommentByID(ctx, last.ID).
+			Return(&models.Comment{ID: last.ID, PostID: uuid.New(), CreatedAt: &now}, nil)
+
+		result, err := commentService.GetMissedComments(ctx, postID, nil, &last.ID)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "does not belong to post")
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail when too many comments were missed", func(t *testing.T) {
+		postStorage.EXPECT().
+			GetPostByID(ctx, postID).
+			Return(post, nil)
+
+		commentStorage.EXPECT().
+			GetCommentsAfter(ctx, postID, models.Cursor{CreatedAt: now}, consts.MaxReplayComments+1, "").
+			Return(make([]*models.Comment, consts.MaxReplayComments+1), nil)
+
+		result, err := commentService.GetMissedComments(ctx, postID, &now, nil)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "reload the post")
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail when post removed by moderator", func(t *testing.T) {
+		postStorage.EXPECT().
+			GetPostByID(ctx, postID).
+			Return(&models.Post{ID: postID, IsRemoved: true}, nil)
+
+		result, err := commentService.GetMissedComments(ctx, postID, &now, nil)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "does not exist")
+		assert.Nil(t, result)
+	})
+}
+
+func TestCommentService_SearchComments(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	commentStorage := store_mock.NewMockCommentStorage(ctrl)
+
+	commentService := NewCommentService(commentStorage, store_mock.NewMockPostStorage(ctrl),
+		store_mock.NewMockCommunityStorage(ctrl), store_mock.NewMockUserStorage(ctrl), nil)
+
+	postID := uuid.New()
+
+	t.Run("successfully search comments", func(t *testing.T) {
+		results := []*models.CommentSearchResult{{Comment: &models.Comment{ID: uuid.New(), PostID: postID}, Rank: 0.5}}
+		commentStorage.EXPECT().
+			SearchComments(ctx, "search", &postID, consts.SearchCommentsLimit).
+			Return(results, nil)
+
+		found, err := commentService.SearchComments(ctx, " search\n", &postID)
+
+		require.NoError(t, err)
+		assert.Equal(t, results, found)
+	})
+
+	t.Run("fail with empty query", func(t *testing.T) {
+		found, err := commentService.SearchComments(ctx, "", nil)
+
+		assert.Error(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("fail when storage fails", func(t *testing.T) {
+		commentStorage.EXPECT().
+			SearchComments(ctx, "search", nil, consts.SearchCommentsLimit).
+			Return(nil, assert.AnError)
+
+		found, err := commentService.SearchComments(ctx, "search", nil)
+
+		assert.Error(t, err)
+		assert.Nil(t, found)
+	})
+}
+
+func TestCommentService_HideRemovedComments(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	commentStorage := store_mock.NewMockCommentStorage(ctrl)
+	postStorage := store_mock.NewMockPostStorage(ctrl)
+	userStorage := store_mock.NewMockUserStorage(ctrl)
+
+	commentService := NewCommentService(commentStorage, postStorage, store_mock.NewMockCommunityStorage(ctrl),
+		userStorage, nil)
+
+	post := &models.Post{ID: uuid.New(), Author: "test_author"}
+	postIDs := []uuid.UUID{post.ID}
+	newComments := func() []*models.Comment {
+		return []*models.Comment{
+			{ID: uuid.New(), Author: "test_user", Content: "visible", PostID: post.ID},
+			{ID: uuid.New(), Author: "spammer", Content: "spam", PostID: post.ID, IsRemoved: true},
+		}
+	}
+
+	expectComments := func(ctx context.Context, viewer string) {
+		commentStorage.EXPECT().
+			GetCommentsByPostIDs(ctx, postIDs, models.CommentSortNew,
+				models.Page{Limit: consts.PageSize + 1}, viewer).
+			Return(map[uuid.UUID][]*models.Comment{post.ID: newComments()}, nil)
+		commentStorage.EXPECT().
+			CountCommentsByPostIDs(ctx, postIDs, viewer).
+			Return(map[uuid.UUID]int{post.ID: 2}, nil)
+		postStorage.EXPECT().
+			GetPostByID(ctx, post.ID).
+			Return(post, nil)
+	}
+
+	t.Run("redact removed comment for normal user", func(t *testing.T) {
+		// Setup
+		ctx := withUser("test_user")
+
+		// Mock expectations
+		expectComments(ctx, "test_user")
+		userStorage.EXPECT().
+			GetUserByID(ctx, gomock.Any()).
+			Return(&models.User{Username: "test_user", Role: models.RoleUser}, nil)
+
+		// Execute
+		result, err := commentService.GetCommentsByPostIDs(ctx, postIDs, models.CommentSortNew, models.PageRequest{})
+
+		// Verify
+		require.NoError(t, err)
+		edges := result[post.ID].Edges
+		require.Len(t, edges, 2)
+		assert.Equal(t, "visible", edges[0].Node.Content)
+		assert.True(t, edges[1].Node.IsRemoved)
+		assert.Empty(t, edges[1].Node.Content)
+		assert.Empty(t, edges[1].Node.Author)
+	})
+
+	t.Run("show removed comment to moderator", func(t *testing.T) {
+		// Setup
+		ctx := withUser("test_moderator")
+
+		// Mock expectations
+		expectComments(ctx, "test_moderator")
+		userStorage.EXPECT().
+			GetUserByID(ctx, gomock.Any()).
+			Return(&models.User{Username: "test_moderator", Role: models.RoleModerator}, nil)
+
+		// Execute
+		result, err := commentService.GetCommentsByPostIDs(ctx, postIDs, models.CommentSortNew, models.PageRequest{})
+
+		// Verify
+		require.NoError(t, err)
+		edges := result[post.ID].Edges
+		require.Len(t, edges, 2)
+		assert.Equal(t, "spam", edges[1].Node.Content)
+		assert.Equal(t, "spammer", edges[1].Node.Author)
+	})
+}
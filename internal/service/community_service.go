@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/internal/storage"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+	"github.com/nedokyrill/posts-service/pkg/logger"
+	"github.com/nedokyrill/posts-service/pkg/utils"
+)
+
+// slug используется в ссылках на сообщество, поэтому только латиница, цифры, "-" и "_"
+var slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+type CommunityServiceImpl struct {
+	store storage.CommunityStorage
+}
+
+func NewCommunityService(store storage.CommunityStorage) *CommunityServiceImpl {
+	return &CommunityServiceImpl{
+		store: store,
+	}
+}
+
+func (s *CommunityServiceImpl) CreateCommunity(ctx context.Context,
+	req models.CommunityRequest) (*models.Community, error) {
+	identity, err := requireIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = validateCommunity(&req); err != nil {
+		return nil, err
+	}
+
+	community, err := s.store.CreateCommunity(ctx, models.Community{
+		Slug:          req.Slug,
+		Title:         req.Title,
+		Description:   req.Description,
+		Owner:         identity.Username,
+		PostPolicy:    req.PostPolicy,
+		CommentPolicy: req.CommentPolicy,
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrAlreadyExists) {
+			return nil, utils.GqlError{
+				Msg:  fmt.Sprintf("community slug %s is already taken", req.Slug),
+				Type: consts.BadRequestType,
+			}
+		}
+		logger.Logger.Error(fmt.Sprintf("error creating community: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error creating community",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	logger.Logger.Info(fmt.Sprintf("create community with id: %s successfully", community.ID.String()))
+	return &community, nil
+}
+func (s *CommunityServiceImpl) GetCommunityBySlug(ctx context.Context, slug string) (*models.Community, error) {
+	return getCommunity(ctx, s.store, slug)
+}
+func (s *CommunityServiceImpl) GetCommunitiesByIDs(ctx context.Context,
+	ids []uuid.UUID) (map[uuid.UUID]*models.Community, error) {
+	communities, err := s.store.GetCommunitiesByIDs(ctx, ids)
+	if err != nil {
+		logger.Logger.Error(fmt.Sprintf("error getting communities: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error getting communities",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	logger.Logger.Info(fmt.Sprintf("get %d communities successfully", len(ids)))
+	return communities, nil
+}
+func (s *CommunityServiceImpl) JoinCommunity(ctx context.Context, slug string) (*models.Community, error) {
+	identity, err := requireIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	community, err := getCommunity(ctx, s.store, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	joined, err := s.store.AddMember(ctx, community.ID, identity.Username)
+	if err != nil {
+		logger.Logger.Error(fmt.Sprintf("error joining community: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error joining community",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	logger.Logger.Info(fmt.Sprintf("join community with id: %s successfully", community.ID.String()))
+	return &joined, nil
+}
+func (s *CommunityServiceImpl) LeaveCommunity(ctx context.Context, slug string) (*models.Community, error) {
+	identity, err := requireIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	community, err := getCommunity(ctx, s.store, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	// иначе у сообщества мог бы не остаться ни одного участника, который им управляет
+	if community.Owner == identity.Username {
+		return nil, utils.GqlError{
+			Msg:  "community owner can not leave the community",
+			Type: consts.BadRequestType,
+		}
+	}
+
+	left, err := s.store.RemoveMember(ctx, community.ID, identity.Username)
+	if err != nil {
+		logger.Logger.Error(fmt.Sprintf("error leaving community: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error leaving community",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	logger.Logger.Info(fmt.Sprintf("leave community with id: %s successfully", community.ID.String()))
+	return &left, nil
+}
+func (s *CommunityServiceImpl) GetMemberships(ctx context.Context, communityIDs []uuid.UUID,
+	username string) (map[uuid.UUID]bool, error) {
+	memberships, err := s.store.GetMemberships(ctx, communityIDs, username)
+	if err != nil {
+		logger.Logger.Error(fmt.Sprintf("error getting community memberships: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error getting community memberships",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+	return memberships, nil
+}
+
+// получение сообщества по slug без учета регистра и пробелов по краям, нужно и сервису постов
+func getCommunity(ctx context.Context, store storage.CommunityStorage, slug string) (*models.Community, error) {
+	slug = strings.ToLower(strings.TrimSpace(slug))
+
+	community, err := store.GetCommunityBySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.GqlError{
+				Msg:  fmt.Sprintf("community %s not found", slug),
+				Type: consts.BadRequestType,
+			}
+		}
+		logger.Logger.Error(fmt.Sprintf("error getting community: %v", err))
+		return nil, utils.GqlError{
+			Msg:  fmt.Sprintf("error getting community %s", slug),
+			Type: consts.InternalServerErrorType,
+		}
+	}
+	return community, nil
+}
+
+// проверяем запрос и приводим slug к нижнему регистру
+func validateCommunity(req *models.CommunityRequest) error {
+	req.Slug = strings.ToLower(strings.TrimSpace(req.Slug))
+	req.Title = strings.TrimSpace(req.Title)
+
+	if n := len(req.Slug); n < consts.CommunitySlugMinLen || n > consts.CommunitySlugMaxLen ||
+		!slugPattern.MatchString(req.Slug) {
+		return utils.GqlError{
+			Msg: fmt.Sprintf("slug must be %d to %d latin letters, digits, \"-\" or \"_\"",
+				consts.CommunitySlugMinLen, consts.CommunitySlugMaxLen),
+			Type: consts.BadRequestType,
+		}
+	}
+	if req.Title == "" || utf8.RuneCountInString(req.Title) > consts.CommunityTitleMaxLen {
+		return utils.GqlError{
+			Msg:  fmt.Sprintf("community title must be between 1 and %d characters", consts.CommunityTitleMaxLen),
+			Type: consts.BadRequestType,
+		}
+	}
+	if utf8.RuneCountInString(req.Description) > consts.CommunityDescriptionMaxLen {
+		return utils.GqlError{
+			Msg:  fmt.Sprintf("community description must be no longer than %d characters", consts.CommunityDescriptionMaxLen),
+			Type: consts.BadRequestType,
+		}
+	}
+	if !req.PostPolicy.IsValid() || !req.CommentPolicy.IsValid() {
+		return utils.GqlError{
+			Msg:  fmt.Sprintf("invalid community policies: post %s, comment %s", req.PostPolicy, req.CommentPolicy),
+			Type: consts.BadRequestType,
+		}
+	}
+	return nil
+}
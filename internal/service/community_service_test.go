@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/internal/storage"
+	store_mock "github.com/nedokyrill/posts-service/internal/storage/mocks"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+	"github.com/nedokyrill/posts-service/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommunityService_CreateCommunity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := withUser("test_owner")
+	communityStorage := store_mock.NewMockCommunityStorage(ctrl)
+
+	communityService := NewCommunityService(communityStorage)
+
+	validReq := func() models.CommunityRequest {
+		return models.CommunityRequest{
+			Slug:          " GoLang ",
+			Title:         "Go",
+			PostPolicy:    models.PostPolicyOpen,
+			CommentPolicy: models.CommentPolicyOpen,
+		}
+	}
+
+	t.Run("successfully create community", func(t *testing.T) {
+		// Mock expectations
+		communityStorage.EXPECT().
+			CreateCommunity(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, community models.Community) (models.Community, error) {
+				assert.Equal(t, "golang", community.Slug)
+				assert.Equal(t, "test_owner", community.Owner)
+				community.ID = uuid.New()
+				community.MemberCount = 1
+				return community, nil
+			})
+
+		// Execute
+		result, err := communityService.CreateCommunity(ctx, validReq())
+
+		// Verify
+		require.NoError(t, err)
+		assert.Equal(t, "golang", result.Slug)
+		assert.Equal(t, int32(1), result.MemberCount)
+	})
+
+	t.Run("fail on invalid request", func(t *testing.T) {
+		for name, change := range map[string]func(req *models.CommunityRequest){
+			"short slug":     func(req *models.CommunityRequest) { req.Slug = "go" },
+			"bad slug":       func(req *models.CommunityRequest) { req.Slug = "go lang" },
+			"empty title":    func(req *models.CommunityRequest) { req.Title = " " },
+			"invalid policy": func(req *models.CommunityRequest) { req.PostPolicy = "CLOSED" },
+		} {
+			t.Run(name, func(t *testing.T) {
+				// Setup
+				req := validReq()
+				change(&req)
+
+				// Execute
+				result, err := communityService.CreateCommunity(ctx, req)
+
+				// Verify
+				require.Error(t, err)
+				assert.Equal(t, consts.BadRequestType, err.(utils.GqlError).Type)
+				assert.Nil(t, result)
+			})
+		}
+	})
+
+	t.Run("fail when slug is taken", func(t *testing.T) {
+		// Mock expectations
+		communityStorage.EXPECT().
+			CreateCommunity(ctx, gomock.Any()).
+			Return(models.Community{}, fmt.Errorf("community: %w", storage.ErrAlreadyExists))
+
+		// Execute
+		result, err := communityService.CreateCommunity(ctx, validReq())
+
+		// Verify
+		require.Error(t, err)
+		gqlErr := err.(utils.GqlError)
+		assert.Equal(t, "community slug golang is already taken", gqlErr.Msg)
+		assert.Equal(t, consts.BadRequestType, gqlErr.Type)
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail when user is not authenticated", func(t *testing.T) {
+		// Execute
+		result, err := communityService.CreateCommunity(context.Background(), validReq())
+
+		// Verify
+		require.Error(t, err)
+		assert.Equal(t, consts.UnauthorizedType, err.(utils.GqlError).Type)
+		assert.Nil(t, result)
+	})
+}
+
+func TestCommunityService_Membership(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ownerCtx := withUser("test_owner")
+	userCtx := withUser("test_user")
+	communityStorage := store_mock.NewMockCommunityStorage(ctrl)
+
+	communityService := NewCommunityService(communityStorage)
+
+	community := &models.Community{ID: uuid.New(), Slug: "golang", Owner: "test_owner", MemberCount: 1}
+
+	t.Run("successfully join community", func(t *testing.T) {
+		// Mock expectations
+		communityStorage.EXPECT().GetCommunityBySlug(userCtx, "golang").Return(community, nil)
+		communityStorage.EXPECT().
+			AddMember(userCtx, community.ID, "test_user").
+			Return(models.Community{ID: community.ID, MemberCount: 2}, nil)
+
+		// Execute
+		result, err := communityService.JoinCommunity(userCtx, "GoLang")
+
+		// Verify
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), result.MemberCount)
+	})
+
+	t.Run("successfully leave community", func(t *testing.T) {
+		// Mock expectations
+		communityStorage.EXPECT().GetCommunityBySlug(userCtx, "golang").Return(community, nil)
+		communityStorage.EXPECT().
+			RemoveMember(userCtx, community.ID, "test_user").
+			Return(models.Community{ID: community.ID, MemberCount: 1}, nil)
+
+		// Execute
+		result, err := communityService.LeaveCommunity(userCtx, "golang")
+
+		// Verify
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), result.MemberCount)
+	})
+
+	t.Run("fail when owner leaves community", func(t *testing.T) {
+		// Mock expectations
+		communityStorage.EXPECT().GetCommunityBySlug(ownerCtx, "golang").Return(community, nil)
+
+		// Execute
+		result, err := communityService.LeaveCommunity(ownerCtx, "golang")
+
+		// Verify
+		require.Error(t, err)
+		gqlErr := err.(utils.GqlError)
+		assert.Equal(t, "community owner can not leave the community", gqlErr.Msg)
+		assert.Equal(t, consts.BadRequestType, gqlErr.Type)
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail when community not found", func(t *testing.T) {
+		// Mock expectations
+		communityStorage.EXPECT().
+			GetCommunityBySlug(userCtx, "missing").
+			Return(nil, fmt.Errorf("community: %w", sql.ErrNoRows))
+
+		// Execute
+		result, err := communityService.JoinCommunity(userCtx, "missing")
+
+		// Verify
+		require.Error(t, err)
+		gqlErr := err.(utils.GqlError)
+		assert.Equal(t, "community missing not found", gqlErr.Msg)
+		assert.Equal(t, consts.BadRequestType, gqlErr.Type)
+		assert.Nil(t, result)
+	})
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/internal/storage"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+	"github.com/nedokyrill/posts-service/pkg/logger"
+	"github.com/nedokyrill/posts-service/pkg/stemmer"
+	"github.com/nedokyrill/posts-service/pkg/utils"
+)
+
+// FilterMode - что делать с контентом, не прошедшим фильтры
+type FilterMode int
+
+const (
+	FilterReject FilterMode = iota // вернуть автору ошибку фильтра
+	FilterShadow                   // сохранить контент видимым только автору
+)
+
+func ParseFilterMode(mode string) (FilterMode, error) {
+	switch mode {
+	case "", "reject":
+		return FilterReject, nil
+	case "shadow":
+		return FilterShadow, nil
+	default:
+		return 0, fmt.Errorf("unknown content filter mode: %s", mode)
+	}
+}
+
+type ContentKind string
+
+const (
+	ContentKindPost    ContentKind = "post"
+	ContentKindComment ContentKind = "comment"
+)
+
+// Content - пост или комментарий перед сохранением. ID есть только у редактируемого контента
+type Content struct {
+	Kind   ContentKind
+	ID     uuid.UUID
+	Author string
+	Title  string
+	Text   string
+}
+
+// ContentFilter проверяет контент перед сохранением: отклоненный контент - utils.GqlError со своим типом,
+// другая ошибка - фильтр не смог проверить контент
+type ContentFilter interface {
+	Check(ctx context.Context, content Content) error
+}
+
+// ContentFilterChain - фильтры, через которые проходят новые и измененные посты и комментарии
+type ContentFilterChain struct {
+	filters []ContentFilter
+	mode    FilterMode
+}
+
+func NewContentFilterChain(mode FilterMode, filters ...ContentFilter) *ContentFilterChain {
+	return &ContentFilterChain{
+		filters: filters,
+		mode:    mode,
+	}
+}
+
+// Apply прогоняет контент через фильтры по порядку до первого отказа. В режиме FilterShadow отказ не возвращается,
+// а контент помечается скрытым (shadowed). Сломавшийся фильтр пропускается, чтобы не блокировать публикацию.
+// Пустая цепочка (nil) пропускает все
+func (c *ContentFilterChain) Apply(ctx context.Context, content Content) (shadowed bool, err error) {
+	if c == nil {
+		return false, nil
+	}
+
+	for _, filter := range c.filters {
+		err = filter.Check(ctx, content)
+		if err == nil {
+			continue
+		}
+
+		var gqlErr utils.GqlError
+		if !errors.As(err, &gqlErr) {
+			logger.Logger.Error(fmt.Sprintf("error filtering %s: %v", content.Kind, err))
+			continue
+		}
+
+		if c.mode == FilterShadow {
+			logger.Logger.Info(fmt.Sprintf("shadow %s of %s: %s", content.Kind, content.Author, gqlErr.Msg))
+			return true, nil
+		}
+		return false, gqlErr
+	}
+	return false, nil
+}
+
+// похожие на кириллицу латинские буквы, которыми подменяют буквы запрещенных слов
+var homoglyphs = strings.NewReplacer("a", "а", "b", "в", "c", "с", "e", "е", "h", "н", "k", "к", "m", "м", "o", "о",
+	"p", "р", "t", "т", "x", "х", "y", "у")
+
+// BannedWordsFilter отклоняет контент с запрещенными словами. Слово находится в любой форме ("спамер" -
+// "спамерами"), слово со звездочкой на конце ("казино*") - по началу. Латинские буквы внутри русского слова
+// считаются похожими на них русскими
+type BannedWordsFilter struct {
+	stems    map[string]bool
+	prefixes []string
+}
+
+func NewBannedWordsFilter(words []string) *BannedWordsFilter {
+	f := &BannedWordsFilter{stems: make(map[string]bool)}
+	for _, word := range words {
+		if prefix, ok := strings.CutSuffix(word, "*"); ok {
+			f.prefixes = append(f.prefixes, normalizeWord(prefix))
+			continue
+		}
+		f.stems[stemmer.Russian(normalizeWord(word))] = true
+	}
+	return f
+}
+
+func (f *BannedWordsFilter) Check(_ context.Context, content Content) error {
+	words := strings.FieldsFunc(content.Title+" "+content.Text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for _, word := range words {
+		if f.isBanned(normalizeWord(word)) {
+			return utils.GqlError{
+				Msg:  fmt.Sprintf("%s contains banned words", content.Kind),
+				Type: consts.BannedWordsType,
+			}
+		}
+	}
+	return nil
+}
+
+func (f *BannedWordsFilter) isBanned(word string) bool {
+	if f.stems[stemmer.Russian(word)] {
+		return true
+	}
+	for _, prefix := range f.prefixes {
+		if strings.HasPrefix(word, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// слово в нижнем регистре с е вместо ё, в словах с кириллицей латинские двойники заменены русскими буквами
+func normalizeWord(word string) string {
+	word = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(word)), "ё", "е")
+	if strings.IndexFunc(word, func(r rune) bool { return unicode.Is(unicode.Cyrillic, r) }) >= 0 {
+		word = homoglyphs.Replace(word)
+	}
+	return word
+}
+
+var linkRe = regexp.MustCompile(`(?i)\b(?:https?://|www\.)`)
+
+// LinkFilter ограничивает количество ссылок в посте и комментарии, 0 - без ограничения
+type LinkFilter struct {
+	maxPostLinks    int
+	maxCommentLinks int
+}
+
+func NewLinkFilter(maxPostLinks, maxCommentLinks int) *LinkFilter {
+	return &LinkFilter{
+		maxPostLinks:    maxPostLinks,
+		maxCommentLinks: maxCommentLinks,
+	}
+}
+
+func (f *LinkFilter) Check(_ context.Context, content Content) error {
+	limit := f.maxCommentLinks
+	if content.Kind == ContentKindPost {
+		limit = f.maxPostLinks
+	}
+
+	if limit > 0 && len(linkRe.FindAllStringIndex(content.Title+" "+content.Text, -1)) > limit {
+		return utils.GqlError{
+			Msg:  fmt.Sprintf("%s should contain no more than %d links", content.Kind, limit),
+			Type: consts.TooManyLinksType,
+		}
+	}
+	return nil
+}
+
+// DuplicateFilter отклоняет текст, который автор уже публиковал за последнее время window: тот же пост
+// или тот же комментарий (к любому посту). Тексты короче minLen символов не проверяются
+type DuplicateFilter struct {
+	postStore storage.PostStorage
+	commStore storage.CommentStorage
+	window    time.Duration
+	minLen    int
+}
+
+func NewDuplicateFilter(postStore storage.PostStorage, commStore storage.CommentStorage, window time.Duration,
+	minLen int) *DuplicateFilter {
+	return &DuplicateFilter{
+		postStore: postStore,
+		commStore: commStore,
+		window:    window,
+		minLen:    minLen,
+	}
+}
+
+func (f *DuplicateFilter) Check(ctx context.Context, content Content) error {
+	if utf8.RuneCountInString(strings.TrimSpace(content.Text)) < f.minLen {
+		return nil
+	}
+
+	// created_at хранится без часового пояса в UTC, поэтому и начало окна в UTC
+	hash, since := models.ContentHash(content.Text), time.Now().UTC().Add(-f.window)
+
+	var found bool
+	var err error
+	if content.Kind == ContentKindPost {
+		found, err = f.postStore.HasDuplicate(ctx, content.Author, hash, since, content.ID)
+	} else {
+		found, err = f.commStore.HasDuplicate(ctx, content.Author, hash, since, content.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("error checking duplicate %s: %w", content.Kind, err)
+	}
+
+	if found {
+		return utils.GqlError{
+			Msg:  fmt.Sprintf("you have already posted the same %s", content.Kind),
+			Type: consts.DuplicateContentType,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,356 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/models"
+	store_mock "github.com/nedokyrill/posts-service/internal/storage/mocks"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+	"github.com/nedokyrill/posts-service/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func requireFilterError(t *testing.T, err error, errType string) {
+	t.Helper()
+
+	var gqlErr utils.GqlError
+	require.True(t, errors.As(err, &gqlErr), "expected GqlError, got %v", err)
+	assert.Equal(t, errType, gqlErr.Type)
+}
+
+func TestBannedWordsFilter(t *testing.T) {
+	filter := NewBannedWordsFilter([]string{"спамер", "Казино*", "viagra"})
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		text   string
+		banned bool
+	}{
+		{name: "exact word", text: "тут был спамер", banned: true},
+		{name: "other word form", text: "Не верьте СПАМЕРАМИ написанному", banned: true},
+		{name: "latin look-alike letters", text: "лучший cпaмeр", banned: true},
+		{name: "prefix pattern", text: "онлайн-казиношка", banned: true},
+		{name: "latin word", text: "buy Viagra now", banned: true},
+		{name: "word with same prefix", text: "спам и спамить можно", banned: false},
+		{name: "prefix inside word", text: "аказино", banned: false},
+		{name: "clean text", text: "обычный комментарий", banned: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Execute
+			err := filter.Check(ctx, Content{Kind: ContentKindComment, Text: tt.text})
+
+			// Verify
+			if !tt.banned {
+				assert.NoError(t, err)
+				return
+			}
+			requireFilterError(t, err, consts.BannedWordsType)
+		})
+	}
+
+	t.Run("check post title", func(t *testing.T) {
+		// Execute
+		err := filter.Check(ctx, Content{Kind: ContentKindPost, Title: "Казино рядом", Text: "обычный текст"})
+
+		// Verify
+		requireFilterError(t, err, consts.BannedWordsType)
+		assert.Contains(t, err.Error(), "post contains banned words")
+	})
+}
+
+func TestLinkFilter(t *testing.T) {
+	filter := NewLinkFilter(2, 1)
+	noLimit := NewLinkFilter(0, 0)
+	ctx := context.Background()
+
+	twoLinks := "см. https://example.com и www.example.org"
+
+	t.Run("allow links within limit", func(t *testing.T) {
+		assert.NoError(t, filter.Check(ctx, Content{Kind: ContentKindPost, Text: twoLinks}))
+		assert.NoError(t, filter.Check(ctx, Content{Kind: ContentKindComment, Text: "http://example.com"}))
+	})
+
+	t.Run("fail with too many links", func(t *testing.T) {
+		// Execute
+		err := filter.Check(ctx, Content{Kind: ContentKindComment, Text: twoLinks})
+
+		// Verify
+		requireFilterError(t, err, consts.TooManyLinksType)
+		assert.Contains(t, err.Error(), "no more than 1 links")
+	})
+
+	t.Run("count links in post title", func(t *testing.T) {
+		err := filter.Check(ctx, Content{Kind: ContentKindPost, Title: "HTTPS://example.com", Text: twoLinks})
+
+		requireFilterError(t, err, consts.TooManyLinksType)
+	})
+
+	t.Run("skip disabled limit", func(t *testing.T) {
+		assert.NoError(t, noLimit.Check(ctx, Content{Kind: ContentKindComment, Text: twoLinks}))
+	})
+}
+
+func TestDuplicateFilter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	postStorage := store_mock.NewMockPostStorage(ctrl)
+	commentStorage := store_mock.NewMockCommentStorage(ctrl)
+	filter := NewDuplicateFilter(postStorage, commentStorage, time.Hour, 10)
+	ctx := context.Background()
+
+	text := "Подпишитесь на мой канал"
+	commentID := uuid.New()
+
+	t.Run("fail on repeated comment", func(t *testing.T) {
+		// Mock expectations
+		commentStorage.EXPECT().
+			HasDuplicate(ctx, "spammer", models.ContentHash(text), gomock.Any(), uuid.Nil).
+			DoAndReturn(func(ctx context.Context, author, contentHash string, since time.Time,
+				excludeID uuid.UUID) (bool, error) {
+				assert.WithinDuration(t, time.Now().Add(-time.Hour), since, time.Minute)
+				assert.Equal(t, time.UTC, since.Location())
+				return true, nil
+			})
+
+		// Execute
+		err := filter.Check(ctx, Content{Kind: ContentKindComment, Author: "spammer", Text: text})
+
+		// Verify
+		requireFilterError(t, err, consts.DuplicateContentType)
+	})
+
+	t.Run("exclude edited comment", func(t *testing.T) {
+		// Mock expectations
+		commentStorage.EXPECT().
+			HasDuplicate(ctx, "spammer", models.ContentHash(text), gomock.Any(), commentID).
+			Return(false, nil)
+
+		// Execute
+		err := filter.Check(ctx, Content{Kind: ContentKindComment, ID: commentID, Author: "spammer", Text: text})
+
+		// Verify
+		assert.NoError(t, err)
+	})
+
+	t.Run("check posts in post storage", func(t *testing.T) {
+		// Mock expectations
+		postStorage.EXPECT().
+			HasDuplicate(ctx, "spammer", models.ContentHash(text), gomock.Any(), uuid.Nil).
+			Return(true, nil)
+
+		// Execute
+		err := filter.Check(ctx, Content{Kind: ContentKindPost, Author: "spammer", Title: "Новый", Text: text})
+
+		// Verify
+		requireFilterError(t, err, consts.DuplicateContentType)
+	})
+
+	t.Run("skip short text", func(t *testing.T) {
+		assert.NoError(t, filter.Check(ctx, Content{Kind: ContentKindComment, Author: "spammer", Text: " +1 "}))
+	})
+
+	t.Run("return storage error", func(t *testing.T) {
+		// Mock expectations
+		commentStorage.EXPECT().
+			HasDuplicate(ctx, "spammer", gomock.Any(), gomock.Any(), uuid.Nil).
+			Return(false, assert.AnError)
+
+		// Execute
+		err := filter.Check(ctx, Content{Kind: ContentKindComment, Author: "spammer", Text: text})
+
+		// Verify
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+}
+
+// фильтр с заданным результатом проверки
+type stubFilter struct {
+	err   error
+	calls int
+}
+
+func (f *stubFilter) Check(context.Context, Content) error {
+	f.calls++
+	return f.err
+}
+
+func TestContentFilterChain_Apply(t *testing.T) {
+	ctx := context.Background()
+	content := Content{Kind: ContentKindComment, Author: "spammer", Text: "spam"}
+	rejected := utils.GqlError{Msg: "comment contains banned words", Type: consts.BannedWordsType}
+
+	t.Run("pass clean content", func(t *testing.T) {
+		// Setup
+		first, second := &stubFilter{}, &stubFilter{}
+		chain := NewContentFilterChain(FilterReject, first, second)
+
+		// Execute
+		shadowed, err := chain.Apply(ctx, content)
+
+		// Verify
+		require.NoError(t, err)
+		assert.False(t, shadowed)
+		assert.Equal(t, 1, second.calls)
+	})
+
+	t.Run("reject at first failed filter", func(t *testing.T) {
+		// Setup
+		second := &stubFilter{}
+		chain := NewContentFilterChain(FilterReject, &stubFilter{err: rejected}, second)
+
+		// Execute
+		shadowed, err := chain.Apply(ctx, content)
+
+		// Verify
+		requireFilterError(t, err, consts.BannedWordsType)
+		assert.False(t, shadowed)
+		assert.Zero(t, second.calls)
+	})
+
+	t.Run("shadow instead of reject", func(t *testing.T) {
+		// Setup
+		chain := NewContentFilterChain(FilterShadow, &stubFilter{err: rejected})
+
+		// Execute
+		shadowed, err := chain.Apply(ctx, content)
+
+		// Verify
+		require.NoError(t, err)
+		assert.True(t, shadowed)
+	})
+
+	t.Run("skip broken filter", func(t *testing.T) {
+		// Setup
+		second := &stubFilter{err: rejected}
+		chain := NewContentFilterChain(FilterReject, &stubFilter{err: assert.AnError}, second)
+
+		// Execute
+		_, err := chain.Apply(ctx, content)
+
+		// Verify
+		requireFilterError(t, err, consts.BannedWordsType)
+		assert.Equal(t, 1, second.calls)
+	})
+
+	t.Run("pass everything without chain", func(t *testing.T) {
+		var chain *ContentFilterChain
+
+		shadowed, err := chain.Apply(ctx, content)
+
+		require.NoError(t, err)
+		assert.False(t, shadowed)
+	})
+}
+
+func TestParseFilterMode(t *testing.T) {
+	mode, err := ParseFilterMode("")
+	require.NoError(t, err)
+	assert.Equal(t, FilterReject, mode)
+
+	mode, err = ParseFilterMode("shadow")
+	require.NoError(t, err)
+	assert.Equal(t, FilterShadow, mode)
+
+	_, err = ParseFilterMode("hide")
+	assert.Error(t, err)
+}
+
+func TestCommentService_FilterComment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	author := "spammer"
+	ctx := withUser(author)
+	postStorage := store_mock.NewMockPostStorage(ctrl)
+	commentStorage := store_mock.NewMockCommentStorage(ctrl)
+	newService := func(mode FilterMode) *CommentServiceImpl {
+		return NewCommentService(commentStorage, postStorage, store_mock.NewMockCommunityStorage(ctrl),
+			store_mock.NewMockUserStorage(ctrl), NewContentFilterChain(mode, NewBannedWordsFilter([]string{"казино"})))
+	}
+
+	post := &models.Post{ID: uuid.New(), Author: "post_author", IsCommentsAllowed: true}
+	commentReq := models.CommentRequest{Content: "лучшие казино тут", PostID: post.ID}
+
+	t.Run("reject comment with banned words", func(t *testing.T) {
+		// Mock expectations
+		postStorage.EXPECT().
+			GetPostByID(ctx, post.ID).
+			Return(post, nil)
+
+		// Execute
+		result, err := newService(FilterReject).CreateComment(ctx, commentReq)
+
+		// Verify
+		requireFilterError(t, err, consts.BannedWordsType)
+		assert.Nil(t, result)
+	})
+
+	t.Run("shadow comment with banned words", func(t *testing.T) {
+		// Mock expectations
+		postStorage.EXPECT().
+			GetPostByID(ctx, post.ID).
+			Return(post, nil)
+		commentStorage.EXPECT().
+			CreateComment(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, comm models.Comment) (models.Comment, error) {
+				assert.True(t, comm.IsShadowed)
+				return comm, nil
+			})
+
+		// Execute
+		result, err := newService(FilterShadow).CreateComment(ctx, commentReq)
+
+		// Verify
+		require.NoError(t, err)
+		assert.True(t, result.IsShadowed)
+	})
+
+	t.Run("keep edited comment shadowed", func(t *testing.T) {
+		// Setup
+		comment := &models.Comment{ID: uuid.New(), Author: author, PostID: post.ID, Content: "казино"}
+
+		// Mock expectations
+		commentStorage.EXPECT().
+			GetCommentByID(ctx, comment.ID).
+			Return(comment, nil)
+		commentStorage.EXPECT().
+			UpdateComment(ctx, comment.ID, "казино рядом", true).
+			Return(models.Comment{ID: comment.ID, IsShadowed: true}, nil)
+
+		// Execute
+		result, err := newService(FilterShadow).EditComment(ctx, models.CommentEditRequest{ID: comment.ID,
+			Content: "казино рядом"})
+
+		// Verify
+		require.NoError(t, err)
+		assert.True(t, result.IsShadowed)
+	})
+
+	t.Run("hide shadowed post from other users", func(t *testing.T) {
+		// Mock expectations
+		postStorage.EXPECT().
+			GetPostByID(ctx, post.ID).
+			Return(&models.Post{ID: post.ID, Author: "post_author", IsCommentsAllowed: true, IsShadowed: true}, nil)
+
+		// Execute
+		result, err := newService(FilterReject).CreateComment(ctx, models.CommentRequest{Content: "привет",
+			PostID: post.ID})
+
+		// Verify
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not exist")
+		assert.Nil(t, result)
+	})
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+	"github.com/nedokyrill/posts-service/pkg/logger"
+)
+
+// InboxServicePg рассылает уведомления через postgres NOTIFY, чтобы их получали подписки на всех инстансах
+// приложения, как ViewerServicePg для событий постов
+type InboxServicePg struct {
+	pool  *pgxpool.Pool
+	local *InboxServiceImpl
+}
+
+func NewInboxServicePg(pool *pgxpool.Pool, local *InboxServiceImpl) *InboxServicePg {
+	return &InboxServicePg{
+		pool:  pool,
+		local: local,
+	}
+}
+
+func (s *InboxServicePg) Subscribe(ctx context.Context) (int, chan *models.Notification, error) {
+	return s.local.Subscribe(ctx)
+}
+
+func (s *InboxServicePg) Unsubscribe(ctx context.Context, id int) error {
+	return s.local.Unsubscribe(ctx, id)
+}
+
+// уведомление содержит только идентификаторы и имена, поэтому всегда помещается в payload NOTIFY
+func (s *InboxServicePg) Deliver(ctx context.Context, notification *models.Notification) error {
+	payload, err := json.Marshal(notification)
+	if err == nil {
+		_, err = s.pool.Exec(ctx, "SELECT pg_notify($1, $2)", consts.NotificationsChannel, string(payload))
+	}
+	if err != nil {
+		logger.Logger.Error(fmt.Sprintf("error publishing notification for user %s: %v", notification.Recipient, err))
+		return s.local.Deliver(ctx, notification)
+	}
+
+	return nil
+}
+
+// Listen слушает канал уведомлений до отмены ctx и отдает их локальным подпискам
+func (s *InboxServicePg) Listen(ctx context.Context) {
+	listenChannel(ctx, s.pool, consts.NotificationsChannel, func(ctx context.Context, payload string) {
+		var notification models.Notification
+		if err := json.Unmarshal([]byte(payload), &notification); err != nil {
+			logger.Logger.Error(fmt.Sprintf("error decoding user notification: %v", err))
+			return
+		}
+		_ = s.local.Deliver(ctx, &notification)
+	})
+}
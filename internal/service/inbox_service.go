@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+	"github.com/nedokyrill/posts-service/pkg/logger"
+	"github.com/nedokyrill/posts-service/pkg/utils"
+)
+
+type inboxSubscriber struct {
+	ch chan *models.Notification
+	id int
+}
+
+// InboxServiceImpl рассылает новые уведомления открытым подпискам получателя, так же как ViewerServiceImpl
+// рассылает события поста: у каждой подписки свой буфер, отправка неблокирующая
+type InboxServiceImpl struct {
+	subscribers map[string][]inboxSubscriber // подписки по имени получателя
+	recipients  map[int]string               // получатель по id подписки
+	maxPerUser  int
+	bufferSize  int
+	cnt         int
+	mu          sync.Mutex
+}
+
+func NewInboxService(maxPerUser, bufferSize int) *InboxServiceImpl {
+	return &InboxServiceImpl{
+		subscribers: make(map[string][]inboxSubscriber),
+		recipients:  make(map[int]string),
+		maxPerUser:  maxPerUser,
+		bufferSize:  bufferSize,
+		cnt:         0,
+		mu:          sync.Mutex{},
+	}
+}
+
+// подписываемся на уведомления текущего пользователя
+func (s *InboxServiceImpl) Subscribe(ctx context.Context) (int, chan *models.Notification, error) {
+	identity, err := requireIdentity(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.subscribers[identity.Username]) >= s.maxPerUser {
+		return 0, nil, utils.GqlError{
+			Msg:  fmt.Sprintf("subscriptions limit of %d per user is reached", s.maxPerUser),
+			Type: consts.TooManyRequestsType,
+		}
+	}
+
+	id := s.cnt
+	notifications := make(chan *models.Notification, s.bufferSize)
+	s.subscribers[identity.Username] = append(s.subscribers[identity.Username],
+		inboxSubscriber{ch: notifications, id: id})
+	s.recipients[id] = identity.Username
+	s.cnt++
+
+	logger.Logger.Infof("create inbox subscriber for user %s", identity.Username)
+	return id, notifications, nil
+}
+
+func (s *InboxServiceImpl) Unsubscribe(_ context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	recipient, ok := s.recipients[id]
+	if !ok {
+		return nil
+	}
+	delete(s.recipients, id)
+
+	subscribers := s.subscribers[recipient]
+	for i, subscriber := range subscribers {
+		if subscriber.id == id {
+			s.subscribers[recipient] = append(subscribers[:i], subscribers[i+1:]...)
+			if len(s.subscribers[recipient]) == 0 {
+				delete(s.subscribers, recipient)
+			}
+			close(subscriber.ch)
+			break
+		}
+	}
+
+	logger.Logger.Infof("delete inbox subscriber for user %s", recipient)
+	return nil
+}
+
+// отправляем уведомление подпискам получателя. Уведомления хранятся, поэтому при переполнении буфера
+// выкидывается самое старое: клиент все равно увидит его в MyNotifications
+func (s *InboxServiceImpl) Deliver(_ context.Context, notification *models.Notification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, subscriber := range s.subscribers[notification.Recipient] {
+		pushLatest(subscriber.ch, notification)
+	}
+	return nil
+}
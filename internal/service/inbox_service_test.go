@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+	"github.com/nedokyrill/posts-service/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInboxService(t *testing.T) {
+	t.Run("fail when user is not authenticated", func(t *testing.T) {
+		inbox := NewInboxService(consts.MaxSubscriptionsPerUser, consts.ViewerBufferSize)
+
+		_, ch, err := inbox.Subscribe(context.Background())
+
+		assert.Nil(t, ch)
+
+		var gqlErr utils.GqlError
+		require.ErrorAs(t, err, &gqlErr)
+		assert.Equal(t, consts.UnauthorizedType, gqlErr.Type)
+	})
+
+	t.Run("deliver only to recipient", func(t *testing.T) {
+		inbox := NewInboxService(consts.MaxSubscriptionsPerUser, consts.ViewerBufferSize)
+
+		id, mine, err := inbox.Subscribe(withUser("test_user"))
+		require.NoError(t, err)
+		_, other, err := inbox.Subscribe(withUser("another_user"))
+		require.NoError(t, err)
+
+		notification := &models.Notification{ID: uuid.New(), Recipient: "test_user"}
+		require.NoError(t, inbox.Deliver(context.Background(), notification))
+
+		assert.Equal(t, notification, <-mine)
+		assert.Empty(t, other)
+
+		require.NoError(t, inbox.Unsubscribe(context.Background(), id))
+		_, ok := <-mine
+		assert.False(t, ok)
+		assert.NoError(t, inbox.Unsubscribe(context.Background(), id))
+	})
+
+	t.Run("fail when subscriptions limit is reached", func(t *testing.T) {
+		inbox := NewInboxService(1, consts.ViewerBufferSize)
+
+		_, _, err := inbox.Subscribe(withUser("test_user"))
+		require.NoError(t, err)
+
+		_, _, err = inbox.Subscribe(withUser("test_user"))
+
+		var gqlErr utils.GqlError
+		require.ErrorAs(t, err, &gqlErr)
+		assert.Equal(t, consts.TooManyRequestsType, gqlErr.Type)
+	})
+}
@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	uuid "github.com/google/uuid"
@@ -51,19 +52,48 @@ func (mr *MockPostServiceMockRecorder) CreatePost(ctx, postReq interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePost", reflect.TypeOf((*MockPostService)(nil).CreatePost), ctx, postReq)
 }
 
+// DeletePost mocks base method.
+func (m *MockPostService) DeletePost(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePost", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePost indicates an expected call of DeletePost.
+func (mr *MockPostServiceMockRecorder) DeletePost(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePost", reflect.TypeOf((*MockPostService)(nil).DeletePost), ctx, id)
+}
+
 // GetAllPosts mocks base method.
-func (m *MockPostService) GetAllPosts(ctx context.Context, page *int32) ([]*models.Post, error) {
+func (m *MockPostService) GetAllPosts(ctx context.Context, sort models.PostSort, window models.TimeWindow, filter models.PostFilter, page models.PageRequest) (*models.PostConnection, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllPosts", ctx, page)
-	ret0, _ := ret[0].([]*models.Post)
+	ret := m.ctrl.Call(m, "GetAllPosts", ctx, sort, window, filter, page)
+	ret0, _ := ret[0].(*models.PostConnection)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAllPosts indicates an expected call of GetAllPosts.
-func (mr *MockPostServiceMockRecorder) GetAllPosts(ctx, page interface{}) *gomock.Call {
+func (mr *MockPostServiceMockRecorder) GetAllPosts(ctx, sort, window, filter, page interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllPosts", reflect.TypeOf((*MockPostService)(nil).GetAllPosts), ctx, page)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllPosts", reflect.TypeOf((*MockPostService)(nil).GetAllPosts), ctx, sort, window, filter, page)
+}
+
+// GetPopularTags mocks base method.
+func (m *MockPostService) GetPopularTags(ctx context.Context, limit *int32) ([]*models.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPopularTags", ctx, limit)
+	ret0, _ := ret[0].([]*models.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPopularTags indicates an expected call of GetPopularTags.
+func (mr *MockPostServiceMockRecorder) GetPopularTags(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPopularTags", reflect.TypeOf((*MockPostService)(nil).GetPopularTags), ctx, limit)
 }
 
 // GetPostByID mocks base method.
@@ -81,6 +111,51 @@ func (mr *MockPostServiceMockRecorder) GetPostByID(ctx, id interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPostByID", reflect.TypeOf((*MockPostService)(nil).GetPostByID), ctx, id)
 }
 
+// SearchPosts mocks base method.
+func (m *MockPostService) SearchPosts(ctx context.Context, query string, page models.PageRequest) (*models.PostSearchConnection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchPosts", ctx, query, page)
+	ret0, _ := ret[0].(*models.PostSearchConnection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchPosts indicates an expected call of SearchPosts.
+func (mr *MockPostServiceMockRecorder) SearchPosts(ctx, query, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchPosts", reflect.TypeOf((*MockPostService)(nil).SearchPosts), ctx, query, page)
+}
+
+// SetCommentsAllowed mocks base method.
+func (m *MockPostService) SetCommentsAllowed(ctx context.Context, id uuid.UUID, allowed bool) (*models.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCommentsAllowed", ctx, id, allowed)
+	ret0, _ := ret[0].(*models.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetCommentsAllowed indicates an expected call of SetCommentsAllowed.
+func (mr *MockPostServiceMockRecorder) SetCommentsAllowed(ctx, id, allowed interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCommentsAllowed", reflect.TypeOf((*MockPostService)(nil).SetCommentsAllowed), ctx, id, allowed)
+}
+
+// UpdatePost mocks base method.
+func (m *MockPostService) UpdatePost(ctx context.Context, postReq models.PostUpdateRequest) (*models.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePost", ctx, postReq)
+	ret0, _ := ret[0].(*models.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePost indicates an expected call of UpdatePost.
+func (mr *MockPostServiceMockRecorder) UpdatePost(ctx, postReq interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePost", reflect.TypeOf((*MockPostService)(nil).UpdatePost), ctx, postReq)
+}
+
 // MockCommentService is a mock of CommentService interface.
 type MockCommentService struct {
 	ctrl     *gomock.Controller
@@ -119,34 +194,290 @@ func (mr *MockCommentServiceMockRecorder) CreateComment(ctx, commReq interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateComment", reflect.TypeOf((*MockCommentService)(nil).CreateComment), ctx, commReq)
 }
 
-// GetCommentsByPostID mocks base method.
-func (m *MockCommentService) GetCommentsByPostID(ctx context.Context, postID uuid.UUID, page *int32) ([]*models.Comment, error) {
+// DeleteComment mocks base method.
+func (m *MockCommentService) DeleteComment(ctx context.Context, commentID uuid.UUID) (*models.Comment, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetCommentsByPostID", ctx, postID, page)
-	ret0, _ := ret[0].([]*models.Comment)
+	ret := m.ctrl.Call(m, "DeleteComment", ctx, commentID)
+	ret0, _ := ret[0].(*models.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteComment indicates an expected call of DeleteComment.
+func (mr *MockCommentServiceMockRecorder) DeleteComment(ctx, commentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteComment", reflect.TypeOf((*MockCommentService)(nil).DeleteComment), ctx, commentID)
+}
+
+// EditComment mocks base method.
+func (m *MockCommentService) EditComment(ctx context.Context, commReq models.CommentEditRequest) (*models.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EditComment", ctx, commReq)
+	ret0, _ := ret[0].(*models.Comment)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetCommentsByPostID indicates an expected call of GetCommentsByPostID.
-func (mr *MockCommentServiceMockRecorder) GetCommentsByPostID(ctx, postID, page interface{}) *gomock.Call {
+// EditComment indicates an expected call of EditComment.
+func (mr *MockCommentServiceMockRecorder) EditComment(ctx, commReq interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommentsByPostID", reflect.TypeOf((*MockCommentService)(nil).GetCommentsByPostID), ctx, postID, page)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditComment", reflect.TypeOf((*MockCommentService)(nil).EditComment), ctx, commReq)
 }
 
-// GetRepliesByComment mocks base method.
-func (m *MockCommentService) GetRepliesByComment(ctx context.Context, commentID uuid.UUID) ([]*models.Comment, error) {
+// GetCommentCounts mocks base method.
+func (m *MockCommentService) GetCommentCounts(ctx context.Context, postIDs []uuid.UUID) (map[uuid.UUID]int32, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetRepliesByComment", ctx, commentID)
+	ret := m.ctrl.Call(m, "GetCommentCounts", ctx, postIDs)
+	ret0, _ := ret[0].(map[uuid.UUID]int32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommentCounts indicates an expected call of GetCommentCounts.
+func (mr *MockCommentServiceMockRecorder) GetCommentCounts(ctx, postIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommentCounts", reflect.TypeOf((*MockCommentService)(nil).GetCommentCounts), ctx, postIDs)
+}
+
+// GetCommentTree mocks base method.
+func (m *MockCommentService) GetCommentTree(ctx context.Context, postID uuid.UUID, maxDepth, page *int32) ([]*models.CommentTreeNode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommentTree", ctx, postID, maxDepth, page)
+	ret0, _ := ret[0].([]*models.CommentTreeNode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommentTree indicates an expected call of GetCommentTree.
+func (mr *MockCommentServiceMockRecorder) GetCommentTree(ctx, postID, maxDepth, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommentTree", reflect.TypeOf((*MockCommentService)(nil).GetCommentTree), ctx, postID, maxDepth, page)
+}
+
+// GetCommentsByPostIDs mocks base method.
+func (m *MockCommentService) GetCommentsByPostIDs(ctx context.Context, postIDs []uuid.UUID, sort models.CommentSort, page models.PageRequest) (map[uuid.UUID]*models.CommentConnection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommentsByPostIDs", ctx, postIDs, sort, page)
+	ret0, _ := ret[0].(map[uuid.UUID]*models.CommentConnection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommentsByPostIDs indicates an expected call of GetCommentsByPostIDs.
+func (mr *MockCommentServiceMockRecorder) GetCommentsByPostIDs(ctx, postIDs, sort, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommentsByPostIDs", reflect.TypeOf((*MockCommentService)(nil).GetCommentsByPostIDs), ctx, postIDs, sort, page)
+}
+
+// GetMissedComments mocks base method.
+func (m *MockCommentService) GetMissedComments(ctx context.Context, postID uuid.UUID, since *time.Time, lastCommentID *uuid.UUID) ([]*models.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMissedComments", ctx, postID, since, lastCommentID)
 	ret0, _ := ret[0].([]*models.Comment)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetRepliesByComment indicates an expected call of GetRepliesByComment.
-func (mr *MockCommentServiceMockRecorder) GetRepliesByComment(ctx, commentID interface{}) *gomock.Call {
+// GetMissedComments indicates an expected call of GetMissedComments.
+func (mr *MockCommentServiceMockRecorder) GetMissedComments(ctx, postID, since, lastCommentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMissedComments", reflect.TypeOf((*MockCommentService)(nil).GetMissedComments), ctx, postID, since, lastCommentID)
+}
+
+// GetRepliesByCommentIDs mocks base method.
+func (m *MockCommentService) GetRepliesByCommentIDs(ctx context.Context, commentIDs []uuid.UUID, sort models.CommentSort, page models.PageRequest) (map[uuid.UUID]*models.CommentConnection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRepliesByCommentIDs", ctx, commentIDs, sort, page)
+	ret0, _ := ret[0].(map[uuid.UUID]*models.CommentConnection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRepliesByCommentIDs indicates an expected call of GetRepliesByCommentIDs.
+func (mr *MockCommentServiceMockRecorder) GetRepliesByCommentIDs(ctx, commentIDs, sort, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRepliesByCommentIDs", reflect.TypeOf((*MockCommentService)(nil).GetRepliesByCommentIDs), ctx, commentIDs, sort, page)
+}
+
+// SearchComments mocks base method.
+func (m *MockCommentService) SearchComments(ctx context.Context, query string, postID *uuid.UUID) ([]*models.CommentSearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchComments", ctx, query, postID)
+	ret0, _ := ret[0].([]*models.CommentSearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchComments indicates an expected call of SearchComments.
+func (mr *MockCommentServiceMockRecorder) SearchComments(ctx, query, postID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchComments", reflect.TypeOf((*MockCommentService)(nil).SearchComments), ctx, query, postID)
+}
+
+// MockVoteService is a mock of VoteService interface.
+type MockVoteService struct {
+	ctrl     *gomock.Controller
+	recorder *MockVoteServiceMockRecorder
+}
+
+// MockVoteServiceMockRecorder is the mock recorder for MockVoteService.
+type MockVoteServiceMockRecorder struct {
+	mock *MockVoteService
+}
+
+// NewMockVoteService creates a new mock instance.
+func NewMockVoteService(ctrl *gomock.Controller) *MockVoteService {
+	mock := &MockVoteService{ctrl: ctrl}
+	mock.recorder = &MockVoteServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVoteService) EXPECT() *MockVoteServiceMockRecorder {
+	return m.recorder
+}
+
+// GetCommentVotes mocks base method.
+func (m *MockVoteService) GetCommentVotes(ctx context.Context, commentIDs []uuid.UUID, voter string) (map[uuid.UUID]int32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommentVotes", ctx, commentIDs, voter)
+	ret0, _ := ret[0].(map[uuid.UUID]int32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommentVotes indicates an expected call of GetCommentVotes.
+func (mr *MockVoteServiceMockRecorder) GetCommentVotes(ctx, commentIDs, voter interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRepliesByComment", reflect.TypeOf((*MockCommentService)(nil).GetRepliesByComment), ctx, commentID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommentVotes", reflect.TypeOf((*MockVoteService)(nil).GetCommentVotes), ctx, commentIDs, voter)
+}
+
+// GetPostVotes mocks base method.
+func (m *MockVoteService) GetPostVotes(ctx context.Context, postIDs []uuid.UUID, voter string) (map[uuid.UUID]int32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPostVotes", ctx, postIDs, voter)
+	ret0, _ := ret[0].(map[uuid.UUID]int32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPostVotes indicates an expected call of GetPostVotes.
+func (mr *MockVoteServiceMockRecorder) GetPostVotes(ctx, postIDs, voter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPostVotes", reflect.TypeOf((*MockVoteService)(nil).GetPostVotes), ctx, postIDs, voter)
+}
+
+// VoteComment mocks base method.
+func (m *MockVoteService) VoteComment(ctx context.Context, commentID uuid.UUID, value int32) (*models.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VoteComment", ctx, commentID, value)
+	ret0, _ := ret[0].(*models.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VoteComment indicates an expected call of VoteComment.
+func (mr *MockVoteServiceMockRecorder) VoteComment(ctx, commentID, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VoteComment", reflect.TypeOf((*MockVoteService)(nil).VoteComment), ctx, commentID, value)
+}
+
+// VotePost mocks base method.
+func (m *MockVoteService) VotePost(ctx context.Context, postID uuid.UUID, value int32) (*models.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VotePost", ctx, postID, value)
+	ret0, _ := ret[0].(*models.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VotePost indicates an expected call of VotePost.
+func (mr *MockVoteServiceMockRecorder) VotePost(ctx, postID, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VotePost", reflect.TypeOf((*MockVoteService)(nil).VotePost), ctx, postID, value)
+}
+
+// MockUserService is a mock of UserService interface.
+type MockUserService struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserServiceMockRecorder
+}
+
+// MockUserServiceMockRecorder is the mock recorder for MockUserService.
+type MockUserServiceMockRecorder struct {
+	mock *MockUserService
+}
+
+// NewMockUserService creates a new mock instance.
+func NewMockUserService(ctrl *gomock.Controller) *MockUserService {
+	mock := &MockUserService{ctrl: ctrl}
+	mock.recorder = &MockUserServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserService) EXPECT() *MockUserServiceMockRecorder {
+	return m.recorder
+}
+
+// Login mocks base method.
+func (m *MockUserService) Login(ctx context.Context, username, password string) (*models.AuthPayload, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Login", ctx, username, password)
+	ret0, _ := ret[0].(*models.AuthPayload)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Login indicates an expected call of Login.
+func (mr *MockUserServiceMockRecorder) Login(ctx, username, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockUserService)(nil).Login), ctx, username, password)
+}
+
+// Me mocks base method.
+func (m *MockUserService) Me(ctx context.Context) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Me", ctx)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Me indicates an expected call of Me.
+func (mr *MockUserServiceMockRecorder) Me(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Me", reflect.TypeOf((*MockUserService)(nil).Me), ctx)
+}
+
+// RefreshToken mocks base method.
+func (m *MockUserService) RefreshToken(ctx context.Context, refreshToken string) (*models.AuthPayload, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshToken", ctx, refreshToken)
+	ret0, _ := ret[0].(*models.AuthPayload)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshToken indicates an expected call of RefreshToken.
+func (mr *MockUserServiceMockRecorder) RefreshToken(ctx, refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshToken", reflect.TypeOf((*MockUserService)(nil).RefreshToken), ctx, refreshToken)
+}
+
+// Register mocks base method.
+func (m *MockUserService) Register(ctx context.Context, username, password string) (*models.AuthPayload, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Register", ctx, username, password)
+	ret0, _ := ret[0].(*models.AuthPayload)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Register indicates an expected call of Register.
+func (mr *MockUserServiceMockRecorder) Register(ctx, username, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockUserService)(nil).Register), ctx, username, password)
 }
 
 // MockViewerService is a mock of ViewerService interface.
@@ -173,11 +504,11 @@ func (m *MockViewerService) EXPECT() *MockViewerServiceMockRecorder {
 }
 
 // CreateViewer mocks base method.
-func (m *MockViewerService) CreateViewer(ctx context.Context, postId uuid.UUID) (int, chan *models.Comment, error) {
+func (m *MockViewerService) CreateViewer(ctx context.Context, postId uuid.UUID) (int, chan models.PostEvent, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateViewer", ctx, postId)
 	ret0, _ := ret[0].(int)
-	ret1, _ := ret[1].(chan *models.Comment)
+	ret1, _ := ret[1].(chan models.PostEvent)
 	ret2, _ := ret[2].(error)
 	return ret0, ret1, ret2
 }
@@ -203,15 +534,510 @@ func (mr *MockViewerServiceMockRecorder) DeleteViewer(ctx, postId, id interface{
 }
 
 // NotifyViewers mocks base method.
-func (m *MockViewerService) NotifyViewers(ctx context.Context, postId uuid.UUID, comment models.Comment) error {
+func (m *MockViewerService) NotifyViewers(ctx context.Context, postId uuid.UUID, event models.PostEvent) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "NotifyViewers", ctx, postId, comment)
+	ret := m.ctrl.Call(m, "NotifyViewers", ctx, postId, event)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // NotifyViewers indicates an expected call of NotifyViewers.
-func (mr *MockViewerServiceMockRecorder) NotifyViewers(ctx, postId, comment interface{}) *gomock.Call {
+func (mr *MockViewerServiceMockRecorder) NotifyViewers(ctx, postId, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyViewers", reflect.TypeOf((*MockViewerService)(nil).NotifyViewers), ctx, postId, event)
+}
+
+// UnwatchActivity mocks base method.
+func (m *MockViewerService) UnwatchActivity(ctx context.Context, postId uuid.UUID, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnwatchActivity", ctx, postId, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnwatchActivity indicates an expected call of UnwatchActivity.
+func (mr *MockViewerServiceMockRecorder) UnwatchActivity(ctx, postId, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnwatchActivity", reflect.TypeOf((*MockViewerService)(nil).UnwatchActivity), ctx, postId, id)
+}
+
+// WatchActivity mocks base method.
+func (m *MockViewerService) WatchActivity(ctx context.Context, postId uuid.UUID) (int, chan *models.PostActivity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchActivity", ctx, postId)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(chan *models.PostActivity)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// WatchActivity indicates an expected call of WatchActivity.
+func (mr *MockViewerServiceMockRecorder) WatchActivity(ctx, postId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchActivity", reflect.TypeOf((*MockViewerService)(nil).WatchActivity), ctx, postId)
+}
+
+// MockNotificationService is a mock of NotificationService interface.
+type MockNotificationService struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationServiceMockRecorder
+}
+
+// MockNotificationServiceMockRecorder is the mock recorder for MockNotificationService.
+type MockNotificationServiceMockRecorder struct {
+	mock *MockNotificationService
+}
+
+// NewMockNotificationService creates a new mock instance.
+func NewMockNotificationService(ctrl *gomock.Controller) *MockNotificationService {
+	mock := &MockNotificationService{ctrl: ctrl}
+	mock.recorder = &MockNotificationServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationService) EXPECT() *MockNotificationServiceMockRecorder {
+	return m.recorder
+}
+
+// GetMyNotifications mocks base method.
+func (m *MockNotificationService) GetMyNotifications(ctx context.Context, page models.PageRequest) (*models.NotificationConnection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMyNotifications", ctx, page)
+	ret0, _ := ret[0].(*models.NotificationConnection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMyNotifications indicates an expected call of GetMyNotifications.
+func (mr *MockNotificationServiceMockRecorder) GetMyNotifications(ctx, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMyNotifications", reflect.TypeOf((*MockNotificationService)(nil).GetMyNotifications), ctx, page)
+}
+
+// MarkNotificationsRead mocks base method.
+func (m *MockNotificationService) MarkNotificationsRead(ctx context.Context, ids []uuid.UUID) (int32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkNotificationsRead", ctx, ids)
+	ret0, _ := ret[0].(int32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkNotificationsRead indicates an expected call of MarkNotificationsRead.
+func (mr *MockNotificationServiceMockRecorder) MarkNotificationsRead(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkNotificationsRead", reflect.TypeOf((*MockNotificationService)(nil).MarkNotificationsRead), ctx, ids)
+}
+
+// NotifyComment mocks base method.
+func (m *MockNotificationService) NotifyComment(ctx context.Context, comment *models.Comment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotifyComment", ctx, comment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// NotifyComment indicates an expected call of NotifyComment.
+func (mr *MockNotificationServiceMockRecorder) NotifyComment(ctx, comment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyComment", reflect.TypeOf((*MockNotificationService)(nil).NotifyComment), ctx, comment)
+}
+
+// MockInboxService is a mock of InboxService interface.
+type MockInboxService struct {
+	ctrl     *gomock.Controller
+	recorder *MockInboxServiceMockRecorder
+}
+
+// MockInboxServiceMockRecorder is the mock recorder for MockInboxService.
+type MockInboxServiceMockRecorder struct {
+	mock *MockInboxService
+}
+
+// NewMockInboxService creates a new mock instance.
+func NewMockInboxService(ctrl *gomock.Controller) *MockInboxService {
+	mock := &MockInboxService{ctrl: ctrl}
+	mock.recorder = &MockInboxServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInboxService) EXPECT() *MockInboxServiceMockRecorder {
+	return m.recorder
+}
+
+// Deliver mocks base method.
+func (m *MockInboxService) Deliver(ctx context.Context, notification *models.Notification) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deliver", ctx, notification)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Deliver indicates an expected call of Deliver.
+func (mr *MockInboxServiceMockRecorder) Deliver(ctx, notification interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deliver", reflect.TypeOf((*MockInboxService)(nil).Deliver), ctx, notification)
+}
+
+// Subscribe mocks base method.
+func (m *MockInboxService) Subscribe(ctx context.Context) (int, chan *models.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(chan *models.Notification)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockInboxServiceMockRecorder) Subscribe(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockInboxService)(nil).Subscribe), ctx)
+}
+
+// Unsubscribe mocks base method.
+func (m *MockInboxService) Unsubscribe(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unsubscribe", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unsubscribe indicates an expected call of Unsubscribe.
+func (mr *MockInboxServiceMockRecorder) Unsubscribe(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unsubscribe", reflect.TypeOf((*MockInboxService)(nil).Unsubscribe), ctx, id)
+}
+
+// MockCommunityService is a mock of CommunityService interface.
+type MockCommunityService struct {
+	ctrl     *gomock.Controller
+	recorder *MockCommunityServiceMockRecorder
+}
+
+// MockCommunityServiceMockRecorder is the mock recorder for MockCommunityService.
+type MockCommunityServiceMockRecorder struct {
+	mock *MockCommunityService
+}
+
+// NewMockCommunityService creates a new mock instance.
+func NewMockCommunityService(ctrl *gomock.Controller) *MockCommunityService {
+	mock := &MockCommunityService{ctrl: ctrl}
+	mock.recorder = &MockCommunityServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCommunityService) EXPECT() *MockCommunityServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateCommunity mocks base method.
+func (m *MockCommunityService) CreateCommunity(ctx context.Context, req models.CommunityRequest) (*models.Community, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCommunity", ctx, req)
+	ret0, _ := ret[0].(*models.Community)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCommunity indicates an expected call of CreateCommunity.
+func (mr *MockCommunityServiceMockRecorder) CreateCommunity(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCommunity", reflect.TypeOf((*MockCommunityService)(nil).CreateCommunity), ctx, req)
+}
+
+// GetCommunitiesByIDs mocks base method.
+func (m *MockCommunityService) GetCommunitiesByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.Community, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommunitiesByIDs", ctx, ids)
+	ret0, _ := ret[0].(map[uuid.UUID]*models.Community)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommunitiesByIDs indicates an expected call of GetCommunitiesByIDs.
+func (mr *MockCommunityServiceMockRecorder) GetCommunitiesByIDs(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommunitiesByIDs", reflect.TypeOf((*MockCommunityService)(nil).GetCommunitiesByIDs), ctx, ids)
+}
+
+// GetCommunityBySlug mocks base method.
+func (m *MockCommunityService) GetCommunityBySlug(ctx context.Context, slug string) (*models.Community, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommunityBySlug", ctx, slug)
+	ret0, _ := ret[0].(*models.Community)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommunityBySlug indicates an expected call of GetCommunityBySlug.
+func (mr *MockCommunityServiceMockRecorder) GetCommunityBySlug(ctx, slug interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommunityBySlug", reflect.TypeOf((*MockCommunityService)(nil).GetCommunityBySlug), ctx, slug)
+}
+
+// GetMemberships mocks base method.
+func (m *MockCommunityService) GetMemberships(ctx context.Context, communityIDs []uuid.UUID, username string) (map[uuid.UUID]bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMemberships", ctx, communityIDs, username)
+	ret0, _ := ret[0].(map[uuid.UUID]bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMemberships indicates an expected call of GetMemberships.
+func (mr *MockCommunityServiceMockRecorder) GetMemberships(ctx, communityIDs, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberships", reflect.TypeOf((*MockCommunityService)(nil).GetMemberships), ctx, communityIDs, username)
+}
+
+// JoinCommunity mocks base method.
+func (m *MockCommunityService) JoinCommunity(ctx context.Context, slug string) (*models.Community, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JoinCommunity", ctx, slug)
+	ret0, _ := ret[0].(*models.Community)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// JoinCommunity indicates an expected call of JoinCommunity.
+func (mr *MockCommunityServiceMockRecorder) JoinCommunity(ctx, slug interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinCommunity", reflect.TypeOf((*MockCommunityService)(nil).JoinCommunity), ctx, slug)
+}
+
+// LeaveCommunity mocks base method.
+func (m *MockCommunityService) LeaveCommunity(ctx context.Context, slug string) (*models.Community, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LeaveCommunity", ctx, slug)
+	ret0, _ := ret[0].(*models.Community)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LeaveCommunity indicates an expected call of LeaveCommunity.
+func (mr *MockCommunityServiceMockRecorder) LeaveCommunity(ctx, slug interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LeaveCommunity", reflect.TypeOf((*MockCommunityService)(nil).LeaveCommunity), ctx, slug)
+}
+
+// MockModerationService is a mock of ModerationService interface.
+type MockModerationService struct {
+	ctrl     *gomock.Controller
+	recorder *MockModerationServiceMockRecorder
+}
+
+// MockModerationServiceMockRecorder is the mock recorder for MockModerationService.
+type MockModerationServiceMockRecorder struct {
+	mock *MockModerationService
+}
+
+// NewMockModerationService creates a new mock instance.
+func NewMockModerationService(ctrl *gomock.Controller) *MockModerationService {
+	mock := &MockModerationService{ctrl: ctrl}
+	mock.recorder = &MockModerationServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockModerationService) EXPECT() *MockModerationServiceMockRecorder {
+	return m.recorder
+}
+
+// AddCommunityModerator mocks base method.
+func (m *MockModerationService) AddCommunityModerator(ctx context.Context, slug, username string) (*models.Community, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCommunityModerator", ctx, slug, username)
+	ret0, _ := ret[0].(*models.Community)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddCommunityModerator indicates an expected call of AddCommunityModerator.
+func (mr *MockModerationServiceMockRecorder) AddCommunityModerator(ctx, slug, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCommunityModerator", reflect.TypeOf((*MockModerationService)(nil).AddCommunityModerator), ctx, slug, username)
+}
+
+// GetModerationLog mocks base method.
+func (m *MockModerationService) GetModerationLog(ctx context.Context, slug *string, pageReq models.PageRequest) (*models.ModerationActionConnection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetModerationLog", ctx, slug, pageReq)
+	ret0, _ := ret[0].(*models.ModerationActionConnection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetModerationLog indicates an expected call of GetModerationLog.
+func (mr *MockModerationServiceMockRecorder) GetModerationLog(ctx, slug, pageReq interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetModerationLog", reflect.TypeOf((*MockModerationService)(nil).GetModerationLog), ctx, slug, pageReq)
+}
+
+// RemoveComment mocks base method.
+func (m *MockModerationService) RemoveComment(ctx context.Context, commentID uuid.UUID, reason string) (*models.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveComment", ctx, commentID, reason)
+	ret0, _ := ret[0].(*models.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveComment indicates an expected call of RemoveComment.
+func (mr *MockModerationServiceMockRecorder) RemoveComment(ctx, commentID, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveComment", reflect.TypeOf((*MockModerationService)(nil).RemoveComment), ctx, commentID, reason)
+}
+
+// RemoveCommunityModerator mocks base method.
+func (m *MockModerationService) RemoveCommunityModerator(ctx context.Context, slug, username string) (*models.Community, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveCommunityModerator", ctx, slug, username)
+	ret0, _ := ret[0].(*models.Community)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveCommunityModerator indicates an expected call of RemoveCommunityModerator.
+func (mr *MockModerationServiceMockRecorder) RemoveCommunityModerator(ctx, slug, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveCommunityModerator", reflect.TypeOf((*MockModerationService)(nil).RemoveCommunityModerator), ctx, slug, username)
+}
+
+// RemovePost mocks base method.
+func (m *MockModerationService) RemovePost(ctx context.Context, postID uuid.UUID, reason string) (*models.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemovePost", ctx, postID, reason)
+	ret0, _ := ret[0].(*models.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemovePost indicates an expected call of RemovePost.
+func (mr *MockModerationServiceMockRecorder) RemovePost(ctx, postID, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePost", reflect.TypeOf((*MockModerationService)(nil).RemovePost), ctx, postID, reason)
+}
+
+// SetUserRole mocks base method.
+func (m *MockModerationService) SetUserRole(ctx context.Context, username string, role models.Role) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserRole", ctx, username, role)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetUserRole indicates an expected call of SetUserRole.
+func (mr *MockModerationServiceMockRecorder) SetUserRole(ctx, username, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserRole", reflect.TypeOf((*MockModerationService)(nil).SetUserRole), ctx, username, role)
+}
+
+// MockReportService is a mock of ReportService interface.
+type MockReportService struct {
+	ctrl     *gomock.Controller
+	recorder *MockReportServiceMockRecorder
+}
+
+// MockReportServiceMockRecorder is the mock recorder for MockReportService.
+type MockReportServiceMockRecorder struct {
+	mock *MockReportService
+}
+
+// NewMockReportService creates a new mock instance.
+func NewMockReportService(ctrl *gomock.Controller) *MockReportService {
+	mock := &MockReportService{ctrl: ctrl}
+	mock.recorder = &MockReportServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReportService) EXPECT() *MockReportServiceMockRecorder {
+	return m.recorder
+}
+
+// GetReportQueue mocks base method.
+func (m *MockReportService) GetReportQueue(ctx context.Context, status models.ReportStatus, pageReq models.PageRequest) (*models.ReportConnection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReportQueue", ctx, status, pageReq)
+	ret0, _ := ret[0].(*models.ReportConnection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReportQueue indicates an expected call of GetReportQueue.
+func (mr *MockReportServiceMockRecorder) GetReportQueue(ctx, status, pageReq interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportQueue", reflect.TypeOf((*MockReportService)(nil).GetReportQueue), ctx, status, pageReq)
+}
+
+// ReportContent mocks base method.
+func (m *MockReportService) ReportContent(ctx context.Context, targetType models.ReportTargetType, targetID uuid.UUID, reason models.ReportReason, note *string) (*models.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReportContent", ctx, targetType, targetID, reason, note)
+	ret0, _ := ret[0].(*models.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReportContent indicates an expected call of ReportContent.
+func (mr *MockReportServiceMockRecorder) ReportContent(ctx, targetType, targetID, reason, note interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportContent", reflect.TypeOf((*MockReportService)(nil).ReportContent), ctx, targetType, targetID, reason, note)
+}
+
+// ResolveReport mocks base method.
+func (m *MockReportService) ResolveReport(ctx context.Context, reportID uuid.UUID, action models.ReportAction) (*models.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveReport", ctx, reportID, action)
+	ret0, _ := ret[0].(*models.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveReport indicates an expected call of ResolveReport.
+func (mr *MockReportServiceMockRecorder) ResolveReport(ctx, reportID, action interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveReport", reflect.TypeOf((*MockReportService)(nil).ResolveReport), ctx, reportID, action)
+}
+
+// MockRateLimitService is a mock of RateLimitService interface.
+type MockRateLimitService struct {
+	ctrl     *gomock.Controller
+	recorder *MockRateLimitServiceMockRecorder
+}
+
+// MockRateLimitServiceMockRecorder is the mock recorder for MockRateLimitService.
+type MockRateLimitServiceMockRecorder struct {
+	mock *MockRateLimitService
+}
+
+// NewMockRateLimitService creates a new mock instance.
+func NewMockRateLimitService(ctrl *gomock.Controller) *MockRateLimitService {
+	mock := &MockRateLimitService{ctrl: ctrl}
+	mock.recorder = &MockRateLimitServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRateLimitService) EXPECT() *MockRateLimitServiceMockRecorder {
+	return m.recorder
+}
+
+// Allow mocks base method.
+func (m *MockRateLimitService) Allow(ctx context.Context, operation string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Allow", ctx, operation)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Allow indicates an expected call of Allow.
+func (mr *MockRateLimitServiceMockRecorder) Allow(ctx, operation interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyViewers", reflect.TypeOf((*MockViewerService)(nil).NotifyViewers), ctx, postId, comment)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Allow", reflect.TypeOf((*MockRateLimitService)(nil).Allow), ctx, operation)
 }
@@ -0,0 +1,426 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/auth"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/internal/storage"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+	"github.com/nedokyrill/posts-service/pkg/logger"
+	"github.com/nedokyrill/posts-service/pkg/utils"
+)
+
+type ModerationServiceImpl struct {
+	store          storage.ModerationStorage
+	postStore      storage.PostStorage
+	commStore      storage.CommentStorage
+	communityStore storage.CommunityStorage
+	userStore      storage.UserStorage
+	moderators     moderators
+}
+
+func NewModerationService(store storage.ModerationStorage, postStore storage.PostStorage,
+	commStore storage.CommentStorage, communityStore storage.CommunityStorage,
+	userStore storage.UserStorage) *ModerationServiceImpl {
+	return &ModerationServiceImpl{
+		store:          store,
+		postStore:      postStore,
+		commStore:      commStore,
+		communityStore: communityStore,
+		userStore:      userStore,
+		moderators:     moderators{userStore: userStore, communityStore: communityStore},
+	}
+}
+
+func (s *ModerationServiceImpl) RemovePost(ctx context.Context, postID uuid.UUID,
+	reason string) (*models.Post, error) {
+	identity, err := requireIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if reason, err = parseReason(reason); err != nil {
+		return nil, err
+	}
+
+	post, err := s.postStore.GetPostByID(ctx, postID)
+	if err != nil {
+		return nil, notFoundOr(err, fmt.Sprintf("post with id: %s not found", postID.String()),
+			"error removing post")
+	}
+
+	if post.IsRemoved {
+		return nil, utils.GqlError{
+			Msg:  fmt.Sprintf("post with id: %s is already removed", postID.String()),
+			Type: consts.BadRequestType,
+		}
+	}
+
+	if err = s.moderators.require(ctx, post.CommunityID, "error removing post"); err != nil {
+		return nil, err
+	}
+
+	removed, err := s.store.RemovePost(ctx, postID, models.ModerationAction{
+		Actor:       identity.Username,
+		Action:      models.ModerationActionTypeRemovePost,
+		PostID:      &postID,
+		CommunityID: post.CommunityID,
+		Reason:      reason,
+	})
+	if err != nil {
+		return nil, notFoundOr(err, fmt.Sprintf("post with id: %s is already removed", postID.String()),
+			"error removing post")
+	}
+
+	logger.Logger.Info(fmt.Sprintf("remove post with id: %s by %s successfully", postID.String(), identity.Username))
+	return &removed, nil
+}
+func (s *ModerationServiceImpl) RemoveComment(ctx context.Context, commentID uuid.UUID,
+	reason string) (*models.Comment, error) {
+	identity, err := requireIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if reason, err = parseReason(reason); err != nil {
+		return nil, err
+	}
+
+	comment, err := s.commStore.GetCommentByID(ctx, commentID)
+	if err != nil {
+		return nil, notFoundOr(err, fmt.Sprintf("comment with id: %s not found", commentID.String()),
+			"error removing comment")
+	}
+
+	if comment.IsDeleted || comment.IsRemoved {
+		return nil, utils.GqlError{
+			Msg:  fmt.Sprintf("comment with id: %s is already deleted or removed", commentID.String()),
+			Type: consts.BadRequestType,
+		}
+	}
+
+	// комментарий модерируют модераторы сообщества его поста
+	post, err := s.postStore.GetPostByID(ctx, comment.PostID)
+	if err != nil {
+		logger.Logger.Error(fmt.Sprintf("error getting post of comment: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error removing comment",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	if err = s.moderators.require(ctx, post.CommunityID, "error removing comment"); err != nil {
+		return nil, err
+	}
+
+	removed, err := s.store.RemoveComment(ctx, commentID, models.ModerationAction{
+		Actor:       identity.Username,
+		Action:      models.ModerationActionTypeRemoveComment,
+		PostID:      &comment.PostID,
+		CommentID:   &commentID,
+		CommunityID: post.CommunityID,
+		Reason:      reason,
+	})
+	if err != nil {
+		return nil, notFoundOr(err, fmt.Sprintf("comment with id: %s is already removed", commentID.String()),
+			"error removing comment")
+	}
+
+	logger.Logger.Info(fmt.Sprintf("remove comment with id: %s by %s successfully", commentID.String(),
+		identity.Username))
+	return &removed, nil
+}
+func (s *ModerationServiceImpl) SetUserRole(ctx context.Context, username string,
+	role models.Role) (*models.User, error) {
+	identity, err := s.requireAdmin(ctx, "error setting user role")
+	if err != nil {
+		return nil, err
+	}
+
+	if !role.IsValid() {
+		return nil, utils.GqlError{
+			Msg:  fmt.Sprintf("invalid role: %s", role),
+			Type: consts.BadRequestType,
+		}
+	}
+
+	// иначе последний администратор мог бы случайно лишить себя прав
+	if username == identity.Username {
+		return nil, utils.GqlError{
+			Msg:  "can not change own role",
+			Type: consts.BadRequestType,
+		}
+	}
+
+	user, err := s.getUser(ctx, username, "error setting user role")
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Role == role {
+		return user, nil
+	}
+
+	updated, err := s.store.SetUserRole(ctx, username, role, models.ModerationAction{
+		Actor:      identity.Username,
+		Action:     models.ModerationActionTypeSetRole,
+		TargetUser: &username,
+		Role:       &role,
+	})
+	if err != nil {
+		logger.Logger.Error(fmt.Sprintf("error setting user role: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error setting user role",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	logger.Logger.Info(fmt.Sprintf("set role %s to user %s successfully", role, username))
+	return &updated, nil
+}
+
+// PromoteAdmins при запуске назначает роль ADMIN уже зарегистрированным пользователям из списка. Роль не выдается
+// при регистрации, иначе имя из списка мог бы первым занять кто угодно. Незарегистрированные имена пропускаются
+func (s *ModerationServiceImpl) PromoteAdmins(ctx context.Context, usernames []string) error {
+	admin := models.RoleAdmin
+	for _, username := range usernames {
+		user, err := s.userStore.GetUserByUsername(ctx, username)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				logger.Logger.Warn(fmt.Sprintf("admin %s is not registered, skipping", username))
+				continue
+			}
+			return fmt.Errorf("error getting user %s: %w", username, err)
+		}
+
+		if user.Role == admin {
+			continue
+		}
+
+		_, err = s.store.SetUserRole(ctx, username, admin, models.ModerationAction{
+			Actor:      consts.SystemActor,
+			Action:     models.ModerationActionTypeSetRole,
+			TargetUser: &user.Username,
+			Role:       &admin,
+			Reason:     "listed in ADMIN_USERNAMES",
+		})
+		if err != nil {
+			return fmt.Errorf("error promoting user %s: %w", username, err)
+		}
+
+		logger.Logger.Info(fmt.Sprintf("set role %s to user %s successfully", admin, username))
+	}
+	return nil
+}
+func (s *ModerationServiceImpl) AddCommunityModerator(ctx context.Context, slug,
+	username string) (*models.Community, error) {
+	identity, community, err := s.getManagedCommunity(ctx, slug, "error adding community moderator")
+	if err != nil {
+		return nil, err
+	}
+
+	if username == community.Owner {
+		return nil, utils.GqlError{
+			Msg:  fmt.Sprintf("user %s owns community %s and already moderates it", username, community.Slug),
+			Type: consts.BadRequestType,
+		}
+	}
+
+	if _, err = s.getUser(ctx, username, "error adding community moderator"); err != nil {
+		return nil, err
+	}
+
+	err = s.store.AddCommunityModerator(ctx, community.ID, username, models.ModerationAction{
+		Actor:       identity.Username,
+		Action:      models.ModerationActionTypeAddModerator,
+		TargetUser:  &username,
+		CommunityID: &community.ID,
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrAlreadyExists) {
+			return nil, utils.GqlError{
+				Msg:  fmt.Sprintf("user %s is already a moderator of community %s", username, community.Slug),
+				Type: consts.BadRequestType,
+			}
+		}
+		logger.Logger.Error(fmt.Sprintf("error adding community moderator: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error adding community moderator",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	logger.Logger.Info(fmt.Sprintf("add moderator %s to community %s successfully", username, community.Slug))
+	return community, nil
+}
+func (s *ModerationServiceImpl) RemoveCommunityModerator(ctx context.Context, slug,
+	username string) (*models.Community, error) {
+	identity, community, err := s.getManagedCommunity(ctx, slug, "error removing community moderator")
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.store.RemoveCommunityModerator(ctx, community.ID, username, models.ModerationAction{
+		Actor:       identity.Username,
+		Action:      models.ModerationActionTypeRemoveModerator,
+		TargetUser:  &username,
+		CommunityID: &community.ID,
+	})
+	if err != nil {
+		return nil, notFoundOr(err, fmt.Sprintf("user %s is not a moderator of community %s", username,
+			community.Slug), "error removing community moderator")
+	}
+
+	logger.Logger.Info(fmt.Sprintf("remove moderator %s from community %s successfully", username, community.Slug))
+	return community, nil
+}
+
+// журнал модерации от новых записей к старым: весь - для модераторов, по сообществу - и для его модераторов
+func (s *ModerationServiceImpl) GetModerationLog(ctx context.Context, slug *string,
+	pageReq models.PageRequest) (*models.ModerationActionConnection, error) {
+	if _, err := requireIdentity(ctx); err != nil {
+		return nil, err
+	}
+
+	var communityID *uuid.UUID
+	if slug != nil {
+		community, err := getCommunity(ctx, s.communityStore, *slug)
+		if err != nil {
+			return nil, err
+		}
+		communityID = &community.ID
+	}
+
+	if err := s.moderators.require(ctx, communityID, "error getting moderation log"); err != nil {
+		return nil, err
+	}
+
+	page, err := parsePageRequest(pageReq)
+	if err != nil {
+		return nil, err
+	}
+
+	actions, err := s.store.GetModerationLog(ctx, communityID, withLookahead(page))
+	if err != nil {
+		logger.Logger.Error(fmt.Sprintf("error getting moderation log: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error getting moderation log",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	actions, pageInfo := cutPage(actions, page, models.ModerationActionCursor)
+
+	edges := make([]*models.ModerationActionEdge, 0, len(actions))
+	for _, action := range actions {
+		edges = append(edges, &models.ModerationActionEdge{
+			Cursor: models.ModerationActionCursor(action).Encode(),
+			Node:   action,
+		})
+	}
+
+	logger.Logger.Info("get moderation log successfully")
+	return &models.ModerationActionConnection{Edges: edges, PageInfo: pageInfo}, nil
+}
+
+// проверяем, что текущий пользователь - администратор
+func (s *ModerationServiceImpl) requireAdmin(ctx context.Context, errMsg string) (auth.Identity, error) {
+	identity, err := requireIdentity(ctx)
+	if err != nil {
+		return auth.Identity{}, err
+	}
+
+	role, err := s.moderators.role(ctx)
+	if err != nil {
+		logger.Logger.Error(fmt.Sprintf("error getting user role: %v", err))
+		return auth.Identity{}, utils.GqlError{
+			Msg:  errMsg,
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	if role != models.RoleAdmin {
+		return auth.Identity{}, utils.GqlError{
+			Msg:  "only admins can do this",
+			Type: consts.ForbiddenType,
+		}
+	}
+	return identity, nil
+}
+
+// получаем сообщество и проверяем, что модераторов в нем назначает текущий пользователь: создатель или администратор
+func (s *ModerationServiceImpl) getManagedCommunity(ctx context.Context, slug,
+	errMsg string) (auth.Identity, *models.Community, error) {
+	identity, err := requireIdentity(ctx)
+	if err != nil {
+		return auth.Identity{}, nil, err
+	}
+
+	community, err := getCommunity(ctx, s.communityStore, slug)
+	if err != nil {
+		return auth.Identity{}, nil, err
+	}
+
+	if community.Owner == identity.Username {
+		return identity, community, nil
+	}
+
+	role, err := s.moderators.role(ctx)
+	if err != nil {
+		logger.Logger.Error(fmt.Sprintf("error getting user role: %v", err))
+		return auth.Identity{}, nil, utils.GqlError{
+			Msg:  errMsg,
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	if role != models.RoleAdmin {
+		return auth.Identity{}, nil, utils.GqlError{
+			Msg:  "only community owner or admins can manage community moderators",
+			Type: consts.ForbiddenType,
+		}
+	}
+	return identity, community, nil
+}
+
+func (s *ModerationServiceImpl) getUser(ctx context.Context, username, errMsg string) (*models.User, error) {
+	user, err := s.userStore.GetUserByUsername(ctx, username)
+	if err != nil {
+		return nil, notFoundOr(err, fmt.Sprintf("user %s not found", username), errMsg)
+	}
+	return user, nil
+}
+
+// причина скрытия контента без пробелов по краям, пустая или слишком длинная причина - ошибка
+func parseReason(reason string) (string, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || utf8.RuneCountInString(reason) > consts.ModerationReasonMaxLen {
+		return "", utils.GqlError{
+			Msg:  fmt.Sprintf("reason must be between 1 and %d characters", consts.ModerationReasonMaxLen),
+			Type: consts.BadRequestType,
+		}
+	}
+	return reason, nil
+}
+
+// sql.ErrNoRows превращаем в ошибку BadRequest с сообщением notFoundMsg, остальные ошибки - во внутреннюю
+func notFoundOr(err error, notFoundMsg, errMsg string) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return utils.GqlError{
+			Msg:  notFoundMsg,
+			Type: consts.BadRequestType,
+		}
+	}
+	logger.Logger.Error(fmt.Sprintf("%s: %v", errMsg, err))
+	return utils.GqlError{
+		Msg:  errMsg,
+		Type: consts.InternalServerErrorType,
+	}
+}
@@ -0,0 +1,506 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/models"
+	store_mock "github.com/nedokyrill/posts-service/internal/storage/mocks"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+	"github.com/nedokyrill/posts-service/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModerationService_RemovePost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	moderationStorage := store_mock.NewMockModerationStorage(ctrl)
+	postStorage := store_mock.NewMockPostStorage(ctrl)
+	communityStorage := store_mock.NewMockCommunityStorage(ctrl)
+	userStorage := store_mock.NewMockUserStorage(ctrl)
+
+	moderationService := NewModerationService(moderationStorage, postStorage, store_mock.NewMockCommentStorage(ctrl),
+		communityStorage, userStorage)
+
+	t.Run("successfully remove post by moderator", func(t *testing.T) {
+		// Setup
+		ctx := withUser("test_moderator")
+		post := &models.Post{ID: uuid.New(), Author: "test_author", Title: "Spam"}
+
+		// Mock expectations
+		postStorage.EXPECT().
+			GetPostByID(ctx, post.ID).
+			Return(post, nil)
+		userStorage.EXPECT().
+			GetUserByID(ctx, gomock.Any()).
+			Return(&models.User{Username: "test_moderator", Role: models.RoleModerator}, nil)
+		moderationStorage.EXPECT().
+			RemovePost(ctx, post.ID, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, postID uuid.UUID, action models.ModerationAction) (models.Post, error) {
+				assert.Equal(t, "test_moderator", action.Actor)
+				assert.Equal(t, models.ModerationActionTypeRemovePost, action.Action)
+				assert.Equal(t, "spam", action.Reason)
+				removed := *post
+				removed.IsRemoved = true
+				return removed, nil
+			})
+
+		// Execute
+		result, err := moderationService.RemovePost(ctx, post.ID, " spam ")
+
+		// Verify
+		require.NoError(t, err)
+		assert.True(t, result.IsRemoved)
+	})
+
+	t.Run("successfully remove post by community moderator", func(t *testing.T) {
+		// Setup
+		ctx := withUser("community_moderator")
+		communityID := uuid.New()
+		post := &models.Post{ID: uuid.New(), Author: "test_author", CommunityID: &communityID}
+
+		// Mock expectations
+		postStorage.EXPECT().
+			GetPostByID(ctx, post.ID).
+			Return(post, nil)
+		userStorage.EXPECT().
+			GetUserByID(ctx, gomock.Any()).
+			Return(&models.User{Username: "community_moderator", Role: models.RoleUser}, nil)
+		communityStorage.EXPECT().
+			IsModerator(ctx, communityID, "community_moderator").
+			Return(true, nil)
+		moderationStorage.EXPECT().
+			RemovePost(ctx, post.ID, gomock.Any()).
+			Return(models.Post{ID: post.ID, CommunityID: &communityID, IsRemoved: true}, nil)
+
+		// Execute
+		result, err := moderationService.RemovePost(ctx, post.ID, "off-topic")
+
+		// Verify
+		require.NoError(t, err)
+		assert.True(t, result.IsRemoved)
+	})
+
+	t.Run("fail when user is not moderator", func(t *testing.T) {
+		// Setup
+		ctx := withUser("test_user")
+		post := &models.Post{ID: uuid.New(), Author: "test_author"}
+
+		// Mock expectations
+		postStorage.EXPECT().
+			GetPostByID(ctx, post.ID).
+			Return(post, nil)
+		userStorage.EXPECT().
+			GetUserByID(ctx, gomock.Any()).
+			Return(&models.User{Username: "test_user", Role: models.RoleUser}, nil)
+
+		// Execute
+		result, err := moderationService.RemovePost(ctx, post.ID, "spam")
+
+		// Verify
+		require.Error(t, err)
+		assert.Equal(t, consts.ForbiddenType, err.(utils.GqlError).Type)
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail on empty reason", func(t *testing.T) {
+		// Execute
+		result, err := moderationService.RemovePost(withUser("test_moderator"), uuid.New(), "  ")
+
+		// Verify
+		require.Error(t, err)
+		assert.Equal(t, consts.BadRequestType, err.(utils.GqlError).Type)
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail when post is already removed", func(t *testing.T) {
+		// Setup
+		ctx := withUser("test_moderator")
+		post := &models.Post{ID: uuid.New(), IsRemoved: true}
+
+		// Mock expectations
+		postStorage.EXPECT().
+			GetPostByID(ctx, post.ID).
+			Return(post, nil)
+
+		// Execute
+		result, err := moderationService.RemovePost(ctx, post.ID, "spam")
+
+		// Verify
+		require.Error(t, err)
+		assert.Equal(t, consts.BadRequestType, err.(utils.GqlError).Type)
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail when unauthenticated", func(t *testing.T) {
+		// Execute
+		result, err := moderationService.RemovePost(context.Background(), uuid.New(), "spam")
+
+		// Verify
+		require.Error(t, err)
+		assert.Equal(t, consts.UnauthorizedType, err.(utils.GqlError).Type)
+		assert.Nil(t, result)
+	})
+}
+
+func TestModerationService_RemoveComment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	moderationStorage := store_mock.NewMockModerationStorage(ctrl)
+	postStorage := store_mock.NewMockPostStorage(ctrl)
+	commentStorage := store_mock.NewMockCommentStorage(ctrl)
+	userStorage := store_mock.NewMockUserStorage(ctrl)
+
+	moderationService := NewModerationService(moderationStorage, postStorage, commentStorage,
+		store_mock.NewMockCommunityStorage(ctrl), userStorage)
+
+	ctx := withUser("test_admin")
+	post := &models.Post{ID: uuid.New(), Author: "test_author"}
+
+	t.Run("successfully remove comment", func(t *testing.T) {
+		// Setup
+		comment := &models.Comment{ID: uuid.New(), PostID: post.ID, Author: "test_user", Content: "spam"}
+
+		// Mock expectations
+		commentStorage.EXPECT().
+			GetCommentByID(ctx, comment.ID).
+			Return(comment, nil)
+		postStorage.EXPECT().
+			GetPostByID(ctx, post.ID).
+			Return(post, nil)
+		userStorage.EXPECT().
+			GetUserByID(ctx, gomock.Any()).
+			Return(&models.User{Username: "test_admin", Role: models.RoleAdmin}, nil)
+		moderationStorage.EXPECT().
+			RemoveComment(ctx, comment.ID, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, commentID uuid.UUID,
+				action models.ModerationAction) (models.Comment, error) {
+				assert.Equal(t, models.ModerationActionTypeRemoveComment, action.Action)
+				assert.Equal(t, &post.ID, action.PostID)
+				assert.Equal(t, &comment.ID, action.CommentID)
+				removed := *comment
+				removed.IsRemoved = true
+				return removed, nil
+			})
+
+		// Execute
+		result, err := moderationService.RemoveComment(ctx, comment.ID, "spam")
+
+		// Verify
+		require.NoError(t, err)
+		assert.True(t, result.IsRemoved)
+	})
+
+	t.Run("fail when comment is deleted", func(t *testing.T) {
+		// Setup
+		comment := &models.Comment{ID: uuid.New(), PostID: post.ID, IsDeleted: true}
+
+		// Mock expectations
+		commentStorage.EXPECT().
+			GetCommentByID(ctx, comment.ID).
+			Return(comment, nil)
+
+		// Execute
+		result, err := moderationService.RemoveComment(ctx, comment.ID, "spam")
+
+		// Verify
+		require.Error(t, err)
+		assert.Equal(t, consts.BadRequestType, err.(utils.GqlError).Type)
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail when comment not found", func(t *testing.T) {
+		// Setup
+		commentID := uuid.New()
+
+		// Mock expectations
+		commentStorage.EXPECT().
+			GetCommentByID(ctx, commentID).
+			Return(nil, sql.ErrNoRows)
+
+		// Execute
+		result, err := moderationService.RemoveComment(ctx, commentID, "spam")
+
+		// Verify
+		require.Error(t, err)
+		assert.Equal(t, consts.BadRequestType, err.(utils.GqlError).Type)
+		assert.Nil(t, result)
+	})
+}
+
+func TestModerationService_SetUserRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	moderationStorage := store_mock.NewMockModerationStorage(ctrl)
+	userStorage := store_mock.NewMockUserStorage(ctrl)
+
+	moderationService := NewModerationService(moderationStorage, store_mock.NewMockPostStorage(ctrl),
+		store_mock.NewMockCommentStorage(ctrl), store_mock.NewMockCommunityStorage(ctrl), userStorage)
+
+	t.Run("successfully set role by admin", func(t *testing.T) {
+		// Setup
+		ctx := withUser("test_admin")
+
+		// Mock expectations
+		userStorage.EXPECT().
+			GetUserByID(ctx, gomock.Any()).
+			Return(&models.User{Username: "test_admin", Role: models.RoleAdmin}, nil)
+		userStorage.EXPECT().
+			GetUserByUsername(ctx, "test_user").
+			Return(&models.User{Username: "test_user", Role: models.RoleUser}, nil)
+		moderationStorage.EXPECT().
+			SetUserRole(ctx, "test_user", models.RoleModerator, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, username string, role models.Role,
+				action models.ModerationAction) (models.User, error) {
+				assert.Equal(t, models.ModerationActionTypeSetRole, action.Action)
+				assert.Equal(t, &role, action.Role)
+				return models.User{Username: username, Role: role}, nil
+			})
+
+		// Execute
+		result, err := moderationService.SetUserRole(ctx, "test_user", models.RoleModerator)
+
+		// Verify
+		require.NoError(t, err)
+		assert.Equal(t, models.RoleModerator, result.Role)
+	})
+
+	t.Run("fail when user is not admin", func(t *testing.T) {
+		// Setup
+		ctx := withUser("test_moderator")
+
+		// Mock expectations
+		userStorage.EXPECT().
+			GetUserByID(ctx, gomock.Any()).
+			Return(&models.User{Username: "test_moderator", Role: models.RoleModerator}, nil)
+
+		// Execute
+		result, err := moderationService.SetUserRole(ctx, "test_user", models.RoleAdmin)
+
+		// Verify
+		require.Error(t, err)
+		assert.Equal(t, consts.ForbiddenType, err.(utils.GqlError).Type)
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail on own role", func(t *testing.T) {
+		// Setup
+		ctx := withUser("test_admin")
+
+		// Mock expectations
+		userStorage.EXPECT().
+			GetUserByID(ctx, gomock.Any()).
+			Return(&models.User{Username: "test_admin", Role: models.RoleAdmin}, nil)
+
+		// Execute
+		result, err := moderationService.SetUserRole(ctx, "test_admin", models.RoleUser)
+
+		// Verify
+		require.Error(t, err)
+		assert.Equal(t, consts.BadRequestType, err.(utils.GqlError).Type)
+		assert.Nil(t, result)
+	})
+}
+
+func TestModerationService_PromoteAdmins(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	moderationStorage := store_mock.NewMockModerationStorage(ctrl)
+	userStorage := store_mock.NewMockUserStorage(ctrl)
+
+	moderationService := NewModerationService(moderationStorage, store_mock.NewMockPostStorage(ctrl),
+		store_mock.NewMockCommentStorage(ctrl), store_mock.NewMockCommunityStorage(ctrl), userStorage)
+
+	t.Run("promote registered users only", func(t *testing.T) {
+		// Mock expectations
+		userStorage.EXPECT().
+			GetUserByUsername(ctx, "operator").
+			Return(&models.User{Username: "operator", Role: models.RoleUser}, nil)
+		userStorage.EXPECT().
+			GetUserByUsername(ctx, "test_admin").
+			Return(&models.User{Username: "test_admin", Role: models.RoleAdmin}, nil)
+		userStorage.EXPECT().
+			GetUserByUsername(ctx, "unknown").
+			Return(nil, sql.ErrNoRows)
+		moderationStorage.EXPECT().
+			SetUserRole(ctx, "operator", models.RoleAdmin, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, username string, role models.Role,
+				action models.ModerationAction) (models.User, error) {
+				assert.Equal(t, consts.SystemActor, action.Actor)
+				assert.Equal(t, models.ModerationActionTypeSetRole, action.Action)
+				return models.User{Username: username, Role: role}, nil
+			})
+
+		// Execute
+		err := moderationService.PromoteAdmins(ctx, []string{"operator", "test_admin", "unknown"})
+
+		// Verify
+		assert.NoError(t, err)
+	})
+
+	t.Run("fail on storage error", func(t *testing.T) {
+		// Mock expectations
+		userStorage.EXPECT().
+			GetUserByUsername(ctx, "operator").
+			Return(nil, assert.AnError)
+
+		// Execute
+		err := moderationService.PromoteAdmins(ctx, []string{"operator"})
+
+		// Verify
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+}
+
+func TestModerationService_CommunityModerators(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	moderationStorage := store_mock.NewMockModerationStorage(ctrl)
+	communityStorage := store_mock.NewMockCommunityStorage(ctrl)
+	userStorage := store_mock.NewMockUserStorage(ctrl)
+
+	moderationService := NewModerationService(moderationStorage, store_mock.NewMockPostStorage(ctrl),
+		store_mock.NewMockCommentStorage(ctrl), communityStorage, userStorage)
+
+	community := &models.Community{ID: uuid.New(), Slug: "golang", Owner: "test_owner"}
+
+	t.Run("successfully add moderator by owner", func(t *testing.T) {
+		// Setup
+		ctx := withUser("test_owner")
+
+		// Mock expectations
+		communityStorage.EXPECT().
+			GetCommunityBySlug(ctx, "golang").
+			Return(community, nil)
+		userStorage.EXPECT().
+			GetUserByUsername(ctx, "test_user").
+			Return(&models.User{Username: "test_user"}, nil)
+		moderationStorage.EXPECT().
+			AddCommunityModerator(ctx, community.ID, "test_user", gomock.Any()).
+			Return(nil)
+
+		// Execute
+		result, err := moderationService.AddCommunityModerator(ctx, "golang", "test_user")
+
+		// Verify
+		require.NoError(t, err)
+		assert.Equal(t, community, result)
+	})
+
+	t.Run("fail when user is not owner or admin", func(t *testing.T) {
+		// Setup
+		ctx := withUser("test_user")
+
+		// Mock expectations
+		communityStorage.EXPECT().
+			GetCommunityBySlug(ctx, "golang").
+			Return(community, nil)
+		userStorage.EXPECT().
+			GetUserByID(ctx, gomock.Any()).
+			Return(&models.User{Username: "test_user", Role: models.RoleModerator}, nil)
+
+		// Execute
+		result, err := moderationService.AddCommunityModerator(ctx, "golang", "another_user")
+
+		// Verify
+		require.Error(t, err)
+		assert.Equal(t, consts.ForbiddenType, err.(utils.GqlError).Type)
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail to remove not assigned moderator", func(t *testing.T) {
+		// Setup
+		ctx := withUser("test_owner")
+
+		// Mock expectations
+		communityStorage.EXPECT().
+			GetCommunityBySlug(ctx, "golang").
+			Return(community, nil)
+		moderationStorage.EXPECT().
+			RemoveCommunityModerator(ctx, community.ID, "test_user", gomock.Any()).
+			Return(sql.ErrNoRows)
+
+		// Execute
+		result, err := moderationService.RemoveCommunityModerator(ctx, "golang", "test_user")
+
+		// Verify
+		require.Error(t, err)
+		assert.Equal(t, consts.BadRequestType, err.(utils.GqlError).Type)
+		assert.Nil(t, result)
+	})
+}
+
+func TestModerationService_GetModerationLog(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	moderationStorage := store_mock.NewMockModerationStorage(ctrl)
+	communityStorage := store_mock.NewMockCommunityStorage(ctrl)
+	userStorage := store_mock.NewMockUserStorage(ctrl)
+
+	moderationService := NewModerationService(moderationStorage, store_mock.NewMockPostStorage(ctrl),
+		store_mock.NewMockCommentStorage(ctrl), communityStorage, userStorage)
+
+	community := &models.Community{ID: uuid.New(), Slug: "golang", Owner: "test_owner"}
+
+	t.Run("successfully get community log by community moderator", func(t *testing.T) {
+		// Setup
+		ctx := withUser("community_moderator")
+		slug := "golang"
+		actions := []*models.ModerationAction{
+			{ID: uuid.New(), Actor: "community_moderator", Action: models.ModerationActionTypeRemovePost,
+				CommunityID: &community.ID},
+		}
+
+		// Mock expectations
+		communityStorage.EXPECT().
+			GetCommunityBySlug(ctx, "golang").
+			Return(community, nil)
+		userStorage.EXPECT().
+			GetUserByID(ctx, gomock.Any()).
+			Return(&models.User{Username: "community_moderator", Role: models.RoleUser}, nil)
+		communityStorage.EXPECT().
+			IsModerator(ctx, community.ID, "community_moderator").
+			Return(true, nil)
+		moderationStorage.EXPECT().
+			GetModerationLog(ctx, &community.ID, models.Page{Limit: consts.PageSize + 1}).
+			Return(actions, nil)
+
+		// Execute
+		result, err := moderationService.GetModerationLog(ctx, &slug, models.PageRequest{})
+
+		// Verify
+		require.NoError(t, err)
+		require.Len(t, result.Edges, 1)
+		assert.Equal(t, actions[0], result.Edges[0].Node)
+		assert.False(t, result.PageInfo.HasNextPage)
+	})
+
+	t.Run("fail to get whole log by community moderator", func(t *testing.T) {
+		// Setup
+		ctx := withUser("community_moderator")
+
+		// Mock expectations
+		userStorage.EXPECT().
+			GetUserByID(ctx, gomock.Any()).
+			Return(&models.User{Username: "community_moderator", Role: models.RoleUser}, nil)
+
+		// Execute
+		result, err := moderationService.GetModerationLog(ctx, nil, models.PageRequest{})
+
+		// Verify
+		require.Error(t, err)
+		assert.Equal(t, consts.ForbiddenType, err.(utils.GqlError).Type)
+		assert.Nil(t, result)
+	})
+}
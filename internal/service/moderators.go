@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/auth"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/internal/storage"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+	"github.com/nedokyrill/posts-service/pkg/logger"
+	"github.com/nedokyrill/posts-service/pkg/utils"
+)
+
+// moderators - проверка прав модерации по глобальной роли пользователя и модераторам сообществ.
+// Роль читается из хранилища при каждой проверке, поэтому снятие роли действует сразу, а не после смены токена
+type moderators struct {
+	userStore      storage.UserStorage
+	communityStore storage.CommunityStorage
+}
+
+// роль текущего пользователя, у анонимного запроса - USER
+func (m moderators) role(ctx context.Context) (models.Role, error) {
+	identity, ok := auth.IdentityFrom(ctx)
+	if !ok {
+		return models.RoleUser, nil
+	}
+
+	user, err := m.userStore.GetUserByID(ctx, identity.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.RoleUser, nil
+		}
+		return "", err
+	}
+	return user.Role, nil
+}
+
+// может ли текущий пользователь модерировать контент сообщества communityID. Контент вне сообществ
+// (communityID == nil) модерируют только глобальные модераторы и администраторы
+func (m moderators) canModerate(ctx context.Context, communityID *uuid.UUID) (bool, error) {
+	identity, ok := auth.IdentityFrom(ctx)
+	if !ok {
+		return false, nil
+	}
+
+	role, err := m.role(ctx)
+	if err != nil || role.CanModerate() {
+		return role.CanModerate(), err
+	}
+
+	if communityID == nil {
+		return false, nil
+	}
+	return m.communityStore.IsModerator(ctx, *communityID, identity.Username)
+}
+
+// проверяем, что текущий пользователь модерирует сообщество communityID (nil - контент вне сообществ),
+// errMsg - сообщение внутренней ошибки
+func (m moderators) require(ctx context.Context, communityID *uuid.UUID, errMsg string) error {
+	canModerate, err := m.canModerate(ctx, communityID)
+	if err != nil {
+		logger.Logger.Error(fmt.Sprintf("error checking moderator: %v", err))
+		return utils.GqlError{
+			Msg:  errMsg,
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	if !canModerate {
+		return utils.GqlError{
+			Msg:  "only moderators can do this",
+			Type: consts.ForbiddenType,
+		}
+	}
+	return nil
+}
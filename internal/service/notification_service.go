@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/internal/storage"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+	"github.com/nedokyrill/posts-service/pkg/logger"
+	"github.com/nedokyrill/posts-service/pkg/utils"
+)
+
+type NotificationServiceImpl struct {
+	store     storage.NotificationStorage
+	commStore storage.CommentStorage
+	postStore storage.PostStorage
+	inbox     InboxService
+}
+
+func NewNotificationService(store storage.NotificationStorage, commStore storage.CommentStorage,
+	postStore storage.PostStorage, inbox InboxService) *NotificationServiceImpl {
+	return &NotificationServiceImpl{
+		store:     store,
+		commStore: commStore,
+		postStore: postStore,
+		inbox:     inbox,
+	}
+}
+
+// уведомляем автора комментария, на который ответили, или автора поста о новом комментарии к нему.
+// Свои ответы и ответы на удаленные комментарии уведомлений не создают
+func (s *NotificationServiceImpl) NotifyComment(ctx context.Context, comment *models.Comment) error {
+	notification := models.Notification{
+		Type:      models.NotificationTypeComment,
+		Actor:     comment.Author,
+		PostID:    comment.PostID,
+		CommentID: comment.ID,
+	}
+
+	if comment.ParentCommentID != nil {
+		parent, err := s.commStore.GetCommentByID(ctx, *comment.ParentCommentID)
+		if err != nil {
+			logger.Logger.Error(fmt.Sprintf("error getting parent comment for notification: %v", err))
+			return utils.GqlError{
+				Msg:  "error creating notification",
+				Type: consts.InternalServerErrorType,
+			}
+		}
+		notification.Type, notification.Recipient = models.NotificationTypeReply, parent.Author
+	} else {
+		post, err := s.postStore.GetPostByID(ctx, comment.PostID)
+		if err != nil {
+			logger.Logger.Error(fmt.Sprintf("error getting post for notification: %v", err))
+			return utils.GqlError{
+				Msg:  "error creating notification",
+				Type: consts.InternalServerErrorType,
+			}
+		}
+		notification.Recipient = post.Author
+	}
+
+	if notification.Recipient == "" || notification.Recipient == comment.Author {
+		return nil
+	}
+
+	created, err := s.store.CreateNotification(ctx, notification)
+	if err != nil {
+		logger.Logger.Error(fmt.Sprintf("error creating notification: %v", err))
+		return utils.GqlError{
+			Msg:  "error creating notification",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	logger.Logger.Info(fmt.Sprintf("create notification with id: %s successfully", created.ID.String()))
+	return s.inbox.Deliver(ctx, &created)
+}
+
+// уведомления текущего пользователя от новых к старым
+func (s *NotificationServiceImpl) GetMyNotifications(ctx context.Context,
+	pageReq models.PageRequest) (*models.NotificationConnection, error) {
+	identity, err := requireIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := parsePageRequest(pageReq)
+	if err != nil {
+		return nil, err
+	}
+
+	notifications, err := s.store.GetNotifications(ctx, identity.Username, withLookahead(page))
+	if err != nil {
+		logger.Logger.Error(fmt.Sprintf("error getting notifications: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error getting notifications",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	unread, err := s.store.CountUnreadNotifications(ctx, identity.Username)
+	if err != nil {
+		logger.Logger.Error(fmt.Sprintf("error counting notifications: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error getting notifications",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	notifications, pageInfo := cutPage(notifications, page, models.NotificationCursor)
+
+	edges := make([]*models.NotificationEdge, 0, len(notifications))
+	for _, notification := range notifications {
+		edges = append(edges, &models.NotificationEdge{
+			Cursor: models.NotificationCursor(notification).Encode(),
+			Node:   notification,
+		})
+	}
+
+	logger.Logger.Info(fmt.Sprintf("get notifications of user %s successfully", identity.Username))
+	return &models.NotificationConnection{Edges: edges, PageInfo: pageInfo, UnreadCount: int32(unread)}, nil
+}
+
+// отмечаем прочитанными уведомления текущего пользователя, чужие и уже прочитанные id пропускаются
+func (s *NotificationServiceImpl) MarkNotificationsRead(ctx context.Context, ids []uuid.UUID) (int32, error) {
+	identity, err := requireIdentity(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(ids) > consts.MaxPageSize {
+		return 0, utils.GqlError{
+			Msg:  fmt.Sprintf("can not mark more than %d notifications at once", consts.MaxPageSize),
+			Type: consts.BadRequestType,
+		}
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	marked, err := s.store.MarkNotificationsRead(ctx, identity.Username, ids)
+	if err != nil {
+		logger.Logger.Error(fmt.Sprintf("error marking notifications: %v", err))
+		return 0, utils.GqlError{
+			Msg:  "error marking notifications",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	logger.Logger.Info(fmt.Sprintf("mark %d notifications of user %s read successfully", marked, identity.Username))
+	return int32(marked), nil
+}
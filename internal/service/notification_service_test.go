@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/models"
+	store_mock "github.com/nedokyrill/posts-service/internal/storage/mocks"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+	"github.com/nedokyrill/posts-service/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationService_NotifyComment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	notificationStorage := store_mock.NewMockNotificationStorage(ctrl)
+	commentStorage := store_mock.NewMockCommentStorage(ctrl)
+	postStorage := store_mock.NewMockPostStorage(ctrl)
+	inbox := NewInboxService(consts.MaxSubscriptionsPerUser, consts.ViewerBufferSize)
+
+	notificationService := NewNotificationService(notificationStorage, commentStorage, postStorage, inbox)
+
+	postID := uuid.New()
+	parentID := uuid.New()
+	reply := &models.Comment{ID: uuid.New(), Author: "replier", PostID: postID, ParentCommentID: &parentID}
+	root := &models.Comment{ID: uuid.New(), Author: "commenter", PostID: postID}
+
+	// Setup
+	_, received, err := inbox.Subscribe(withUser("parent_author"))
+	require.NoError(t, err)
+
+	t.Run("successfully notify author of parent comment", func(t *testing.T) {
+		// Mock expectations
+		commentStorage.EXPECT().
+			GetCommentByID(ctx, parentID).
+			Return(&models.Comment{ID: parentID, Author: "parent_author", PostID: postID}, nil)
+
+		notificationStorage.EXPECT().
+			CreateNotification(ctx, models.Notification{
+				Recipient: "parent_author",
+				Type:      models.NotificationTypeReply,
+				Actor:     "replier",
+				PostID:    postID,
+				CommentID: reply.ID,
+			}).
+			DoAndReturn(func(_ context.Context, n models.Notification) (models.Notification, error) {
+				n.ID = uuid.New()
+				return n, nil
+			})
+
+		// Execute
+		err := notificationService.NotifyComment(ctx, reply)
+
+		// Verify
+		require.NoError(t, err)
+		require.Len(t, received, 1)
+		notification := <-received
+		assert.Equal(t, models.NotificationTypeReply, notification.Type)
+		assert.Equal(t, reply.ID, notification.CommentID)
+	})
+
+	t.Run("successfully notify author of post", func(t *testing.T) {
+		// Mock expectations
+		postStorage.EXPECT().
+			GetPostByID(ctx, postID).
+			Return(&models.Post{ID: postID, Author: "post_author"}, nil)
+
+		notificationStorage.EXPECT().
+			CreateNotification(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, n models.Notification) (models.Notification, error) {
+				assert.Equal(t, "post_author", n.Recipient)
+				assert.Equal(t, models.NotificationTypeComment, n.Type)
+				return n, nil
+			})
+
+		// Execute
+		err := notificationService.NotifyComment(ctx, root)
+
+		// Verify
+		require.NoError(t, err)
+		assert.Empty(t, received) // уведомление другому пользователю
+	})
+
+	t.Run("skip own comments and deleted parents", func(t *testing.T) {
+		// Mock expectations
+		postStorage.EXPECT().
+			GetPostByID(ctx, postID).
+			Return(&models.Post{ID: postID, Author: "commenter"}, nil)
+		commentStorage.EXPECT().
+			GetCommentByID(ctx, parentID).
+			Return(&models.Comment{ID: parentID, IsDeleted: true, PostID: postID}, nil)
+
+		// Execute
+		errOwn := notificationService.NotifyComment(ctx, root)
+		errDeleted := notificationService.NotifyComment(ctx, reply)
+
+		// Verify
+		assert.NoError(t, errOwn)
+		assert.NoError(t, errDeleted)
+	})
+
+	t.Run("fail when storage fails", func(t *testing.T) {
+		// Mock expectations
+		postStorage.EXPECT().
+			GetPostByID(ctx, postID).
+			Return(&models.Post{ID: postID, Author: "post_author"}, nil)
+		notificationStorage.EXPECT().
+			CreateNotification(ctx, gomock.Any()).
+			Return(models.Notification{}, assert.AnError)
+
+		// Execute
+		err := notificationService.NotifyComment(ctx, root)
+
+		// Verify
+		var gqlErr utils.GqlError
+		require.ErrorAs(t, err, &gqlErr)
+		assert.Equal(t, consts.InternalServerErrorType, gqlErr.Type)
+	})
+}
+
+func TestNotificationService_GetMyNotifications(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := withUser("test_user")
+	notificationStorage := store_mock.NewMockNotificationStorage(ctrl)
+
+	notificationService := NewNotificationService(notificationStorage, store_mock.NewMockCommentStorage(ctrl),
+		store_mock.NewMockPostStorage(ctrl), NewInboxService(consts.MaxSubscriptionsPerUser, consts.ViewerBufferSize))
+
+	now := time.Now()
+	notifications := []*models.Notification{
+		{ID: uuid.New(), Recipient: "test_user", CreatedAt: &now},
+		{ID: uuid.New(), Recipient: "test_user", CreatedAt: &now},
+	}
+
+	t.Run("successfully get first page", func(t *testing.T) {
+		first := int32(1)
+
+		// Mock expectations
+		notificationStorage.EXPECT().
+			GetNotifications(ctx, "test_user", models.Page{Limit: 2}).
+			Return(notifications, nil)
+		notificationStorage.EXPECT().
+			CountUnreadNotifications(ctx, "test_user").
+			Return(2, nil)
+
+		// Execute
+		result, err := notificationService.GetMyNotifications(ctx, models.PageRequest{First: &first})
+
+		// Verify
+		require.NoError(t, err)
+		require.Len(t, result.Edges, 1)
+		assert.Equal(t, notifications[0], result.Edges[0].Node)
+		assert.Equal(t, models.NotificationCursor(notifications[0]).Encode(), *result.PageInfo.EndCursor)
+		assert.True(t, result.PageInfo.HasNextPage)
+		assert.Equal(t, int32(2), result.UnreadCount)
+	})
+
+	t.Run("fail when user is not authenticated", func(t *testing.T) {
+		// Execute
+		result, err := notificationService.GetMyNotifications(context.Background(), models.PageRequest{})
+
+		// Verify
+		assert.Nil(t, result)
+
+		var gqlErr utils.GqlError
+		require.ErrorAs(t, err, &gqlErr)
+		assert.Equal(t, consts.UnauthorizedType, gqlErr.Type)
+	})
+}
+
+func TestNotificationService_MarkNotificationsRead(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := withUser("test_user")
+	notificationStorage := store_mock.NewMockNotificationStorage(ctrl)
+
+	notificationService := NewNotificationService(notificationStorage, store_mock.NewMockCommentStorage(ctrl),
+		store_mock.NewMockPostStorage(ctrl), NewInboxService(consts.MaxSubscriptionsPerUser, consts.ViewerBufferSize))
+
+	t.Run("successfully mark notifications", func(t *testing.T) {
+		ids := []uuid.UUID{uuid.New(), uuid.New()}
+
+		// Mock expectations
+		notificationStorage.EXPECT().
+			MarkNotificationsRead(ctx, "test_user", ids).
+			Return(1, nil)
+
+		// Execute
+		marked, err := notificationService.MarkNotificationsRead(ctx, ids)
+
+		// Verify
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), marked)
+	})
+
+	t.Run("fail with too many ids", func(t *testing.T) {
+		// Execute
+		_, err := notificationService.MarkNotificationsRead(ctx, make([]uuid.UUID, consts.MaxPageSize+1))
+
+		// Verify
+		var gqlErr utils.GqlError
+		require.ErrorAs(t, err, &gqlErr)
+		assert.Equal(t, consts.BadRequestType, gqlErr.Type)
+	})
+}
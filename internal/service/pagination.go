@@ -0,0 +1,93 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+	"github.com/nedokyrill/posts-service/pkg/utils"
+)
+
+// разбираем аргументы first/after/last/before в параметры выборки страницы для хранилища
+func parsePageRequest(req models.PageRequest) (models.Page, error) {
+	if req.First != nil && req.Last != nil {
+		return models.Page{}, utils.GqlError{
+			Msg:  "first and last can not be used together",
+			Type: consts.BadRequestType,
+		}
+	}
+
+	page := models.Page{Limit: consts.PageSize}
+
+	size := req.First
+	if req.Last != nil {
+		size = req.Last
+		page.FromEnd = true
+	}
+	if size != nil {
+		if *size < 0 || *size > consts.MaxPageSize {
+			return models.Page{}, utils.GqlError{
+				Msg:  fmt.Sprintf("page size must be between 0 and %d", consts.MaxPageSize),
+				Type: consts.BadRequestType,
+			}
+		}
+		page.Limit = int(*size)
+	}
+
+	var err error
+	if page.After, err = parseCursor(req.After); err != nil {
+		return models.Page{}, err
+	}
+	if page.Before, err = parseCursor(req.Before); err != nil {
+		return models.Page{}, err
+	}
+
+	return page, nil
+}
+
+func parseCursor(cursor *string) (*models.Cursor, error) {
+	if cursor == nil {
+		return nil, nil
+	}
+
+	c, err := models.DecodeCursor(*cursor)
+	if err != nil {
+		return nil, utils.GqlError{
+			Msg:  fmt.Sprintf("invalid cursor: %s", *cursor),
+			Type: consts.BadRequestType,
+		}
+	}
+	return &c, nil
+}
+
+// хранилище запрашивается с лимитом на один элемент больше страницы, лишний элемент обрезаем
+// и по нему определяем, есть ли следующая (или предыдущая при last/before) страница
+func cutPage[T any](items []T, page models.Page, cursorOf func(T) models.Cursor) ([]T, *models.PageInfo) {
+	info := &models.PageInfo{
+		HasNextPage:     page.Before != nil,
+		HasPreviousPage: page.After != nil,
+	}
+
+	if len(items) > page.Limit {
+		if page.FromEnd {
+			items = items[len(items)-page.Limit:]
+			info.HasPreviousPage = true
+		} else {
+			items = items[:page.Limit]
+			info.HasNextPage = true
+		}
+	}
+
+	if len(items) > 0 {
+		start, end := cursorOf(items[0]).Encode(), cursorOf(items[len(items)-1]).Encode()
+		info.StartCursor, info.EndCursor = &start, &end
+	}
+
+	return items, info
+}
+
+// страница с одним лишним элементом для определения наличия следующей страницы
+func withLookahead(page models.Page) models.Page {
+	page.Limit++
+	return page
+}
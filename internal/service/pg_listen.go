@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+	"github.com/nedokyrill/posts-service/pkg/logger"
+)
+
+// listenChannel слушает канал postgres до отмены ctx и передает payload каждого уведомления в handle.
+// При обрыве соединения переподключается с экспоненциальной задержкой и заново подписывается на канал,
+// уведомления, опубликованные во время переподключения, теряются
+func listenChannel(ctx context.Context, pool *pgxpool.Pool, channel string,
+	handle func(ctx context.Context, payload string)) {
+	backoff := consts.ListenMinBackoff
+	for {
+		err := listen(ctx, pool, channel, handle, func() { backoff = consts.ListenMinBackoff })
+		if ctx.Err() != nil {
+			return
+		}
+
+		logger.Logger.Error(fmt.Sprintf("listen connection for channel %s lost: %v, reconnecting in %s",
+			channel, err, backoff))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, consts.ListenMaxBackoff)
+	}
+}
+
+func listen(ctx context.Context, pool *pgxpool.Pool, channel string, handle func(ctx context.Context, payload string),
+	onListen func()) error {
+	pooled, err := pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// забираем соединение из пула, чтобы после LISTEN оно не досталось другим запросам
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err = conn.Exec(ctx, "LISTEN "+channel); err != nil {
+		return err
+	}
+	logger.Logger.Info(fmt.Sprintf("listening channel %s", channel))
+	onListen()
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		handle(ctx, n.Payload)
+	}
+}
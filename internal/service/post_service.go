@@ -5,6 +5,11 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/nedokyrill/posts-service/internal/models"
@@ -15,26 +20,46 @@ import (
 )
 
 type PostServiceImpl struct {
-	store storage.PostStorage
+	store          storage.PostStorage
+	communityStore storage.CommunityStorage
+	moderators     moderators
+	filters        *ContentFilterChain
 }
 
-func NewPostService(store storage.PostStorage) *PostServiceImpl {
+func NewPostService(store storage.PostStorage, communityStore storage.CommunityStorage,
+	userStore storage.UserStorage, filters *ContentFilterChain) *PostServiceImpl {
 	return &PostServiceImpl{
-		store: store,
+		store:          store,
+		communityStore: communityStore,
+		moderators:     moderators{userStore: userStore, communityStore: communityStore},
+		filters:        filters,
 	}
 }
 
-func (s *PostServiceImpl) GetAllPosts(ctx context.Context, page *int32) ([]*models.Post, error) {
-	if page != nil && *page <= 0 {
+func (s *PostServiceImpl) GetAllPosts(ctx context.Context, sort models.PostSort, window models.TimeWindow,
+	filter models.PostFilter, pageReq models.PageRequest) (*models.PostConnection, error) {
+	feed, err := newPostFeed(sort, window, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := parsePageRequest(pageReq)
+	if err != nil {
+		return nil, err
+	}
+
+	// скрытые посты в ленте видят модераторы, в ленте сообщества - и его модераторы
+	feed.IncludeRemoved, err = s.moderators.canModerate(ctx, feed.CommunityID)
+	if err != nil {
+		logger.Logger.Error("error with checking moderator: ", err)
 		return nil, utils.GqlError{
-			Msg:  "page must be greater than zero",
-			Type: consts.BadRequestType,
+			Msg:  "error with getting posts",
+			Type: consts.InternalServerErrorType,
 		}
 	}
+	feed.Viewer = viewerName(ctx)
 
-	offset, limit := utils.GetOffsetNLimit(page, consts.PageSize)
-
-	posts, err := s.store.GetAllPosts(ctx, offset, limit)
+	posts, err := s.store.GetAllPosts(ctx, feed, withLookahead(page))
 	if err != nil {
 		logger.Logger.Error("error with getting posts: ", err)
 		return nil, utils.GqlError{
@@ -43,8 +68,25 @@ func (s *PostServiceImpl) GetAllPosts(ctx context.Context, page *int32) ([]*mode
 		}
 	}
 
+	total, err := s.store.CountPosts(ctx, feed)
+	if err != nil {
+		logger.Logger.Error("error with counting posts: ", err)
+		return nil, utils.GqlError{
+			Msg:  "error with getting posts",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	cursorOf := models.PostSortCursor(sort)
+	posts, pageInfo := cutPage(posts, page, cursorOf)
+
+	edges := make([]*models.PostEdge, 0, len(posts))
+	for _, post := range posts {
+		edges = append(edges, &models.PostEdge{Cursor: cursorOf(post).Encode(), Node: post})
+	}
+
 	logger.Logger.Info("get all posts successfully")
-	return posts, nil
+	return &models.PostConnection{Edges: edges, PageInfo: pageInfo, TotalCount: int32(total)}, nil
 }
 func (s *PostServiceImpl) GetPostByID(ctx context.Context, id uuid.UUID) (*models.Post, error) {
 	post, err := s.store.GetPostByID(ctx, id)
@@ -63,10 +105,41 @@ func (s *PostServiceImpl) GetPostByID(ctx context.Context, id uuid.UUID) (*model
 		}
 	}
 
+	// скрытый фильтром контента пост видит только автор
+	if !post.VisibleTo(viewerName(ctx)) {
+		return nil, utils.GqlError{
+			Msg:  fmt.Sprintf("post with id: %s not found", id.String()),
+			Type: consts.BadRequestType,
+		}
+	}
+
+	// скрытый пост для всех, кроме модераторов, выглядит как несуществующий
+	if post.IsRemoved {
+		canModerate, err := s.moderators.canModerate(ctx, post.CommunityID)
+		if err != nil {
+			logger.Logger.Error("error with checking moderator: ", err)
+			return nil, utils.GqlError{
+				Msg:  fmt.Sprintf("error with getting post with id: %s", id.String()),
+				Type: consts.InternalServerErrorType,
+			}
+		}
+		if !canModerate {
+			return nil, utils.GqlError{
+				Msg:  fmt.Sprintf("post with id: %s not found", id.String()),
+				Type: consts.BadRequestType,
+			}
+		}
+	}
+
 	logger.Logger.Info(fmt.Sprintf("get post with id: %s successfully", post.ID.String()))
 	return post, nil
 }
 func (s *PostServiceImpl) CreatePost(ctx context.Context, postReq models.PostRequest) (*models.Post, error) {
+	identity, err := requireIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	if len(postReq.Title) == 0 {
 		return nil, utils.GqlError{
 			Msg:  "post must have a title",
@@ -74,19 +147,42 @@ func (s *PostServiceImpl) CreatePost(ctx context.Context, postReq models.PostReq
 		}
 	}
 
-	if postReq.Author == nil || len(*postReq.Author) == 0 {
-		return nil, utils.GqlError{
-			Msg:  "post must have a author",
-			Type: consts.BadRequestType,
-		}
+	tags, err := normalizeTags(postReq.Tags, consts.MaxTagsPerPost)
+	if err != nil {
+		return nil, err
 	}
 
-	newPost, err := s.store.CreatePost(ctx, models.Post{
+	post := models.Post{
 		Title:             postReq.Title,
-		Author:            *postReq.Author,
+		Author:            identity.Username,
 		Content:           postReq.Content,
-		IsCommentsAllowed: postReq.IsCommentAllowed,
+		IsCommentsAllowed: true,
+		Tags:              tags,
+	}
+
+	if postReq.Community != nil {
+		community, err := s.getPostableCommunity(ctx, *postReq.Community, identity.Username)
+		if err != nil {
+			return nil, err
+		}
+		post.CommunityID = &community.ID
+		post.IsCommentsAllowed = community.CommentPolicy == models.CommentPolicyOpen
+	}
+	if postReq.IsCommentAllowed != nil {
+		post.IsCommentsAllowed = *postReq.IsCommentAllowed
+	}
+
+	post.IsShadowed, err = s.filters.Apply(ctx, Content{
+		Kind:   ContentKindPost,
+		Author: post.Author,
+		Title:  post.Title,
+		Text:   post.Content,
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	newPost, err := s.store.CreatePost(ctx, post)
 
 	if err != nil {
 		logger.Logger.Error(fmt.Sprintf("error with creating post: %v", err))
@@ -99,3 +195,281 @@ func (s *PostServiceImpl) CreatePost(ctx context.Context, postReq models.PostReq
 	logger.Logger.Info(fmt.Sprintf("create post with id: %s successfully", newPost.ID.String()))
 	return &newPost, nil
 }
+func (s *PostServiceImpl) UpdatePost(ctx context.Context, postReq models.PostUpdateRequest) (*models.Post, error) {
+	if len(postReq.Title) == 0 {
+		return nil, utils.GqlError{
+			Msg:  "post must have a title",
+			Type: consts.BadRequestType,
+		}
+	}
+
+	tags, err := normalizeTags(postReq.Tags, consts.MaxTagsPerPost)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := s.getOwnedPost(ctx, postReq.ID)
+	if err != nil {
+		return nil, err
+	}
+	if postReq.Tags == nil {
+		tags = post.Tags
+	}
+
+	shadowed, err := s.filters.Apply(ctx, Content{
+		Kind:   ContentKindPost,
+		ID:     post.ID,
+		Author: post.Author,
+		Title:  postReq.Title,
+		Text:   postReq.Content,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	updatedPost, err := s.store.UpdatePost(ctx, models.Post{
+		ID:                post.ID,
+		Title:             postReq.Title,
+		Author:            post.Author,
+		Content:           postReq.Content,
+		IsCommentsAllowed: post.IsCommentsAllowed,
+		Tags:              tags,
+		IsShadowed:        shadowed,
+	})
+
+	if err != nil {
+		logger.Logger.Error(fmt.Sprintf("error with updating post: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error updating post",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	logger.Logger.Info(fmt.Sprintf("update post with id: %s successfully", updatedPost.ID.String()))
+	return &updatedPost, nil
+}
+func (s *PostServiceImpl) DeletePost(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.getOwnedPost(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.store.DeletePost(ctx, id); err != nil {
+		logger.Logger.Error(fmt.Sprintf("error with deleting post: %v", err))
+		return utils.GqlError{
+			Msg:  "error deleting post",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	logger.Logger.Info(fmt.Sprintf("delete post with id: %s successfully", id.String()))
+	return nil
+}
+func (s *PostServiceImpl) SetCommentsAllowed(ctx context.Context, id uuid.UUID,
+	allowed bool) (*models.Post, error) {
+	if _, err := s.getOwnedPost(ctx, id); err != nil {
+		return nil, err
+	}
+
+	post, err := s.store.SetCommentsAllowed(ctx, id, allowed)
+	if err != nil {
+		logger.Logger.Error(fmt.Sprintf("error with changing comments permission: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error changing comments permission",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	logger.Logger.Info(fmt.Sprintf("set comments allowed to %t for post with id: %s successfully", allowed,
+		id.String()))
+	return &post, nil
+}
+func (s *PostServiceImpl) SearchPosts(ctx context.Context, query string,
+	pageReq models.PageRequest) (*models.PostSearchConnection, error) {
+	query, err := parseSearchQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := parsePageRequest(pageReq)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := s.store.SearchPosts(ctx, query, withLookahead(page))
+	if err != nil {
+		logger.Logger.Error("error with searching posts: ", err)
+		return nil, utils.GqlError{
+			Msg:  "error with searching posts",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	total, err := s.store.CountSearchPosts(ctx, query)
+	if err != nil {
+		logger.Logger.Error("error with counting found posts: ", err)
+		return nil, utils.GqlError{
+			Msg:  "error with searching posts",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	results, pageInfo := cutPage(results, page, models.PostSearchCursor)
+
+	edges := make([]*models.PostSearchEdge, 0, len(results))
+	for _, result := range results {
+		edges = append(edges, &models.PostSearchEdge{Cursor: models.PostSearchCursor(result).Encode(), Node: result})
+	}
+
+	logger.Logger.Info("search posts successfully")
+	return &models.PostSearchConnection{Edges: edges, PageInfo: pageInfo, TotalCount: int32(total)}, nil
+}
+func (s *PostServiceImpl) GetPopularTags(ctx context.Context, limit *int32) ([]*models.Tag, error) {
+	size := consts.PopularTagsSize
+	if limit != nil {
+		if *limit < 1 || *limit > consts.MaxPopularTagsSize {
+			return nil, utils.GqlError{
+				Msg:  fmt.Sprintf("limit must be between 1 and %d", consts.MaxPopularTagsSize),
+				Type: consts.BadRequestType,
+			}
+		}
+		size = int(*limit)
+	}
+
+	tags, err := s.store.GetPopularTags(ctx, size)
+	if err != nil {
+		logger.Logger.Error(fmt.Sprintf("error with getting popular tags: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error getting popular tags",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	logger.Logger.Info("get popular tags successfully")
+	return tags, nil
+}
+
+// получаем сообщество и проверяем, что пользователь может публиковать в нем посты
+func (s *PostServiceImpl) getPostableCommunity(ctx context.Context, slug, username string) (*models.Community, error) {
+	community, err := getCommunity(ctx, s.communityStore, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	if community.PostPolicy == models.PostPolicyMembers {
+		memberships, err := s.communityStore.GetMemberships(ctx, []uuid.UUID{community.ID}, username)
+		if err != nil {
+			logger.Logger.Error(fmt.Sprintf("error getting community memberships: %v", err))
+			return nil, utils.GqlError{
+				Msg:  "error creating post",
+				Type: consts.InternalServerErrorType,
+			}
+		}
+		if !memberships[community.ID] {
+			return nil, utils.GqlError{
+				Msg:  fmt.Sprintf("only members can post in community %s", community.Slug),
+				Type: consts.ForbiddenType,
+			}
+		}
+	}
+	return community, nil
+}
+
+// получаем пост и проверяем, что его изменяет автор
+func (s *PostServiceImpl) getOwnedPost(ctx context.Context, id uuid.UUID) (*models.Post, error) {
+	identity, err := requireIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := s.GetPostByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if post.Author != identity.Username {
+		return nil, utils.GqlError{
+			Msg:  fmt.Sprintf("only author of the post with id: %s can change it", id.String()),
+			Type: consts.ForbiddenType,
+		}
+	}
+
+	return post, nil
+}
+
+// поисковый запрос без пробелов по краям, пустой или слишком длинный запрос - ошибка
+func parseSearchQuery(query string) (string, error) {
+	query = strings.TrimSpace(query)
+	if query == "" || utf8.RuneCountInString(query) > consts.SearchQueryMaxLen {
+		return "", utils.GqlError{
+			Msg:  fmt.Sprintf("search query must be between 1 and %d characters", consts.SearchQueryMaxLen),
+			Type: consts.BadRequestType,
+		}
+	}
+	return query, nil
+}
+
+// приводим теги к виду, в котором они хранятся: нижний регистр без "#" в начале, пробелы заменены на "-",
+// без повторов и по алфавиту. Тег с недопустимыми символами или больше max тегов - ошибка
+func normalizeTags(tags []string, max int) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	res := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.Join(strings.Fields(strings.TrimPrefix(strings.TrimSpace(tag), "#")), "-"))
+
+		valid := tag != "" && utf8.RuneCountInString(tag) <= consts.MaxTagLen
+		for _, r := range tag {
+			valid = valid && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_')
+		}
+		if !valid {
+			return nil, utils.GqlError{
+				Msg: fmt.Sprintf("invalid tag %q: tag must be 1 to %d letters, digits, \"-\" or \"_\"",
+					tag, consts.MaxTagLen),
+				Type: consts.BadRequestType,
+			}
+		}
+		res = append(res, tag)
+	}
+
+	slices.Sort(res)
+	res = slices.Compact(res)
+	if len(res) > max {
+		return nil, utils.GqlError{
+			Msg:  fmt.Sprintf("no more than %d tags allowed", max),
+			Type: consts.BadRequestType,
+		}
+	}
+	return res, nil
+}
+
+// окно времени ленты отсчитывается от текущего момента
+func newPostFeed(sort models.PostSort, window models.TimeWindow, filter models.PostFilter) (models.PostFeed, error) {
+	if !sort.IsValid() || !window.IsValid() || (len(filter.Tags) > 0 && !filter.Match.IsValid()) {
+		return models.PostFeed{}, utils.GqlError{
+			Msg: fmt.Sprintf("invalid feed parameters: sort %s, time window %s, tag match %s", sort, window,
+				filter.Match),
+			Type: consts.BadRequestType,
+		}
+	}
+
+	tags, err := normalizeTags(filter.Tags, consts.MaxFilterTags)
+	if err != nil {
+		return models.PostFeed{}, err
+	}
+
+	feed := models.PostFeed{Sort: sort, Tags: tags, Match: filter.Match, CommunityID: filter.CommunityID}
+	if duration, ok := timeWindows[window]; ok {
+		// created_at хранится без часового пояса в UTC, поэтому и граница окна в UTC
+		since := time.Now().UTC().Add(-duration)
+		feed.Since = &since
+	}
+	return feed, nil
+}
+
+var timeWindows = map[models.TimeWindow]time.Duration{
+	models.TimeWindowDay:   24 * time.Hour,
+	models.TimeWindowWeek:  7 * 24 * time.Hour,
+	models.TimeWindowMonth: 30 * 24 * time.Hour,
+}
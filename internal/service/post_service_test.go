@@ -3,10 +3,14 @@ package service
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/auth"
 	"github.com/nedokyrill/posts-service/internal/models"
 	store_mock "github.com/nedokyrill/posts-service/internal/storage/mocks"
 	"github.com/nedokyrill/posts-service/pkg/consts"
@@ -15,26 +19,30 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// контекст запроса аутентифицированного пользователя
+func withUser(username string) context.Context {
+	return auth.WithIdentity(context.Background(), auth.Identity{UserID: uuid.New(), Username: username})
+}
+
 func TestPostService_CreatePost(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	ctx := context.Background()
-	postStorage := store_mock.NewMockPostStorage(ctrl)
-
-	postService := NewPostService(postStorage)
-
 	title := "Test Post Title"
 	author := "test_author"
 	content := "test content"
 
+	ctx := withUser(author)
+	postStorage := store_mock.NewMockPostStorage(ctrl)
+
+	postService := NewPostService(postStorage, store_mock.NewMockCommunityStorage(ctrl),
+		store_mock.NewMockUserStorage(ctrl), nil)
+
 	t.Run("successfully create post", func(t *testing.T) {
 		// Setup
 		postReq := models.PostRequest{
-			Title:            title,
-			Author:           &author,
-			Content:          content,
-			IsCommentAllowed: true,
+			Title:   title,
+			Content: content,
 		}
 
 		expectedPost := models.Post{
@@ -67,10 +75,8 @@ func TestPostService_CreatePost(t *testing.T) {
 	t.Run("fail when title is empty", func(t *testing.T) {
 		// Setup
 		postReq := models.PostRequest{
-			Title:            "",
-			Author:           &author,
-			Content:          content,
-			IsCommentAllowed: true,
+			Title:   "",
+			Content: content,
 		}
 
 		// Execute
@@ -85,56 +91,30 @@ func TestPostService_CreatePost(t *testing.T) {
 		assert.Nil(t, result)
 	})
 
-	t.Run("fail when author is empty", func(t *testing.T) {
+	t.Run("fail when user is not authenticated", func(t *testing.T) {
 		// Setup
-		emptyAuthor := ""
 		postReq := models.PostRequest{
-			Title:            title,
-			Author:           &emptyAuthor,
-			Content:          content,
-			IsCommentAllowed: true,
+			Title:   title,
+			Content: content,
 		}
 
 		// Execute
-		result, err := postService.CreatePost(ctx, postReq)
+		result, err := postService.CreatePost(context.Background(), postReq)
 
 		// Verify
 		assert.Error(t, err)
 		assert.IsType(t, utils.GqlError{}, err)
 		gqlErr := err.(utils.GqlError)
-		assert.Equal(t, "post must have a author", gqlErr.Msg)
-		assert.Equal(t, consts.BadRequestType, gqlErr.Type)
-		assert.Nil(t, result)
-	})
-
-	t.Run("fail when author is nil", func(t *testing.T) {
-		// Setup
-		postReq := models.PostRequest{
-			Title:            title,
-			Author:           nil,
-			Content:          content,
-			IsCommentAllowed: true,
-		}
-
-		// Execute
-		result, err := postService.CreatePost(ctx, postReq)
-
-		// Verify
-		assert.Error(t, err)
-		assert.IsType(t, utils.GqlError{}, err)
-		gqlErr := err.(utils.GqlError)
-		assert.Equal(t, "post must have a author", gqlErr.Msg)
-		assert.Equal(t, consts.BadRequestType, gqlErr.Type)
+		assert.Equal(t, "authentication required", gqlErr.Msg)
+		assert.Equal(t, consts.UnauthorizedType, gqlErr.Type)
 		assert.Nil(t, result)
 	})
 
 	t.Run("fail when storage returns error", func(t *testing.T) {
 		// Setup
 		postReq := models.PostRequest{
-			Title:            title,
-			Author:           &author,
-			Content:          content,
-			IsCommentAllowed: true,
+			Title:   title,
+			Content: content,
 		}
 
 		// Mock expectations
@@ -162,7 +142,8 @@ func TestPostService_GetPostByID(t *testing.T) {
 	ctx := context.Background()
 	postStorage := store_mock.NewMockPostStorage(ctrl)
 
-	postService := NewPostService(postStorage)
+	postService := NewPostService(postStorage, store_mock.NewMockCommunityStorage(ctrl),
+		store_mock.NewMockUserStorage(ctrl), nil)
 
 	postID := uuid.New()
 	title := "Test Post Title"
@@ -237,103 +218,157 @@ func TestPostService_GetAllPosts(t *testing.T) {
 	ctx := context.Background()
 	postStorage := store_mock.NewMockPostStorage(ctrl)
 
-	postService := NewPostService(postStorage)
+	postService := NewPostService(postStorage, store_mock.NewMockCommunityStorage(ctrl),
+		store_mock.NewMockUserStorage(ctrl), nil)
+	newFeed := models.PostFeed{Sort: models.PostSortNew}
 
 	title := "Test Post Title"
 	author := "test_author"
 	content := "test content"
 
+	newPost := func(i int) *models.Post {
+		createdAt := time.Now().Add(-time.Duration(i) * time.Minute)
+		return &models.Post{
+			ID:                uuid.New(),
+			Title:             fmt.Sprintf("%s %d", title, i),
+			Author:            author,
+			Content:           content,
+			IsCommentsAllowed: true,
+			CreatedAt:         &createdAt,
+		}
+	}
+
 	t.Run("successfully get all posts without pagination", func(t *testing.T) {
 		// Setup
-		expectedPosts := []*models.Post{
-			{
-				ID:                uuid.New(),
-				Title:             title + " 1",
-				Author:            author,
-				Content:           content,
-				IsCommentsAllowed: true,
-			},
-			{
-				ID:                uuid.New(),
-				Title:             title + " 2",
-				Author:            author,
-				Content:           content,
-				IsCommentsAllowed: false,
-			},
-		}
+		expectedPosts := []*models.Post{newPost(1), newPost(2)}
 
-		// Mock expectations - исправляем на реальные значения из вашего сервиса
-		// По логам видно, что используется limit=20, а не 50
+		// Mock expectations - по умолчанию запрашиваем страницу PageSize и один лишний пост
 		postStorage.EXPECT().
-			GetAllPosts(ctx, 0, 20).
+			GetAllPosts(ctx, newFeed, models.Page{Limit: consts.PageSize + 1}).
 			Return(expectedPosts, nil)
+		postStorage.EXPECT().
+			CountPosts(ctx, newFeed).
+			Return(2, nil)
 
 		// Execute
-		result, err := postService.GetAllPosts(ctx, nil)
+		result, err := postService.GetAllPosts(ctx, models.PostSortNew, models.TimeWindowAll, models.PostFilter{}, models.PageRequest{})
 
 		// Verify
 		require.NoError(t, err)
-		assert.Equal(t, expectedPosts, result)
+		require.Len(t, result.Edges, 2)
+		assert.Equal(t, expectedPosts[0], result.Edges[0].Node)
+		assert.Equal(t, expectedPosts[1], result.Edges[1].Node)
+		assert.Equal(t, int32(2), result.TotalCount)
+		assert.False(t, result.PageInfo.HasNextPage)
+		assert.False(t, result.PageInfo.HasPreviousPage)
+		assert.Equal(t, result.Edges[0].Cursor, *result.PageInfo.StartCursor)
+		assert.Equal(t, result.Edges[1].Cursor, *result.PageInfo.EndCursor)
 	})
 
-	t.Run("successfully get all posts with pagination", func(t *testing.T) {
+	t.Run("successfully get next page with cursor", func(t *testing.T) {
 		// Setup
-		page := int32(2)
-		// Для page=2: offset = (2-1) * 20 = 20, limit = 20
-		expectedOffset := 20
-		expectedLimit := 20
-
-		expectedPosts := []*models.Post{
-			{
-				ID:                uuid.New(),
-				Title:             title,
-				Author:            author,
-				Content:           content,
-				IsCommentsAllowed: true,
-			},
-		}
+		first := int32(2)
+		afterPost := newPost(0)
+		after := models.PostCursor(afterPost).Encode()
+		expectedPosts := []*models.Post{newPost(1), newPost(2), newPost(3)}
 
 		// Mock expectations
 		postStorage.EXPECT().
-			GetAllPosts(ctx, expectedOffset, expectedLimit).
+			GetAllPosts(ctx, newFeed, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, feed models.PostFeed, page models.Page) ([]*models.Post, error) {
+				assert.Equal(t, 3, page.Limit)
+				require.NotNil(t, page.After)
+				assert.Equal(t, afterPost.ID, page.After.ID)
+				assert.True(t, afterPost.CreatedAt.Equal(page.After.CreatedAt))
+				assert.False(t, page.FromEnd)
+				return expectedPosts, nil
+			})
+		postStorage.EXPECT().
+			CountPosts(ctx, newFeed).
+			Return(4, nil)
+
+		// Execute
+		result, err := postService.GetAllPosts(ctx, models.PostSortNew, models.TimeWindowAll, models.PostFilter{}, models.PageRequest{First: &first, After: &after})
+
+		// Verify
+		require.NoError(t, err)
+		require.Len(t, result.Edges, 2)
+		assert.Equal(t, expectedPosts[0], result.Edges[0].Node)
+		assert.Equal(t, expectedPosts[1], result.Edges[1].Node)
+		assert.True(t, result.PageInfo.HasNextPage)
+		assert.True(t, result.PageInfo.HasPreviousPage)
+		assert.Equal(t, models.PostCursor(expectedPosts[1]).Encode(), *result.PageInfo.EndCursor)
+	})
+
+	t.Run("successfully get last page", func(t *testing.T) {
+		// Setup
+		last := int32(2)
+		expectedPosts := []*models.Post{newPost(1), newPost(2), newPost(3)}
+
+		// Mock expectations
+		postStorage.EXPECT().
+			GetAllPosts(ctx, newFeed, models.Page{Limit: 3, FromEnd: true}).
 			Return(expectedPosts, nil)
+		postStorage.EXPECT().
+			CountPosts(ctx, newFeed).
+			Return(3, nil)
 
 		// Execute
-		result, err := postService.GetAllPosts(ctx, &page)
+		result, err := postService.GetAllPosts(ctx, models.PostSortNew, models.TimeWindowAll, models.PostFilter{}, models.PageRequest{Last: &last})
 
 		// Verify
 		require.NoError(t, err)
-		assert.Equal(t, expectedPosts, result)
+		require.Len(t, result.Edges, 2)
+		assert.Equal(t, expectedPosts[1], result.Edges[0].Node)
+		assert.Equal(t, expectedPosts[2], result.Edges[1].Node)
+		assert.False(t, result.PageInfo.HasNextPage)
+		assert.True(t, result.PageInfo.HasPreviousPage)
 	})
 
-	t.Run("fail when page is zero", func(t *testing.T) {
+	t.Run("fail when first and last are used together", func(t *testing.T) {
 		// Setup
-		page := int32(0)
+		first, last := int32(1), int32(1)
 
 		// Execute
-		result, err := postService.GetAllPosts(ctx, &page)
+		result, err := postService.GetAllPosts(ctx, models.PostSortNew, models.TimeWindowAll, models.PostFilter{}, models.PageRequest{First: &first, Last: &last})
 
 		// Verify
 		assert.Error(t, err)
 		assert.IsType(t, utils.GqlError{}, err)
 		gqlErr := err.(utils.GqlError)
-		assert.Equal(t, "page must be greater than zero", gqlErr.Msg)
+		assert.Equal(t, "first and last can not be used together", gqlErr.Msg)
 		assert.Equal(t, consts.BadRequestType, gqlErr.Type)
 		assert.Nil(t, result)
 	})
 
-	t.Run("fail when page is negative", func(t *testing.T) {
+	t.Run("fail when page size is negative", func(t *testing.T) {
 		// Setup
-		page := int32(-1)
+		first := int32(-1)
 
 		// Execute
-		result, err := postService.GetAllPosts(ctx, &page)
+		result, err := postService.GetAllPosts(ctx, models.PostSortNew, models.TimeWindowAll, models.PostFilter{}, models.PageRequest{First: &first})
 
 		// Verify
 		assert.Error(t, err)
 		assert.IsType(t, utils.GqlError{}, err)
 		gqlErr := err.(utils.GqlError)
-		assert.Equal(t, "page must be greater than zero", gqlErr.Msg)
+		assert.Contains(t, gqlErr.Msg, "page size must be between")
+		assert.Equal(t, consts.BadRequestType, gqlErr.Type)
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail when cursor is invalid", func(t *testing.T) {
+		// Setup
+		after := "not a cursor"
+
+		// Execute
+		result, err := postService.GetAllPosts(ctx, models.PostSortNew, models.TimeWindowAll, models.PostFilter{}, models.PageRequest{After: &after})
+
+		// Verify
+		assert.Error(t, err)
+		assert.IsType(t, utils.GqlError{}, err)
+		gqlErr := err.(utils.GqlError)
+		assert.Contains(t, gqlErr.Msg, "invalid cursor")
 		assert.Equal(t, consts.BadRequestType, gqlErr.Type)
 		assert.Nil(t, result)
 	})
@@ -341,11 +376,11 @@ func TestPostService_GetAllPosts(t *testing.T) {
 	t.Run("fail when storage returns error", func(t *testing.T) {
 		// Mock expectations
 		postStorage.EXPECT().
-			GetAllPosts(ctx, 0, 20).
+			GetAllPosts(ctx, newFeed, models.Page{Limit: consts.PageSize + 1}).
 			Return(nil, assert.AnError)
 
 		// Execute
-		result, err := postService.GetAllPosts(ctx, nil)
+		result, err := postService.GetAllPosts(ctx, models.PostSortNew, models.TimeWindowAll, models.PostFilter{}, models.PageRequest{})
 
 		// Verify
 		assert.Error(t, err)
@@ -359,15 +394,64 @@ func TestPostService_GetAllPosts(t *testing.T) {
 	t.Run("return empty when no posts found", func(t *testing.T) {
 		// Mock expectations
 		postStorage.EXPECT().
-			GetAllPosts(ctx, 0, 20).
+			GetAllPosts(ctx, newFeed, models.Page{Limit: consts.PageSize + 1}).
 			Return([]*models.Post{}, nil)
+		postStorage.EXPECT().
+			CountPosts(ctx, newFeed).
+			Return(0, nil)
 
 		// Execute
-		result, err := postService.GetAllPosts(ctx, nil)
+		result, err := postService.GetAllPosts(ctx, models.PostSortNew, models.TimeWindowAll, models.PostFilter{}, models.PageRequest{})
 
 		// Verify
 		require.NoError(t, err)
-		assert.Empty(t, result)
+		assert.Empty(t, result.Edges)
+		assert.Nil(t, result.PageInfo.StartCursor)
+		assert.Nil(t, result.PageInfo.EndCursor)
+	})
+
+	t.Run("successfully get top posts for a day", func(t *testing.T) {
+		// Setup
+		post := newPost(0)
+		post.Upvotes = 3
+		expectedCursor := models.PostSortCursor(models.PostSortTop)(post).Encode()
+		var gotFeed models.PostFeed
+
+		// Mock expectations
+		postStorage.EXPECT().
+			GetAllPosts(ctx, gomock.Any(), models.Page{Limit: consts.PageSize + 1}).
+			DoAndReturn(func(ctx context.Context, feed models.PostFeed, page models.Page) ([]*models.Post, error) {
+				gotFeed = feed
+				return []*models.Post{post}, nil
+			})
+		postStorage.EXPECT().
+			CountPosts(ctx, gomock.Any()).
+			Return(1, nil)
+
+		// Execute
+		result, err := postService.GetAllPosts(ctx, models.PostSortTop, models.TimeWindowDay, models.PostFilter{}, models.PageRequest{})
+
+		// Verify
+		require.NoError(t, err)
+		assert.Equal(t, models.PostSortTop, gotFeed.Sort)
+		require.NotNil(t, gotFeed.Since)
+		assert.WithinDuration(t, time.Now().Add(-24*time.Hour), *gotFeed.Since, time.Minute)
+		assert.Equal(t, time.UTC, gotFeed.Since.Location())
+		require.Len(t, result.Edges, 1)
+		assert.Equal(t, expectedCursor, result.Edges[0].Cursor)
+	})
+
+	t.Run("fail when sort is invalid", func(t *testing.T) {
+		// Execute
+		result, err := postService.GetAllPosts(ctx, models.PostSort("OLD"), models.TimeWindowAll, models.PostFilter{}, models.PageRequest{})
+
+		// Verify
+		assert.Error(t, err)
+		assert.IsType(t, utils.GqlError{}, err)
+		gqlErr := err.(utils.GqlError)
+		assert.Contains(t, gqlErr.Msg, "invalid feed parameters")
+		assert.Equal(t, consts.BadRequestType, gqlErr.Type)
+		assert.Nil(t, result)
 	})
 }
 
@@ -375,29 +459,38 @@ func TestPostService_EdgeCases(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	ctx := context.Background()
+	ctx := withUser("test_author")
 	postStorage := store_mock.NewMockPostStorage(ctrl)
+	userStorage := store_mock.NewMockUserStorage(ctrl)
+	userStorage.EXPECT().GetUserByID(ctx, gomock.Any()).Return(&models.User{Role: models.RoleUser}, nil).AnyTimes()
 
-	postService := NewPostService(postStorage)
+	postService := NewPostService(postStorage, store_mock.NewMockCommunityStorage(ctrl), userStorage, nil)
+	newFeed := models.PostFeed{Sort: models.PostSortNew, Viewer: "test_author"}
 
-	t.Run("handle large page numbers correctly", func(t *testing.T) {
+	t.Run("handle maximum page size correctly", func(t *testing.T) {
 		// Setup
-		page := int32(1000000)
-		// Для page=1000000: offset = (1000000-1) * 20 = 19999980, limit = 20
-		expectedOffset := 19999980
-		expectedLimit := 20
+		first := int32(consts.MaxPageSize)
 
 		// Mock expectations
 		postStorage.EXPECT().
-			GetAllPosts(ctx, expectedOffset, expectedLimit).
+			GetAllPosts(ctx, newFeed, models.Page{Limit: consts.MaxPageSize + 1}).
 			Return([]*models.Post{}, nil)
+		postStorage.EXPECT().
+			CountPosts(ctx, newFeed).
+			Return(0, nil)
 
 		// Execute
-		result, err := postService.GetAllPosts(ctx, &page)
+		result, err := postService.GetAllPosts(ctx, models.PostSortNew, models.TimeWindowAll, models.PostFilter{}, models.PageRequest{First: &first})
 
 		// Verify
 		require.NoError(t, err)
 		assert.NotNil(t, result)
+
+		// больше максимального размера страницы запросить нельзя
+		first++
+		result, err = postService.GetAllPosts(ctx, models.PostSortNew, models.TimeWindowAll, models.PostFilter{}, models.PageRequest{First: &first})
+		assert.Error(t, err)
+		assert.Nil(t, result)
 	})
 
 	t.Run("handle content edge cases", func(t *testing.T) {
@@ -408,10 +501,8 @@ func TestPostService_EdgeCases(t *testing.T) {
 		longContent := "very long content " // в реальности можно сделать действительно длинный контент
 
 		postReq := models.PostRequest{
-			Title:            title,
-			Author:           &author,
-			Content:          emptyContent,
-			IsCommentAllowed: true,
+			Title:   title,
+			Content: emptyContent,
 		}
 
 		expectedPost := models.Post{
@@ -453,3 +544,632 @@ func TestPostService_EdgeCases(t *testing.T) {
 		assert.Equal(t, &expectedPost, result)
 	})
 }
+
+func TestPostService_UpdatePost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	author := "test_author"
+	ctx := withUser(author)
+	anotherCtx := withUser("another_author")
+	postStorage := store_mock.NewMockPostStorage(ctrl)
+
+	postService := NewPostService(postStorage, store_mock.NewMockCommunityStorage(ctrl),
+		store_mock.NewMockUserStorage(ctrl), nil)
+
+	postID := uuid.New()
+	existingPost := &models.Post{
+		ID:                postID,
+		Title:             "Old Title",
+		Author:            author,
+		Content:           "old content",
+		IsCommentsAllowed: true,
+	}
+
+	t.Run("successfully update post", func(t *testing.T) {
+		// Setup
+		postReq := models.PostUpdateRequest{
+			ID:      postID,
+			Title:   "New Title",
+			Content: "new content",
+		}
+
+		// Mock expectations
+		postStorage.EXPECT().
+			GetPostByID(ctx, postID).
+			Return(existingPost, nil)
+		postStorage.EXPECT().
+			UpdatePost(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, post models.Post) (models.Post, error) {
+				assert.Equal(t, postID, post.ID)
+				assert.Equal(t, "New Title", post.Title)
+				assert.Equal(t, "new content", post.Content)
+				assert.True(t, post.IsCommentsAllowed) // комментарии меняются только через SetCommentsAllowed
+				return post, nil
+			})
+
+		// Execute
+		result, err := postService.UpdatePost(ctx, postReq)
+
+		// Verify
+		require.NoError(t, err)
+		assert.Equal(t, "New Title", result.Title)
+		assert.Equal(t, author, result.Author)
+	})
+
+	t.Run("fail when title is empty", func(t *testing.T) {
+		// Setup
+		postReq := models.PostUpdateRequest{
+			ID:    postID,
+			Title: "",
+		}
+
+		// Execute
+		result, err := postService.UpdatePost(ctx, postReq)
+
+		// Verify
+		assert.Error(t, err)
+		assert.IsType(t, utils.GqlError{}, err)
+		gqlErr := err.(utils.GqlError)
+		assert.Equal(t, "post must have a title", gqlErr.Msg)
+		assert.Equal(t, consts.BadRequestType, gqlErr.Type)
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail when user is not authenticated", func(t *testing.T) {
+		// Setup
+		postReq := models.PostUpdateRequest{
+			ID:    postID,
+			Title: "New Title",
+		}
+
+		// Execute
+		result, err := postService.UpdatePost(context.Background(), postReq)
+
+		// Verify
+		assert.Error(t, err)
+		assert.IsType(t, utils.GqlError{}, err)
+		gqlErr := err.(utils.GqlError)
+		assert.Equal(t, "authentication required", gqlErr.Msg)
+		assert.Equal(t, consts.UnauthorizedType, gqlErr.Type)
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail when user is not the author", func(t *testing.T) {
+		// Setup
+		postReq := models.PostUpdateRequest{
+			ID:    postID,
+			Title: "New Title",
+		}
+
+		// Mock expectations
+		postStorage.EXPECT().
+			GetPostByID(anotherCtx, postID).
+			Return(existingPost, nil)
+
+		// Execute
+		result, err := postService.UpdatePost(anotherCtx, postReq)
+
+		// Verify
+		assert.Error(t, err)
+		assert.IsType(t, utils.GqlError{}, err)
+		gqlErr := err.(utils.GqlError)
+		assert.Equal(t, consts.ForbiddenType, gqlErr.Type)
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail when post not found", func(t *testing.T) {
+		// Setup
+		postReq := models.PostUpdateRequest{
+			ID:    postID,
+			Title: "New Title",
+		}
+
+		// Mock expectations
+		postStorage.EXPECT().
+			GetPostByID(ctx, postID).
+			Return(nil, sql.ErrNoRows)
+
+		// Execute
+		result, err := postService.UpdatePost(ctx, postReq)
+
+		// Verify
+		assert.Error(t, err)
+		assert.IsType(t, utils.GqlError{}, err)
+		gqlErr := err.(utils.GqlError)
+		assert.Contains(t, gqlErr.Msg, "not found")
+		assert.Equal(t, consts.BadRequestType, gqlErr.Type)
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail when storage returns error", func(t *testing.T) {
+		// Setup
+		postReq := models.PostUpdateRequest{
+			ID:    postID,
+			Title: "New Title",
+		}
+
+		// Mock expectations
+		postStorage.EXPECT().
+			GetPostByID(ctx, postID).
+			Return(existingPost, nil)
+		postStorage.EXPECT().
+			UpdatePost(ctx, gomock.Any()).
+			Return(models.Post{}, assert.AnError)
+
+		// Execute
+		result, err := postService.UpdatePost(ctx, postReq)
+
+		// Verify
+		assert.Error(t, err)
+		assert.IsType(t, utils.GqlError{}, err)
+		gqlErr := err.(utils.GqlError)
+		assert.Equal(t, "error updating post", gqlErr.Msg)
+		assert.Equal(t, consts.InternalServerErrorType, gqlErr.Type)
+		assert.Nil(t, result)
+	})
+}
+
+func TestPostService_DeletePost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	author := "test_author"
+	ctx := withUser(author)
+	anotherCtx := withUser("another_author")
+	postStorage := store_mock.NewMockPostStorage(ctrl)
+
+	postService := NewPostService(postStorage, store_mock.NewMockCommunityStorage(ctrl),
+		store_mock.NewMockUserStorage(ctrl), nil)
+
+	postID := uuid.New()
+	existingPost := &models.Post{
+		ID:     postID,
+		Title:  "Test Post Title",
+		Author: author,
+	}
+
+	t.Run("successfully delete post", func(t *testing.T) {
+		// Mock expectations
+		postStorage.EXPECT().
+			GetPostByID(ctx, postID).
+			Return(existingPost, nil)
+		postStorage.EXPECT().
+			DeletePost(ctx, postID).
+			Return(nil)
+
+		// Execute
+		err := postService.DeletePost(ctx, postID)
+
+		// Verify
+		require.NoError(t, err)
+	})
+
+	t.Run("fail when user is not the author", func(t *testing.T) {
+		// Mock expectations
+		postStorage.EXPECT().
+			GetPostByID(anotherCtx, postID).
+			Return(existingPost, nil)
+
+		// Execute
+		err := postService.DeletePost(anotherCtx, postID)
+
+		// Verify
+		assert.Error(t, err)
+		assert.IsType(t, utils.GqlError{}, err)
+		gqlErr := err.(utils.GqlError)
+		assert.Equal(t, consts.ForbiddenType, gqlErr.Type)
+	})
+
+	t.Run("fail when storage returns error", func(t *testing.T) {
+		// Mock expectations
+		postStorage.EXPECT().
+			GetPostByID(ctx, postID).
+			Return(existingPost, nil)
+		postStorage.EXPECT().
+			DeletePost(ctx, postID).
+			Return(assert.AnError)
+
+		// Execute
+		err := postService.DeletePost(ctx, postID)
+
+		// Verify
+		assert.Error(t, err)
+		assert.IsType(t, utils.GqlError{}, err)
+		gqlErr := err.(utils.GqlError)
+		assert.Equal(t, "error deleting post", gqlErr.Msg)
+		assert.Equal(t, consts.InternalServerErrorType, gqlErr.Type)
+	})
+}
+
+func TestPostService_SetCommentsAllowed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	author := "test_author"
+	ctx := withUser(author)
+	anotherCtx := withUser("another_author")
+	postStorage := store_mock.NewMockPostStorage(ctrl)
+
+	postService := NewPostService(postStorage, store_mock.NewMockCommunityStorage(ctrl),
+		store_mock.NewMockUserStorage(ctrl), nil)
+
+	postID := uuid.New()
+	existingPost := &models.Post{
+		ID:                postID,
+		Title:             "Test Post Title",
+		Author:            author,
+		IsCommentsAllowed: true,
+	}
+
+	t.Run("successfully disable comments", func(t *testing.T) {
+		// Mock expectations
+		postStorage.EXPECT().
+			GetPostByID(ctx, postID).
+			Return(existingPost, nil)
+		postStorage.EXPECT().
+			SetCommentsAllowed(ctx, postID, false).
+			Return(models.Post{ID: postID, Title: existingPost.Title, Author: author, IsCommentsAllowed: false}, nil)
+
+		// Execute
+		result, err := postService.SetCommentsAllowed(ctx, postID, false)
+
+		// Verify
+		require.NoError(t, err)
+		assert.False(t, result.IsCommentsAllowed)
+	})
+
+	t.Run("fail when user is not the author", func(t *testing.T) {
+		// Mock expectations
+		postStorage.EXPECT().
+			GetPostByID(anotherCtx, postID).
+			Return(existingPost, nil)
+
+		// Execute
+		result, err := postService.SetCommentsAllowed(anotherCtx, postID, false)
+
+		// Verify
+		assert.Error(t, err)
+		assert.IsType(t, utils.GqlError{}, err)
+		gqlErr := err.(utils.GqlError)
+		assert.Equal(t, consts.ForbiddenType, gqlErr.Type)
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail when storage returns error", func(t *testing.T) {
+		// Mock expectations
+		postStorage.EXPECT().
+			GetPostByID(ctx, postID).
+			Return(existingPost, nil)
+		postStorage.EXPECT().
+			SetCommentsAllowed(ctx, postID, false).
+			Return(models.Post{}, assert.AnError)
+
+		// Execute
+		result, err := postService.SetCommentsAllowed(ctx, postID, false)
+
+		// Verify
+		assert.Error(t, err)
+		assert.IsType(t, utils.GqlError{}, err)
+		gqlErr := err.(utils.GqlError)
+		assert.Equal(t, "error changing comments permission", gqlErr.Msg)
+		assert.Equal(t, consts.InternalServerErrorType, gqlErr.Type)
+		assert.Nil(t, result)
+	})
+}
+
+func TestPostService_SearchPosts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	postStorage := store_mock.NewMockPostStorage(ctrl)
+
+	postService := NewPostService(postStorage, store_mock.NewMockCommunityStorage(ctrl),
+		store_mock.NewMockUserStorage(ctrl), nil)
+
+	newResult := func(rank float64) *models.PostSearchResult {
+		createdAt := time.Now()
+		return &models.PostSearchResult{
+			Post:    &models.Post{ID: uuid.New(), Title: "Поиск", CreatedAt: &createdAt},
+			Rank:    rank,
+			Snippet: "<mark>Поиск</mark>",
+		}
+	}
+
+	t.Run("successfully search posts", func(t *testing.T) {
+		// Setup
+		first := int32(2)
+		results := []*models.PostSearchResult{newResult(0.9), newResult(0.5), newResult(0.1)}
+
+		// Mock expectations - запрос передается без пробелов по краям
+		postStorage.EXPECT().
+			SearchPosts(ctx, "поиск", models.Page{Limit: 3}).
+			Return(results, nil)
+		postStorage.EXPECT().
+			CountSearchPosts(ctx, "поиск").
+			Return(3, nil)
+
+		// Execute
+		result, err := postService.SearchPosts(ctx, "  поиск ", models.PageRequest{First: &first})
+
+		// Verify
+		require.NoError(t, err)
+		require.Len(t, result.Edges, 2)
+		assert.Equal(t, results[0], result.Edges[0].Node)
+		assert.Equal(t, models.PostSearchCursor(results[1]).Encode(), result.Edges[1].Cursor)
+		assert.True(t, result.PageInfo.HasNextPage)
+		assert.Equal(t, int32(3), result.TotalCount)
+	})
+
+	t.Run("fail with invalid query", func(t *testing.T) {
+		for _, query := range []string{" ", strings.Repeat("а", consts.SearchQueryMaxLen+1)} {
+			// Execute
+			result, err := postService.SearchPosts(ctx, query, models.PageRequest{})
+
+			// Verify
+			var gqlErr utils.GqlError
+			require.ErrorAs(t, err, &gqlErr)
+			assert.Equal(t, consts.BadRequestType, gqlErr.Type)
+			assert.Nil(t, result)
+		}
+	})
+
+	t.Run("fail when storage returns error", func(t *testing.T) {
+		// Mock expectations
+		postStorage.EXPECT().
+			SearchPosts(ctx, "поиск", models.Page{Limit: consts.PageSize + 1}).
+			Return(nil, assert.AnError)
+
+		// Execute
+		result, err := postService.SearchPosts(ctx, "поиск", models.PageRequest{})
+
+		// Verify
+		var gqlErr utils.GqlError
+		require.ErrorAs(t, err, &gqlErr)
+		assert.Equal(t, consts.InternalServerErrorType, gqlErr.Type)
+		assert.Nil(t, result)
+	})
+}
+
+func TestPostService_Tags(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	author := "test_author"
+	ctx := withUser(author)
+	postStorage := store_mock.NewMockPostStorage(ctrl)
+	userStorage := store_mock.NewMockUserStorage(ctrl)
+	userStorage.EXPECT().GetUserByID(ctx, gomock.Any()).Return(&models.User{Role: models.RoleUser}, nil).AnyTimes()
+
+	postService := NewPostService(postStorage, store_mock.NewMockCommunityStorage(ctrl), userStorage, nil)
+
+	t.Run("normalize tags on create", func(t *testing.T) {
+		// Setup
+		postReq := models.PostRequest{
+			Title: "Test Post Title",
+			Tags:  []string{"#Golang", " graph QL ", "golang", "Базы_данных"},
+		}
+
+		// Mock expectations
+		postStorage.EXPECT().
+			CreatePost(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, post models.Post) (models.Post, error) {
+				return post, nil
+			})
+
+		// Execute
+		result, err := postService.CreatePost(ctx, postReq)
+
+		// Verify
+		require.NoError(t, err)
+		assert.Equal(t, []string{"golang", "graph-ql", "базы_данных"}, result.Tags)
+	})
+
+	t.Run("fail with invalid tags", func(t *testing.T) {
+		for _, tags := range [][]string{
+			{"c++"},
+			{"#"},
+			{strings.Repeat("a", consts.MaxTagLen+1)},
+			{"a", "b", "c", "d", "e", "f"},
+		} {
+			// Execute
+			result, err := postService.CreatePost(ctx, models.PostRequest{Title: "Test Post Title", Tags: tags})
+
+			// Verify
+			var gqlErr utils.GqlError
+			require.ErrorAs(t, err, &gqlErr, tags)
+			assert.Equal(t, consts.BadRequestType, gqlErr.Type)
+			assert.Nil(t, result)
+		}
+	})
+
+	t.Run("keep tags when update has no tags", func(t *testing.T) {
+		// Setup
+		existingPost := &models.Post{ID: uuid.New(), Title: "Old Title", Author: author, Tags: []string{"go"}}
+
+		// Mock expectations
+		postStorage.EXPECT().
+			GetPostByID(ctx, existingPost.ID).
+			Return(existingPost, nil).
+			Times(2)
+		postStorage.EXPECT().
+			UpdatePost(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, post models.Post) (models.Post, error) {
+				return post, nil
+			}).
+			Times(2)
+
+		// Execute
+		kept, err := postService.UpdatePost(ctx, models.PostUpdateRequest{ID: existingPost.ID, Title: "New Title"})
+		require.NoError(t, err)
+		cleared, err := postService.UpdatePost(ctx, models.PostUpdateRequest{ID: existingPost.ID, Title: "New Title",
+			Tags: []string{}})
+		require.NoError(t, err)
+
+		// Verify
+		assert.Equal(t, []string{"go"}, kept.Tags)
+		assert.Empty(t, cleared.Tags)
+	})
+
+	t.Run("filter feed by tags", func(t *testing.T) {
+		// Setup
+		feed := models.PostFeed{Sort: models.PostSortNew, Tags: []string{"go", "sql"}, Match: models.TagMatchAll,
+			Viewer: author}
+
+		// Mock expectations
+		postStorage.EXPECT().
+			GetAllPosts(ctx, feed, models.Page{Limit: consts.PageSize + 1}).
+			Return(nil, nil)
+		postStorage.EXPECT().
+			CountPosts(ctx, feed).
+			Return(0, nil)
+
+		// Execute
+		result, err := postService.GetAllPosts(ctx, models.PostSortNew, models.TimeWindowAll,
+			models.PostFilter{Tags: []string{"SQL", "#go"}, Match: models.TagMatchAll}, models.PageRequest{})
+
+		// Verify
+		require.NoError(t, err)
+		assert.Empty(t, result.Edges)
+	})
+
+	t.Run("get popular tags", func(t *testing.T) {
+		// Setup
+		limit := int32(3)
+		tags := []*models.Tag{{Name: "go", PostCount: 2}}
+
+		// Mock expectations
+		postStorage.EXPECT().
+			GetPopularTags(ctx, consts.PopularTagsSize).
+			Return(tags, nil)
+		postStorage.EXPECT().
+			GetPopularTags(ctx, 3).
+			Return(tags, nil)
+
+		// Execute
+		byDefault, err := postService.GetPopularTags(ctx, nil)
+		require.NoError(t, err)
+		limited, err := postService.GetPopularTags(ctx, &limit)
+		require.NoError(t, err)
+
+		// Verify
+		assert.Equal(t, tags, byDefault)
+		assert.Equal(t, tags, limited)
+	})
+
+	t.Run("fail with invalid popular tags limit", func(t *testing.T) {
+		// Setup
+		limit := int32(consts.MaxPopularTagsSize + 1)
+
+		// Execute
+		result, err := postService.GetPopularTags(ctx, &limit)
+
+		// Verify
+		var gqlErr utils.GqlError
+		require.ErrorAs(t, err, &gqlErr)
+		assert.Equal(t, consts.BadRequestType, gqlErr.Type)
+		assert.Nil(t, result)
+	})
+}
+
+func TestPostService_CreatePostInCommunity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := withUser("test_author")
+	postStorage := store_mock.NewMockPostStorage(ctrl)
+	communityStorage := store_mock.NewMockCommunityStorage(ctrl)
+
+	postService := NewPostService(postStorage, communityStorage, store_mock.NewMockUserStorage(ctrl), nil)
+
+	slug := "golang"
+	community := &models.Community{
+		ID:            uuid.New(),
+		Slug:          slug,
+		PostPolicy:    models.PostPolicyMembers,
+		CommentPolicy: models.CommentPolicyClosed,
+	}
+
+	t.Run("successfully create post as member with community comment policy", func(t *testing.T) {
+		// Mock expectations
+		communityStorage.EXPECT().GetCommunityBySlug(ctx, slug).Return(community, nil)
+		communityStorage.EXPECT().
+			GetMemberships(ctx, []uuid.UUID{community.ID}, "test_author").
+			Return(map[uuid.UUID]bool{community.ID: true}, nil)
+		postStorage.EXPECT().
+			CreatePost(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, post models.Post) (models.Post, error) {
+				assert.Equal(t, &community.ID, post.CommunityID)
+				assert.False(t, post.IsCommentsAllowed)
+				return post, nil
+			})
+
+		// Execute
+		result, err := postService.CreatePost(ctx, models.PostRequest{Title: "title", Community: &slug})
+
+		// Verify
+		require.NoError(t, err)
+		assert.Equal(t, &community.ID, result.CommunityID)
+	})
+
+	t.Run("explicit comment setting overrides community policy", func(t *testing.T) {
+		// Setup
+		allowed := true
+
+		// Mock expectations
+		communityStorage.EXPECT().GetCommunityBySlug(ctx, slug).Return(community, nil)
+		communityStorage.EXPECT().
+			GetMemberships(ctx, []uuid.UUID{community.ID}, "test_author").
+			Return(map[uuid.UUID]bool{community.ID: true}, nil)
+		postStorage.EXPECT().
+			CreatePost(ctx, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, post models.Post) (models.Post, error) {
+				assert.True(t, post.IsCommentsAllowed)
+				return post, nil
+			})
+
+		// Execute
+		_, err := postService.CreatePost(ctx, models.PostRequest{Title: "title", Community: &slug,
+			IsCommentAllowed: &allowed})
+
+		// Verify
+		require.NoError(t, err)
+	})
+
+	t.Run("fail when non-member posts in members-only community", func(t *testing.T) {
+		// Mock expectations
+		communityStorage.EXPECT().GetCommunityBySlug(ctx, slug).Return(community, nil)
+		communityStorage.EXPECT().
+			GetMemberships(ctx, []uuid.UUID{community.ID}, "test_author").
+			Return(map[uuid.UUID]bool{}, nil)
+
+		// Execute
+		result, err := postService.CreatePost(ctx, models.PostRequest{Title: "title", Community: &slug})
+
+		// Verify
+		require.Error(t, err)
+		gqlErr := err.(utils.GqlError)
+		assert.Equal(t, "only members can post in community golang", gqlErr.Msg)
+		assert.Equal(t, consts.ForbiddenType, gqlErr.Type)
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail when community not found", func(t *testing.T) {
+		// Setup
+		missing := "missing"
+
+		// Mock expectations
+		communityStorage.EXPECT().
+			GetCommunityBySlug(ctx, missing).
+			Return(nil, fmt.Errorf("community: %w", sql.ErrNoRows))
+
+		// Execute
+		result, err := postService.CreatePost(ctx, models.PostRequest{Title: "title", Community: &missing})
+
+		// Verify
+		require.Error(t, err)
+		assert.Equal(t, consts.BadRequestType, err.(utils.GqlError).Type)
+		assert.Nil(t, result)
+	})
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nedokyrill/posts-service/internal/auth"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/internal/storage"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+	"github.com/nedokyrill/posts-service/pkg/logger"
+	"github.com/nedokyrill/posts-service/pkg/utils"
+)
+
+// AnyOperation - ограничение для операций, у которых нет своего
+const AnyOperation = "*"
+
+type RateLimitServiceImpl struct {
+	store  storage.RateLimitStorage
+	limits map[string]models.RateLimit // по имени операции
+}
+
+func NewRateLimitService(store storage.RateLimitStorage, limits map[string]models.RateLimit) *RateLimitServiceImpl {
+	return &RateLimitServiceImpl{
+		store:  store,
+		limits: limits,
+	}
+}
+
+// ParseRateLimits разбирает ограничения вида "CreatePost=5/1m,AddComment=20/1m,*=60/1m": 5 вызовов подряд и
+// 5 новых в минуту, 0 вызовов - без ограничения. Ограничения из следующих строк заменяют предыдущие
+func ParseRateLimits(specs ...string) (map[string]models.RateLimit, error) {
+	limits := make(map[string]models.RateLimit)
+	for _, spec := range specs {
+		for _, item := range strings.Split(spec, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+
+			operation, limit, ok := strings.Cut(item, "=")
+			burst, per, ok2 := strings.Cut(limit, "/")
+			if !ok || !ok2 || strings.TrimSpace(operation) == "" {
+				return nil, fmt.Errorf("invalid rate limit: %q", item)
+			}
+
+			n, err := strconv.Atoi(strings.TrimSpace(burst))
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid rate limit burst: %q", item)
+			}
+			d, err := time.ParseDuration(strings.TrimSpace(per))
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("invalid rate limit period: %q", item)
+			}
+
+			limits[strings.TrimSpace(operation)] = models.RateLimit{Burst: n, Per: d}
+		}
+	}
+	return limits, nil
+}
+
+func (s *RateLimitServiceImpl) Allow(ctx context.Context, operation string) error {
+	limit, ok := s.limits[operation]
+	if !ok {
+		limit, ok = s.limits[AnyOperation]
+	}
+	if !ok || limit.Burst == 0 {
+		return nil
+	}
+
+	// токен берется и из корзины пользователя, и из корзины его IP: иначе несколько аккаунтов с одного адреса
+	// получают в несколько раз больше запросов. Анонимные клиенты делят корзину своего IP
+	ip := auth.ClientIPFrom(ctx)
+	var subjects []string
+	if identity, ok := auth.IdentityFrom(ctx); ok {
+		subjects = append(subjects, "user:"+identity.UserID.String())
+	}
+	if ip != "" || len(subjects) == 0 {
+		subjects = append(subjects, "ip:"+ip)
+	}
+
+	var limited []string
+	var wait time.Duration
+	for _, subject := range subjects {
+		taken, retryAfter, err := s.store.TakeToken(ctx, operation+":"+subject, limit)
+		if err != nil {
+			// без хранилища ограничителя запросы пропускаются, чтобы его недоступность не останавливала сервис
+			logger.Logger.Error(fmt.Sprintf("error taking rate limit token: %v", err))
+			continue
+		}
+		if !taken {
+			limited = append(limited, subject)
+			wait = max(wait, retryAfter)
+		}
+	}
+
+	if len(limited) > 0 {
+		logger.Logger.Info(fmt.Sprintf("rate limit exceeded for %s by %s", operation, strings.Join(limited, ", ")))
+		return utils.GqlError{
+			Msg:        fmt.Sprintf("too many %s requests, retry later", operation),
+			Type:       consts.RateLimitedType,
+			RetryAfter: max(wait, time.Second),
+		}
+	}
+	return nil
+}
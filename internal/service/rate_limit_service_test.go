@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/nedokyrill/posts-service/internal/auth"
+	"github.com/nedokyrill/posts-service/internal/models"
+	store_mock "github.com/nedokyrill/posts-service/internal/storage/mocks"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+	"github.com/nedokyrill/posts-service/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitService_Allow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	rateLimitStorage := store_mock.NewMockRateLimitStorage(ctrl)
+	postLimit := models.RateLimit{Burst: 5, Per: time.Minute}
+	anyLimit := models.RateLimit{Burst: 60, Per: time.Minute}
+	rateLimitService := NewRateLimitService(rateLimitStorage, map[string]models.RateLimit{
+		"CreatePost": postLimit,
+		"Login":      {Burst: 0, Per: time.Minute},
+		AnyOperation: anyLimit,
+	})
+
+	ctx := auth.WithClientIP(withUser("test_user"), "10.0.0.1")
+	identity, _ := auth.IdentityFrom(ctx)
+	otherCtx := auth.WithClientIP(withUser("other_user"), "10.0.0.1")
+	otherIdentity, _ := auth.IdentityFrom(otherCtx)
+	anonCtx := auth.WithClientIP(context.Background(), "10.0.0.1")
+
+	t.Run("take user and ip tokens", func(t *testing.T) {
+		// Mock expectations
+		rateLimitStorage.EXPECT().
+			TakeToken(ctx, "CreatePost:user:"+identity.UserID.String(), postLimit).
+			Return(true, time.Duration(0), nil)
+		rateLimitStorage.EXPECT().
+			TakeToken(ctx, "CreatePost:ip:10.0.0.1", postLimit).
+			Return(true, time.Duration(0), nil)
+
+		// Execute
+		err := rateLimitService.Allow(ctx, "CreatePost")
+
+		// Verify
+		assert.NoError(t, err)
+	})
+
+	t.Run("fail when users share empty ip bucket", func(t *testing.T) {
+		// Mock expectations
+		rateLimitStorage.EXPECT().
+			TakeToken(otherCtx, "CreatePost:user:"+otherIdentity.UserID.String(), postLimit).
+			Return(true, time.Duration(0), nil)
+		rateLimitStorage.EXPECT().
+			TakeToken(otherCtx, "CreatePost:ip:10.0.0.1", postLimit).
+			Return(false, 3*time.Second, nil)
+
+		// Execute
+		err := rateLimitService.Allow(otherCtx, "CreatePost")
+
+		// Verify
+		var gqlErr utils.GqlError
+		require.ErrorAs(t, err, &gqlErr)
+		assert.Equal(t, consts.RateLimitedType, gqlErr.Type)
+		assert.Equal(t, 3, gqlErr.Extensions()["retryAfter"])
+	})
+
+	t.Run("take only user token without known ip", func(t *testing.T) {
+		// Setup
+		noIPCtx := withUser("test_user")
+
+		// Mock expectations
+		rateLimitStorage.EXPECT().
+			TakeToken(noIPCtx, gomock.Any(), postLimit).
+			Return(true, time.Duration(0), nil)
+
+		// Execute
+		err := rateLimitService.Allow(noIPCtx, "CreatePost")
+
+		// Verify
+		assert.NoError(t, err)
+	})
+
+	t.Run("take anonymous token by ip with default limit", func(t *testing.T) {
+		// Mock expectations
+		rateLimitStorage.EXPECT().
+			TakeToken(anonCtx, "Register:ip:10.0.0.1", anyLimit).
+			Return(true, time.Duration(0), nil)
+
+		// Execute
+		err := rateLimitService.Allow(anonCtx, "Register")
+
+		// Verify
+		assert.NoError(t, err)
+	})
+
+	t.Run("skip disabled limit", func(t *testing.T) {
+		// Execute
+		err := rateLimitService.Allow(anonCtx, "Login")
+
+		// Verify
+		assert.NoError(t, err)
+	})
+
+	t.Run("fail on empty bucket with larger retry after", func(t *testing.T) {
+		// Mock expectations
+		rateLimitStorage.EXPECT().
+			TakeToken(ctx, gomock.Any(), postLimit).
+			Return(false, 1500*time.Millisecond, nil)
+		rateLimitStorage.EXPECT().
+			TakeToken(ctx, gomock.Any(), postLimit).
+			Return(false, 500*time.Millisecond, nil)
+
+		// Execute
+		err := rateLimitService.Allow(ctx, "CreatePost")
+
+		// Verify
+		var gqlErr utils.GqlError
+		require.ErrorAs(t, err, &gqlErr)
+		assert.Equal(t, consts.RateLimitedType, gqlErr.Type)
+		assert.Equal(t, 2, gqlErr.Extensions()["retryAfter"])
+	})
+
+	t.Run("allow on storage error", func(t *testing.T) {
+		// Mock expectations
+		rateLimitStorage.EXPECT().
+			TakeToken(ctx, gomock.Any(), postLimit).
+			Return(false, time.Duration(0), errors.New("database error")).
+			Times(2)
+
+		// Execute
+		err := rateLimitService.Allow(ctx, "CreatePost")
+
+		// Verify
+		assert.NoError(t, err)
+	})
+}
+
+func TestParseRateLimits(t *testing.T) {
+	limits, err := ParseRateLimits("CreatePost=5/1m, AddComment=20/1m,*=60/1m", "AddComment=10/30s,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]models.RateLimit{
+		"CreatePost": {Burst: 5, Per: time.Minute},
+		"AddComment": {Burst: 10, Per: 30 * time.Second},
+		"*":          {Burst: 60, Per: time.Minute},
+	}, limits)
+
+	for _, spec := range []string{"CreatePost", "CreatePost=5", "=5/1m", "CreatePost=-1/1m", "CreatePost=5/0s",
+		"CreatePost=5/minute"} {
+		_, err = ParseRateLimits(spec)
+		assert.Error(t, err, spec)
+	}
+}
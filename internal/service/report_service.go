@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/internal/storage"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+	"github.com/nedokyrill/posts-service/pkg/logger"
+	"github.com/nedokyrill/posts-service/pkg/utils"
+)
+
+type ReportServiceImpl struct {
+	store         storage.ReportStorage
+	postStore     storage.PostStorage
+	commStore     storage.CommentStorage
+	moderators    moderators
+	hideThreshold int // после скольких открытых жалоб контент скрывается автоматически, 0 - не скрывается
+}
+
+func NewReportService(store storage.ReportStorage, postStore storage.PostStorage, commStore storage.CommentStorage,
+	communityStore storage.CommunityStorage, userStore storage.UserStorage, hideThreshold int) *ReportServiceImpl {
+	return &ReportServiceImpl{
+		store:         store,
+		postStore:     postStore,
+		commStore:     commStore,
+		moderators:    moderators{userStore: userStore, communityStore: communityStore},
+		hideThreshold: hideThreshold,
+	}
+}
+
+func (s *ReportServiceImpl) ReportContent(ctx context.Context, targetType models.ReportTargetType,
+	targetID uuid.UUID, reason models.ReportReason, note *string) (*models.Report, error) {
+	identity, err := requireIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !targetType.IsValid() || !reason.IsValid() {
+		return nil, utils.GqlError{
+			Msg:  fmt.Sprintf("invalid report target type or reason: %s, %s", targetType, reason),
+			Type: consts.BadRequestType,
+		}
+	}
+
+	text, err := parseNote(reason, note)
+	if err != nil {
+		return nil, err
+	}
+
+	post, author, err := s.getTarget(ctx, targetType, targetID, identity.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	if author == identity.Username {
+		return nil, utils.GqlError{
+			Msg:  fmt.Sprintf("can not report own %s", targetName(targetType)),
+			Type: consts.BadRequestType,
+		}
+	}
+
+	report := models.Report{
+		TargetType: targetType,
+		TargetID:   targetID,
+		PostID:     post.ID,
+		Reporter:   identity.Username,
+		Reason:     reason,
+		Note:       text,
+	}
+	// контент скрывает сервис, а не пожаловавшийся последним: журнал не должен раскрывать авторов жалоб
+	_, autoHide, _ := contentActions(targetType)
+	hide := models.ModerationAction{
+		Actor:       consts.SystemActor,
+		Action:      autoHide,
+		PostID:      &post.ID,
+		CommentID:   report.CommentID(),
+		CommunityID: post.CommunityID,
+		Reason:      fmt.Sprintf("reported by %d users", s.hideThreshold),
+	}
+
+	created, err := s.store.CreateReport(ctx, report, s.hideThreshold, hide)
+	if err != nil {
+		if errors.Is(err, storage.ErrAlreadyExists) {
+			return nil, utils.GqlError{
+				Msg: fmt.Sprintf("%s with id: %s is already reported by you", targetName(targetType),
+					targetID.String()),
+				Type: consts.BadRequestType,
+			}
+		}
+		return nil, notFoundOr(err, fmt.Sprintf("%s with id: %s not found", targetName(targetType),
+			targetID.String()), "error creating report")
+	}
+
+	logger.Logger.Info(fmt.Sprintf("create report with id: %s successfully", created.ID.String()))
+	return &created, nil
+}
+func (s *ReportServiceImpl) GetReportQueue(ctx context.Context, status models.ReportStatus,
+	pageReq models.PageRequest) (*models.ReportConnection, error) {
+	if _, err := requireIdentity(ctx); err != nil {
+		return nil, err
+	}
+
+	// очередь общая для всех сообществ, поэтому она доступна только глобальным модераторам
+	if err := s.moderators.require(ctx, nil, "error getting report queue"); err != nil {
+		return nil, err
+	}
+
+	if !status.IsValid() {
+		return nil, utils.GqlError{
+			Msg:  fmt.Sprintf("invalid report status: %s", status),
+			Type: consts.BadRequestType,
+		}
+	}
+
+	page, err := parsePageRequest(pageReq)
+	if err != nil {
+		return nil, err
+	}
+
+	reports, err := s.store.GetReports(ctx, status, withLookahead(page))
+	if err != nil {
+		logger.Logger.Error(fmt.Sprintf("error getting report queue: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error getting report queue",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	reports, pageInfo := cutPage(reports, page, models.ReportCursor)
+
+	edges := make([]*models.ReportEdge, 0, len(reports))
+	for _, report := range reports {
+		edges = append(edges, &models.ReportEdge{
+			Cursor: models.ReportCursor(report).Encode(),
+			Node:   report,
+		})
+	}
+
+	logger.Logger.Info("get report queue successfully")
+	return &models.ReportConnection{Edges: edges, PageInfo: pageInfo}, nil
+}
+func (s *ReportServiceImpl) ResolveReport(ctx context.Context, reportID uuid.UUID,
+	action models.ReportAction) (*models.Report, error) {
+	identity, err := requireIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !action.IsValid() {
+		return nil, utils.GqlError{
+			Msg:  fmt.Sprintf("invalid report action: %s", action),
+			Type: consts.BadRequestType,
+		}
+	}
+
+	report, err := s.store.GetReportByID(ctx, reportID)
+	if err != nil {
+		return nil, notFoundOr(err, fmt.Sprintf("report with id: %s not found", reportID.String()),
+			"error resolving report")
+	}
+
+	alreadyResolved := utils.GqlError{
+		Msg:  fmt.Sprintf("report with id: %s is already resolved", reportID.String()),
+		Type: consts.BadRequestType,
+	}
+	if report.Status != models.ReportStatusOpen {
+		return nil, alreadyResolved
+	}
+
+	// жалобы решают модераторы сообщества поста, как и при скрытии контента
+	post, err := s.postStore.GetPostByID(ctx, report.PostID)
+	if err != nil {
+		return nil, notFoundOr(err, fmt.Sprintf("report with id: %s not found", reportID.String()),
+			"error resolving report")
+	}
+
+	if err = s.moderators.require(ctx, post.CommunityID, "error resolving report"); err != nil {
+		return nil, err
+	}
+
+	remove, _, restore := contentActions(report.TargetType)
+	status, modAction := models.ReportStatusDismissed, models.ModerationAction{
+		Actor:       identity.Username,
+		Action:      restore,
+		PostID:      &post.ID,
+		CommentID:   report.CommentID(),
+		CommunityID: post.CommunityID,
+		Reason:      "reports dismissed",
+	}
+	if action == models.ReportActionRemove {
+		status, modAction.Action = models.ReportStatusRemoved, remove
+		modAction.Reason = fmt.Sprintf("reported as %s", report.Reason)
+	}
+
+	resolved, err := s.store.ResolveReport(ctx, reportID, status, identity.Username, modAction)
+	if err != nil {
+		// жалобу успел решить другой модератор
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, alreadyResolved
+		}
+		logger.Logger.Error(fmt.Sprintf("error resolving report: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error resolving report",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	logger.Logger.Info(fmt.Sprintf("resolve report with id: %s as %s successfully", reportID.String(), status))
+	return &resolved, nil
+}
+
+// пост, на который (или на комментарий к которому) жалуются, и автор контента жалобы.
+// Скрытый контент, невидимый пользователю reporter контент и удаленные комментарии для жалоб не существуют
+func (s *ReportServiceImpl) getTarget(ctx context.Context, targetType models.ReportTargetType,
+	targetID uuid.UUID, reporter string) (*models.Post, string, error) {
+	notFound := utils.GqlError{
+		Msg:  fmt.Sprintf("%s with id: %s not found", targetName(targetType), targetID.String()),
+		Type: consts.BadRequestType,
+	}
+
+	postID, author := targetID, ""
+	if targetType == models.ReportTargetTypeComment {
+		comment, err := s.commStore.GetCommentByID(ctx, targetID)
+		if err != nil {
+			return nil, "", notFoundOr(err, notFound.Msg, "error creating report")
+		}
+		if comment.IsRemoved || comment.IsDeleted || !comment.VisibleTo(reporter) {
+			return nil, "", notFound
+		}
+		postID, author = comment.PostID, comment.Author
+	}
+
+	post, err := s.postStore.GetPostByID(ctx, postID)
+	if err != nil {
+		return nil, "", notFoundOr(err, notFound.Msg, "error creating report")
+	}
+	if post.IsRemoved || !post.VisibleTo(reporter) {
+		return nil, "", notFound
+	}
+
+	if targetType == models.ReportTargetTypePost {
+		author = post.Author
+	}
+	return post, author, nil
+}
+
+// действия журнала модерации для контента жалобы: скрытие модератором, автоматическое скрытие и восстановление
+func contentActions(targetType models.ReportTargetType) (remove, autoHide, restore models.ModerationActionType) {
+	if targetType == models.ReportTargetTypeComment {
+		return models.ModerationActionTypeRemoveComment, models.ModerationActionTypeAutoHideComment,
+			models.ModerationActionTypeRestoreComment
+	}
+	return models.ModerationActionTypeRemovePost, models.ModerationActionTypeAutoHidePost,
+		models.ModerationActionTypeRestorePost
+}
+
+func targetName(targetType models.ReportTargetType) string {
+	return strings.ToLower(string(targetType))
+}
+
+// пояснение к жалобе без пробелов по краям, для причины OTHER оно обязательно
+func parseNote(reason models.ReportReason, note *string) (string, error) {
+	var text string
+	if note != nil {
+		text = strings.TrimSpace(*note)
+	}
+
+	if utf8.RuneCountInString(text) > consts.ReportNoteMaxLen {
+		return "", utils.GqlError{
+			Msg:  fmt.Sprintf("note must be at most %d characters", consts.ReportNoteMaxLen),
+			Type: consts.BadRequestType,
+		}
+	}
+
+	if text == "" && reason == models.ReportReasonOther {
+		return "", utils.GqlError{
+			Msg:  "note is required for reason OTHER",
+			Type: consts.BadRequestType,
+		}
+	}
+	return text, nil
+}
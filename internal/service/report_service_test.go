@@ -0,0 +1,411 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/internal/storage"
+	store_mock "github.com/nedokyrill/posts-service/internal/storage/mocks"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+	"github.com/nedokyrill/posts-service/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportService_ReportContent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := withUser("test_user")
+	reportStorage := store_mock.NewMockReportStorage(ctrl)
+	postStorage := store_mock.NewMockPostStorage(ctrl)
+	commentStorage := store_mock.NewMockCommentStorage(ctrl)
+
+	reportService := NewReportService(reportStorage, postStorage, commentStorage,
+		store_mock.NewMockCommunityStorage(ctrl), store_mock.NewMockUserStorage(ctrl), 3)
+
+	post := &models.Post{ID: uuid.New(), Author: "test_author", Title: "Spam"}
+	note := " buy now "
+
+	t.Run("successfully report post", func(t *testing.T) {
+		// Mock expectations
+		postStorage.EXPECT().
+			GetPostByID(ctx, post.ID).
+			Return(post, nil)
+		reportStorage.EXPECT().
+			CreateReport(ctx, gomock.Any(), 3, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, report models.Report, hideThreshold int,
+				hide models.ModerationAction) (models.Report, error) {
+				assert.Equal(t, post.ID, report.PostID)
+				assert.Equal(t, "test_user", report.Reporter)
+				assert.Equal(t, "buy now", report.Note)
+				assert.Equal(t, models.ModerationActionTypeAutoHidePost, hide.Action)
+				assert.Equal(t, consts.SystemActor, hide.Actor)
+				assert.Nil(t, hide.CommentID)
+				report.ID = uuid.New()
+				report.Status = models.ReportStatusOpen
+				return report, nil
+			})
+
+		// Execute
+		result, err := reportService.ReportContent(ctx, models.ReportTargetTypePost, post.ID,
+			models.ReportReasonSpam, &note)
+
+		// Verify
+		require.NoError(t, err)
+		assert.Equal(t, models.ReportStatusOpen, result.Status)
+		assert.Equal(t, models.ReportTargetTypePost, result.TargetType)
+	})
+
+	t.Run("successfully report comment", func(t *testing.T) {
+		// Setup
+		comment := &models.Comment{ID: uuid.New(), PostID: post.ID, Author: "test_author", Content: "spam"}
+
+		// Mock expectations
+		commentStorage.EXPECT().
+			GetCommentByID(ctx, comment.ID).
+			Return(comment, nil)
+		postStorage.EXPECT().
+			GetPostByID(ctx, post.ID).
+			Return(post, nil)
+		reportStorage.EXPECT().
+			CreateReport(ctx, gomock.Any(), 3, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, report models.Report, hideThreshold int,
+				hide models.ModerationAction) (models.Report, error) {
+				assert.Equal(t, comment.ID, report.TargetID)
+				assert.Equal(t, post.ID, report.PostID)
+				assert.Equal(t, models.ModerationActionTypeAutoHideComment, hide.Action)
+				assert.Equal(t, &comment.ID, hide.CommentID)
+				return report, nil
+			})
+
+		// Execute
+		result, err := reportService.ReportContent(ctx, models.ReportTargetTypeComment, comment.ID,
+			models.ReportReasonAbuse, nil)
+
+		// Verify
+		require.NoError(t, err)
+		assert.Equal(t, comment.ID, result.TargetID)
+	})
+
+	t.Run("fail on own post", func(t *testing.T) {
+		// Setup
+		authorCtx := withUser("test_author")
+
+		// Mock expectations
+		postStorage.EXPECT().
+			GetPostByID(authorCtx, post.ID).
+			Return(post, nil)
+
+		// Execute
+		result, err := reportService.ReportContent(authorCtx, models.ReportTargetTypePost, post.ID,
+			models.ReportReasonSpam, nil)
+
+		// Verify
+		require.Error(t, err)
+		assert.Equal(t, consts.BadRequestType, err.(utils.GqlError).Type)
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail on other reason without note", func(t *testing.T) {
+		// Execute
+		result, err := reportService.ReportContent(ctx, models.ReportTargetTypePost, post.ID,
+			models.ReportReasonOther, nil)
+
+		// Verify
+		require.Error(t, err)
+		assert.Equal(t, consts.BadRequestType, err.(utils.GqlError).Type)
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail on removed post", func(t *testing.T) {
+		// Setup
+		removed := &models.Post{ID: uuid.New(), Author: "test_author", IsRemoved: true}
+
+		// Mock expectations
+		postStorage.EXPECT().
+			GetPostByID(ctx, removed.ID).
+			Return(removed, nil)
+
+		// Execute
+		result, err := reportService.ReportContent(ctx, models.ReportTargetTypePost, removed.ID,
+			models.ReportReasonSpam, nil)
+
+		// Verify
+		require.Error(t, err)
+		assert.Equal(t, consts.BadRequestType, err.(utils.GqlError).Type)
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail on comment shadowed from reporter", func(t *testing.T) {
+		// Setup
+		shadowed := &models.Comment{ID: uuid.New(), PostID: post.ID, Author: "test_author", IsShadowed: true}
+
+		// Mock expectations
+		commentStorage.EXPECT().
+			GetCommentByID(ctx, shadowed.ID).
+			Return(shadowed, nil)
+
+		// Execute
+		result, err := reportService.ReportContent(ctx, models.ReportTargetTypeComment, shadowed.ID,
+			models.ReportReasonSpam, nil)
+
+		// Verify
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail on post shadowed from reporter", func(t *testing.T) {
+		// Setup
+		shadowed := &models.Post{ID: uuid.New(), Author: "test_author", IsShadowed: true}
+
+		// Mock expectations
+		postStorage.EXPECT().
+			GetPostByID(ctx, shadowed.ID).
+			Return(shadowed, nil)
+
+		// Execute
+		result, err := reportService.ReportContent(ctx, models.ReportTargetTypePost, shadowed.ID,
+			models.ReportReasonSpam, nil)
+
+		// Verify
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail on repeated report", func(t *testing.T) {
+		// Mock expectations
+		postStorage.EXPECT().
+			GetPostByID(ctx, post.ID).
+			Return(post, nil)
+		reportStorage.EXPECT().
+			CreateReport(ctx, gomock.Any(), 3, gomock.Any()).
+			Return(models.Report{}, fmt.Errorf("report: %w", storage.ErrAlreadyExists))
+
+		// Execute
+		result, err := reportService.ReportContent(ctx, models.ReportTargetTypePost, post.ID,
+			models.ReportReasonSpam, nil)
+
+		// Verify
+		require.Error(t, err)
+		assert.Equal(t, consts.BadRequestType, err.(utils.GqlError).Type)
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail when unauthenticated", func(t *testing.T) {
+		// Execute
+		result, err := reportService.ReportContent(context.Background(), models.ReportTargetTypePost, post.ID,
+			models.ReportReasonSpam, nil)
+
+		// Verify
+		require.Error(t, err)
+		assert.Equal(t, consts.UnauthorizedType, err.(utils.GqlError).Type)
+		assert.Nil(t, result)
+	})
+}
+
+func TestReportService_GetReportQueue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reportStorage := store_mock.NewMockReportStorage(ctrl)
+	userStorage := store_mock.NewMockUserStorage(ctrl)
+
+	reportService := NewReportService(reportStorage, store_mock.NewMockPostStorage(ctrl),
+		store_mock.NewMockCommentStorage(ctrl), store_mock.NewMockCommunityStorage(ctrl), userStorage, 3)
+
+	t.Run("successfully get queue by moderator", func(t *testing.T) {
+		// Setup
+		ctx := withUser("test_moderator")
+		first := int32(1)
+		reports := []*models.Report{
+			{ID: uuid.New(), TargetType: models.ReportTargetTypePost, Status: models.ReportStatusOpen},
+			{ID: uuid.New(), TargetType: models.ReportTargetTypeComment, Status: models.ReportStatusOpen},
+		}
+
+		// Mock expectations
+		userStorage.EXPECT().
+			GetUserByID(ctx, gomock.Any()).
+			Return(&models.User{Username: "test_moderator", Role: models.RoleModerator}, nil)
+		reportStorage.EXPECT().
+			GetReports(ctx, models.ReportStatusOpen, models.Page{Limit: 2}).
+			Return(reports, nil)
+
+		// Execute
+		result, err := reportService.GetReportQueue(ctx, models.ReportStatusOpen, models.PageRequest{First: &first})
+
+		// Verify
+		require.NoError(t, err)
+		require.Len(t, result.Edges, 1)
+		assert.Equal(t, reports[0], result.Edges[0].Node)
+		assert.True(t, result.PageInfo.HasNextPage)
+	})
+
+	t.Run("fail when user is not moderator", func(t *testing.T) {
+		// Setup
+		ctx := withUser("test_user")
+
+		// Mock expectations
+		userStorage.EXPECT().
+			GetUserByID(ctx, gomock.Any()).
+			Return(&models.User{Username: "test_user", Role: models.RoleUser}, nil)
+
+		// Execute
+		result, err := reportService.GetReportQueue(ctx, models.ReportStatusOpen, models.PageRequest{})
+
+		// Verify
+		require.Error(t, err)
+		assert.Equal(t, consts.ForbiddenType, err.(utils.GqlError).Type)
+		assert.Nil(t, result)
+	})
+}
+
+func TestReportService_ResolveReport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := withUser("test_moderator")
+	reportStorage := store_mock.NewMockReportStorage(ctrl)
+	postStorage := store_mock.NewMockPostStorage(ctrl)
+	userStorage := store_mock.NewMockUserStorage(ctrl)
+
+	reportService := NewReportService(reportStorage, postStorage, store_mock.NewMockCommentStorage(ctrl),
+		store_mock.NewMockCommunityStorage(ctrl), userStorage, 3)
+
+	post := &models.Post{ID: uuid.New(), Author: "test_author"}
+	newReport := func() *models.Report {
+		return &models.Report{ID: uuid.New(), TargetType: models.ReportTargetTypeComment, TargetID: uuid.New(),
+			PostID: post.ID, Reporter: "test_user", Reason: models.ReportReasonSpam, Status: models.ReportStatusOpen}
+	}
+	expectModerator := func() {
+		postStorage.EXPECT().
+			GetPostByID(ctx, post.ID).
+			Return(post, nil)
+		userStorage.EXPECT().
+			GetUserByID(ctx, gomock.Any()).
+			Return(&models.User{Username: "test_moderator", Role: models.RoleModerator}, nil)
+	}
+
+	t.Run("successfully remove reported comment", func(t *testing.T) {
+		// Setup
+		report := newReport()
+
+		// Mock expectations
+		reportStorage.EXPECT().
+			GetReportByID(ctx, report.ID).
+			Return(report, nil)
+		expectModerator()
+		reportStorage.EXPECT().
+			ResolveReport(ctx, report.ID, models.ReportStatusRemoved, "test_moderator", gomock.Any()).
+			DoAndReturn(func(ctx context.Context, reportID uuid.UUID, status models.ReportStatus, resolver string,
+				action models.ModerationAction) (models.Report, error) {
+				assert.Equal(t, models.ModerationActionTypeRemoveComment, action.Action)
+				assert.Equal(t, &report.TargetID, action.CommentID)
+				resolved := *report
+				resolved.Status = status
+				resolved.ResolvedBy = &resolver
+				return resolved, nil
+			})
+
+		// Execute
+		result, err := reportService.ResolveReport(ctx, report.ID, models.ReportActionRemove)
+
+		// Verify
+		require.NoError(t, err)
+		assert.Equal(t, models.ReportStatusRemoved, result.Status)
+		assert.Equal(t, "test_moderator", *result.ResolvedBy)
+	})
+
+	t.Run("successfully dismiss report", func(t *testing.T) {
+		// Setup
+		report := newReport()
+
+		// Mock expectations
+		reportStorage.EXPECT().
+			GetReportByID(ctx, report.ID).
+			Return(report, nil)
+		expectModerator()
+		reportStorage.EXPECT().
+			ResolveReport(ctx, report.ID, models.ReportStatusDismissed, "test_moderator", gomock.Any()).
+			DoAndReturn(func(ctx context.Context, reportID uuid.UUID, status models.ReportStatus, resolver string,
+				action models.ModerationAction) (models.Report, error) {
+				assert.Equal(t, models.ModerationActionTypeRestoreComment, action.Action)
+				resolved := *report
+				resolved.Status = status
+				return resolved, nil
+			})
+
+		// Execute
+		result, err := reportService.ResolveReport(ctx, report.ID, models.ReportActionDismiss)
+
+		// Verify
+		require.NoError(t, err)
+		assert.Equal(t, models.ReportStatusDismissed, result.Status)
+	})
+
+	t.Run("fail when report is already resolved", func(t *testing.T) {
+		// Setup
+		report := newReport()
+		report.Status = models.ReportStatusDismissed
+
+		// Mock expectations
+		reportStorage.EXPECT().
+			GetReportByID(ctx, report.ID).
+			Return(report, nil)
+
+		// Execute
+		result, err := reportService.ResolveReport(ctx, report.ID, models.ReportActionRemove)
+
+		// Verify
+		require.Error(t, err)
+		assert.Equal(t, consts.BadRequestType, err.(utils.GqlError).Type)
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail when report is resolved concurrently", func(t *testing.T) {
+		// Setup
+		report := newReport()
+
+		// Mock expectations
+		reportStorage.EXPECT().
+			GetReportByID(ctx, report.ID).
+			Return(report, nil)
+		expectModerator()
+		reportStorage.EXPECT().
+			ResolveReport(ctx, report.ID, models.ReportStatusRemoved, "test_moderator", gomock.Any()).
+			Return(models.Report{}, sql.ErrNoRows)
+
+		// Execute
+		result, err := reportService.ResolveReport(ctx, report.ID, models.ReportActionRemove)
+
+		// Verify
+		require.Error(t, err)
+		assert.Equal(t, consts.BadRequestType, err.(utils.GqlError).Type)
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail when report not found", func(t *testing.T) {
+		// Setup
+		reportID := uuid.New()
+
+		// Mock expectations
+		reportStorage.EXPECT().
+			GetReportByID(ctx, reportID).
+			Return(nil, sql.ErrNoRows)
+
+		// Execute
+		result, err := reportService.ResolveReport(ctx, reportID, models.ReportActionDismiss)
+
+		// Verify
+		require.Error(t, err)
+		assert.Equal(t, consts.BadRequestType, err.(utils.GqlError).Type)
+		assert.Nil(t, result)
+	})
+}
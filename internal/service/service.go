@@ -2,25 +2,93 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/nedokyrill/posts-service/internal/models"
 )
 
 type PostService interface {
-	GetAllPosts(ctx context.Context, page *int32) ([]*models.Post, error)
+	GetAllPosts(ctx context.Context, sort models.PostSort, window models.TimeWindow, filter models.PostFilter, page models.PageRequest) (*models.PostConnection, error)
 	GetPostByID(ctx context.Context, id uuid.UUID) (*models.Post, error)
 	CreatePost(ctx context.Context, postReq models.PostRequest) (*models.Post, error)
+	UpdatePost(ctx context.Context, postReq models.PostUpdateRequest) (*models.Post, error)
+	DeletePost(ctx context.Context, id uuid.UUID) error
+	SetCommentsAllowed(ctx context.Context, id uuid.UUID, allowed bool) (*models.Post, error)
+	SearchPosts(ctx context.Context, query string, page models.PageRequest) (*models.PostSearchConnection, error)
+	GetPopularTags(ctx context.Context, limit *int32) ([]*models.Tag, error)
 }
 
 type CommentService interface {
 	CreateComment(ctx context.Context, commReq models.CommentRequest) (*models.Comment, error)
-	GetCommentsByPostID(ctx context.Context, postID uuid.UUID, page *int32) ([]*models.Comment, error)
-	GetRepliesByComment(ctx context.Context, commentID uuid.UUID) ([]*models.Comment, error)
+	GetCommentsByPostIDs(ctx context.Context, postIDs []uuid.UUID, sort models.CommentSort, page models.PageRequest) (map[uuid.UUID]*models.CommentConnection, error)
+	GetCommentCounts(ctx context.Context, postIDs []uuid.UUID) (map[uuid.UUID]int32, error)
+	GetRepliesByCommentIDs(ctx context.Context, commentIDs []uuid.UUID, sort models.CommentSort, page models.PageRequest) (map[uuid.UUID]*models.CommentConnection, error)
+	GetCommentTree(ctx context.Context, postID uuid.UUID, maxDepth *int32, page *int32) ([]*models.CommentTreeNode, error)
+	EditComment(ctx context.Context, commReq models.CommentEditRequest) (*models.Comment, error)
+	DeleteComment(ctx context.Context, commentID uuid.UUID) (*models.Comment, error)
+	GetMissedComments(ctx context.Context, postID uuid.UUID, since *time.Time, lastCommentID *uuid.UUID) ([]*models.Comment, error)
+	SearchComments(ctx context.Context, query string, postID *uuid.UUID) ([]*models.CommentSearchResult, error)
+}
+
+type VoteService interface {
+	VotePost(ctx context.Context, postID uuid.UUID, value int32) (*models.Post, error)
+	VoteComment(ctx context.Context, commentID uuid.UUID, value int32) (*models.Comment, error)
+	GetPostVotes(ctx context.Context, postIDs []uuid.UUID, voter string) (map[uuid.UUID]int32, error)
+	GetCommentVotes(ctx context.Context, commentIDs []uuid.UUID, voter string) (map[uuid.UUID]int32, error)
+}
+
+type UserService interface {
+	Register(ctx context.Context, username, password string) (*models.AuthPayload, error)
+	Login(ctx context.Context, username, password string) (*models.AuthPayload, error)
+	RefreshToken(ctx context.Context, refreshToken string) (*models.AuthPayload, error)
+	Me(ctx context.Context) (*models.User, error)
 }
 
 type ViewerService interface {
-	CreateViewer(ctx context.Context, postId uuid.UUID) (int, chan *models.Comment, error)
+	CreateViewer(ctx context.Context, postId uuid.UUID) (int, chan models.PostEvent, error)
 	DeleteViewer(ctx context.Context, postId uuid.UUID, id int) error
-	NotifyViewers(ctx context.Context, postId uuid.UUID, comment models.Comment) error
+	NotifyViewers(ctx context.Context, postId uuid.UUID, event models.PostEvent) error
+	WatchActivity(ctx context.Context, postId uuid.UUID) (int, chan *models.PostActivity, error)
+	UnwatchActivity(ctx context.Context, postId uuid.UUID, id int) error
+}
+
+type NotificationService interface {
+	NotifyComment(ctx context.Context, comment *models.Comment) error
+	GetMyNotifications(ctx context.Context, page models.PageRequest) (*models.NotificationConnection, error)
+	MarkNotificationsRead(ctx context.Context, ids []uuid.UUID) (int32, error)
+}
+
+type InboxService interface {
+	Subscribe(ctx context.Context) (int, chan *models.Notification, error)
+	Unsubscribe(ctx context.Context, id int) error
+	Deliver(ctx context.Context, notification *models.Notification) error
+}
+
+type CommunityService interface {
+	CreateCommunity(ctx context.Context, req models.CommunityRequest) (*models.Community, error)
+	GetCommunityBySlug(ctx context.Context, slug string) (*models.Community, error)
+	GetCommunitiesByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.Community, error)
+	JoinCommunity(ctx context.Context, slug string) (*models.Community, error)
+	LeaveCommunity(ctx context.Context, slug string) (*models.Community, error)
+	GetMemberships(ctx context.Context, communityIDs []uuid.UUID, username string) (map[uuid.UUID]bool, error)
+}
+
+type ModerationService interface {
+	RemovePost(ctx context.Context, postID uuid.UUID, reason string) (*models.Post, error)
+	RemoveComment(ctx context.Context, commentID uuid.UUID, reason string) (*models.Comment, error)
+	SetUserRole(ctx context.Context, username string, role models.Role) (*models.User, error)
+	AddCommunityModerator(ctx context.Context, slug, username string) (*models.Community, error)
+	RemoveCommunityModerator(ctx context.Context, slug, username string) (*models.Community, error)
+	GetModerationLog(ctx context.Context, slug *string, pageReq models.PageRequest) (*models.ModerationActionConnection, error)
+}
+
+type ReportService interface {
+	ReportContent(ctx context.Context, targetType models.ReportTargetType, targetID uuid.UUID, reason models.ReportReason, note *string) (*models.Report, error)
+	GetReportQueue(ctx context.Context, status models.ReportStatus, pageReq models.PageRequest) (*models.ReportConnection, error)
+	ResolveReport(ctx context.Context, reportID uuid.UUID, action models.ReportAction) (*models.Report, error)
+}
+
+type RateLimitService interface {
+	Allow(ctx context.Context, operation string) error
 }
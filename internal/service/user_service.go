@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/nedokyrill/posts-service/internal/auth"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/internal/storage"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+	"github.com/nedokyrill/posts-service/pkg/logger"
+	"github.com/nedokyrill/posts-service/pkg/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// имя пользователя подставляется автором постов и комментариев, поэтому без пробелов и спецсимволов
+var usernamePattern = regexp.MustCompile(`^[\p{L}\p{N}_-]+$`)
+
+type UserServiceImpl struct {
+	store  storage.UserStorage
+	tokens *auth.TokenManager
+}
+
+func NewUserService(store storage.UserStorage, tokens *auth.TokenManager) *UserServiceImpl {
+	return &UserServiceImpl{
+		store:  store,
+		tokens: tokens,
+	}
+}
+
+func (s *UserServiceImpl) Register(ctx context.Context, username, password string) (*models.AuthPayload, error) {
+	if err := validateCredentials(username, password); err != nil {
+		return nil, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		logger.Logger.Error(fmt.Sprintf("error hashing password: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error registering user",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	user, err := s.store.CreateUser(ctx, models.User{Username: username, PasswordHash: string(hash),
+		Role: models.RoleUser})
+	if err != nil {
+		if errors.Is(err, storage.ErrAlreadyExists) {
+			return nil, utils.GqlError{
+				Msg:  fmt.Sprintf("username %s is already taken", username),
+				Type: consts.BadRequestType,
+			}
+		}
+		logger.Logger.Error(fmt.Sprintf("error creating user: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error registering user",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	logger.Logger.Info(fmt.Sprintf("register user with id: %s successfully", user.ID.String()))
+	return s.issueTokens(&user)
+}
+
+func (s *UserServiceImpl) Login(ctx context.Context, username, password string) (*models.AuthPayload, error) {
+	// одинаковая ошибка для неизвестного пользователя и неверного пароля, чтобы не раскрывать занятые имена
+	invalidCredentials := utils.GqlError{
+		Msg:  "invalid username or password",
+		Type: consts.UnauthorizedType,
+	}
+
+	user, err := s.store.GetUserByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, invalidCredentials
+		}
+		logger.Logger.Error(fmt.Sprintf("error getting user: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error logging in",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	if err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, invalidCredentials
+	}
+
+	logger.Logger.Info(fmt.Sprintf("login user with id: %s successfully", user.ID.String()))
+	return s.issueTokens(user)
+}
+
+func (s *UserServiceImpl) RefreshToken(ctx context.Context, refreshToken string) (*models.AuthPayload, error) {
+	invalidToken := utils.GqlError{
+		Msg:  "invalid or expired refresh token",
+		Type: consts.UnauthorizedType,
+	}
+
+	identity, err := s.tokens.ParseRefresh(refreshToken)
+	if err != nil {
+		return nil, invalidToken
+	}
+
+	// пользователь мог быть удален после выпуска токена
+	user, err := s.store.GetUserByID(ctx, identity.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, invalidToken
+		}
+		logger.Logger.Error(fmt.Sprintf("error getting user: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error refreshing token",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	return s.issueTokens(user)
+}
+
+func (s *UserServiceImpl) Me(ctx context.Context) (*models.User, error) {
+	identity, ok := auth.IdentityFrom(ctx)
+	if !ok {
+		return nil, nil
+	}
+
+	user, err := s.store.GetUserByID(ctx, identity.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		logger.Logger.Error(fmt.Sprintf("error getting user: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error getting user",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	return user, nil
+}
+
+func (s *UserServiceImpl) issueTokens(user *models.User) (*models.AuthPayload, error) {
+	access, refresh, err := s.tokens.Issue(auth.Identity{UserID: user.ID, Username: user.Username})
+	if err != nil {
+		logger.Logger.Error(fmt.Sprintf("error issuing tokens: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error issuing tokens",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	return &models.AuthPayload{AccessToken: access, RefreshToken: refresh, User: user}, nil
+}
+
+// пользователь запроса, для анонимного запроса - ошибка Unauthorized
+func requireIdentity(ctx context.Context) (auth.Identity, error) {
+	identity, ok := auth.IdentityFrom(ctx)
+	if !ok {
+		return auth.Identity{}, utils.GqlError{
+			Msg:  "authentication required",
+			Type: consts.UnauthorizedType,
+		}
+	}
+	return identity, nil
+}
+
+// имя пользователя запроса, у анонимного запроса - пустое. Скрытый фильтром контент виден только его автору
+func viewerName(ctx context.Context) string {
+	identity, _ := auth.IdentityFrom(ctx)
+	return identity.Username
+}
+
+func validateCredentials(username, password string) error {
+	if n := utf8.RuneCountInString(username); n < consts.UsernameMinLen || n > consts.UsernameMaxLen {
+		return utils.GqlError{
+			Msg: fmt.Sprintf("username must be between %d and %d characters", consts.UsernameMinLen,
+				consts.UsernameMaxLen),
+			Type: consts.BadRequestType,
+		}
+	}
+
+	if !usernamePattern.MatchString(username) {
+		return utils.GqlError{
+			Msg:  "username may contain only letters, digits, '_' and '-'",
+			Type: consts.BadRequestType,
+		}
+	}
+
+	if len(password) < consts.PasswordMinLen || len(password) > consts.PasswordMaxLen {
+		return utils.GqlError{
+			Msg: fmt.Sprintf("password must be between %d and %d bytes", consts.PasswordMinLen,
+				consts.PasswordMaxLen),
+			Type: consts.BadRequestType,
+		}
+	}
+	return nil
+}
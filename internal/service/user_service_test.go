@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/auth"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/internal/storage"
+	store_mock "github.com/nedokyrill/posts-service/internal/storage/mocks"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+	"github.com/nedokyrill/posts-service/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestUserService_Register(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	userStorage := store_mock.NewMockUserStorage(ctrl)
+	tokens := auth.NewTokenManager("secret", time.Minute, time.Hour)
+
+	userService := NewUserService(userStorage, tokens)
+
+	username := "test_user"
+	password := "test_password"
+
+	t.Run("successfully register user", func(t *testing.T) {
+		// Mock expectations
+		userStorage.EXPECT().
+			CreateUser(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, user models.User) (models.User, error) {
+				assert.Equal(t, username, user.Username)
+				assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)))
+				user.ID = uuid.New()
+				return user, nil
+			})
+
+		// Execute
+		result, err := userService.Register(ctx, username, password)
+
+		// Verify
+		require.NoError(t, err)
+		assert.Equal(t, username, result.User.Username)
+
+		identity, err := tokens.ParseAccess(result.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, result.User.ID, identity.UserID)
+		assert.Equal(t, username, identity.Username)
+	})
+
+	t.Run("fail when username is taken", func(t *testing.T) {
+		// Mock expectations
+		userStorage.EXPECT().
+			CreateUser(ctx, gomock.Any()).
+			Return(models.User{}, fmt.Errorf("user with username: %s: %w", username, storage.ErrAlreadyExists))
+
+		// Execute
+		result, err := userService.Register(ctx, username, password)
+
+		// Verify
+		assert.Nil(t, result)
+
+		var gqlErr utils.GqlError
+		require.ErrorAs(t, err, &gqlErr)
+		assert.Equal(t, "username test_user is already taken", gqlErr.Msg)
+		assert.Equal(t, consts.BadRequestType, gqlErr.Type)
+	})
+
+	t.Run("fail with invalid credentials", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			username string
+			password string
+		}{
+			{name: "short username", username: "ab", password: password},
+			{name: "username with spaces", username: "test user", password: password},
+			{name: "short password", username: username, password: "short"},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				// Execute
+				result, err := userService.Register(ctx, tc.username, tc.password)
+
+				// Verify
+				assert.Nil(t, result)
+
+				var gqlErr utils.GqlError
+				require.ErrorAs(t, err, &gqlErr)
+				assert.Equal(t, consts.BadRequestType, gqlErr.Type)
+			})
+		}
+	})
+}
+
+func TestUserService_Login(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	userStorage := store_mock.NewMockUserStorage(ctrl)
+	tokens := auth.NewTokenManager("secret", time.Minute, time.Hour)
+
+	userService := NewUserService(userStorage, tokens)
+
+	password := "test_password"
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+	user := &models.User{ID: uuid.New(), Username: "test_user", PasswordHash: string(hash)}
+
+	t.Run("successfully login", func(t *testing.T) {
+		// Mock expectations
+		userStorage.EXPECT().
+			GetUserByUsername(ctx, user.Username).
+			Return(user, nil)
+
+		// Execute
+		result, err := userService.Login(ctx, user.Username, password)
+
+		// Verify
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, result.User.ID)
+		assert.NotEmpty(t, result.AccessToken)
+		assert.NotEmpty(t, result.RefreshToken)
+	})
+
+	t.Run("fail with wrong password", func(t *testing.T) {
+		// Mock expectations
+		userStorage.EXPECT().
+			GetUserByUsername(ctx, user.Username).
+			Return(user, nil)
+
+		// Execute
+		result, err := userService.Login(ctx, user.Username, "wrong_password")
+
+		// Verify
+		assert.Nil(t, result)
+
+		var gqlErr utils.GqlError
+		require.ErrorAs(t, err, &gqlErr)
+		assert.Equal(t, "invalid username or password", gqlErr.Msg)
+		assert.Equal(t, consts.UnauthorizedType, gqlErr.Type)
+	})
+
+	t.Run("fail when user not found", func(t *testing.T) {
+		// Mock expectations
+		userStorage.EXPECT().
+			GetUserByUsername(ctx, "unknown_user").
+			Return(nil, sql.ErrNoRows)
+
+		// Execute
+		result, err := userService.Login(ctx, "unknown_user", password)
+
+		// Verify
+		assert.Nil(t, result)
+
+		var gqlErr utils.GqlError
+		require.ErrorAs(t, err, &gqlErr)
+		assert.Equal(t, "invalid username or password", gqlErr.Msg)
+		assert.Equal(t, consts.UnauthorizedType, gqlErr.Type)
+	})
+}
+
+func TestUserService_RefreshToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	userStorage := store_mock.NewMockUserStorage(ctrl)
+	tokens := auth.NewTokenManager("secret", time.Minute, time.Hour)
+
+	userService := NewUserService(userStorage, tokens)
+
+	user := &models.User{ID: uuid.New(), Username: "test_user"}
+	access, refresh, err := tokens.Issue(auth.Identity{UserID: user.ID, Username: user.Username})
+	require.NoError(t, err)
+
+	t.Run("successfully refresh tokens", func(t *testing.T) {
+		// Mock expectations
+		userStorage.EXPECT().
+			GetUserByID(ctx, user.ID).
+			Return(user, nil)
+
+		// Execute
+		result, err := userService.RefreshToken(ctx, refresh)
+
+		// Verify
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, result.User.ID)
+	})
+
+	t.Run("fail with access token", func(t *testing.T) {
+		// Execute
+		result, err := userService.RefreshToken(ctx, access)
+
+		// Verify
+		assert.Nil(t, result)
+
+		var gqlErr utils.GqlError
+		require.ErrorAs(t, err, &gqlErr)
+		assert.Equal(t, consts.UnauthorizedType, gqlErr.Type)
+	})
+
+	t.Run("fail when user was deleted", func(t *testing.T) {
+		// Mock expectations
+		userStorage.EXPECT().
+			GetUserByID(ctx, user.ID).
+			Return(nil, sql.ErrNoRows)
+
+		// Execute
+		result, err := userService.RefreshToken(ctx, refresh)
+
+		// Verify
+		assert.Nil(t, result)
+
+		var gqlErr utils.GqlError
+		require.ErrorAs(t, err, &gqlErr)
+		assert.Equal(t, consts.UnauthorizedType, gqlErr.Type)
+	})
+}
+
+func TestUserService_Me(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userStorage := store_mock.NewMockUserStorage(ctrl)
+
+	userService := NewUserService(userStorage, auth.NewTokenManager("secret", time.Minute, time.Hour))
+
+	t.Run("anonymous user", func(t *testing.T) {
+		// Execute
+		result, err := userService.Me(context.Background())
+
+		// Verify
+		require.NoError(t, err)
+		assert.Nil(t, result)
+	})
+
+	t.Run("authenticated user", func(t *testing.T) {
+		// Setup
+		user := &models.User{ID: uuid.New(), Username: "test_user"}
+		ctx := auth.WithIdentity(context.Background(), auth.Identity{UserID: user.ID, Username: user.Username})
+
+		// Mock expectations
+		userStorage.EXPECT().
+			GetUserByID(ctx, user.ID).
+			Return(user, nil)
+
+		// Execute
+		result, err := userService.Me(ctx)
+
+		// Verify
+		require.NoError(t, err)
+		assert.Equal(t, user, result)
+	})
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/internal/storage"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+	"github.com/nedokyrill/posts-service/pkg/logger"
+)
+
+const (
+	commentEventType        = "comment"
+	commentsStatusEventType = "comments_status"
+)
+
+// notification - событие поста в payload NOTIFY
+type notification struct {
+	Type      string                 `json:"type"`
+	PostID    uuid.UUID              `json:"postId"`
+	Comment   *models.Comment        `json:"comment,omitempty"`
+	CommentID *uuid.UUID             `json:"commentId,omitempty"` // вместо comment, если комментарий не влезает в payload
+	Status    *models.CommentsStatus `json:"status,omitempty"`
+}
+
+// ViewerServicePg рассылает события постов через postgres NOTIFY, поэтому их получают подписчики всех инстансов
+// приложения. Каждый инстанс слушает канал через LISTEN и отдает события своим локальным подписчикам
+type ViewerServicePg struct {
+	pool      *pgxpool.Pool
+	commStore storage.CommentStorage
+	local     *ViewerServiceImpl
+}
+
+func NewViewerServicePg(pool *pgxpool.Pool, commStore storage.CommentStorage, local *ViewerServiceImpl) *ViewerServicePg {
+	return &ViewerServicePg{
+		pool:      pool,
+		commStore: commStore,
+		local:     local,
+	}
+}
+
+func (s *ViewerServicePg) CreateViewer(ctx context.Context, postId uuid.UUID) (int, chan models.PostEvent, error) {
+	return s.local.CreateViewer(ctx, postId)
+}
+
+func (s *ViewerServicePg) DeleteViewer(ctx context.Context, postId uuid.UUID, id int) error {
+	return s.local.DeleteViewer(ctx, postId, id)
+}
+
+// читатели поста считаются по подпискам этого инстанса, изменение количества комментариев
+// подписчики получают вместе с событием нового комментария через LISTEN
+func (s *ViewerServicePg) WatchActivity(ctx context.Context, postId uuid.UUID) (int, chan *models.PostActivity, error) {
+	return s.local.WatchActivity(ctx, postId)
+}
+
+func (s *ViewerServicePg) UnwatchActivity(ctx context.Context, postId uuid.UUID, id int) error {
+	return s.local.UnwatchActivity(ctx, postId, id)
+}
+
+// публикуем событие в канал, локальные подписчики получат его вместе с остальными через LISTEN
+func (s *ViewerServicePg) NotifyViewers(ctx context.Context, postId uuid.UUID, event models.PostEvent) error {
+	payload, err := encodeNotification(postId, event)
+	if err == nil {
+		_, err = s.pool.Exec(ctx, "SELECT pg_notify($1, $2)", consts.PostEventsChannel, payload)
+	}
+	if err != nil {
+		// хотя бы подписчики этого инстанса получат событие
+		logger.Logger.Error(fmt.Sprintf("error publishing event for postId %s: %v", postId.String(), err))
+		return s.local.NotifyViewers(ctx, postId, event)
+	}
+
+	return nil
+}
+
+// Listen слушает канал событий до отмены ctx и отдает события локальным подписчикам
+func (s *ViewerServicePg) Listen(ctx context.Context) {
+	listenChannel(ctx, s.pool, consts.PostEventsChannel, func(ctx context.Context, payload string) {
+		postId, event, err := s.decodeNotification(ctx, payload)
+		if err != nil {
+			logger.Logger.Error(fmt.Sprintf("error decoding notification: %v", err))
+			return
+		}
+		_ = s.local.NotifyViewers(ctx, postId, event)
+	})
+}
+
+func encodeNotification(postId uuid.UUID, event models.PostEvent) (string, error) {
+	n := notification{PostID: postId}
+	switch e := event.(type) {
+	case *models.Comment:
+		n.Type = commentEventType
+		n.Comment = e
+	case *models.CommentsStatus:
+		n.Type = commentsStatusEventType
+		n.Status = e
+	default:
+		return "", fmt.Errorf("unknown post event %T", event)
+	}
+
+	payload, err := json.Marshal(n)
+	if err != nil {
+		return "", err
+	}
+
+	// payload NOTIFY ограничен 8000 байт, длинный комментарий подписчики загрузят по id
+	if len(payload) > consts.MaxNotifyPayload && n.Comment != nil {
+		n.CommentID, n.Comment = &n.Comment.ID, nil
+		if payload, err = json.Marshal(n); err != nil {
+			return "", err
+		}
+	}
+	return string(payload), nil
+}
+
+func (s *ViewerServicePg) decodeNotification(ctx context.Context, payload string) (uuid.UUID, models.PostEvent, error) {
+	var n notification
+	if err := json.Unmarshal([]byte(payload), &n); err != nil {
+		return uuid.Nil, nil, err
+	}
+
+	switch {
+	case n.Type == commentEventType && n.Comment != nil:
+		return n.PostID, n.Comment, nil
+	case n.Type == commentEventType && n.CommentID != nil:
+		comment, err := s.commStore.GetCommentByID(ctx, *n.CommentID)
+		if err != nil {
+			return uuid.Nil, nil, err
+		}
+		return n.PostID, comment, nil
+	case n.Type == commentsStatusEventType && n.Status != nil:
+		return n.PostID, n.Status, nil
+	default:
+		return uuid.Nil, nil, fmt.Errorf("unknown notification type %q", n.Type)
+	}
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/models"
+	store_mock "github.com/nedokyrill/posts-service/internal/storage/mocks"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestViewerServicePg_Notification(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	commentStorage := store_mock.NewMockCommentStorage(ctrl)
+
+	viewerService := NewViewerServicePg(nil, commentStorage, nil)
+
+	postID := uuid.New()
+	now := time.Now().UTC()
+
+	t.Run("comment", func(t *testing.T) {
+		comment := &models.Comment{ID: uuid.New(), PostID: postID, Author: "test_author", Content: "test content",
+			CreatedAt: &now}
+
+		payload, err := encodeNotification(postID, comment)
+		require.NoError(t, err)
+
+		gotPostID, event, err := viewerService.decodeNotification(ctx, payload)
+		require.NoError(t, err)
+		assert.Equal(t, postID, gotPostID)
+		assert.Equal(t, comment, event)
+	})
+
+	t.Run("comments status", func(t *testing.T) {
+		status := &models.CommentsStatus{PostID: postID, IsCommentsAllowed: false, ChangedAt: &now}
+
+		payload, err := encodeNotification(postID, status)
+		require.NoError(t, err)
+
+		_, event, err := viewerService.decodeNotification(ctx, payload)
+		require.NoError(t, err)
+		assert.Equal(t, status, event)
+	})
+
+	t.Run("long comment is loaded by id", func(t *testing.T) {
+		// 2000 символов по 4 байта не влезают в payload NOTIFY
+		comment := &models.Comment{ID: uuid.New(), PostID: postID, Content: strings.Repeat("😀", consts.ContentMaxLen)}
+
+		payload, err := encodeNotification(postID, comment)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(payload), consts.MaxNotifyPayload)
+
+		commentStorage.EXPECT().
+			GetCommentByID(ctx, comment.ID).
+			Return(comment, nil)
+
+		_, event, err := viewerService.decodeNotification(ctx, payload)
+		require.NoError(t, err)
+		assert.Equal(t, comment, event)
+	})
+
+	t.Run("fail with unknown notification", func(t *testing.T) {
+		_, _, err := viewerService.decodeNotification(ctx, `{"type":"unknown"}`)
+		assert.Error(t, err)
+	})
+}
@@ -12,81 +12,305 @@ import (
 	"github.com/nedokyrill/posts-service/pkg/utils"
 )
 
+// OverflowPolicy - что делать с событием, если буфер подписчика заполнен (клиент не успевает читать)
+type OverflowPolicy int
+
+const (
+	OverflowDropOldest OverflowPolicy = iota // выкинуть самое старое событие из буфера
+	OverflowDropNewest                       // не доставлять новое событие
+	OverflowDisconnect                       // закрыть подписку клиента
+)
+
+func ParseOverflowPolicy(policy string) (OverflowPolicy, error) {
+	switch policy {
+	case "", "drop_oldest":
+		return OverflowDropOldest, nil
+	case "drop_newest":
+		return OverflowDropNewest, nil
+	case "disconnect":
+		return OverflowDisconnect, nil
+	default:
+		return 0, fmt.Errorf("unknown overflow policy: %s", policy)
+	}
+}
+
 type Viewer struct {
-	ch chan *models.Comment
-	id int
+	ch     chan models.PostEvent
+	id     int
+	userID uuid.UUID
+}
+
+// подписчик на активность поста
+type activityViewer struct {
+	ch     chan *models.PostActivity
+	id     int
+	userID uuid.UUID
 }
 
 type ViewerServiceImpl struct {
-	viewers map[uuid.UUID][]Viewer
-	cnt     int
-	mu      sync.Mutex
+	viewers    map[uuid.UUID][]Viewer
+	activity   map[uuid.UUID][]activityViewer
+	perUser    map[uuid.UUID]int // количество открытых подписок пользователя
+	maxPerUser int
+	bufferSize int
+	policy     OverflowPolicy
+	cnt        int
+	mu         sync.Mutex
 }
 
-func NewViewerService() *ViewerServiceImpl {
+func NewViewerService(maxPerUser, bufferSize int, policy OverflowPolicy) *ViewerServiceImpl {
 	return &ViewerServiceImpl{
-		viewers: make(map[uuid.UUID][]Viewer),
-		cnt:     0,
-		mu:      sync.Mutex{},
+		viewers:    make(map[uuid.UUID][]Viewer),
+		activity:   make(map[uuid.UUID][]activityViewer),
+		perUser:    make(map[uuid.UUID]int),
+		maxPerUser: maxPerUser,
+		bufferSize: bufferSize,
+		policy:     policy,
+		cnt:        0,
+		mu:         sync.Mutex{},
 	}
 }
 
-// добавляем подписчика
-func (s *ViewerServiceImpl) CreateViewer(_ context.Context, postId uuid.UUID) (int, chan *models.Comment, error) {
+// добавляем подписчика, подписываться могут только аутентифицированные пользователи
+func (s *ViewerServiceImpl) CreateViewer(ctx context.Context, postId uuid.UUID) (int, chan models.PostEvent, error) {
+	identity, err := requireIdentity(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	comm := make(chan *models.Comment)
-	s.viewers[postId] = append(s.viewers[postId], Viewer{ch: comm, id: s.cnt})
+	if err = s.checkLimit(identity.UserID); err != nil {
+		return 0, nil, err
+	}
+
+	id := s.cnt
+	events := make(chan models.PostEvent, s.bufferSize)
+	s.viewers[postId] = append(s.viewers[postId], Viewer{ch: events, id: id, userID: identity.UserID})
+	s.perUser[identity.UserID]++
 	s.cnt++
+	s.notifyActivity(postId)
 
 	logger.Logger.Infof("create viewer for post id %s", postId.String())
-	return s.cnt, comm, nil
+	return id, events, nil
 }
 
-// удаляем подписчика из пула при закрытии подписки
+// удаляем подписчика из пула при закрытии подписки. Подписчик мог быть уже отключен
+// при переполнении буфера, тогда удалять нечего
 func (s *ViewerServiceImpl) DeleteViewer(_ context.Context, postId uuid.UUID, id int) error {
 	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	viewers, ok := s.viewers[postId]
-	if !ok {
-		s.mu.Unlock()
-		logger.Logger.Error(fmt.Sprintf("no post with postId: %s", postId.String()))
-		return utils.GqlError{Msg: fmt.Sprintf("no post with postId: %s", postId.String()),
-			Type: consts.BadRequestType}
-	}
-	for i, viewer := range viewers {
+	for i, viewer := range s.viewers[postId] {
 		if viewer.id == id {
-			s.viewers[postId] = append(viewers[:i], viewers[i+1:]...)
-			close(viewer.ch)
-			break
+			s.removeViewer(postId, i)
+			logger.Logger.Infof("delete viewer for post id %s", postId.String())
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// отправляем уведомление о событии (новый комментарий, изменение статуса комментариев) всем подписчикам.
+// Отправка неблокирующая, поэтому выполняется под мьютексом: медленный клиент не задерживает остальных,
+// а канал не может быть закрыт в DeleteViewer во время отправки
+func (s *ViewerServiceImpl) NotifyViewers(_ context.Context, postId uuid.UUID, event models.PostEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	viewers := s.viewers[postId]
+	for i := len(viewers) - 1; i >= 0; i-- { // с конца, т.к. отключенные подписчики удаляются из слайса
+		if !s.send(viewers[i], event) {
+			logger.Logger.Warnf("disconnect slow viewer %d for post id %s", viewers[i].id, postId.String())
+			s.removeViewer(postId, i)
 		}
 	}
 
-	s.mu.Unlock()
+	if _, ok := event.(*models.Comment); ok { // изменилось количество комментариев
+		s.notifyActivity(postId)
+	}
 
-	logger.Logger.Infof("delete viewer for post id %s", postId.String())
+	logger.Logger.Info(fmt.Sprintf("notify viewers for postId %s successfully", postId.String()))
 	return nil
 }
 
-// отправляем уведомление в виде комментария всем подписчикам
-func (s *ViewerServiceImpl) NotifyViewers(_ context.Context, postId uuid.UUID, comm models.Comment) error {
+// подписываемся на активность поста, первым событием приходит текущее состояние. Подписка считается в лимит
+// подписок пользователя, но в число читателей поста не входит
+func (s *ViewerServiceImpl) WatchActivity(ctx context.Context, postId uuid.UUID) (int, chan *models.PostActivity, error) {
+	identity, err := requireIdentity(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+
 	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	viewers, ok := s.viewers[postId]
-	if !ok { // подписчиков на данный пост нет
-		s.mu.Unlock()
+	if err = s.checkLimit(identity.UserID); err != nil {
+		return 0, nil, err
+	}
+
+	id := s.cnt
+	events := make(chan *models.PostActivity, s.bufferSize)
+	pushLatest(events, s.activityOf(postId))
+	s.activity[postId] = append(s.activity[postId], activityViewer{ch: events, id: id, userID: identity.UserID})
+	s.perUser[identity.UserID]++
+	s.cnt++
+
+	logger.Logger.Infof("create activity viewer for post id %s", postId.String())
+	return id, events, nil
+}
+
+func (s *ViewerServiceImpl) UnwatchActivity(_ context.Context, postId uuid.UUID, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	viewers := s.activity[postId]
+	for i, viewer := range viewers {
+		if viewer.id != id {
+			continue
+		}
+
+		s.activity[postId] = append(viewers[:i], viewers[i+1:]...)
+		if len(s.activity[postId]) == 0 {
+			delete(s.activity, postId)
+		}
+		s.releaseLimit(viewer.userID)
+		close(viewer.ch)
+
+		logger.Logger.Infof("delete activity viewer for post id %s", postId.String())
 		return nil
 	}
 
-	snap := make([]Viewer, len(viewers))
-	copy(snap, viewers)
-	s.mu.Unlock() // сделали копию каналов и разлочили мьютекс, чтобы далее не блокировать данные
+	return nil
+}
 
-	for _, v := range snap {
-		v.ch <- &comm
+// рассылка текущей активности поста под мьютексом. Важно только последнее состояние,
+// поэтому при переполнении буфера старые события выкидываются независимо от политики
+func (s *ViewerServiceImpl) notifyActivity(postId uuid.UUID) {
+	if len(s.activity[postId]) == 0 {
+		return
 	}
 
-	logger.Logger.Info(fmt.Sprintf("notify viewers for postId %s successfully", postId.String()))
+	event := s.activityOf(postId)
+	for _, viewer := range s.activity[postId] {
+		pushLatest(viewer.ch, event)
+	}
+}
+
+// читатели поста - разные пользователи с открытой подпиской SubOnPost (несколько вкладок считаются за одного)
+func (s *ViewerServiceImpl) activityOf(postId uuid.UUID) *models.PostActivity {
+	users := make(map[uuid.UUID]struct{}, len(s.viewers[postId]))
+	for _, viewer := range s.viewers[postId] {
+		users[viewer.userID] = struct{}{}
+	}
+	return &models.PostActivity{PostID: postId, Viewers: int32(len(users))}
+}
+
+// проверка лимита подписок пользователя под мьютексом
+func (s *ViewerServiceImpl) checkLimit(userID uuid.UUID) error {
+	if s.perUser[userID] >= s.maxPerUser {
+		return utils.GqlError{
+			Msg:  fmt.Sprintf("subscriptions limit of %d per user is reached", s.maxPerUser),
+			Type: consts.TooManyRequestsType,
+		}
+	}
 	return nil
 }
+
+func (s *ViewerServiceImpl) releaseLimit(userID uuid.UUID) {
+	s.perUser[userID]--
+	if s.perUser[userID] == 0 {
+		delete(s.perUser, userID)
+	}
+}
+
+// кладем событие в буфер подписчика, false - подписчика нужно отключить
+func (s *ViewerServiceImpl) send(viewer Viewer, event models.PostEvent) bool {
+	select {
+	case viewer.ch <- event:
+		return true
+	default:
+	}
+
+	switch s.policy {
+	case OverflowDropNewest:
+		return true
+	case OverflowDisconnect:
+		return false
+	default:
+		pushLatest(viewer.ch, event)
+		return true
+	}
+}
+
+// кладем событие в канал, выкидывая самое старое событие, если буфер заполнен
+func pushLatest[T any](ch chan T, event T) {
+	select {
+	case ch <- event:
+		return
+	default:
+	}
+
+	select {
+	case <-ch:
+	default: // клиент успел прочитать буфер
+	}
+	select {
+	case ch <- event:
+	default:
+	}
+}
+
+// удаление подписчика под мьютексом
+func (s *ViewerServiceImpl) removeViewer(postId uuid.UUID, i int) {
+	viewers := s.viewers[postId]
+	viewer := viewers[i]
+
+	s.viewers[postId] = append(viewers[:i], viewers[i+1:]...)
+	if len(s.viewers[postId]) == 0 {
+		delete(s.viewers, postId)
+	}
+
+	s.releaseLimit(viewer.userID)
+	close(viewer.ch)
+	s.notifyActivity(postId)
+}
+
+// ReplayEvents отдает сначала пропущенные комментарии missed, затем события live. Подписка в live оформляется до
+// загрузки missed, поэтому комментарии, созданные между ними, приходят дважды и отдаются один раз
+func ReplayEvents(ctx context.Context, missed []*models.Comment, live <-chan models.PostEvent) <-chan models.PostEvent {
+	out := make(chan models.PostEvent)
+
+	go func() {
+		defer close(out)
+
+		replayed := make(map[uuid.UUID]struct{}, len(missed))
+		for _, comment := range missed {
+			replayed[comment.ID] = struct{}{}
+
+			select {
+			case out <- comment:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		for event := range live {
+			if comment, ok := event.(*models.Comment); ok {
+				if _, ok = replayed[comment.ID]; ok {
+					continue
+				}
+			}
+
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+	"github.com/nedokyrill/posts-service/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestViewerService_CreateViewer(t *testing.T) {
+	postID := uuid.New()
+
+	t.Run("fail when user is not authenticated", func(t *testing.T) {
+		viewerService := NewViewerService(2, consts.ViewerBufferSize, OverflowDropOldest)
+
+		_, ch, err := viewerService.CreateViewer(context.Background(), postID)
+
+		assert.Nil(t, ch)
+
+		var gqlErr utils.GqlError
+		require.ErrorAs(t, err, &gqlErr)
+		assert.Equal(t, consts.UnauthorizedType, gqlErr.Type)
+	})
+
+	t.Run("fail when subscriptions limit is reached", func(t *testing.T) {
+		viewerService := NewViewerService(2, consts.ViewerBufferSize, OverflowDropOldest)
+		ctx := withUser("test_user")
+
+		for range 2 {
+			_, _, err := viewerService.CreateViewer(ctx, postID)
+			require.NoError(t, err)
+		}
+
+		_, _, err := viewerService.CreateViewer(ctx, uuid.New())
+
+		var gqlErr utils.GqlError
+		require.ErrorAs(t, err, &gqlErr)
+		assert.Equal(t, consts.TooManyRequestsType, gqlErr.Type)
+
+		// лимит считается для каждого пользователя отдельно
+		_, _, err = viewerService.CreateViewer(withUser("another_user"), postID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("deleted viewer frees the limit", func(t *testing.T) {
+		viewerService := NewViewerService(1, consts.ViewerBufferSize, OverflowDropOldest)
+		ctx := withUser("test_user")
+
+		id, ch, err := viewerService.CreateViewer(ctx, postID)
+		require.NoError(t, err)
+
+		require.NoError(t, viewerService.DeleteViewer(context.Background(), postID, id))
+		_, ok := <-ch
+		assert.False(t, ok)
+
+		_, _, err = viewerService.CreateViewer(ctx, postID)
+		assert.NoError(t, err)
+	})
+}
+
+func TestViewerService_DeleteViewer(t *testing.T) {
+	ctx := withUser("test_user")
+	postID := uuid.New()
+	viewerService := NewViewerService(consts.MaxSubscriptionsPerUser, consts.ViewerBufferSize, OverflowDropOldest)
+
+	firstID, first, err := viewerService.CreateViewer(ctx, postID)
+	require.NoError(t, err)
+	secondID, second, err := viewerService.CreateViewer(ctx, postID)
+	require.NoError(t, err)
+
+	// удаляется именно тот подписчик, id которого вернул CreateViewer
+	require.NoError(t, viewerService.DeleteViewer(context.Background(), postID, firstID))
+	_, ok := <-first
+	assert.False(t, ok)
+
+	event := &models.CommentsStatus{PostID: postID}
+	go func() {
+		_ = viewerService.NotifyViewers(context.Background(), postID, event)
+	}()
+	assert.Equal(t, models.PostEvent(event), <-second)
+
+	require.NoError(t, viewerService.DeleteViewer(context.Background(), postID, secondID))
+}
+
+func TestViewerService_NotifyViewers(t *testing.T) {
+	postID := uuid.New()
+	events := make([]models.PostEvent, 3)
+	for i := range events {
+		events[i] = &models.Comment{ID: uuid.New(), PostID: postID}
+	}
+
+	// буфер на 2 события, клиент ничего не читает
+	notifyAll := func(t *testing.T, policy OverflowPolicy) chan models.PostEvent {
+		viewerService := NewViewerService(consts.MaxSubscriptionsPerUser, 2, policy)
+
+		_, ch, err := viewerService.CreateViewer(withUser("test_user"), postID)
+		require.NoError(t, err)
+
+		for _, event := range events {
+			require.NoError(t, viewerService.NotifyViewers(context.Background(), postID, event))
+		}
+		return ch
+	}
+
+	received := func(ch chan models.PostEvent) []models.PostEvent {
+		var res []models.PostEvent
+		for len(ch) > 0 {
+			res = append(res, <-ch)
+		}
+		return res
+	}
+
+	t.Run("drop oldest", func(t *testing.T) {
+		ch := notifyAll(t, OverflowDropOldest)
+		assert.Equal(t, events[1:], received(ch))
+	})
+
+	t.Run("drop newest", func(t *testing.T) {
+		ch := notifyAll(t, OverflowDropNewest)
+		assert.Equal(t, events[:2], received(ch))
+	})
+
+	t.Run("disconnect", func(t *testing.T) {
+		ch := notifyAll(t, OverflowDisconnect)
+		assert.Equal(t, events[:2], received(ch))
+
+		_, ok := <-ch
+		assert.False(t, ok)
+	})
+
+	t.Run("slow viewer does not block others", func(t *testing.T) {
+		viewerService := NewViewerService(consts.MaxSubscriptionsPerUser, 1, OverflowDropNewest)
+
+		_, slow, err := viewerService.CreateViewer(withUser("slow_user"), postID)
+		require.NoError(t, err)
+		_, fast, err := viewerService.CreateViewer(withUser("fast_user"), postID)
+		require.NoError(t, err)
+
+		for _, event := range events {
+			require.NoError(t, viewerService.NotifyViewers(context.Background(), postID, event))
+			assert.Equal(t, event, <-fast)
+		}
+		assert.Len(t, slow, 1)
+	})
+}
+
+func TestViewerService_WatchActivity(t *testing.T) {
+	postID := uuid.New()
+	activity := func(viewers int32) *models.PostActivity {
+		return &models.PostActivity{PostID: postID, Viewers: viewers}
+	}
+
+	t.Run("fail when user is not authenticated", func(t *testing.T) {
+		viewerService := NewViewerService(consts.MaxSubscriptionsPerUser, consts.ViewerBufferSize, OverflowDropOldest)
+
+		_, ch, err := viewerService.WatchActivity(context.Background(), postID)
+
+		assert.Nil(t, ch)
+
+		var gqlErr utils.GqlError
+		require.ErrorAs(t, err, &gqlErr)
+		assert.Equal(t, consts.UnauthorizedType, gqlErr.Type)
+	})
+
+	t.Run("viewers join and leave", func(t *testing.T) {
+		viewerService := NewViewerService(consts.MaxSubscriptionsPerUser, consts.ViewerBufferSize, OverflowDropOldest)
+
+		watchID, ch, err := viewerService.WatchActivity(withUser("watcher"), postID)
+		require.NoError(t, err)
+		assert.Equal(t, activity(0), <-ch)
+
+		userCtx := withUser("test_user")
+		firstID, _, err := viewerService.CreateViewer(userCtx, postID)
+		require.NoError(t, err)
+		assert.Equal(t, activity(1), <-ch)
+
+		// вторая вкладка того же пользователя
+		secondID, _, err := viewerService.CreateViewer(userCtx, postID)
+		require.NoError(t, err)
+		assert.Equal(t, activity(1), <-ch)
+
+		// читатели другого поста не учитываются
+		_, _, err = viewerService.CreateViewer(withUser("another_user"), uuid.New())
+		require.NoError(t, err)
+		assert.Empty(t, ch)
+
+		require.NoError(t, viewerService.NotifyViewers(context.Background(), postID, &models.Comment{PostID: postID}))
+		assert.Equal(t, activity(1), <-ch)
+
+		require.NoError(t, viewerService.NotifyViewers(context.Background(), postID, &models.CommentsStatus{}))
+		assert.Empty(t, ch)
+
+		require.NoError(t, viewerService.DeleteViewer(context.Background(), postID, firstID))
+		assert.Equal(t, activity(1), <-ch)
+		require.NoError(t, viewerService.DeleteViewer(context.Background(), postID, secondID))
+		assert.Equal(t, activity(0), <-ch)
+
+		require.NoError(t, viewerService.UnwatchActivity(context.Background(), postID, watchID))
+		_, ok := <-ch
+		assert.False(t, ok)
+		assert.Len(t, viewerService.perUser, 1) // осталась только подписка another_user
+	})
+
+	t.Run("slow watcher gets the latest state", func(t *testing.T) {
+		viewerService := NewViewerService(consts.MaxSubscriptionsPerUser, 1, OverflowDropNewest)
+
+		_, ch, err := viewerService.WatchActivity(withUser("watcher"), postID)
+		require.NoError(t, err)
+
+		for range 3 {
+			_, _, err = viewerService.CreateViewer(withUser(uuid.NewString()), postID)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, activity(3), <-ch)
+	})
+
+	t.Run("fail when subscriptions limit is reached", func(t *testing.T) {
+		viewerService := NewViewerService(1, consts.ViewerBufferSize, OverflowDropOldest)
+		ctx := withUser("test_user")
+
+		_, _, err := viewerService.CreateViewer(ctx, postID)
+		require.NoError(t, err)
+
+		_, _, err = viewerService.WatchActivity(ctx, postID)
+
+		var gqlErr utils.GqlError
+		require.ErrorAs(t, err, &gqlErr)
+		assert.Equal(t, consts.TooManyRequestsType, gqlErr.Type)
+	})
+}
+
+// запускать с -race: одновременные подписки, отписки и уведомления
+func TestViewerService_Concurrent(t *testing.T) {
+	postID := uuid.New()
+
+	for _, policy := range []OverflowPolicy{OverflowDropOldest, OverflowDropNewest, OverflowDisconnect} {
+		viewerService := NewViewerService(consts.MaxSubscriptionsPerUser, 4, policy)
+
+		var wg sync.WaitGroup
+		for i := range 20 {
+			wg.Add(2)
+
+			go func() {
+				defer wg.Done()
+
+				ctx := withUser(fmt.Sprintf("user_%d", i))
+				for range 5 {
+					id, ch, err := viewerService.CreateViewer(ctx, postID)
+					if !assert.NoError(t, err) {
+						return
+					}
+
+					// читаем часть событий и отписываемся, не дочитав канал
+					for range i % 3 {
+						select {
+						case <-ch:
+						default:
+						}
+					}
+					assert.NoError(t, viewerService.DeleteViewer(context.Background(), postID, id))
+				}
+			}()
+
+			go func() {
+				defer wg.Done()
+
+				for range 50 {
+					assert.NoError(t, viewerService.NotifyViewers(context.Background(), postID, &models.Comment{}))
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Empty(t, viewerService.viewers)
+		assert.Empty(t, viewerService.perUser)
+	}
+}
+
+func TestReplayEvents(t *testing.T) {
+	postID := uuid.New()
+	missed := []*models.Comment{{ID: uuid.New(), PostID: postID}, {ID: uuid.New(), PostID: postID}}
+	fresh := &models.Comment{ID: uuid.New(), PostID: postID}
+	status := &models.CommentsStatus{PostID: postID}
+
+	// второй пропущенный комментарий создан уже после подписки и приходит еще и в live
+	live := make(chan models.PostEvent, 3)
+	live <- missed[1]
+	live <- fresh
+	live <- status
+	close(live)
+
+	var received []models.PostEvent
+	for event := range ReplayEvents(context.Background(), missed, live) {
+		received = append(received, event)
+	}
+
+	assert.Equal(t, []models.PostEvent{missed[0], missed[1], fresh, status}, received)
+}
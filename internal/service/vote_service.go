@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/internal/storage"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+	"github.com/nedokyrill/posts-service/pkg/logger"
+	"github.com/nedokyrill/posts-service/pkg/utils"
+)
+
+type VoteServiceImpl struct {
+	voteStore storage.VoteStorage
+	postStore storage.PostStorage
+	commStore storage.CommentStorage
+}
+
+func NewVoteService(voteStore storage.VoteStorage, postStore storage.PostStorage,
+	commStore storage.CommentStorage) *VoteServiceImpl {
+	return &VoteServiceImpl{
+		voteStore: voteStore,
+		postStore: postStore,
+		commStore: commStore,
+	}
+}
+
+func (s *VoteServiceImpl) VotePost(ctx context.Context, postID uuid.UUID, value int32) (*models.Post, error) {
+	voter, err := validateVote(ctx, value)
+	if err != nil {
+		return nil, err
+	}
+
+	notFound := utils.GqlError{
+		Msg:  fmt.Sprintf("post with id: %s does not exist", postID.String()),
+		Type: consts.BadRequestType,
+	}
+
+	// за скрытый пост голосовать нельзя, как и комментировать его
+	existing, err := s.postStore.GetPostByID(ctx, postID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, notFound
+		}
+		logger.Logger.Error(fmt.Sprintf("error getting post: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error voting for post",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	if existing.IsRemoved || !existing.VisibleTo(voter) {
+		return nil, notFound
+	}
+
+	post, err := s.voteStore.VotePost(ctx, postID, voter, int(value))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, notFound
+		}
+		logger.Logger.Error(fmt.Sprintf("error voting for post: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error voting for post",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	logger.Logger.Info(fmt.Sprintf("vote for post with id: %s successfully", postID.String()))
+	return &post, nil
+}
+
+func (s *VoteServiceImpl) VoteComment(ctx context.Context, commentID uuid.UUID,
+	value int32) (*models.Comment, error) {
+	voter, err := validateVote(ctx, value)
+	if err != nil {
+		return nil, err
+	}
+
+	notFound := utils.GqlError{
+		Msg:  fmt.Sprintf("comment with id: %s not found", commentID.String()),
+		Type: consts.BadRequestType,
+	}
+
+	existing, err := s.commStore.GetCommentByID(ctx, commentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, notFound
+		}
+		logger.Logger.Error(fmt.Sprintf("error getting comment: %v", err))
+		return nil, utils.GqlError{
+			Msg:  fmt.Sprintf("error getting comment with id: %s", commentID.String()),
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	if existing.IsRemoved || !existing.VisibleTo(voter) {
+		return nil, notFound
+	}
+
+	if existing.IsDeleted {
+		return nil, utils.GqlError{
+			Msg:  fmt.Sprintf("comment with id: %s is deleted", commentID.String()),
+			Type: consts.BadRequestType,
+		}
+	}
+
+	comment, err := s.voteStore.VoteComment(ctx, commentID, voter, int(value))
+	if err != nil {
+		logger.Logger.Error(fmt.Sprintf("error voting for comment: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error voting for comment",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	logger.Logger.Info(fmt.Sprintf("vote for comment with id: %s successfully", commentID.String()))
+	return &comment, nil
+}
+
+func (s *VoteServiceImpl) GetPostVotes(ctx context.Context, postIDs []uuid.UUID,
+	voter string) (map[uuid.UUID]int32, error) {
+	votes, err := s.voteStore.GetPostVotes(ctx, postIDs, voter)
+	if err != nil {
+		logger.Logger.Error(fmt.Sprintf("error getting votes: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error getting votes",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	return toVotes(votes), nil
+}
+
+func (s *VoteServiceImpl) GetCommentVotes(ctx context.Context, commentIDs []uuid.UUID,
+	voter string) (map[uuid.UUID]int32, error) {
+	votes, err := s.voteStore.GetCommentVotes(ctx, commentIDs, voter)
+	if err != nil {
+		logger.Logger.Error(fmt.Sprintf("error getting votes: %v", err))
+		return nil, utils.GqlError{
+			Msg:  "error getting votes",
+			Type: consts.InternalServerErrorType,
+		}
+	}
+
+	return toVotes(votes), nil
+}
+
+// голосующий - текущий пользователь, возвращаем его имя
+func validateVote(ctx context.Context, value int32) (string, error) {
+	identity, err := requireIdentity(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if value < -1 || value > 1 {
+		return "", utils.GqlError{
+			Msg:  "vote value must be -1, 0 or 1",
+			Type: consts.BadRequestType,
+		}
+	}
+	return identity.Username, nil
+}
+
+func toVotes(votes map[uuid.UUID]int) map[uuid.UUID]int32 {
+	res := make(map[uuid.UUID]int32, len(votes))
+	for id, value := range votes {
+		res[id] = int32(value)
+	}
+	return res
+}
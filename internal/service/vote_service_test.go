@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/models"
+	store_mock "github.com/nedokyrill/posts-service/internal/storage/mocks"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+	"github.com/nedokyrill/posts-service/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVoteService_VotePost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	voter := "test_voter"
+	ctx := withUser(voter)
+	voteStorage := store_mock.NewMockVoteStorage(ctrl)
+	postStorage := store_mock.NewMockPostStorage(ctrl)
+	commentStorage := store_mock.NewMockCommentStorage(ctrl)
+
+	voteService := NewVoteService(voteStorage, postStorage, commentStorage)
+
+	postID := uuid.New()
+
+	t.Run("successfully vote for post", func(t *testing.T) {
+		// Mock expectations
+		postStorage.EXPECT().
+			GetPostByID(ctx, postID).
+			Return(&models.Post{ID: postID}, nil)
+
+		voteStorage.EXPECT().
+			VotePost(ctx, postID, voter, 1).
+			Return(models.Post{ID: postID, Upvotes: 3, Downvotes: 1}, nil)
+
+		// Execute
+		result, err := voteService.VotePost(ctx, postID, 1)
+
+		// Verify
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), result.Upvotes)
+		assert.Equal(t, int32(2), result.Score())
+	})
+
+	t.Run("fail with invalid vote value", func(t *testing.T) {
+		// Execute
+		result, err := voteService.VotePost(ctx, postID, 2)
+
+		// Verify
+		assert.Error(t, err)
+		assert.Nil(t, result)
+
+		var gqlErr utils.GqlError
+		require.ErrorAs(t, err, &gqlErr)
+		assert.Equal(t, consts.BadRequestType, gqlErr.Type)
+	})
+
+	t.Run("fail when user is not authenticated", func(t *testing.T) {
+		// Execute
+		result, err := voteService.VotePost(context.Background(), postID, 1)
+
+		// Verify
+		assert.Nil(t, result)
+
+		var gqlErr utils.GqlError
+		require.ErrorAs(t, err, &gqlErr)
+		assert.Equal(t, consts.UnauthorizedType, gqlErr.Type)
+	})
+
+	t.Run("fail when post not found", func(t *testing.T) {
+		// Mock expectations
+		postStorage.EXPECT().
+			GetPostByID(ctx, postID).
+			Return(nil, sql.ErrNoRows)
+
+		// Execute
+		result, err := voteService.VotePost(ctx, postID, -1)
+
+		// Verify
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "does not exist")
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail when post is removed", func(t *testing.T) {
+		// Mock expectations
+		postStorage.EXPECT().
+			GetPostByID(ctx, postID).
+			Return(&models.Post{ID: postID, IsRemoved: true}, nil)
+
+		// Execute
+		result, err := voteService.VotePost(ctx, postID, 1)
+
+		// Verify
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "does not exist")
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail when post is shadowed for voter", func(t *testing.T) {
+		// Mock expectations
+		postStorage.EXPECT().
+			GetPostByID(ctx, postID).
+			Return(&models.Post{ID: postID, Author: "spammer", IsShadowed: true}, nil)
+
+		// Execute
+		result, err := voteService.VotePost(ctx, postID, 1)
+
+		// Verify
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "does not exist")
+		assert.Nil(t, result)
+	})
+}
+
+func TestVoteService_VoteComment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	voter := "test_voter"
+	ctx := withUser(voter)
+	voteStorage := store_mock.NewMockVoteStorage(ctrl)
+	postStorage := store_mock.NewMockPostStorage(ctrl)
+	commentStorage := store_mock.NewMockCommentStorage(ctrl)
+
+	voteService := NewVoteService(voteStorage, postStorage, commentStorage)
+
+	commentID := uuid.New()
+
+	t.Run("successfully retract vote for comment", func(t *testing.T) {
+		// Mock expectations
+		commentStorage.EXPECT().
+			GetCommentByID(ctx, commentID).
+			Return(&models.Comment{ID: commentID}, nil)
+
+		voteStorage.EXPECT().
+			VoteComment(ctx, commentID, voter, 0).
+			Return(models.Comment{ID: commentID}, nil)
+
+		// Execute
+		result, err := voteService.VoteComment(ctx, commentID, 0)
+
+		// Verify
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), result.Score())
+	})
+
+	t.Run("fail when comment is deleted", func(t *testing.T) {
+		// Mock expectations
+		commentStorage.EXPECT().
+			GetCommentByID(ctx, commentID).
+			Return(&models.Comment{ID: commentID, IsDeleted: true}, nil)
+
+		// Execute
+		result, err := voteService.VoteComment(ctx, commentID, 1)
+
+		// Verify
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "is deleted")
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail when comment is removed", func(t *testing.T) {
+		// Mock expectations
+		commentStorage.EXPECT().
+			GetCommentByID(ctx, commentID).
+			Return(&models.Comment{ID: commentID, IsRemoved: true}, nil)
+
+		// Execute
+		result, err := voteService.VoteComment(ctx, commentID, 1)
+
+		// Verify
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail when comment is shadowed for voter", func(t *testing.T) {
+		// Mock expectations
+		commentStorage.EXPECT().
+			GetCommentByID(ctx, commentID).
+			Return(&models.Comment{ID: commentID, Author: "spammer", IsShadowed: true}, nil)
+
+		// Execute
+		result, err := voteService.VoteComment(ctx, commentID, 1)
+
+		// Verify
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+		assert.Nil(t, result)
+	})
+
+	t.Run("fail when comment not found", func(t *testing.T) {
+		// Mock expectations
+		commentStorage.EXPECT().
+			GetCommentByID(ctx, commentID).
+			Return(nil, sql.ErrNoRows)
+
+		// Execute
+		result, err := voteService.VoteComment(ctx, commentID, 1)
+
+		// Verify
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+		assert.Nil(t, result)
+	})
+}
+
+func TestVoteService_GetPostVotes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	voteStorage := store_mock.NewMockVoteStorage(ctrl)
+	postStorage := store_mock.NewMockPostStorage(ctrl)
+	commentStorage := store_mock.NewMockCommentStorage(ctrl)
+
+	voteService := NewVoteService(voteStorage, postStorage, commentStorage)
+
+	postIDs := []uuid.UUID{uuid.New(), uuid.New()}
+	voter := "test_voter"
+
+	t.Run("successfully get votes", func(t *testing.T) {
+		// Mock expectations
+		voteStorage.EXPECT().
+			GetPostVotes(ctx, postIDs, voter).
+			Return(map[uuid.UUID]int{postIDs[0]: -1}, nil)
+
+		// Execute
+		result, err := voteService.GetPostVotes(ctx, postIDs, voter)
+
+		// Verify
+		require.NoError(t, err)
+		assert.Equal(t, map[uuid.UUID]int32{postIDs[0]: -1}, result)
+	})
+
+	t.Run("fail when storage fails", func(t *testing.T) {
+		// Mock expectations
+		voteStorage.EXPECT().
+			GetPostVotes(ctx, postIDs, voter).
+			Return(nil, assert.AnError)
+
+		// Execute
+		result, err := voteService.GetPostVotes(ctx, postIDs, voter)
+
+		// Verify
+		assert.Error(t, err)
+		assert.Nil(t, result)
+	})
+}
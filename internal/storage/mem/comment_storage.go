@@ -2,7 +2,10 @@ package mem
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"slices"
 	"sync"
 	"time"
 
@@ -12,13 +15,21 @@ import (
 )
 
 type CommentsStorageMem struct {
-	comms []models.Comment
-	mu    sync.RWMutex
+	posts    *PostStorageMem // посты комментариев, чтобы не искать комментарии к скрытым постам
+	comms    []models.Comment
+	perPost  map[uuid.UUID]int            // количество комментариев и ответов к посту, чтобы не пересчитывать их по всему слайсу
+	shadowed map[uuid.UUID]map[string]int // то же для скрытых фильтром контента, по авторам (в perPost их нет)
+	index    *searchIndex                 // поисковый индекс по тексту комментариев
+	mu       sync.RWMutex
 }
 
-func NewCommentsStorageMem() *CommentsStorageMem {
+func NewCommentsStorageMem(posts *PostStorageMem) *CommentsStorageMem {
 	return &CommentsStorageMem{
-		comms: make([]models.Comment, 0, consts.InitCommentsSizeInMem),
+		posts:    posts,
+		comms:    make([]models.Comment, 0, consts.InitCommentsSizeInMem),
+		perPost:  make(map[uuid.UUID]int),
+		shadowed: make(map[uuid.UUID]map[string]int),
+		index:    newSearchIndex(consts.SearchContentWeight),
 	}
 }
 
@@ -28,51 +39,388 @@ func (s *CommentsStorageMem) CreateComment(_ context.Context, comment models.Com
 		comment.ID = uuid.New()
 	}
 	comment.CreatedAt = &now
+	comment.UpdateRanking()
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	s.comms = append(s.comms, comment)
+	if comment.IsShadowed {
+		s.countShadowed(comment, 1)
+	} else {
+		s.perPost[comment.PostID]++
+		s.index.put(comment.ID, comment.Content)
+	}
 	return comment, nil
 }
-func (s *CommentsStorageMem) GetCommentsByPostID(_ context.Context, postID uuid.UUID,
-	offset, limit int) ([]*models.Comment, error) {
-	if limit < 0 || offset < 0 {
+func (s *CommentsStorageMem) GetCommentsByPostIDs(_ context.Context, postIDs []uuid.UUID, sort models.CommentSort,
+	page models.Page, viewer string) (map[uuid.UUID][]*models.Comment, error) {
+	groups := s.group(postIDs, postOfRootComment, viewer)
+
+	return paginateGroups(groups, sort, page)
+}
+func (s *CommentsStorageMem) CountCommentsByPostIDs(_ context.Context, postIDs []uuid.UUID,
+	viewer string) (map[uuid.UUID]int, error) {
+	groups := s.group(postIDs, postOfRootComment, viewer)
+
+	return countGroups(groups), nil
+}
+func (s *CommentsStorageMem) CountAllCommentsByPostIDs(_ context.Context, postIDs []uuid.UUID,
+	viewer string) (map[uuid.UUID]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := make(map[uuid.UUID]int, len(postIDs))
+	for _, postID := range postIDs {
+		if count := s.perPost[postID] + s.shadowed[postID][viewer]; count > 0 {
+			counts[postID] = count
+		}
+	}
+	return counts, nil
+}
+func (s *CommentsStorageMem) GetRepliesByParentCommentIDs(_ context.Context, parentCommentIDs []uuid.UUID,
+	sort models.CommentSort, page models.Page, viewer string) (map[uuid.UUID][]*models.Comment, error) {
+	groups := s.group(parentCommentIDs, parentOfReply, viewer)
+
+	return paginateGroups(groups, sort, page)
+}
+func (s *CommentsStorageMem) CountRepliesByParentCommentIDs(_ context.Context, parentCommentIDs []uuid.UUID,
+	viewer string) (map[uuid.UUID]int, error) {
+	groups := s.group(parentCommentIDs, parentOfReply, viewer)
+
+	return countGroups(groups), nil
+}
+func (s *CommentsStorageMem) GetCommentTree(_ context.Context, postID uuid.UUID, maxDepth, offset, limit int,
+	viewer string) ([]*models.CommentTreeNode, error) {
+	if maxDepth < 0 || offset < 0 || limit < 0 {
 		return nil, errors.New("invalid argument")
 	}
 
-	comments := make([]*models.Comment, 0)
+	// ответы на невидимый комментарий в обход не попадут, так как его нет среди родителей
+	comments := s.filter(func(c *models.Comment) bool {
+		return c.PostID == postID && c.VisibleTo(viewer)
+	})
+
+	// на каждом уровне комментарии идут от старых к новым, как и в postgres-хранилище
+	slices.SortFunc(comments, func(a, b *models.Comment) int {
+		return models.CommentCursor(a).Compare(models.CommentCursor(b))
+	})
+
+	children := make(map[uuid.UUID][]*models.Comment)
+	roots := make([]*models.Comment, 0)
+	for _, comment := range comments {
+		if comment.ParentCommentID == nil {
+			roots = append(roots, comment)
+		} else {
+			children[*comment.ParentCommentID] = append(children[*comment.ParentCommentID], comment)
+		}
+	}
+
+	// обход в глубину, собираем узлы только до конца запрошенной страницы
+	nodes := make([]*models.CommentTreeNode, 0)
+	var walk func(level []*models.Comment, depth int, path []uuid.UUID)
+	walk = func(level []*models.Comment, depth int, path []uuid.UUID) {
+		for _, comment := range level {
+			if len(nodes) >= offset+limit {
+				return
+			}
+
+			nodePath := append(slices.Clip(path), comment.ID)
+			nodes = append(nodes, &models.CommentTreeNode{Comment: comment, Depth: int32(depth), Path: nodePath})
+			if depth < maxDepth {
+				walk(children[comment.ID], depth+1, nodePath)
+			}
+		}
+	}
+	walk(roots, 0, nil)
+
+	if offset >= len(nodes) {
+		return []*models.CommentTreeNode{}, nil
+	}
+	return nodes[offset:], nil
+}
+func (s *CommentsStorageMem) GetCommentByID(_ context.Context, commentID uuid.UUID) (*models.Comment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := s.indexOf(commentID)
+	if i < 0 {
+		return nil, errCommentNotFound(commentID)
+	}
+
+	comment := s.comms[i]
+	return &comment, nil
+}
+func (s *CommentsStorageMem) GetCommentsAfter(_ context.Context, postID uuid.UUID, after models.Cursor, limit int,
+	viewer string) ([]*models.Comment, error) {
+	comments := s.filter(func(c *models.Comment) bool {
+		return c.PostID == postID && models.CommentCursor(c).Compare(after) > 0 && c.VisibleTo(viewer)
+	})
+
+	slices.SortFunc(comments, func(a, b *models.Comment) int {
+		return models.CommentCursor(a).Compare(models.CommentCursor(b))
+	})
+
+	if len(comments) > limit {
+		comments = comments[:limit]
+	}
+	return comments, nil
+}
+func (s *CommentsStorageMem) UpdateComment(_ context.Context, commentID uuid.UUID, content string,
+	shadow bool) (models.Comment, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.indexOf(commentID)
+	if i < 0 || s.comms[i].IsDeleted {
+		return models.Comment{}, errCommentNotFound(commentID)
+	}
+
+	// скрытый фильтром контента комментарий остается скрытым
+	if shadow && !s.comms[i].IsShadowed {
+		s.comms[i].IsShadowed = true
+		s.perPost[s.comms[i].PostID]--
+		s.countShadowed(s.comms[i], 1)
+	}
+
+	s.comms[i].Content = content
+	s.comms[i].UpdatedAt = &now
+	if s.comms[i].IsRemoved || s.comms[i].IsShadowed {
+		s.index.remove(commentID)
+	} else {
+		s.index.put(commentID, content)
+	}
+	return s.comms[i], nil
+}
+func (s *CommentsStorageMem) DeleteComment(_ context.Context, commentID uuid.UUID) (models.Comment, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.indexOf(commentID)
+	if i < 0 {
+		return models.Comment{}, errCommentNotFound(commentID)
+	}
+
+	// удаленный скрытый комментарий без автора не виден никому, как и в postgres-хранилище
+	if s.comms[i].IsShadowed && !s.comms[i].IsDeleted {
+		s.countShadowed(s.comms[i], -1)
+	}
+
+	// сам комментарий оставляем, чтобы не потерять ветку ответов на него
+	s.comms[i].Content = ""
+	s.comms[i].Author = ""
+	s.comms[i].IsDeleted = true
+	s.comms[i].UpdatedAt = &now
+	s.index.remove(commentID)
+	return s.comms[i], nil
+}
+func (s *CommentsStorageMem) SearchComments(_ context.Context, query string, postID *uuid.UUID,
+	limit int) ([]*models.CommentSearchResult, error) {
+	if limit < 0 {
+		return nil, errors.New("invalid argument")
+	}
+
+	ranks := s.index.search(query)
+	comments := s.filter(func(c *models.Comment) bool {
+		_, ok := ranks[c.ID]
+		return ok && (postID == nil || c.PostID == *postID)
+	})
+
+	// комментарии к скрытым модератором или фильтром контента постам не ищутся, как и в postgres-хранилище
+	hidden := s.posts.hiddenPosts(comments)
+
+	res := make([]*models.CommentSearchResult, 0, len(comments))
+	for _, comment := range comments {
+		if !hidden[comment.PostID] {
+			res = append(res, &models.CommentSearchResult{Comment: comment, Rank: ranks[comment.ID]})
+		}
+	}
 
+	// от более релевантных к менее, при равной релевантности - от новых к старым
+	slices.SortFunc(res, func(a, b *models.CommentSearchResult) int {
+		ac, bc := models.CommentCursor(a.Comment), models.CommentCursor(b.Comment)
+		ac.Key, bc.Key = a.Rank, b.Rank
+		return bc.Compare(ac)
+	})
+
+	if len(res) > limit {
+		res = res[:limit]
+	}
+	for _, r := range res {
+		r.Snippet = highlight(r.Comment.Content, query)
+	}
+	return res, nil
+}
+
+func (s *CommentsStorageMem) HasDuplicate(_ context.Context, author, contentHash string, since time.Time,
+	excludeID uuid.UUID) (bool, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	for i := len(s.comms) - 1; i >= 0; i-- { // order by created_at desc
-		if s.comms[i].PostID == postID && s.comms[i].ParentCommentID == nil {
-			comments = append(comments, &s.comms[i])
+	for i := range s.comms {
+		c := &s.comms[i]
+		if c.Author == author && c.ID != excludeID && !c.CreatedAt.Before(since) &&
+			models.ContentHash(c.Content) == contentHash {
+			return true, nil
 		}
 	}
+	return false, nil
+}
+
+// изменение счетчиков голосов, вызывается хранилищем голосов
+func (s *CommentsStorageMem) addVotes(commentID uuid.UUID, up, down int) (models.Comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.indexOf(commentID)
+	if i < 0 {
+		return models.Comment{}, errCommentNotFound(commentID)
+	}
+
+	s.comms[i].Upvotes += int32(up)
+	s.comms[i].Downvotes += int32(down)
+	s.comms[i].UpdateRanking()
+	return s.comms[i], nil
+}
 
-	if offset > len(s.comms) {
-		return nil, nil
+// скрытие комментария модератором, вызывается хранилищем модерации
+func (s *CommentsStorageMem) markRemoved(commentID uuid.UUID) (models.Comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.indexOf(commentID)
+	if i < 0 || s.comms[i].IsRemoved {
+		return models.Comment{}, errCommentNotFound(commentID)
 	}
 
-	if offset+limit > len(s.comms) {
-		return comments[offset:], nil
+	s.comms[i].IsRemoved = true
+	s.index.remove(commentID)
+	return s.comms[i], nil
+}
+
+// восстановление скрытого комментария, вызывается хранилищем жалоб
+func (s *CommentsStorageMem) restore(commentID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.indexOf(commentID)
+	if i < 0 || !s.comms[i].IsRemoved {
+		return errCommentNotFound(commentID)
 	}
 
-	return comments[offset : offset+limit], nil
+	s.comms[i].IsRemoved = false
+	// удаленный автором и скрытый фильтром контента комментарии в поиск не возвращаем
+	if !s.comms[i].IsDeleted && !s.comms[i].IsShadowed {
+		s.index.put(commentID, s.comms[i].Content)
+	}
+	return nil
 }
-func (s *CommentsStorageMem) GetRepliesByParentCommentID(_ context.Context, parentCommentID uuid.UUID) ([]*models.Comment, error) {
+
+// отдаем копии комментариев, так как комментарий может быть изменен или удален
+func (s *CommentsStorageMem) filter(match func(c *models.Comment) bool) []*models.Comment {
 	comments := make([]*models.Comment, 0)
 
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	for i := len(s.comms) - 1; i >= 0; i-- {
-		if s.comms[i].ParentCommentID != nil && *s.comms[i].ParentCommentID == parentCommentID {
-			comments = append(comments, &s.comms[i])
+	for i := range s.comms {
+		if match(&s.comms[i]) {
+			comment := s.comms[i]
+			comments = append(comments, &comment)
+		}
+	}
+	return comments
+}
+
+// раскладываем копии видимых viewer комментариев по группам с ключами из ids, keyOf возвращает ключ группы
+// комментария и false, если комментарий не относится ни к одной группе
+func (s *CommentsStorageMem) group(ids []uuid.UUID, keyOf func(c *models.Comment) (uuid.UUID, bool),
+	viewer string) map[uuid.UUID][]*models.Comment {
+	groups := make(map[uuid.UUID][]*models.Comment, len(ids))
+	for _, id := range ids {
+		groups[id] = nil
+	}
+
+	comments := s.filter(func(c *models.Comment) bool {
+		key, ok := keyOf(c)
+		if !ok || !c.VisibleTo(viewer) {
+			return false
 		}
+		_, ok = groups[key]
+		return ok
+	})
+
+	for _, comment := range comments {
+		key, _ := keyOf(comment)
+		groups[key] = append(groups[key], comment)
 	}
+	return groups
+}
 
-	return comments, nil
+// изменение счетчика скрытых фильтром контента комментариев автора к посту, вызывается под мьютексом
+func (s *CommentsStorageMem) countShadowed(comment models.Comment, delta int) {
+	authors, ok := s.shadowed[comment.PostID]
+	if !ok {
+		authors = make(map[string]int)
+		s.shadowed[comment.PostID] = authors
+	}
+
+	authors[comment.Author] += delta
+	if authors[comment.Author] <= 0 {
+		delete(authors, comment.Author)
+	}
+}
+
+// вызывается под мьютексом
+func (s *CommentsStorageMem) indexOf(commentID uuid.UUID) int {
+	for i := range s.comms {
+		if s.comms[i].ID == commentID {
+			return i
+		}
+	}
+	return -1
+}
+
+// комментарии первого уровня группируются по посту
+func postOfRootComment(c *models.Comment) (uuid.UUID, bool) {
+	return c.PostID, c.ParentCommentID == nil
+}
+
+// ответы группируются по родительскому комментарию
+func parentOfReply(c *models.Comment) (uuid.UUID, bool) {
+	if c.ParentCommentID == nil {
+		return uuid.Nil, false
+	}
+	return *c.ParentCommentID, true
+}
+
+func paginateGroups(groups map[uuid.UUID][]*models.Comment, sort models.CommentSort,
+	page models.Page) (map[uuid.UUID][]*models.Comment, error) {
+	pages := make(map[uuid.UUID][]*models.Comment, len(groups))
+	for key, comments := range groups {
+		res, err := paginate(comments, page, models.CommentSortCursor(sort), sort.Desc())
+		if err != nil {
+			return nil, err
+		}
+		pages[key] = res
+	}
+	return pages, nil
+}
+
+func countGroups(groups map[uuid.UUID][]*models.Comment) map[uuid.UUID]int {
+	counts := make(map[uuid.UUID]int, len(groups))
+	for key, comments := range groups {
+		if len(comments) > 0 {
+			counts[key] = len(comments)
+		}
+	}
+	return counts
+}
+
+func errCommentNotFound(commentID uuid.UUID) error {
+	return fmt.Errorf("Comment with id: %s not found: %w", commentID.String(), sql.ErrNoRows)
 }
@@ -2,6 +2,7 @@ package mem
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -18,7 +19,7 @@ func TestCommentsStorageMem_CreateComment(t *testing.T) {
 	content := "Test_test_test"
 
 	t.Run("without ID", func(t *testing.T) {
-		storage := NewCommentsStorageMem()
+		storage := NewCommentsStorageMem(NewPostStorageMem())
 
 		comment := models.Comment{
 			Author:  author,
@@ -39,7 +40,7 @@ func TestCommentsStorageMem_CreateComment(t *testing.T) {
 	})
 
 	t.Run("with predefined ID", func(t *testing.T) {
-		storage := NewCommentsStorageMem()
+		storage := NewCommentsStorageMem(NewPostStorageMem())
 
 		predefinedID := uuid.New()
 		comment := models.Comment{
@@ -55,32 +56,40 @@ func TestCommentsStorageMem_CreateComment(t *testing.T) {
 	})
 }
 
-func TestCommentsStorageMem_GetCommentsByPostID(t *testing.T) {
+func TestCommentsStorageMem_GetCommentsByPostIDs(t *testing.T) {
 	ctx := context.Background()
 	postID := uuid.New()
 	author := "Test_author"
 	content := "Test_test_test"
 
 	t.Run("with invalid arguments", func(t *testing.T) {
-		storage := NewCommentsStorageMem()
+		storage := NewCommentsStorageMem(NewPostStorageMem())
 
-		_, err := storage.GetCommentsByPostID(ctx, postID, -1, 10)
+		_, err := storage.GetCommentsByPostIDs(ctx, []uuid.UUID{postID}, models.CommentSortNew, models.Page{Limit: -5}, "")
 		assert.Error(t, err)
 
-		_, err = storage.GetCommentsByPostID(ctx, postID, 0, -5)
+		_, err = storage.GetRepliesByParentCommentIDs(ctx, []uuid.UUID{uuid.New()}, models.CommentSortOld,
+			models.Page{Limit: -5}, "")
 		assert.Error(t, err)
 	})
 
 	t.Run("with non-existent post", func(t *testing.T) {
-		storage := NewCommentsStorageMem()
+		storage := NewCommentsStorageMem(NewPostStorageMem())
 
-		comments, err := storage.GetCommentsByPostID(ctx, uuid.New(), 0, 10)
+		comments, err := storage.GetCommentsByPostIDs(ctx, []uuid.UUID{uuid.New()}, models.CommentSortNew,
+			models.Page{Limit: 10}, "")
 		require.NoError(t, err)
-		assert.Empty(t, comments)
+		for _, page := range comments {
+			assert.Empty(t, page)
+		}
+
+		counts, err := storage.CountCommentsByPostIDs(ctx, []uuid.UUID{uuid.New()}, "")
+		require.NoError(t, err)
+		assert.Empty(t, counts)
 	})
 
 	t.Run("with pagination", func(t *testing.T) {
-		storage := NewCommentsStorageMem()
+		storage := NewCommentsStorageMem(NewPostStorageMem())
 
 		for i := 0; i < 5; i++ {
 			comment := models.Comment{
@@ -93,27 +102,41 @@ func TestCommentsStorageMem_GetCommentsByPostID(t *testing.T) {
 			time.Sleep(time.Millisecond)
 		}
 
-		retrieved, err := storage.GetCommentsByPostID(ctx, postID, 0, 10)
+		pages, err := storage.GetCommentsByPostIDs(ctx, []uuid.UUID{postID}, models.CommentSortNew,
+			models.Page{Limit: 10}, "")
 		require.NoError(t, err)
+		retrieved := pages[postID]
 		assert.Len(t, retrieved, 5)
 
 		assert.True(t, retrieved[0].CreatedAt.After(*retrieved[1].CreatedAt))
 
-		retrieved, err = storage.GetCommentsByPostID(ctx, postID, 0, 3)
+		pages, err = storage.GetCommentsByPostIDs(ctx, []uuid.UUID{postID}, models.CommentSortNew,
+			models.Page{Limit: 3}, "")
+		require.NoError(t, err)
+		firstPage := pages[postID]
+		assert.Len(t, firstPage, 3)
+		assert.Equal(t, retrieved[:3], firstPage)
+
+		after := models.CommentCursor(firstPage[2])
+		pages, err = storage.GetCommentsByPostIDs(ctx, []uuid.UUID{postID}, models.CommentSortNew,
+			models.Page{Limit: 3, After: &after}, "")
 		require.NoError(t, err)
-		assert.Len(t, retrieved, 3)
+		secondPage := pages[postID]
+		assert.Equal(t, retrieved[3:], secondPage)
 
-		retrieved, err = storage.GetCommentsByPostID(ctx, postID, 2, 10)
+		before := models.CommentCursor(secondPage[0])
+		pages, err = storage.GetCommentsByPostIDs(ctx, []uuid.UUID{postID}, models.CommentSortNew,
+			models.Page{Limit: 2, Before: &before, FromEnd: true}, "")
 		require.NoError(t, err)
-		assert.Len(t, retrieved, 3)
+		assert.Equal(t, retrieved[1:3], pages[postID])
 
-		retrieved, err = storage.GetCommentsByPostID(ctx, postID, 1, 2)
+		counts, err := storage.CountCommentsByPostIDs(ctx, []uuid.UUID{postID}, "")
 		require.NoError(t, err)
-		assert.Len(t, retrieved, 2)
+		assert.Equal(t, 5, counts[postID])
 	})
 
 	t.Run("only returns root comments", func(t *testing.T) {
-		storage := NewCommentsStorageMem()
+		storage := NewCommentsStorageMem(NewPostStorageMem())
 
 		rootComment, err := storage.CreateComment(ctx, models.Comment{
 			Author:  author,
@@ -138,21 +161,60 @@ func TestCommentsStorageMem_GetCommentsByPostID(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		rootComments, err := storage.GetCommentsByPostID(ctx, postID, 0, 10)
+		rootComments, err := storage.GetCommentsByPostIDs(ctx, []uuid.UUID{postID}, models.CommentSortNew,
+			models.Page{Limit: 10}, "")
+		require.NoError(t, err)
+		require.Len(t, rootComments[postID], 1)
+		assert.Equal(t, rootComment.ID, rootComments[postID][0].ID)
+	})
+
+	t.Run("with top and best sorts", func(t *testing.T) {
+		storage := NewCommentsStorageMem(NewPostStorageMem())
+
+		// у второго комментария рейтинг выше, но доля голосов "за" у третьего надежнее
+		ids := make([]uuid.UUID, 0, 3)
+		for _, votes := range [][2]int{{1, 0}, {10, 5}, {3, 0}} {
+			comment, err := storage.CreateComment(ctx, models.Comment{Author: author, Content: content, PostID: postID})
+			require.NoError(t, err)
+			_, err = storage.addVotes(comment.ID, votes[0], votes[1])
+			require.NoError(t, err)
+			ids = append(ids, comment.ID)
+		}
+
+		commentIDs := func(comments []*models.Comment) []uuid.UUID {
+			res := make([]uuid.UUID, 0, len(comments))
+			for _, comment := range comments {
+				res = append(res, comment.ID)
+			}
+			return res
+		}
+
+		pages, err := storage.GetCommentsByPostIDs(ctx, []uuid.UUID{postID}, models.CommentSortTop,
+			models.Page{Limit: 10}, "")
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{ids[1], ids[2], ids[0]}, commentIDs(pages[postID]))
+
+		pages, err = storage.GetCommentsByPostIDs(ctx, []uuid.UUID{postID}, models.CommentSortBest,
+			models.Page{Limit: 1}, "")
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{ids[2]}, commentIDs(pages[postID]))
+
+		after := models.CommentSortCursor(models.CommentSortBest)(pages[postID][0])
+		pages, err = storage.GetCommentsByPostIDs(ctx, []uuid.UUID{postID}, models.CommentSortBest,
+			models.Page{Limit: 10, After: &after}, "")
 		require.NoError(t, err)
-		assert.Len(t, rootComments, 1)
-		assert.Equal(t, rootComment.ID, rootComments[0].ID)
+		assert.Equal(t, []uuid.UUID{ids[1], ids[0]}, commentIDs(pages[postID]))
 	})
 }
 
-func TestCommentsStorageMem_GetRepliesByParentCommentID(t *testing.T) {
+func TestCommentsStorageMem_GetRepliesByParentCommentIDs(t *testing.T) {
 	ctx := context.Background()
 	postID := uuid.New()
 	author := "Test_author"
 	content := "Test_test_test"
 
 	t.Run("with existing parent comment", func(t *testing.T) {
-		storage := NewCommentsStorageMem()
+		storage := NewCommentsStorageMem(NewPostStorageMem())
 
 		parentComment, err := storage.CreateComment(ctx, models.Comment{
 			Author:  author,
@@ -168,6 +230,7 @@ func TestCommentsStorageMem_GetRepliesByParentCommentID(t *testing.T) {
 			ParentCommentID: &parentComment.ID,
 		})
 		require.NoError(t, err)
+		time.Sleep(time.Millisecond)
 
 		reply2, err := storage.CreateComment(ctx, models.Comment{
 			Author:          author,
@@ -177,29 +240,41 @@ func TestCommentsStorageMem_GetRepliesByParentCommentID(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		replies, err := storage.GetRepliesByParentCommentID(ctx, parentComment.ID)
+		replies, err := storage.GetRepliesByParentCommentIDs(ctx, []uuid.UUID{parentComment.ID}, models.CommentSortOld,
+			models.Page{Limit: 10}, "")
 		require.NoError(t, err)
-		assert.Len(t, replies, 2)
+		require.Len(t, replies[parentComment.ID], 2)
 
-		assert.True(t, replies[0].CreatedAt.After(*replies[1].CreatedAt))
+		// ответы идут от старых к новым
+		assert.Equal(t, reply1.ID, replies[parentComment.ID][0].ID)
+		assert.Equal(t, reply2.ID, replies[parentComment.ID][1].ID)
 
-		replyIDs := []uuid.UUID{replies[0].ID, replies[1].ID}
-		assert.Contains(t, replyIDs, reply1.ID)
-		assert.Contains(t, replyIDs, reply2.ID)
+		after := models.CommentCursor(replies[parentComment.ID][0])
+		replies, err = storage.GetRepliesByParentCommentIDs(ctx, []uuid.UUID{parentComment.ID}, models.CommentSortOld,
+			models.Page{Limit: 10, After: &after}, "")
+		require.NoError(t, err)
+		require.Len(t, replies[parentComment.ID], 1)
+		assert.Equal(t, reply2.ID, replies[parentComment.ID][0].ID)
+
+		counts, err := storage.CountRepliesByParentCommentIDs(ctx, []uuid.UUID{parentComment.ID}, "")
+		require.NoError(t, err)
+		assert.Equal(t, 2, counts[parentComment.ID])
 	})
 
 	t.Run("with non-existent parent", func(t *testing.T) {
-		storage := NewCommentsStorageMem()
+		storage := NewCommentsStorageMem(NewPostStorageMem())
 
-		replies, err := storage.GetRepliesByParentCommentID(ctx, uuid.New())
+		parentID := uuid.New()
+		replies, err := storage.GetRepliesByParentCommentIDs(ctx, []uuid.UUID{parentID}, models.CommentSortOld,
+			models.Page{Limit: 10}, "")
 		require.NoError(t, err)
-		assert.Empty(t, replies)
+		assert.Empty(t, replies[parentID])
 	})
 }
 
 func TestCommentsStorageMem_ConcurrentAccess(t *testing.T) {
 	ctx := context.Background()
-	storage := NewCommentsStorageMem()
+	storage := NewCommentsStorageMem(NewPostStorageMem())
 
 	postID := uuid.New()
 	author := "Test_author"
@@ -231,7 +306,470 @@ func TestCommentsStorageMem_ConcurrentAccess(t *testing.T) {
 		assert.NoError(t, <-errChan)
 	}
 
-	comments, err := storage.GetCommentsByPostID(ctx, postID, 0, 50)
+	comments, err := storage.GetCommentsByPostIDs(ctx, []uuid.UUID{postID}, models.CommentSortNew,
+		models.Page{Limit: 50}, "")
+	require.NoError(t, err)
+	assert.Len(t, comments[postID], goroutines*commentsPerRoutine)
+}
+
+func TestCommentsStorageMem_GetCommentByID(t *testing.T) {
+	ctx := context.Background()
+	postID := uuid.New()
+
+	t.Run("with existing comment", func(t *testing.T) {
+		storage := NewCommentsStorageMem(NewPostStorageMem())
+
+		created, err := storage.CreateComment(ctx, models.Comment{
+			Author:  "Test_author",
+			Content: "Test_test_test",
+			PostID:  postID,
+		})
+		require.NoError(t, err)
+
+		retrieved, err := storage.GetCommentByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, created, *retrieved)
+	})
+
+	t.Run("with non-existent comment", func(t *testing.T) {
+		storage := NewCommentsStorageMem(NewPostStorageMem())
+
+		nonExistentID := uuid.New()
+		_, err := storage.GetCommentByID(ctx, nonExistentID)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), nonExistentID.String())
+	})
+}
+
+func TestCommentsStorageMem_GetCommentsAfter(t *testing.T) {
+	ctx := context.Background()
+	postID := uuid.New()
+	storage := NewCommentsStorageMem(NewPostStorageMem())
+
+	var created []models.Comment
+	for i := range 4 {
+		comment := models.Comment{Author: "test_author", Content: fmt.Sprintf("comment %d", i), PostID: postID}
+		if i > 0 { // ответы тоже отдаются
+			comment.ParentCommentID = &created[0].ID
+		}
+		c, err := storage.CreateComment(ctx, comment)
+		require.NoError(t, err)
+		created = append(created, c)
+		time.Sleep(time.Millisecond)
+	}
+	_, err := storage.CreateComment(ctx, models.Comment{Author: "test_author", Content: "other", PostID: uuid.New()})
+	require.NoError(t, err)
+
+	t.Run("after comment", func(t *testing.T) {
+		comments, err := storage.GetCommentsAfter(ctx, postID, models.CommentCursor(&created[1]), 10, "")
+		require.NoError(t, err)
+		require.Len(t, comments, 2)
+		assert.Equal(t, created[2].ID, comments[0].ID)
+		assert.Equal(t, created[3].ID, comments[1].ID)
+	})
+
+	t.Run("since time with limit", func(t *testing.T) {
+		comments, err := storage.GetCommentsAfter(ctx, postID, models.Cursor{CreatedAt: *created[0].CreatedAt}, 3, "")
+		require.NoError(t, err)
+		require.Len(t, comments, 3)
+		assert.Equal(t, created[0].ID, comments[0].ID)
+	})
+}
+
+func TestCommentsStorageMem_CountAllCommentsByPostIDs(t *testing.T) {
+	ctx := context.Background()
+	postID, emptyPostID := uuid.New(), uuid.New()
+	storage := NewCommentsStorageMem(NewPostStorageMem())
+
+	root, err := storage.CreateComment(ctx, models.Comment{Author: "test_author", Content: "root", PostID: postID})
+	require.NoError(t, err)
+	reply, err := storage.CreateComment(ctx, models.Comment{Author: "test_author", Content: "reply", PostID: postID,
+		ParentCommentID: &root.ID})
+	require.NoError(t, err)
+	_, err = storage.CreateComment(ctx, models.Comment{Author: "test_author", Content: "other", PostID: uuid.New()})
+	require.NoError(t, err)
+
+	// удаленный комментарий остается в ветке и учитывается
+	_, err = storage.DeleteComment(ctx, reply.ID)
+	require.NoError(t, err)
+
+	counts, err := storage.CountAllCommentsByPostIDs(ctx, []uuid.UUID{postID, emptyPostID}, "")
+	require.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID]int{postID: 2}, counts)
+}
+
+func TestCommentsStorageMem_UpdateComment(t *testing.T) {
+	ctx := context.Background()
+	postID := uuid.New()
+	author := "Test_author"
+
+	t.Run("with existing comment", func(t *testing.T) {
+		storage := NewCommentsStorageMem(NewPostStorageMem())
+
+		created, err := storage.CreateComment(ctx, models.Comment{
+			Author:  author,
+			Content: "old content",
+			PostID:  postID,
+		})
+		require.NoError(t, err)
+
+		updated, err := storage.UpdateComment(ctx, created.ID, "new content", false)
+		require.NoError(t, err)
+		assert.Equal(t, "new content", updated.Content)
+		assert.Equal(t, author, updated.Author)
+		assert.NotNil(t, updated.UpdatedAt)
+		assert.Equal(t, created.CreatedAt, updated.CreatedAt)
+
+		retrieved, err := storage.GetCommentByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "new content", retrieved.Content)
+	})
+
+	t.Run("does not change previously returned comment", func(t *testing.T) {
+		storage := NewCommentsStorageMem(NewPostStorageMem())
+
+		created, err := storage.CreateComment(ctx, models.Comment{
+			Author:  author,
+			Content: "old content",
+			PostID:  postID,
+		})
+		require.NoError(t, err)
+
+		comments, err := storage.GetCommentsByPostIDs(ctx, []uuid.UUID{postID}, models.CommentSortNew,
+			models.Page{Limit: 10}, "")
+		require.NoError(t, err)
+		require.Len(t, comments[postID], 1)
+
+		_, err = storage.UpdateComment(ctx, created.ID, "new content", false)
+		require.NoError(t, err)
+		assert.Equal(t, "old content", comments[postID][0].Content)
+	})
+
+	t.Run("with deleted comment", func(t *testing.T) {
+		storage := NewCommentsStorageMem(NewPostStorageMem())
+
+		created, err := storage.CreateComment(ctx, models.Comment{
+			Author:  author,
+			Content: "old content",
+			PostID:  postID,
+		})
+		require.NoError(t, err)
+
+		_, err = storage.DeleteComment(ctx, created.ID)
+		require.NoError(t, err)
+
+		_, err = storage.UpdateComment(ctx, created.ID, "new content", false)
+		assert.Error(t, err)
+	})
+
+	t.Run("with non-existent comment", func(t *testing.T) {
+		storage := NewCommentsStorageMem(NewPostStorageMem())
+
+		_, err := storage.UpdateComment(ctx, uuid.New(), "new content", false)
+		assert.Error(t, err)
+	})
+}
+
+func TestCommentsStorageMem_DeleteComment(t *testing.T) {
+	ctx := context.Background()
+	postID := uuid.New()
+	author := "Test_author"
+	content := "Test_test_test"
+
+	t.Run("keeps replies of deleted comment", func(t *testing.T) {
+		storage := NewCommentsStorageMem(NewPostStorageMem())
+
+		parentComment, err := storage.CreateComment(ctx, models.Comment{
+			Author:  author,
+			Content: content,
+			PostID:  postID,
+		})
+		require.NoError(t, err)
+
+		reply, err := storage.CreateComment(ctx, models.Comment{
+			Author:          author,
+			Content:         "Reply",
+			PostID:          postID,
+			ParentCommentID: &parentComment.ID,
+		})
+		require.NoError(t, err)
+
+		deleted, err := storage.DeleteComment(ctx, parentComment.ID)
+		require.NoError(t, err)
+		assert.True(t, deleted.IsDeleted)
+		assert.Empty(t, deleted.Content)
+		assert.Empty(t, deleted.Author)
+		assert.NotNil(t, deleted.UpdatedAt)
+
+		rootComments, err := storage.GetCommentsByPostIDs(ctx, []uuid.UUID{postID}, models.CommentSortNew,
+			models.Page{Limit: 10}, "")
+		require.NoError(t, err)
+		require.Len(t, rootComments[postID], 1)
+		assert.Equal(t, parentComment.ID, rootComments[postID][0].ID)
+		assert.True(t, rootComments[postID][0].IsDeleted)
+
+		replies, err := storage.GetRepliesByParentCommentIDs(ctx, []uuid.UUID{parentComment.ID}, models.CommentSortOld,
+			models.Page{Limit: 10}, "")
+		require.NoError(t, err)
+		require.Len(t, replies[parentComment.ID], 1)
+		assert.Equal(t, reply.ID, replies[parentComment.ID][0].ID)
+		assert.Equal(t, "Reply", replies[parentComment.ID][0].Content)
+		assert.False(t, replies[parentComment.ID][0].IsDeleted)
+	})
+
+	t.Run("with non-existent comment", func(t *testing.T) {
+		storage := NewCommentsStorageMem(NewPostStorageMem())
+
+		nonExistentID := uuid.New()
+		_, err := storage.DeleteComment(ctx, nonExistentID)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), nonExistentID.String())
+	})
+}
+
+func TestCommentsStorageMem_GetCommentTree(t *testing.T) {
+	ctx := context.Background()
+	postID := uuid.New()
+	author := "Test_author"
+
+	storage := NewCommentsStorageMem(NewPostStorageMem())
+
+	create := func(content string, parentID *uuid.UUID) models.Comment {
+		comment, err := storage.CreateComment(ctx, models.Comment{
+			Author:          author,
+			Content:         content,
+			PostID:          postID,
+			ParentCommentID: parentID,
+		})
+		require.NoError(t, err)
+		time.Sleep(time.Millisecond)
+		return comment
+	}
+
+	// root1
+	// ├── reply1
+	// │   └── reply11
+	// └── reply2
+	// root2
+	root1 := create("root1", nil)
+	reply1 := create("reply1", &root1.ID)
+	root2 := create("root2", nil)
+	reply2 := create("reply2", &root1.ID)
+	reply11 := create("reply11", &reply1.ID)
+
+	contents := func(nodes []*models.CommentTreeNode) []string {
+		res := make([]string, 0, len(nodes))
+		for _, node := range nodes {
+			res = append(res, node.Comment.Content)
+		}
+		return res
+	}
+
+	t.Run("returns whole tree in depth-first order", func(t *testing.T) {
+		nodes, err := storage.GetCommentTree(ctx, postID, 10, 0, 10, "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"root1", "reply1", "reply11", "reply2", "root2"}, contents(nodes))
+
+		assert.Equal(t, int32(2), nodes[2].Depth)
+		assert.Equal(t, []uuid.UUID{root1.ID, reply1.ID, reply11.ID}, nodes[2].Path)
+		assert.Equal(t, int32(1), nodes[3].Depth)
+		assert.Equal(t, []uuid.UUID{root1.ID, reply2.ID}, nodes[3].Path)
+		assert.Equal(t, []uuid.UUID{root2.ID}, nodes[4].Path)
+	})
+
+	t.Run("with max depth", func(t *testing.T) {
+		nodes, err := storage.GetCommentTree(ctx, postID, 1, 0, 10, "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"root1", "reply1", "reply2", "root2"}, contents(nodes))
+
+		nodes, err = storage.GetCommentTree(ctx, postID, 0, 0, 10, "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"root1", "root2"}, contents(nodes))
+	})
+
+	t.Run("with pagination", func(t *testing.T) {
+		nodes, err := storage.GetCommentTree(ctx, postID, 10, 2, 2, "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"reply11", "reply2"}, contents(nodes))
+
+		nodes, err = storage.GetCommentTree(ctx, postID, 10, 10, 2, "")
+		require.NoError(t, err)
+		assert.Empty(t, nodes)
+	})
+
+	t.Run("with invalid arguments", func(t *testing.T) {
+		_, err := storage.GetCommentTree(ctx, postID, -1, 0, 10, "")
+		assert.Error(t, err)
+	})
+}
+
+func TestCommentsStorageMem_SearchComments(t *testing.T) {
+	ctx := context.Background()
+	storage := NewCommentsStorageMem(NewPostStorageMem())
+	postID, otherPostID := uuid.New(), uuid.New()
+
+	create := func(postID uuid.UUID, content string) models.Comment {
+		comment, err := storage.CreateComment(ctx, models.Comment{Author: "test_author", Content: content, PostID: postID})
+		require.NoError(t, err)
+		return comment
+	}
+
+	long := create(postID, "Длинный комментарий, в котором слово search встречается где-то в середине")
+	short := create(postID, "Short search")
+	other := create(otherPostID, "Search in another post")
+	deleted := create(postID, "search me")
+
+	_, err := storage.DeleteComment(ctx, deleted.ID)
+	require.NoError(t, err)
+
+	t.Run("all posts", func(t *testing.T) {
+		found, err := storage.SearchComments(ctx, "searching", nil, 10)
+		require.NoError(t, err)
+		require.Len(t, found, 3)
+		assert.Equal(t, short.ID, found[0].Comment.ID) // в коротком тексте слово весит больше
+		assert.Equal(t, long.ID, found[2].Comment.ID)
+		assert.Equal(t, "Short <mark>search</mark>", found[0].Snippet)
+	})
+
+	t.Run("one post with limit", func(t *testing.T) {
+		found, err := storage.SearchComments(ctx, "search", &postID, 1)
+		require.NoError(t, err)
+		require.Len(t, found, 1)
+		assert.Equal(t, short.ID, found[0].Comment.ID)
+
+		found, err = storage.SearchComments(ctx, "another", &postID, 10)
+		require.NoError(t, err)
+		assert.Empty(t, found)
+	})
+
+	t.Run("edited comment is reindexed", func(t *testing.T) {
+		_, err := storage.UpdateComment(ctx, other.ID, "Edited", false)
+		require.NoError(t, err)
+
+		found, err := storage.SearchComments(ctx, "edited", nil, 10)
+		require.NoError(t, err)
+		require.Len(t, found, 1)
+		assert.Equal(t, other.ID, found[0].Comment.ID)
+	})
+
+	t.Run("comments of removed post are not found", func(t *testing.T) {
+		posts := NewPostStorageMem()
+		storage := NewCommentsStorageMem(posts)
+
+		post, err := posts.CreatePost(ctx, models.Post{Title: "Пост", Author: "test_author", Content: "текст"})
+		require.NoError(t, err)
+		_, err = storage.CreateComment(ctx, models.Comment{Author: "test_author", Content: "hidden", PostID: post.ID})
+		require.NoError(t, err)
+
+		_, err = posts.markRemoved(post.ID)
+		require.NoError(t, err)
+
+		found, err := storage.SearchComments(ctx, "hidden", nil, 10)
+		require.NoError(t, err)
+		assert.Empty(t, found)
+	})
+}
+
+func TestCommentsStorageMem_Shadowed(t *testing.T) {
+	ctx := context.Background()
+	postID := uuid.New()
+	storage := NewCommentsStorageMem(NewPostStorageMem())
+
+	root, err := storage.CreateComment(ctx, models.Comment{Author: "test_author", Content: "обычный комментарий",
+		PostID: postID})
+	require.NoError(t, err)
+	shadowed, err := storage.CreateComment(ctx, models.Comment{Author: "spammer", Content: "реклама казино",
+		PostID: postID, IsShadowed: true})
+	require.NoError(t, err)
+	_, err = storage.CreateComment(ctx, models.Comment{Author: "test_author", Content: "ответ на рекламу",
+		PostID: postID, ParentCommentID: &shadowed.ID})
+	require.NoError(t, err)
+
+	t.Run("show shadowed comment only to author", func(t *testing.T) {
+		for viewer, want := range map[string]int{"": 1, "test_author": 1, "spammer": 2} {
+			comments, err := storage.GetCommentsByPostIDs(ctx, []uuid.UUID{postID}, models.CommentSortOld,
+				models.Page{Limit: 10}, viewer)
+			require.NoError(t, err)
+			assert.Len(t, comments[postID], want, viewer)
+
+			counts, err := storage.CountCommentsByPostIDs(ctx, []uuid.UUID{postID}, viewer)
+			require.NoError(t, err)
+			assert.Equal(t, want, counts[postID], viewer)
+		}
+	})
+
+	t.Run("drop replies to shadowed comment from tree", func(t *testing.T) {
+		nodes, err := storage.GetCommentTree(ctx, postID, 10, 0, 10, "test_author")
+		require.NoError(t, err)
+		require.Len(t, nodes, 1)
+		assert.Equal(t, root.ID, nodes[0].Comment.ID)
+
+		nodes, err = storage.GetCommentTree(ctx, postID, 10, 0, 10, "spammer")
+		require.NoError(t, err)
+		assert.Len(t, nodes, 3)
+	})
+
+	t.Run("count shadowed comments only for author", func(t *testing.T) {
+		counts, err := storage.CountAllCommentsByPostIDs(ctx, []uuid.UUID{postID}, "")
+		require.NoError(t, err)
+		assert.Equal(t, 2, counts[postID])
+
+		counts, err = storage.CountAllCommentsByPostIDs(ctx, []uuid.UUID{postID}, "spammer")
+		require.NoError(t, err)
+		assert.Equal(t, 3, counts[postID])
+	})
+
+	t.Run("skip shadowed comment in search", func(t *testing.T) {
+		results, err := storage.SearchComments(ctx, "казино", nil, 10)
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("keep edited comment shadowed", func(t *testing.T) {
+		updated, err := storage.UpdateComment(ctx, shadowed.ID, "казино рядом", false)
+		require.NoError(t, err)
+		assert.True(t, updated.IsShadowed)
+	})
+
+	t.Run("shadow edited comment", func(t *testing.T) {
+		updated, err := storage.UpdateComment(ctx, root.ID, "реклама", true)
+		require.NoError(t, err)
+		assert.True(t, updated.IsShadowed)
+
+		counts, err := storage.CountAllCommentsByPostIDs(ctx, []uuid.UUID{postID}, "")
+		require.NoError(t, err)
+		assert.Equal(t, 1, counts[postID])
+
+		counts, err = storage.CountAllCommentsByPostIDs(ctx, []uuid.UUID{postID}, "test_author")
+		require.NoError(t, err)
+		assert.Equal(t, 2, counts[postID])
+	})
+}
+
+func TestCommentsStorageMem_HasDuplicate(t *testing.T) {
+	ctx := context.Background()
+	storage := NewCommentsStorageMem(NewPostStorageMem())
+
+	created, err := storage.CreateComment(ctx, models.Comment{Author: "spammer", Content: "Подпишитесь  на канал",
+		PostID: uuid.New()})
+	require.NoError(t, err)
+	hash := models.ContentHash("подпишитесь на канал")
+	hourAgo := time.Now().Add(-time.Hour)
+
+	found, err := storage.HasDuplicate(ctx, "spammer", hash, hourAgo, uuid.Nil)
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	// тот же текст у другого автора, сам редактируемый комментарий и старые комментарии не считаются
+	found, err = storage.HasDuplicate(ctx, "test_author", hash, hourAgo, uuid.Nil)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	found, err = storage.HasDuplicate(ctx, "spammer", hash, hourAgo, created.ID)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	found, err = storage.HasDuplicate(ctx, "spammer", hash, time.Now().Add(time.Minute), uuid.Nil)
 	require.NoError(t, err)
-	assert.Len(t, comments, goroutines*commentsPerRoutine)
+	assert.False(t, found)
 }
@@ -0,0 +1,173 @@
+package mem
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/internal/storage"
+)
+
+type CommunityStorageMem struct {
+	communities map[uuid.UUID]*models.Community
+	bySlug      map[string]uuid.UUID
+	members     map[uuid.UUID]map[string]struct{} // участники сообществ по id сообщества
+	moderators  map[uuid.UUID]map[string]struct{} // назначенные модераторы сообществ (без создателей)
+	mu          sync.RWMutex
+}
+
+func NewCommunityStorageMem() *CommunityStorageMem {
+	return &CommunityStorageMem{
+		communities: make(map[uuid.UUID]*models.Community),
+		bySlug:      make(map[string]uuid.UUID),
+		members:     make(map[uuid.UUID]map[string]struct{}),
+		moderators:  make(map[uuid.UUID]map[string]struct{}),
+	}
+}
+
+func (s *CommunityStorageMem) CreateCommunity(_ context.Context,
+	community models.Community) (models.Community, error) {
+	now := time.Now()
+	community.ID = uuid.New()
+	community.CreatedAt = &now
+	community.MemberCount = 1
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.bySlug[community.Slug]; ok {
+		return models.Community{}, fmt.Errorf("community with slug: %s: %w", community.Slug, storage.ErrAlreadyExists)
+	}
+
+	s.communities[community.ID] = &community
+	s.bySlug[community.Slug] = community.ID
+	s.members[community.ID] = map[string]struct{}{community.Owner: {}}
+	s.moderators[community.ID] = make(map[string]struct{})
+	return community, nil
+}
+
+func (s *CommunityStorageMem) GetCommunityBySlug(_ context.Context, slug string) (*models.Community, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	id, ok := s.bySlug[slug]
+	if !ok {
+		return nil, fmt.Errorf("community with slug: %s not found: %w", slug, sql.ErrNoRows)
+	}
+
+	community := *s.communities[id]
+	return &community, nil
+}
+
+func (s *CommunityStorageMem) GetCommunitiesByIDs(_ context.Context,
+	ids []uuid.UUID) (map[uuid.UUID]*models.Community, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	res := make(map[uuid.UUID]*models.Community, len(ids))
+	for _, id := range ids {
+		if community, ok := s.communities[id]; ok {
+			copied := *community
+			res[id] = &copied
+		}
+	}
+	return res, nil
+}
+
+func (s *CommunityStorageMem) AddMember(_ context.Context, communityID uuid.UUID,
+	username string) (models.Community, error) {
+	return s.changeMembers(communityID, func(members map[string]struct{}) {
+		members[username] = struct{}{}
+	})
+}
+
+func (s *CommunityStorageMem) RemoveMember(_ context.Context, communityID uuid.UUID,
+	username string) (models.Community, error) {
+	return s.changeMembers(communityID, func(members map[string]struct{}) {
+		delete(members, username)
+	})
+}
+
+func (s *CommunityStorageMem) GetMemberships(_ context.Context, communityIDs []uuid.UUID,
+	username string) (map[uuid.UUID]bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	res := make(map[uuid.UUID]bool, len(communityIDs))
+	for _, id := range communityIDs {
+		if _, ok := s.members[id][username]; ok {
+			res[id] = true
+		}
+	}
+	return res, nil
+}
+
+func (s *CommunityStorageMem) IsModerator(_ context.Context, communityID uuid.UUID, username string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	community, ok := s.communities[communityID]
+	if !ok {
+		return false, nil
+	}
+
+	_, ok = s.moderators[communityID][username]
+	return ok || community.Owner == username, nil
+}
+
+// назначение модератора, вызывается хранилищем модерации
+func (s *CommunityStorageMem) addModerator(communityID uuid.UUID, username string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	moderators, ok := s.moderators[communityID]
+	if !ok {
+		return fmt.Errorf("community with id: %s not found: %w", communityID.String(), sql.ErrNoRows)
+	}
+	if _, ok = moderators[username]; ok {
+		return fmt.Errorf("moderator %s of community %s: %w", username, communityID.String(),
+			storage.ErrAlreadyExists)
+	}
+
+	moderators[username] = struct{}{}
+	return nil
+}
+
+// снятие модератора, вызывается хранилищем модерации
+func (s *CommunityStorageMem) removeModerator(communityID uuid.UUID, username string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.moderators[communityID][username]; !ok {
+		return fmt.Errorf("moderator %s of community %s not found: %w", username, communityID.String(),
+			sql.ErrNoRows)
+	}
+
+	delete(s.moderators[communityID], username)
+	return nil
+}
+
+// изменение участников сообщества, счетчик участников пересчитывается по результату
+func (s *CommunityStorageMem) changeMembers(communityID uuid.UUID,
+	change func(members map[string]struct{})) (models.Community, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	community, ok := s.communities[communityID]
+	if !ok {
+		return models.Community{}, fmt.Errorf("community with id: %s not found: %w", communityID.String(),
+			sql.ErrNoRows)
+	}
+
+	change(s.members[communityID])
+
+	// подменяем указатель на копию, чтобы не менять сообщество, которое уже могло быть отдано читателям
+	updated := *community
+	updated.MemberCount = int32(len(s.members[communityID]))
+	s.communities[communityID] = &updated
+	return updated, nil
+}
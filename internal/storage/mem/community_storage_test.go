@@ -0,0 +1,78 @@
+package mem
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommunityStorageMem(t *testing.T) {
+	ctx := context.Background()
+	store := NewCommunityStorageMem()
+
+	community, err := store.CreateCommunity(ctx, models.Community{
+		Slug:          "golang",
+		Title:         "Go",
+		Owner:         "test_owner",
+		PostPolicy:    models.PostPolicyMembers,
+		CommentPolicy: models.CommentPolicyOpen,
+	})
+	require.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, community.ID)
+	assert.NotNil(t, community.CreatedAt)
+	assert.Equal(t, int32(1), community.MemberCount)
+
+	t.Run("fail on duplicate slug", func(t *testing.T) {
+		_, err := store.CreateCommunity(ctx, models.Community{Slug: "golang", Title: "Go again", Owner: "another"})
+		assert.ErrorIs(t, err, storage.ErrAlreadyExists)
+	})
+
+	t.Run("get by slug", func(t *testing.T) {
+		found, err := store.GetCommunityBySlug(ctx, "golang")
+		require.NoError(t, err)
+		assert.Equal(t, community, *found)
+
+		_, err = store.GetCommunityBySlug(ctx, "missing")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("join and leave", func(t *testing.T) {
+		joined, err := store.AddMember(ctx, community.ID, "test_user")
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), joined.MemberCount)
+
+		// повторное вступление не меняет счетчик
+		joined, err = store.AddMember(ctx, community.ID, "test_user")
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), joined.MemberCount)
+
+		memberships, err := store.GetMemberships(ctx, []uuid.UUID{community.ID, uuid.New()}, "test_user")
+		require.NoError(t, err)
+		assert.Equal(t, map[uuid.UUID]bool{community.ID: true}, memberships)
+
+		left, err := store.RemoveMember(ctx, community.ID, "test_user")
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), left.MemberCount)
+
+		memberships, err = store.GetMemberships(ctx, []uuid.UUID{community.ID}, "test_user")
+		require.NoError(t, err)
+		assert.Empty(t, memberships)
+
+		_, err = store.AddMember(ctx, uuid.New(), "test_user")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("get by ids", func(t *testing.T) {
+		missing := uuid.New()
+		communities, err := store.GetCommunitiesByIDs(ctx, []uuid.UUID{community.ID, missing})
+		require.NoError(t, err)
+		require.Len(t, communities, 1)
+		assert.Equal(t, "golang", communities[community.ID].Slug)
+	})
+}
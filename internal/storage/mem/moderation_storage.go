@@ -0,0 +1,136 @@
+package mem
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/models"
+)
+
+// скрытие контента, роли и модераторы хранятся в остальных хранилищах, поэтому хранилище модерации работает
+// поверх них и ведет журнал. Изменение и запись о нем делаются под одним мьютексом, чтобы порядок записей
+// совпадал с порядком изменений
+type ModerationStorageMem struct {
+	posts       *PostStorageMem
+	comms       *CommentsStorageMem
+	users       *UserStorageMem
+	communities *CommunityStorageMem
+	log         []*models.ModerationAction // журнал от старых записей к новым, только дополняется
+	mu          sync.RWMutex
+}
+
+func NewModerationStorageMem(posts *PostStorageMem, comms *CommentsStorageMem, users *UserStorageMem,
+	communities *CommunityStorageMem) *ModerationStorageMem {
+	return &ModerationStorageMem{
+		posts:       posts,
+		comms:       comms,
+		users:       users,
+		communities: communities,
+	}
+}
+
+func (s *ModerationStorageMem) RemovePost(_ context.Context, postID uuid.UUID,
+	action models.ModerationAction) (models.Post, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	post, err := s.posts.markRemoved(postID)
+	if err != nil {
+		return models.Post{}, err
+	}
+
+	s.append(action)
+	return post, nil
+}
+
+func (s *ModerationStorageMem) RemoveComment(_ context.Context, commentID uuid.UUID,
+	action models.ModerationAction) (models.Comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	comment, err := s.comms.markRemoved(commentID)
+	if err != nil {
+		return models.Comment{}, err
+	}
+
+	s.append(action)
+	return comment, nil
+}
+
+func (s *ModerationStorageMem) SetUserRole(_ context.Context, username string, role models.Role,
+	action models.ModerationAction) (models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, err := s.users.setRole(username, role)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	s.append(action)
+	return user, nil
+}
+
+func (s *ModerationStorageMem) AddCommunityModerator(_ context.Context, communityID uuid.UUID, username string,
+	action models.ModerationAction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.communities.addModerator(communityID, username); err != nil {
+		return err
+	}
+
+	s.append(action)
+	return nil
+}
+
+func (s *ModerationStorageMem) RemoveCommunityModerator(_ context.Context, communityID uuid.UUID, username string,
+	action models.ModerationAction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.communities.removeModerator(communityID, username); err != nil {
+		return err
+	}
+
+	s.append(action)
+	return nil
+}
+
+func (s *ModerationStorageMem) GetModerationLog(_ context.Context, communityID *uuid.UUID,
+	page models.Page) ([]*models.ModerationAction, error) {
+	s.mu.RLock()
+	actions := make([]*models.ModerationAction, 0, len(s.log))
+	for _, action := range s.log {
+		if communityID == nil || (action.CommunityID != nil && *action.CommunityID == *communityID) {
+			copied := *action
+			actions = append(actions, &copied)
+		}
+	}
+	s.mu.RUnlock()
+
+	return paginate(actions, page, models.ModerationActionCursor, true)
+}
+
+// последнее действие с постом (commentID == nil) или комментарием, вызывается под мьютексом
+func (s *ModerationStorageMem) lastAction(postID uuid.UUID, commentID *uuid.UUID) (models.ModerationActionType, bool) {
+	for _, action := range slices.Backward(s.log) {
+		if commentID == nil && action.CommentID == nil && action.PostID != nil && *action.PostID == postID ||
+			commentID != nil && action.CommentID != nil && *action.CommentID == *commentID {
+			return action.Action, true
+		}
+	}
+	return "", false
+}
+
+// вызывается под мьютексом
+func (s *ModerationStorageMem) append(action models.ModerationAction) {
+	now := time.Now()
+	action.ID = uuid.New()
+	action.CreatedAt = &now
+
+	s.log = append(s.log, &action)
+}
@@ -0,0 +1,128 @@
+package mem
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModerationStorageMem(t *testing.T) {
+	ctx := context.Background()
+	posts := NewPostStorageMem()
+	comms := NewCommentsStorageMem(posts)
+	users, communities := NewUserStorageMem(), NewCommunityStorageMem()
+	store := NewModerationStorageMem(posts, comms, users, communities)
+
+	community, err := communities.CreateCommunity(ctx, models.Community{Slug: "golang", Title: "Go",
+		Owner: "test_owner"})
+	require.NoError(t, err)
+
+	post, err := posts.CreatePost(ctx, models.Post{Title: "Spam", Content: "buy now", Author: "spammer",
+		CommunityID: &community.ID})
+	require.NoError(t, err)
+	comment, err := comms.CreateComment(ctx, models.Comment{PostID: post.ID, Author: "spammer", Content: "spam"})
+	require.NoError(t, err)
+
+	t.Run("remove post", func(t *testing.T) {
+		removed, err := store.RemovePost(ctx, post.ID, models.ModerationAction{Actor: "test_owner",
+			Action: models.ModerationActionTypeRemovePost, PostID: &post.ID, CommunityID: &community.ID,
+			Reason: "spam"})
+		require.NoError(t, err)
+		assert.True(t, removed.IsRemoved)
+
+		// повторное скрытие - ошибка
+		_, err = store.RemovePost(ctx, post.ID, models.ModerationAction{})
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+
+		// из ленты пост пропадает, но виден модераторам
+		feed := models.PostFeed{Sort: models.PostSortNew}
+		visible, err := posts.GetAllPosts(ctx, feed, models.Page{Limit: 10})
+		require.NoError(t, err)
+		assert.Empty(t, visible)
+
+		feed.IncludeRemoved = true
+		all, err := posts.GetAllPosts(ctx, feed, models.Page{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, all, 1)
+		assert.True(t, all[0].IsRemoved)
+	})
+
+	t.Run("remove comment", func(t *testing.T) {
+		removed, err := store.RemoveComment(ctx, comment.ID, models.ModerationAction{Actor: "test_owner",
+			Action: models.ModerationActionTypeRemoveComment, CommentID: &comment.ID, CommunityID: &community.ID,
+			Reason: "spam"})
+		require.NoError(t, err)
+		assert.True(t, removed.IsRemoved)
+
+		_, err = store.RemoveComment(ctx, uuid.New(), models.ModerationAction{})
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("set user role", func(t *testing.T) {
+		_, err := users.CreateUser(ctx, models.User{Username: "test_user", Role: models.RoleUser})
+		require.NoError(t, err)
+
+		role := models.RoleModerator
+		user, err := store.SetUserRole(ctx, "test_user", role, models.ModerationAction{Actor: "test_admin",
+			Action: models.ModerationActionTypeSetRole, Role: &role})
+		require.NoError(t, err)
+		assert.Equal(t, models.RoleModerator, user.Role)
+
+		found, err := users.GetUserByUsername(ctx, "test_user")
+		require.NoError(t, err)
+		assert.Equal(t, models.RoleModerator, found.Role)
+	})
+
+	t.Run("add and remove community moderator", func(t *testing.T) {
+		action := models.ModerationAction{Actor: "test_owner", Action: models.ModerationActionTypeAddModerator,
+			CommunityID: &community.ID}
+		require.NoError(t, store.AddCommunityModerator(ctx, community.ID, "test_user", action))
+		assert.ErrorIs(t, store.AddCommunityModerator(ctx, community.ID, "test_user", action),
+			storage.ErrAlreadyExists)
+
+		isModerator, err := communities.IsModerator(ctx, community.ID, "test_user")
+		require.NoError(t, err)
+		assert.True(t, isModerator)
+
+		// создатель модерирует сообщество без назначения
+		isModerator, err = communities.IsModerator(ctx, community.ID, "test_owner")
+		require.NoError(t, err)
+		assert.True(t, isModerator)
+
+		action.Action = models.ModerationActionTypeRemoveModerator
+		require.NoError(t, store.RemoveCommunityModerator(ctx, community.ID, "test_user", action))
+		assert.ErrorIs(t, store.RemoveCommunityModerator(ctx, community.ID, "test_user", action), sql.ErrNoRows)
+
+		isModerator, err = communities.IsModerator(ctx, community.ID, "test_user")
+		require.NoError(t, err)
+		assert.False(t, isModerator)
+	})
+
+	t.Run("get moderation log", func(t *testing.T) {
+		all, err := store.GetModerationLog(ctx, nil, models.Page{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, all, 5)
+		assert.Equal(t, models.ModerationActionTypeRemoveModerator, all[0].Action)
+		assert.Equal(t, models.ModerationActionTypeRemovePost, all[4].Action)
+
+		// смена роли не относится к сообществу
+		inCommunity, err := store.GetModerationLog(ctx, &community.ID, models.Page{Limit: 10})
+		require.NoError(t, err)
+		assert.Len(t, inCommunity, 4)
+
+		firstPage, err := store.GetModerationLog(ctx, nil, models.Page{Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, firstPage, 2)
+
+		after := models.ModerationActionCursor(firstPage[1])
+		nextPage, err := store.GetModerationLog(ctx, nil, models.Page{Limit: 10, After: &after})
+		require.NoError(t, err)
+		assert.Equal(t, all[2:], nextPage)
+	})
+}
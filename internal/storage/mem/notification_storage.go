@@ -0,0 +1,82 @@
+package mem
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/models"
+)
+
+type NotificationStorageMem struct {
+	byRecipient map[string][]*models.Notification
+	mu          sync.RWMutex
+}
+
+func NewNotificationStorageMem() *NotificationStorageMem {
+	return &NotificationStorageMem{
+		byRecipient: make(map[string][]*models.Notification),
+	}
+}
+
+func (s *NotificationStorageMem) CreateNotification(_ context.Context,
+	notification models.Notification) (models.Notification, error) {
+	now := time.Now()
+	notification.ID = uuid.New()
+	notification.CreatedAt = &now
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := notification
+	s.byRecipient[notification.Recipient] = append(s.byRecipient[notification.Recipient], &stored)
+	return notification, nil
+}
+
+func (s *NotificationStorageMem) GetNotifications(_ context.Context, recipient string,
+	page models.Page) ([]*models.Notification, error) {
+	s.mu.RLock()
+	// отдаем копии, так как уведомление может быть отмечено прочитанным
+	notifications := make([]*models.Notification, 0, len(s.byRecipient[recipient]))
+	for _, notification := range s.byRecipient[recipient] {
+		n := *notification
+		notifications = append(notifications, &n)
+	}
+	s.mu.RUnlock()
+
+	return paginate(notifications, page, models.NotificationCursor, true)
+}
+
+func (s *NotificationStorageMem) CountUnreadNotifications(_ context.Context, recipient string) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	count := 0
+	for _, notification := range s.byRecipient[recipient] {
+		if !notification.IsRead {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *NotificationStorageMem) MarkNotificationsRead(_ context.Context, recipient string,
+	ids []uuid.UUID) (int, error) {
+	marked := make(map[uuid.UUID]struct{}, len(ids))
+	for _, id := range ids {
+		marked[id] = struct{}{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, notification := range s.byRecipient[recipient] {
+		if _, ok := marked[notification.ID]; ok && !notification.IsRead {
+			notification.IsRead = true
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,66 @@
+package mem
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationStorageMem(t *testing.T) {
+	ctx := context.Background()
+	storage := NewNotificationStorageMem()
+
+	var created []models.Notification
+	for range 3 {
+		n, err := storage.CreateNotification(ctx, models.Notification{
+			Recipient: "test_user",
+			Type:      models.NotificationTypeReply,
+			Actor:     "replier",
+			PostID:    uuid.New(),
+			CommentID: uuid.New(),
+		})
+		require.NoError(t, err)
+		created = append(created, n)
+		time.Sleep(time.Millisecond)
+	}
+	_, err := storage.CreateNotification(ctx, models.Notification{Recipient: "another_user"})
+	require.NoError(t, err)
+
+	t.Run("get notifications from newest", func(t *testing.T) {
+		page, err := storage.GetNotifications(ctx, "test_user", models.Page{Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, page, 2)
+		assert.Equal(t, created[2].ID, page[0].ID)
+		assert.Equal(t, created[1].ID, page[1].ID)
+
+		after := models.NotificationCursor(page[1])
+		page, err = storage.GetNotifications(ctx, "test_user", models.Page{Limit: 2, After: &after})
+		require.NoError(t, err)
+		require.Len(t, page, 1)
+		assert.Equal(t, created[0].ID, page[0].ID)
+	})
+
+	t.Run("mark only own notifications", func(t *testing.T) {
+		marked, err := storage.MarkNotificationsRead(ctx, "another_user", []uuid.UUID{created[0].ID})
+		require.NoError(t, err)
+		assert.Zero(t, marked)
+
+		marked, err = storage.MarkNotificationsRead(ctx, "test_user", []uuid.UUID{created[0].ID, created[1].ID})
+		require.NoError(t, err)
+		assert.Equal(t, 2, marked)
+
+		// повторная отметка ничего не меняет
+		marked, err = storage.MarkNotificationsRead(ctx, "test_user", []uuid.UUID{created[0].ID})
+		require.NoError(t, err)
+		assert.Zero(t, marked)
+
+		unread, err := storage.CountUnreadNotifications(ctx, "test_user")
+		require.NoError(t, err)
+		assert.Equal(t, 1, unread)
+	})
+}
@@ -0,0 +1,47 @@
+package mem
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/nedokyrill/posts-service/internal/models"
+)
+
+// keyset-пагинация по (key, created_at, id), повторяет поведение postgres-хранилища.
+// desc - порядок выдачи (true - от больших к меньшим), items сортируется на месте
+func paginate[T any](items []T, page models.Page, cursorOf func(T) models.Cursor, desc bool) ([]T, error) {
+	if page.Limit < 0 {
+		return nil, errors.New("invalid argument")
+	}
+
+	// сравнение в порядке выдачи
+	compare := func(a, b models.Cursor) int {
+		if desc {
+			return b.Compare(a)
+		}
+		return a.Compare(b)
+	}
+
+	slices.SortFunc(items, func(a, b T) int {
+		return compare(cursorOf(a), cursorOf(b))
+	})
+
+	res := make([]T, 0, len(items))
+	for _, item := range items {
+		if page.After != nil && compare(cursorOf(item), *page.After) <= 0 {
+			continue
+		}
+		if page.Before != nil && compare(cursorOf(item), *page.Before) >= 0 {
+			continue
+		}
+		res = append(res, item)
+	}
+
+	if len(res) <= page.Limit {
+		return res, nil
+	}
+	if page.FromEnd {
+		return res[len(res)-page.Limit:], nil
+	}
+	return res[:page.Limit], nil
+}
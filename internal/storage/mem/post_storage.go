@@ -1,9 +1,13 @@
 package mem
 
 import (
+	"cmp"
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,39 +18,40 @@ import (
 
 type PostStorageMem struct {
 	posts []*models.Post
+	index *searchIndex // поисковый индекс по названию и тексту постов
 	mu    sync.RWMutex
 }
 
 func NewPostStorageMem() *PostStorageMem {
 	return &PostStorageMem{
 		posts: make([]*models.Post, 0, consts.InitPostsSizeInMem),
+		index: newSearchIndex(consts.SearchTitleWeight, consts.SearchContentWeight),
 	}
 }
 
-func (s *PostStorageMem) GetAllPosts(_ context.Context, offset, limit int) ([]*models.Post, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	if offset > len(s.posts) {
-		return nil, nil
-	}
-
-	if offset+limit > len(s.posts) {
-		return s.posts[offset:], nil
-	}
+func (s *PostStorageMem) GetAllPosts(_ context.Context, feed models.PostFeed, page models.Page) ([]*models.Post, error) {
+	return paginate(s.feed(feed), page, models.PostSortCursor(feed.Sort), true)
+}
 
-	return s.posts[offset : offset+limit], nil
+func (s *PostStorageMem) CountPosts(_ context.Context, feed models.PostFeed) (int, error) {
+	return len(s.feed(feed)), nil
 }
 
 func (s *PostStorageMem) CreatePost(_ context.Context, post models.Post) (models.Post, error) {
 	now := time.Now()
 	post.ID = uuid.New()
 	post.CreatedAt = &now
+	post.Tags = slices.Clone(post.Tags)
+	post.UpdateRanking()
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	s.posts = append(s.posts, &post)
+	// скрытые фильтром контента посты не ищутся, как и в postgres-хранилище
+	if !post.IsShadowed {
+		s.index.put(post.ID, post.Title, post.Content)
+	}
 	return post, nil
 }
 
@@ -60,5 +65,247 @@ func (s *PostStorageMem) GetPostByID(_ context.Context, postId uuid.UUID) (*mode
 		}
 	}
 
-	return &models.Post{}, errors.New(fmt.Sprintf("Post with id: %s not found", postId.String()))
+	return &models.Post{}, errPostNotFound(postId)
+}
+
+func (s *PostStorageMem) UpdatePost(_ context.Context, post models.Post) (models.Post, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.posts {
+		if s.posts[i].ID == post.ID {
+			// подменяем указатель на копию, чтобы не менять пост, который уже мог быть отдан читателям
+			updated := *s.posts[i]
+			updated.Title = post.Title
+			updated.Content = post.Content
+			updated.Tags = slices.Clone(post.Tags)
+			updated.IsShadowed = updated.IsShadowed || post.IsShadowed // скрытый фильтром пост остается скрытым
+
+			s.posts[i] = &updated
+			if updated.IsRemoved || updated.IsShadowed {
+				s.index.remove(updated.ID)
+			} else {
+				s.index.put(updated.ID, updated.Title, updated.Content)
+			}
+			return updated, nil
+		}
+	}
+
+	return models.Post{}, errPostNotFound(post.ID)
+}
+
+func (s *PostStorageMem) DeletePost(_ context.Context, postId uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.posts {
+		if s.posts[i].ID == postId {
+			s.posts = append(s.posts[:i], s.posts[i+1:]...)
+			s.index.remove(postId)
+			return nil
+		}
+	}
+
+	return errPostNotFound(postId)
+}
+
+func (s *PostStorageMem) SetCommentsAllowed(_ context.Context, postId uuid.UUID, allowed bool) (models.Post, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.posts {
+		if s.posts[i].ID == postId {
+			updated := *s.posts[i]
+			updated.IsCommentsAllowed = allowed
+
+			s.posts[i] = &updated
+			return updated, nil
+		}
+	}
+
+	return models.Post{}, errPostNotFound(postId)
+}
+
+func (s *PostStorageMem) SearchPosts(_ context.Context, query string,
+	page models.Page) ([]*models.PostSearchResult, error) {
+	res, err := paginate(s.search(query), page, models.PostSearchCursor, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// фрагменты строим только для отдаваемой страницы
+	for _, r := range res {
+		r.Snippet = highlight(r.Post.Content, query)
+	}
+	return res, nil
+}
+
+func (s *PostStorageMem) CountSearchPosts(_ context.Context, query string) (int, error) {
+	return len(s.index.search(query)), nil
+}
+
+func (s *PostStorageMem) GetPopularTags(_ context.Context, limit int) ([]*models.Tag, error) {
+	if limit < 0 {
+		return nil, errors.New("invalid argument")
+	}
+
+	s.mu.RLock()
+	counts := make(map[string]int32)
+	for _, post := range s.posts {
+		if post.IsRemoved || post.IsShadowed {
+			continue
+		}
+		for _, tag := range post.Tags {
+			counts[tag]++
+		}
+	}
+	s.mu.RUnlock()
+
+	tags := make([]*models.Tag, 0, len(counts))
+	for name, count := range counts {
+		tags = append(tags, &models.Tag{Name: name, PostCount: count})
+	}
+
+	// от популярных к менее популярным, при равенстве - по алфавиту, как и в postgres-хранилище
+	slices.SortFunc(tags, func(a, b *models.Tag) int {
+		if res := cmp.Compare(b.PostCount, a.PostCount); res != 0 {
+			return res
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	if len(tags) > limit {
+		tags = tags[:limit]
+	}
+	return tags, nil
+}
+
+func (s *PostStorageMem) HasDuplicate(_ context.Context, author, contentHash string, since time.Time,
+	excludeID uuid.UUID) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, post := range s.posts {
+		if post.Author == author && post.ID != excludeID && !post.CreatedAt.Before(since) &&
+			models.ContentHash(post.Content) == contentHash {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// изменение счетчиков голосов, вызывается хранилищем голосов
+func (s *PostStorageMem) addVotes(postId uuid.UUID, up, down int) (models.Post, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.posts {
+		if s.posts[i].ID == postId {
+			updated := *s.posts[i]
+			updated.Upvotes += int32(up)
+			updated.Downvotes += int32(down)
+			updated.UpdateRanking()
+
+			s.posts[i] = &updated
+			return updated, nil
+		}
+	}
+
+	return models.Post{}, errPostNotFound(postId)
+}
+
+// скрытие поста модератором, вызывается хранилищем модерации
+func (s *PostStorageMem) markRemoved(postId uuid.UUID) (models.Post, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.posts {
+		if s.posts[i].ID == postId && !s.posts[i].IsRemoved {
+			updated := *s.posts[i]
+			updated.IsRemoved = true
+
+			s.posts[i] = &updated
+			s.index.remove(postId)
+			return updated, nil
+		}
+	}
+
+	return models.Post{}, errPostNotFound(postId)
+}
+
+// какие из постов комментариев скрыты модератором или фильтром контента, вызывается хранилищем комментариев
+func (s *PostStorageMem) hiddenPosts(comments []*models.Comment) map[uuid.UUID]bool {
+	postIDs := make(map[uuid.UUID]bool, len(comments))
+	for _, comment := range comments {
+		postIDs[comment.PostID] = true
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	hidden := make(map[uuid.UUID]bool)
+	for _, post := range s.posts {
+		if postIDs[post.ID] && (post.IsRemoved || post.IsShadowed) {
+			hidden[post.ID] = true
+		}
+	}
+	return hidden
+}
+
+// восстановление скрытого поста, вызывается хранилищем жалоб
+func (s *PostStorageMem) restore(postId uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.posts {
+		if s.posts[i].ID == postId && s.posts[i].IsRemoved {
+			updated := *s.posts[i]
+			updated.IsRemoved = false
+
+			s.posts[i] = &updated
+			if !updated.IsShadowed {
+				s.index.put(updated.ID, updated.Title, updated.Content)
+			}
+			return nil
+		}
+	}
+
+	return errPostNotFound(postId)
+}
+
+// посты, попадающие в ленту (без учета пагинации)
+func (s *PostStorageMem) feed(feed models.PostFeed) []*models.Post {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	posts := make([]*models.Post, 0, len(s.posts))
+	for _, post := range s.posts {
+		if feed.Since != nil && (post.CreatedAt == nil || post.CreatedAt.Before(*feed.Since)) {
+			continue
+		}
+		if feed.Matches(post) {
+			posts = append(posts, post)
+		}
+	}
+	return posts
+}
+
+// найденные посты без учета пагинации
+func (s *PostStorageMem) search(query string) []*models.PostSearchResult {
+	ranks := s.index.search(query)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	res := make([]*models.PostSearchResult, 0, len(ranks))
+	for _, post := range s.posts {
+		if rank, ok := ranks[post.ID]; ok {
+			res = append(res, &models.PostSearchResult{Post: post, Rank: rank})
+		}
+	}
+	return res
+}
+
+func errPostNotFound(postId uuid.UUID) error {
+	return fmt.Errorf("Post with id: %s not found: %w", postId.String(), sql.ErrNoRows)
 }
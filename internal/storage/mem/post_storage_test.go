@@ -3,6 +3,7 @@ package mem
 import (
 	"context"
 	"fmt"
+	"slices"
 	"testing"
 	"time"
 
@@ -12,6 +13,9 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// лента по умолчанию: от новых к старым за все время
+var newFeed = models.PostFeed{Sort: models.PostSortNew}
+
 func TestPostStorageMem_CreatePost(t *testing.T) {
 	ctx := context.Background()
 	title := "test_title"
@@ -149,113 +153,207 @@ func TestPostStorageMem_GetAllPosts(t *testing.T) {
 	author := "test_author"
 	content := "test_content"
 
+	createPosts := func(t *testing.T, storage *PostStorageMem, count int) []uuid.UUID {
+		ids := make([]uuid.UUID, 0, count)
+		for i := 0; i < count; i++ {
+			created, err := storage.CreatePost(ctx, models.Post{
+				Title:             fmt.Sprintf("%s %d", title, i),
+				Author:            author,
+				Content:           content,
+				IsCommentsAllowed: true,
+			})
+			require.NoError(t, err)
+			ids = append(ids, created.ID)
+			time.Sleep(time.Millisecond) // для разных времен создания
+		}
+		return ids
+	}
+
+	postIDs := func(posts []*models.Post) []uuid.UUID {
+		ids := make([]uuid.UUID, 0, len(posts))
+		for _, post := range posts {
+			ids = append(ids, post.ID)
+		}
+		return ids
+	}
+
 	t.Run("with empty storage", func(t *testing.T) {
 		storage := NewPostStorageMem()
 
-		posts, err := storage.GetAllPosts(ctx, 0, 10)
+		posts, err := storage.GetAllPosts(ctx, newFeed, models.Page{Limit: 10})
 		require.NoError(t, err)
 		assert.Empty(t, posts)
 	})
 
-	t.Run("with single post", func(t *testing.T) {
+	t.Run("with invalid limit", func(t *testing.T) {
 		storage := NewPostStorageMem()
 
-		post := models.Post{
-			Title:             title,
-			Author:            author,
-			Content:           content,
-			IsCommentsAllowed: true,
-		}
+		_, err := storage.GetAllPosts(ctx, newFeed, models.Page{Limit: -1})
+		assert.Error(t, err)
+	})
 
-		created, err := storage.CreatePost(ctx, post)
-		require.NoError(t, err)
+	t.Run("with single post", func(t *testing.T) {
+		storage := NewPostStorageMem()
+
+		ids := createPosts(t, storage, 1)
 
-		posts, err := storage.GetAllPosts(ctx, 0, 10)
+		posts, err := storage.GetAllPosts(ctx, newFeed, models.Page{Limit: 10})
 		require.NoError(t, err)
 		assert.Len(t, posts, 1)
-		assert.Equal(t, created.ID, posts[0].ID)
+		assert.Equal(t, ids[0], posts[0].ID)
 	})
 
-	t.Run("with multiple posts", func(t *testing.T) {
+	t.Run("with multiple posts from newest to oldest", func(t *testing.T) {
 		storage := NewPostStorageMem()
 
 		postCount := 5
-		for i := 0; i < postCount; i++ {
-			post := models.Post{
-				Title:             fmt.Sprintf("%s %d", title, i),
-				Author:            author,
-				Content:           content,
-				IsCommentsAllowed: i%2 == 0, // чередуем разрешение комментариев
-			}
-			_, err := storage.CreatePost(ctx, post)
-			require.NoError(t, err)
-			time.Sleep(time.Millisecond) // для разных времен создания
-		}
+		createPosts(t, storage, postCount)
 
-		posts, err := storage.GetAllPosts(ctx, 0, 10)
+		posts, err := storage.GetAllPosts(ctx, newFeed, models.Page{Limit: 10})
 		require.NoError(t, err)
 		assert.Len(t, posts, postCount)
 
 		for i := 1; i < len(posts); i++ {
-			assert.True(t, posts[i-1].CreatedAt.Before(*posts[i].CreatedAt) ||
-				posts[i-1].CreatedAt.Equal(*posts[i].CreatedAt))
+			assert.True(t, posts[i-1].CreatedAt.After(*posts[i].CreatedAt))
 		}
 	})
 
-	t.Run("with pagination", func(t *testing.T) {
+	t.Run("with forward pagination", func(t *testing.T) {
 		storage := NewPostStorageMem()
 
-		postCount := 10
-		for i := 0; i < postCount; i++ {
-			post := models.Post{
-				Title:             fmt.Sprintf("%s %d", title, i),
-				Author:            author,
-				Content:           content,
-				IsCommentsAllowed: true,
-			}
-			_, err := storage.CreatePost(ctx, post)
+		ids := createPosts(t, storage, 10)
+		slices.Reverse(ids) // от новых к старым
+
+		posts, err := storage.GetAllPosts(ctx, newFeed, models.Page{Limit: 3})
+		require.NoError(t, err)
+		assert.Equal(t, ids[:3], postIDs(posts))
+
+		after := models.PostCursor(posts[2])
+		posts, err = storage.GetAllPosts(ctx, newFeed, models.Page{Limit: 3, After: &after})
+		require.NoError(t, err)
+		assert.Equal(t, ids[3:6], postIDs(posts))
+
+		after = models.PostCursor(posts[2])
+		posts, err = storage.GetAllPosts(ctx, newFeed, models.Page{Limit: 10, After: &after})
+		require.NoError(t, err)
+		assert.Equal(t, ids[6:], postIDs(posts))
+
+		after = models.PostCursor(posts[3])
+		posts, err = storage.GetAllPosts(ctx, newFeed, models.Page{Limit: 10, After: &after})
+		require.NoError(t, err)
+		assert.Empty(t, posts)
+	})
+
+	t.Run("with backward pagination", func(t *testing.T) {
+		storage := NewPostStorageMem()
+
+		ids := createPosts(t, storage, 10)
+		slices.Reverse(ids)
+
+		posts, err := storage.GetAllPosts(ctx, newFeed, models.Page{Limit: 3, FromEnd: true})
+		require.NoError(t, err)
+		assert.Equal(t, ids[7:], postIDs(posts))
+
+		before := models.PostCursor(posts[0])
+		posts, err = storage.GetAllPosts(ctx, newFeed, models.Page{Limit: 3, Before: &before, FromEnd: true})
+		require.NoError(t, err)
+		assert.Equal(t, ids[4:7], postIDs(posts))
+
+		after := models.PostCursor(posts[0])
+		posts, err = storage.GetAllPosts(ctx, newFeed, models.Page{Limit: 10, After: &after, Before: &before})
+		require.NoError(t, err)
+		assert.Equal(t, ids[5:7], postIDs(posts))
+	})
+
+	t.Run("new posts do not shift next page", func(t *testing.T) {
+		storage := NewPostStorageMem()
+
+		ids := createPosts(t, storage, 6)
+		slices.Reverse(ids)
+
+		posts, err := storage.GetAllPosts(ctx, newFeed, models.Page{Limit: 3})
+		require.NoError(t, err)
+		after := models.PostCursor(posts[2])
+
+		createPosts(t, storage, 2)
+
+		posts, err = storage.GetAllPosts(ctx, newFeed, models.Page{Limit: 3, After: &after})
+		require.NoError(t, err)
+		assert.Equal(t, ids[3:], postIDs(posts))
+	})
+
+	t.Run("count posts", func(t *testing.T) {
+		storage := NewPostStorageMem()
+
+		createPosts(t, storage, 4)
+
+		count, err := storage.CountPosts(ctx, newFeed)
+		require.NoError(t, err)
+		assert.Equal(t, 4, count)
+	})
+
+	t.Run("with top sort", func(t *testing.T) {
+		storage := NewPostStorageMem()
+		topFeed := models.PostFeed{Sort: models.PostSortTop}
+
+		ids := createPosts(t, storage, 4)
+		for i, votes := range [][2]int{{1, 0}, {5, 1}, {0, 3}, {2, 0}} {
+			_, err := storage.addVotes(ids[i], votes[0], votes[1])
 			require.NoError(t, err)
 		}
 
-		posts, err := storage.GetAllPosts(ctx, 0, 3)
+		posts, err := storage.GetAllPosts(ctx, topFeed, models.Page{Limit: 2})
 		require.NoError(t, err)
-		assert.Len(t, posts, 3)
+		assert.Equal(t, []uuid.UUID{ids[1], ids[3]}, postIDs(posts))
 
-		posts, err = storage.GetAllPosts(ctx, 5, 10)
+		after := models.PostSortCursor(models.PostSortTop)(posts[1])
+		posts, err = storage.GetAllPosts(ctx, topFeed, models.Page{Limit: 10, After: &after})
 		require.NoError(t, err)
-		assert.Len(t, posts, 5)
+		assert.Equal(t, []uuid.UUID{ids[0], ids[2]}, postIDs(posts))
+	})
+
+	t.Run("with hot sort", func(t *testing.T) {
+		storage := NewPostStorageMem()
 
-		posts, err = storage.GetAllPosts(ctx, 2, 4)
+		ids := createPosts(t, storage, 3)
+		_, err := storage.addVotes(ids[0], 100, 0)
 		require.NoError(t, err)
-		assert.Len(t, posts, 4)
 
-		posts, err = storage.GetAllPosts(ctx, 15, 10)
+		posts, err := storage.GetAllPosts(ctx, models.PostFeed{Sort: models.PostSortHot}, models.Page{Limit: 10})
 		require.NoError(t, err)
-		assert.Nil(t, posts)
+		assert.Equal(t, []uuid.UUID{ids[0], ids[2], ids[1]}, postIDs(posts))
 	})
 
-	t.Run("with boundary conditions", func(t *testing.T) {
+	t.Run("with controversial sort", func(t *testing.T) {
 		storage := NewPostStorageMem()
 
-		for i := 0; i < 3; i++ {
-			post := models.Post{
-				Title:             fmt.Sprintf("%s %d", title, i),
-				Author:            author,
-				Content:           content,
-				IsCommentsAllowed: true,
-			}
-			_, err := storage.CreatePost(ctx, post)
+		ids := createPosts(t, storage, 3)
+		for i, votes := range [][2]int{{10, 1}, {5, 5}, {9, 0}} {
+			_, err := storage.addVotes(ids[i], votes[0], votes[1])
 			require.NoError(t, err)
 		}
 
-		posts, err := storage.GetAllPosts(ctx, 3, 10)
+		posts, err := storage.GetAllPosts(ctx, models.PostFeed{Sort: models.PostSortControversial}, models.Page{Limit: 10})
 		require.NoError(t, err)
-		assert.NotNil(t, posts)
-		assert.Empty(t, posts)
+		assert.Equal(t, []uuid.UUID{ids[1], ids[0], ids[2]}, postIDs(posts))
+	})
+
+	t.Run("with time window", func(t *testing.T) {
+		storage := NewPostStorageMem()
+
+		createPosts(t, storage, 2)
+		since := time.Now()
+		ids := createPosts(t, storage, 2)
+		slices.Reverse(ids)
+
+		feed := models.PostFeed{Sort: models.PostSortNew, Since: &since}
+		posts, err := storage.GetAllPosts(ctx, feed, models.Page{Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, ids, postIDs(posts))
 
-		posts, err = storage.GetAllPosts(ctx, 1, 5)
+		count, err := storage.CountPosts(ctx, feed)
 		require.NoError(t, err)
-		assert.Len(t, posts, 2)
+		assert.Equal(t, 2, count)
 	})
 }
 
@@ -294,7 +392,7 @@ func TestPostStorageMem_ConcurrentAccess(t *testing.T) {
 	}
 	close(createdIDs)
 
-	posts, err := storage.GetAllPosts(ctx, 0, goroutines*postsPerRoutine)
+	posts, err := storage.GetAllPosts(ctx, newFeed, models.Page{Limit: goroutines * postsPerRoutine})
 	require.NoError(t, err)
 	assert.Len(t, posts, goroutines*postsPerRoutine)
 
@@ -304,3 +402,342 @@ func TestPostStorageMem_ConcurrentAccess(t *testing.T) {
 		assert.Equal(t, id, post.ID)
 	}
 }
+
+func TestPostStorageMem_UpdatePost(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("with existing post", func(t *testing.T) {
+		storage := NewPostStorageMem()
+
+		created, err := storage.CreatePost(ctx, models.Post{
+			Title:             "old_title",
+			Author:            "test_author",
+			Content:           "old_content",
+			IsCommentsAllowed: true,
+		})
+		require.NoError(t, err)
+
+		updated, err := storage.UpdatePost(ctx, models.Post{
+			ID:                created.ID,
+			Title:             "new_title",
+			Author:            "another_author",
+			Content:           "new_content",
+			IsCommentsAllowed: false,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, created.ID, updated.ID)
+		assert.Equal(t, "new_title", updated.Title)
+		assert.Equal(t, "new_content", updated.Content)
+		assert.True(t, updated.IsCommentsAllowed)      // комментарии меняются только через SetCommentsAllowed
+		assert.Equal(t, "test_author", updated.Author) // автор поста не меняется
+		assert.Equal(t, created.CreatedAt, updated.CreatedAt)
+
+		retrieved, err := storage.GetPostByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, updated, *retrieved)
+	})
+
+	t.Run("does not change previously returned post", func(t *testing.T) {
+		storage := NewPostStorageMem()
+
+		created, err := storage.CreatePost(ctx, models.Post{Title: "old_title", Author: "test_author"})
+		require.NoError(t, err)
+
+		before, err := storage.GetPostByID(ctx, created.ID)
+		require.NoError(t, err)
+
+		_, err = storage.UpdatePost(ctx, models.Post{ID: created.ID, Title: "new_title"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "old_title", before.Title)
+	})
+
+	t.Run("with non-existent post", func(t *testing.T) {
+		storage := NewPostStorageMem()
+
+		nonExistentID := uuid.New()
+		_, err := storage.UpdatePost(ctx, models.Post{ID: nonExistentID, Title: "new_title"})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), nonExistentID.String())
+	})
+}
+
+func TestPostStorageMem_DeletePost(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("with existing post", func(t *testing.T) {
+		storage := NewPostStorageMem()
+
+		var ids []uuid.UUID
+		for i := 0; i < 3; i++ {
+			created, err := storage.CreatePost(ctx, models.Post{
+				Title:  fmt.Sprintf("test_title %d", i),
+				Author: "test_author",
+			})
+			require.NoError(t, err)
+			ids = append(ids, created.ID)
+		}
+
+		before, err := storage.GetAllPosts(ctx, newFeed, models.Page{Limit: 10})
+		require.NoError(t, err)
+
+		err = storage.DeletePost(ctx, ids[1])
+		require.NoError(t, err)
+
+		_, err = storage.GetPostByID(ctx, ids[1])
+		assert.Error(t, err)
+
+		posts, err := storage.GetAllPosts(ctx, newFeed, models.Page{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, posts, 2)
+		assert.ElementsMatch(t, []uuid.UUID{ids[0], ids[2]}, []uuid.UUID{posts[0].ID, posts[1].ID})
+
+		// ранее полученный список постов не должен измениться
+		require.Len(t, before, 3)
+	})
+
+	t.Run("with non-existent post", func(t *testing.T) {
+		storage := NewPostStorageMem()
+
+		nonExistentID := uuid.New()
+		err := storage.DeletePost(ctx, nonExistentID)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), nonExistentID.String())
+	})
+}
+
+func TestPostStorageMem_SetCommentsAllowed(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("with existing post", func(t *testing.T) {
+		storage := NewPostStorageMem()
+
+		created, err := storage.CreatePost(ctx, models.Post{
+			Title:             "test_title",
+			Author:            "test_author",
+			Content:           "test_content",
+			IsCommentsAllowed: true,
+		})
+		require.NoError(t, err)
+
+		updated, err := storage.SetCommentsAllowed(ctx, created.ID, false)
+		require.NoError(t, err)
+		assert.False(t, updated.IsCommentsAllowed)
+		assert.Equal(t, created.Title, updated.Title)
+
+		retrieved, err := storage.GetPostByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.False(t, retrieved.IsCommentsAllowed)
+
+		updated, err = storage.SetCommentsAllowed(ctx, created.ID, true)
+		require.NoError(t, err)
+		assert.True(t, updated.IsCommentsAllowed)
+	})
+
+	t.Run("with non-existent post", func(t *testing.T) {
+		storage := NewPostStorageMem()
+
+		nonExistentID := uuid.New()
+		_, err := storage.SetCommentsAllowed(ctx, nonExistentID, false)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), nonExistentID.String())
+	})
+}
+
+func TestPostStorageMem_SearchPosts(t *testing.T) {
+	ctx := context.Background()
+	storage := NewPostStorageMem()
+
+	var ids []uuid.UUID
+	for i := range 5 {
+		created, err := storage.CreatePost(ctx, models.Post{
+			Title:   fmt.Sprintf("Пост %d", i),
+			Author:  "test_author",
+			Content: "Текст про полнотекстовый поиск",
+		})
+		require.NoError(t, err)
+		ids = append(ids, created.ID)
+	}
+
+	// совпадение в названии весит больше, чем в тексте
+	best, err := storage.CreatePost(ctx, models.Post{Title: "Поиск", Author: "test_author", Content: "О поиске"})
+	require.NoError(t, err)
+
+	t.Run("ranked pages with snippets", func(t *testing.T) {
+		first, err := storage.SearchPosts(ctx, "поиск", models.Page{Limit: 4})
+		require.NoError(t, err)
+		require.Len(t, first, 4)
+		assert.Equal(t, best.ID, first[0].Post.ID)
+		assert.Equal(t, "О <mark>поиске</mark>", first[0].Snippet)
+		assert.Equal(t, "Текст про полнотекстовый <mark>поиск</mark>", first[1].Snippet)
+
+		after := models.PostSearchCursor(first[3])
+		second, err := storage.SearchPosts(ctx, "поиск", models.Page{Limit: 4, After: &after})
+		require.NoError(t, err)
+		require.Len(t, second, 2)
+
+		var found []uuid.UUID
+		for _, r := range append(first[1:], second...) {
+			found = append(found, r.Post.ID)
+		}
+		assert.ElementsMatch(t, ids, found)
+
+		count, err := storage.CountSearchPosts(ctx, "поиск")
+		require.NoError(t, err)
+		assert.Equal(t, 6, count)
+	})
+
+	t.Run("index follows updates and deletes", func(t *testing.T) {
+		_, err := storage.UpdatePost(ctx, models.Post{ID: ids[0], Title: "Пост 0", Content: "Новый текст"})
+		require.NoError(t, err)
+		require.NoError(t, storage.DeletePost(ctx, ids[1]))
+
+		count, err := storage.CountSearchPosts(ctx, "поиск")
+		require.NoError(t, err)
+		assert.Equal(t, 4, count)
+
+		found, err := storage.SearchPosts(ctx, "новый", models.Page{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, found, 1)
+		assert.Equal(t, ids[0], found[0].Post.ID)
+	})
+}
+
+func TestPostStorageMem_Tags(t *testing.T) {
+	ctx := context.Background()
+	storage := NewPostStorageMem()
+
+	create := func(tags ...string) uuid.UUID {
+		created, err := storage.CreatePost(ctx, models.Post{Title: "test_title", Author: "test_author", Tags: tags})
+		require.NoError(t, err)
+		assert.Equal(t, tags, created.Tags)
+		return created.ID
+	}
+
+	goSQL := create("go", "sql")
+	goOnly := create("go")
+	sqlOnly := create("sql")
+	create()
+
+	feedIDs := func(feed models.PostFeed) []uuid.UUID {
+		posts, err := storage.GetAllPosts(ctx, feed, models.Page{Limit: 10})
+		require.NoError(t, err)
+
+		count, err := storage.CountPosts(ctx, feed)
+		require.NoError(t, err)
+		assert.Len(t, posts, count)
+
+		var ids []uuid.UUID
+		for _, post := range posts {
+			ids = append(ids, post.ID)
+		}
+		return ids
+	}
+
+	t.Run("filter feed by any tag", func(t *testing.T) {
+		ids := feedIDs(models.PostFeed{Sort: models.PostSortNew, Tags: []string{"go", "sql"}, Match: models.TagMatchAny})
+		assert.ElementsMatch(t, []uuid.UUID{goSQL, goOnly, sqlOnly}, ids)
+	})
+
+	t.Run("filter feed by all tags", func(t *testing.T) {
+		ids := feedIDs(models.PostFeed{Sort: models.PostSortNew, Tags: []string{"go", "sql"}, Match: models.TagMatchAll})
+		assert.Equal(t, []uuid.UUID{goSQL}, ids)
+	})
+
+	t.Run("filter feed by community", func(t *testing.T) {
+		communityID := uuid.New()
+		created, err := storage.CreatePost(ctx, models.Post{Title: "test_title", Author: "test_author",
+			Tags: []string{"go"}, CommunityID: &communityID})
+		require.NoError(t, err)
+		defer func() { require.NoError(t, storage.DeletePost(ctx, created.ID)) }()
+
+		ids := feedIDs(models.PostFeed{Sort: models.PostSortNew, CommunityID: &communityID})
+		assert.Equal(t, []uuid.UUID{created.ID}, ids)
+
+		ids = feedIDs(models.PostFeed{Sort: models.PostSortNew, Tags: []string{"sql"}, Match: models.TagMatchAny,
+			CommunityID: &communityID})
+		assert.Empty(t, ids)
+	})
+
+	t.Run("popular tags", func(t *testing.T) {
+		create("sql", "rust")
+
+		tags, err := storage.GetPopularTags(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []*models.Tag{
+			{Name: "sql", PostCount: 3},
+			{Name: "go", PostCount: 2},
+			{Name: "rust", PostCount: 1},
+		}, tags)
+
+		tags, err = storage.GetPopularTags(ctx, 1)
+		require.NoError(t, err)
+		assert.Len(t, tags, 1)
+	})
+
+	t.Run("update replaces tags", func(t *testing.T) {
+		updated, err := storage.UpdatePost(ctx, models.Post{ID: goOnly, Title: "test_title", Tags: []string{"rust"}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"rust"}, updated.Tags)
+
+		ids := feedIDs(models.PostFeed{Sort: models.PostSortNew, Tags: []string{"go"}})
+		assert.Equal(t, []uuid.UUID{goSQL}, ids)
+	})
+}
+
+func TestPostStorageMem_Shadowed(t *testing.T) {
+	ctx := context.Background()
+	storage := NewPostStorageMem()
+
+	_, err := storage.CreatePost(ctx, models.Post{Title: "Обычный пост", Author: "test_author", Tags: []string{"go"}})
+	require.NoError(t, err)
+	shadowed, err := storage.CreatePost(ctx, models.Post{Title: "Казино", Content: "реклама казино",
+		Author: "spammer", Tags: []string{"casino"}, IsShadowed: true})
+	require.NoError(t, err)
+
+	t.Run("show shadowed post only to author", func(t *testing.T) {
+		for viewer, want := range map[string]int{"": 1, "test_author": 1, "spammer": 2} {
+			feed := models.PostFeed{Sort: models.PostSortNew, Viewer: viewer}
+
+			posts, err := storage.GetAllPosts(ctx, feed, models.Page{Limit: 10})
+			require.NoError(t, err)
+			assert.Len(t, posts, want, viewer)
+
+			count, err := storage.CountPosts(ctx, feed)
+			require.NoError(t, err)
+			assert.Equal(t, want, count, viewer)
+		}
+	})
+
+	t.Run("skip shadowed post in search and tags", func(t *testing.T) {
+		results, err := storage.SearchPosts(ctx, "казино", models.Page{Limit: 10})
+		require.NoError(t, err)
+		assert.Empty(t, results)
+
+		tags, err := storage.GetPopularTags(ctx, 10)
+		require.NoError(t, err)
+		require.Len(t, tags, 1)
+		assert.Equal(t, "go", tags[0].Name)
+	})
+
+	t.Run("keep edited post shadowed", func(t *testing.T) {
+		updated, err := storage.UpdatePost(ctx, models.Post{ID: shadowed.ID, Title: "Казино", Content: "казино"})
+		require.NoError(t, err)
+		assert.True(t, updated.IsShadowed)
+
+		results, err := storage.SearchPosts(ctx, "казино", models.Page{Limit: 10})
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("find duplicate of shadowed post", func(t *testing.T) {
+		found, err := storage.HasDuplicate(ctx, "spammer", models.ContentHash("Казино"), time.Now().Add(-time.Hour),
+			uuid.Nil)
+		require.NoError(t, err)
+		assert.True(t, found)
+	})
+}
@@ -0,0 +1,71 @@
+package mem
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+)
+
+// корзина хранит токены на момент последнего обращения, восполнение считается при следующем
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	limit   models.RateLimit
+}
+
+// full - восполнилась ли корзина к моменту now, такую можно удалить: новая будет такой же
+func (b *bucket) full(now time.Time) bool {
+	return b.tokens+now.Sub(b.updated).Seconds()*b.limit.Rate() >= float64(b.limit.Burst)
+}
+
+type RateLimitStorageMem struct {
+	buckets map[string]*bucket
+	swept   time.Time // когда последний раз удалялись полные корзины
+	mu      sync.Mutex
+}
+
+func NewRateLimitStorageMem() *RateLimitStorageMem {
+	return &RateLimitStorageMem{
+		buckets: make(map[string]*bucket),
+		swept:   time.Now(),
+	}
+}
+
+func (s *RateLimitStorageMem) TakeToken(_ context.Context, key string, limit models.RateLimit) (bool,
+	time.Duration, error) {
+	taken, retryAfter := s.take(key, limit, time.Now())
+	return taken, retryAfter, nil
+}
+
+func (s *RateLimitStorageMem) take(key string, limit models.RateLimit, now time.Time) (bool, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.swept) >= consts.RateLimitSweepInterval {
+		for k, b := range s.buckets {
+			if b.full(now) {
+				delete(s.buckets, k)
+			}
+		}
+		s.swept = now
+	}
+
+	rate := limit.Rate()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst)}
+		s.buckets[key] = b
+	} else {
+		b.tokens = min(float64(limit.Burst), b.tokens+now.Sub(b.updated).Seconds()*rate)
+	}
+	b.updated, b.limit = now, limit
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
@@ -0,0 +1,42 @@
+package mem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitStorageMem(t *testing.T) {
+	store := NewRateLimitStorageMem()
+	limit := models.RateLimit{Burst: 2, Per: 10 * time.Second} // токен каждые 5 секунд
+	now := time.Now()
+
+	t.Run("take burst and refill", func(t *testing.T) {
+		for range limit.Burst {
+			taken, _ := store.take("key", limit, now)
+			assert.True(t, taken)
+		}
+
+		taken, retryAfter := store.take("key", limit, now.Add(time.Second))
+		assert.False(t, taken)
+		assert.Equal(t, 4*time.Second, retryAfter)
+
+		// у другого ключа своя корзина
+		taken, _ = store.take("other", limit, now)
+		assert.True(t, taken)
+
+		taken, _ = store.take("key", limit, now.Add(5*time.Second))
+		assert.True(t, taken)
+	})
+
+	t.Run("sweep full buckets", func(t *testing.T) {
+		later := now.Add(consts.RateLimitSweepInterval)
+		store.take("fresh", limit, later)
+
+		assert.Len(t, store.buckets, 1)
+		assert.Contains(t, store.buckets, "fresh")
+	})
+}
@@ -0,0 +1,206 @@
+package mem
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/internal/storage"
+)
+
+// жалобы меняются под мьютексом хранилища модерации: скрытие и восстановление контента по жалобам пишутся в его
+// журнал, а решение о восстановлении зависит от последней записи о контенте
+type ReportStorageMem struct {
+	moderation *ModerationStorageMem
+	reports    []*models.Report // от старых к новым
+	mu         sync.RWMutex
+}
+
+func NewReportStorageMem(moderation *ModerationStorageMem) *ReportStorageMem {
+	return &ReportStorageMem{
+		moderation: moderation,
+	}
+}
+
+func (s *ReportStorageMem) CreateReport(ctx context.Context, report models.Report, hideThreshold int,
+	hide models.ModerationAction) (models.Report, error) {
+	s.moderation.mu.Lock()
+	defer s.moderation.mu.Unlock()
+
+	visible, err := s.isVisible(ctx, report)
+	if err != nil {
+		return models.Report{}, err
+	}
+	if !visible {
+		return models.Report{}, errTargetNotFound(report)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	open := 0
+	for _, r := range s.reports {
+		if r.TargetType != report.TargetType || r.TargetID != report.TargetID {
+			continue
+		}
+		if r.Reporter == report.Reporter {
+			return models.Report{}, fmt.Errorf("report of %s by %s: %w", report.TargetID.String(), report.Reporter,
+				storage.ErrAlreadyExists)
+		}
+		if r.Status == models.ReportStatusOpen {
+			open++
+		}
+	}
+
+	now := time.Now()
+	report.ID = uuid.New()
+	report.Status = models.ReportStatusOpen
+	report.CreatedAt = &now
+
+	stored := report
+	s.reports = append(s.reports, &stored)
+
+	if hideThreshold > 0 && open+1 >= hideThreshold {
+		if err = s.markRemoved(report); err != nil {
+			return models.Report{}, err
+		}
+		s.moderation.append(hide)
+	}
+	return report, nil
+}
+
+func (s *ReportStorageMem) GetReportByID(ctx context.Context, reportID uuid.UUID) (*models.Report, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, report := range s.reports {
+		if report.ID == reportID && s.postExists(ctx, report.PostID) {
+			copied := *report
+			return &copied, nil
+		}
+	}
+	return nil, errReportNotFound(reportID)
+}
+
+func (s *ReportStorageMem) GetReports(ctx context.Context, status models.ReportStatus,
+	page models.Page) ([]*models.Report, error) {
+	s.mu.RLock()
+	reports := make([]*models.Report, 0)
+	for _, report := range s.reports {
+		if report.Status == status && s.postExists(ctx, report.PostID) {
+			copied := *report
+			reports = append(reports, &copied)
+		}
+	}
+	s.mu.RUnlock()
+
+	return paginate(reports, page, models.ReportCursor, false)
+}
+
+func (s *ReportStorageMem) ResolveReport(_ context.Context, reportID uuid.UUID, status models.ReportStatus,
+	resolver string, action models.ModerationAction) (models.Report, error) {
+	s.moderation.mu.Lock()
+	defer s.moderation.mu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var resolved *models.Report
+	for _, report := range s.reports {
+		if report.ID == reportID && report.Status == models.ReportStatusOpen {
+			resolved = report
+		}
+	}
+	if resolved == nil {
+		return models.Report{}, errReportNotFound(reportID)
+	}
+	target := *resolved
+
+	// решение принимается по контенту, поэтому закрываем все открытые жалобы на него
+	now := time.Now()
+	for _, report := range s.reports {
+		if report.TargetType == target.TargetType && report.TargetID == target.TargetID &&
+			report.Status == models.ReportStatusOpen {
+			report.Status = status
+			report.ResolvedBy = &resolver
+			report.ResolvedAt = &now
+		}
+	}
+
+	switch status {
+	case models.ReportStatusRemoved:
+		if err := s.markRemoved(target); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return models.Report{}, err
+		}
+		s.moderation.append(action)
+	case models.ReportStatusDismissed:
+		// восстанавливаем только контент, скрытый по жалобам, а не модератором
+		if last, ok := s.moderation.lastAction(target.PostID, target.CommentID()); ok && last.IsAutoHide() {
+			if err := s.restore(target); err != nil {
+				return models.Report{}, err
+			}
+			s.moderation.append(action)
+		}
+	}
+
+	return *resolved, nil
+}
+
+// виден ли контент жалобы: пост или комментарий существует и не скрыт, комментарий не удален
+func (s *ReportStorageMem) isVisible(ctx context.Context, report models.Report) (bool, error) {
+	if report.TargetType == models.ReportTargetTypeComment {
+		comment, err := s.moderation.comms.GetCommentByID(ctx, report.TargetID)
+		if err != nil {
+			return false, ignoreNotFound(err)
+		}
+		return !comment.IsRemoved && !comment.IsDeleted, nil
+	}
+
+	post, err := s.moderation.posts.GetPostByID(ctx, report.TargetID)
+	if err != nil {
+		return false, ignoreNotFound(err)
+	}
+	return !post.IsRemoved, nil
+}
+
+// жалобы на удаленный пост и его комментарии пропадают вместе с ним, как при каскадном удалении в postgres
+func (s *ReportStorageMem) postExists(ctx context.Context, postID uuid.UUID) bool {
+	_, err := s.moderation.posts.GetPostByID(ctx, postID)
+	return err == nil
+}
+
+func (s *ReportStorageMem) markRemoved(report models.Report) error {
+	if report.TargetType == models.ReportTargetTypeComment {
+		_, err := s.moderation.comms.markRemoved(report.TargetID)
+		return err
+	}
+	_, err := s.moderation.posts.markRemoved(report.TargetID)
+	return err
+}
+
+func (s *ReportStorageMem) restore(report models.Report) error {
+	if report.TargetType == models.ReportTargetTypeComment {
+		return s.moderation.comms.restore(report.TargetID)
+	}
+	return s.moderation.posts.restore(report.TargetID)
+}
+
+func ignoreNotFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	return err
+}
+
+func errTargetNotFound(report models.Report) error {
+	return fmt.Errorf("%s with id: %s not found: %w", report.TargetType, report.TargetID.String(), sql.ErrNoRows)
+}
+
+func errReportNotFound(reportID uuid.UUID) error {
+	return fmt.Errorf("Report with id: %s not found: %w", reportID.String(), sql.ErrNoRows)
+}
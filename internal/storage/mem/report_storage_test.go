@@ -0,0 +1,122 @@
+package mem
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportStorageMem(t *testing.T) {
+	ctx := context.Background()
+	posts := NewPostStorageMem()
+	comms := NewCommentsStorageMem(posts)
+	moderation := NewModerationStorageMem(posts, comms, NewUserStorageMem(), NewCommunityStorageMem())
+	store := NewReportStorageMem(moderation)
+
+	post, err := posts.CreatePost(ctx, models.Post{Title: "Spam", Content: "buy now", Author: "spammer"})
+	require.NoError(t, err)
+
+	postReport := func(reporter string) models.Report {
+		return models.Report{TargetType: models.ReportTargetTypePost, TargetID: post.ID, PostID: post.ID,
+			Reporter: reporter, Reason: models.ReportReasonSpam}
+	}
+	hide := models.ModerationAction{Actor: "system", Action: models.ModerationActionTypeAutoHidePost,
+		PostID: &post.ID}
+	isRemoved := func() bool {
+		found, err := posts.GetPostByID(ctx, post.ID)
+		require.NoError(t, err)
+		return found.IsRemoved
+	}
+
+	first, err := store.CreateReport(ctx, postReport("first"), 2, hide)
+	require.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, first.ID)
+	assert.Equal(t, models.ReportStatusOpen, first.Status)
+	assert.False(t, isRemoved())
+
+	t.Run("fail on repeated report", func(t *testing.T) {
+		_, err := store.CreateReport(ctx, postReport("first"), 2, hide)
+		assert.ErrorIs(t, err, storage.ErrAlreadyExists)
+	})
+
+	t.Run("hide content at threshold", func(t *testing.T) {
+		_, err := store.CreateReport(ctx, postReport("second"), 2, hide)
+		require.NoError(t, err)
+		assert.True(t, isRemoved())
+
+		// на скрытый контент больше не жалуются
+		_, err = store.CreateReport(ctx, postReport("third"), 2, hide)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+
+		queue, err := store.GetReports(ctx, models.ReportStatusOpen, models.Page{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, queue, 2)
+		assert.Equal(t, first.ID, queue[0].ID)
+	})
+
+	t.Run("dismiss restores auto-hidden content", func(t *testing.T) {
+		resolved, err := store.ResolveReport(ctx, first.ID, models.ReportStatusDismissed, "test_moderator",
+			models.ModerationAction{Actor: "test_moderator", Action: models.ModerationActionTypeRestorePost,
+				PostID: &post.ID})
+		require.NoError(t, err)
+		assert.Equal(t, models.ReportStatusDismissed, resolved.Status)
+		assert.Equal(t, "test_moderator", *resolved.ResolvedBy)
+		assert.False(t, isRemoved())
+
+		// решение закрыло обе жалобы на пост
+		open, err := store.GetReports(ctx, models.ReportStatusOpen, models.Page{Limit: 10})
+		require.NoError(t, err)
+		assert.Empty(t, open)
+
+		_, err = store.ResolveReport(ctx, first.ID, models.ReportStatusRemoved, "test_moderator",
+			models.ModerationAction{})
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+
+		log, err := moderation.GetModerationLog(ctx, nil, models.Page{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, log, 2)
+		assert.Equal(t, models.ModerationActionTypeRestorePost, log[0].Action)
+		assert.Equal(t, models.ModerationActionTypeAutoHidePost, log[1].Action)
+	})
+
+	t.Run("remove reported comment", func(t *testing.T) {
+		comment, err := comms.CreateComment(ctx, models.Comment{PostID: post.ID, Author: "spammer", Content: "spam"})
+		require.NoError(t, err)
+
+		report, err := store.CreateReport(ctx, models.Report{TargetType: models.ReportTargetTypeComment,
+			TargetID: comment.ID, PostID: post.ID, Reporter: "first", Reason: models.ReportReasonAbuse}, 0, hide)
+		require.NoError(t, err)
+
+		resolved, err := store.ResolveReport(ctx, report.ID, models.ReportStatusRemoved, "test_moderator",
+			models.ModerationAction{Actor: "test_moderator", Action: models.ModerationActionTypeRemoveComment,
+				PostID: &post.ID, CommentID: &comment.ID})
+		require.NoError(t, err)
+		assert.Equal(t, models.ReportStatusRemoved, resolved.Status)
+
+		found, err := comms.GetCommentByID(ctx, comment.ID)
+		require.NoError(t, err)
+		assert.True(t, found.IsRemoved)
+
+		removed, err := store.GetReports(ctx, models.ReportStatusRemoved, models.Page{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, removed, 1)
+		assert.Equal(t, report.ID, removed[0].ID)
+	})
+
+	t.Run("drop reports with deleted post", func(t *testing.T) {
+		require.NoError(t, posts.DeletePost(ctx, post.ID))
+
+		_, err := store.GetReportByID(ctx, first.ID)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+
+		dismissed, err := store.GetReports(ctx, models.ReportStatusDismissed, models.Page{Limit: 10})
+		require.NoError(t, err)
+		assert.Empty(t, dismissed)
+	})
+}
@@ -0,0 +1,201 @@
+package mem
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+)
+
+// окончания, которые отрезаются от слов, от длинных к коротким (упрощенный стемминг для русского и английского)
+var searchSuffixes = []string{
+	"ями", "ами", "ого", "его", "ому", "ему", "ыми", "ими", "ией", "ing",
+	"ях", "ах", "ям", "ам", "ой", "ей", "ий", "ый", "ая", "яя", "ое", "ее", "ые", "ие", "ов", "ев", "ом", "ем", "ую",
+	"юю", "ия", "ии", "ed", "es", "ly",
+	"а", "я", "о", "е", "ы", "и", "у", "ю", "ь", "й", "s",
+}
+
+// searchIndex - инвертированный индекс для полнотекстового поиска в памяти: терм -> документы с количеством
+// вхождений терма в каждое поле документа. Термы - слова в нижнем регистре с отрезанными окончаниями,
+// поэтому "комментарии" находится по запросу "комментарий". Поля документа имеют веса, как setweight в postgres
+type searchIndex struct {
+	weights  []float64
+	postings map[string]map[uuid.UUID][]int // терм -> документ -> количество вхождений по полям
+	lengths  map[uuid.UUID][]int            // количество слов в полях документа
+	terms    map[uuid.UUID][]string         // термы документа, чтобы удалить его из индекса
+	mu       sync.RWMutex
+}
+
+func newSearchIndex(weights ...float64) *searchIndex {
+	return &searchIndex{
+		weights:  weights,
+		postings: make(map[string]map[uuid.UUID][]int),
+		lengths:  make(map[uuid.UUID][]int),
+		terms:    make(map[uuid.UUID][]string),
+	}
+}
+
+// put индексирует документ заново, fields идут в порядке весов
+func (ix *searchIndex) put(id uuid.UUID, fields ...string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	ix.removeLocked(id)
+
+	lengths := make([]int, len(fields))
+	for f, field := range fields {
+		tokens := tokenize(field)
+		lengths[f] = len(tokens)
+
+		for _, token := range tokens {
+			docs, ok := ix.postings[token.term]
+			if !ok {
+				docs = make(map[uuid.UUID][]int)
+				ix.postings[token.term] = docs
+			}
+			if _, ok = docs[id]; !ok {
+				docs[id] = make([]int, len(fields))
+				ix.terms[id] = append(ix.terms[id], token.term)
+			}
+			docs[id][f]++
+		}
+	}
+	ix.lengths[id] = lengths
+}
+
+func (ix *searchIndex) remove(id uuid.UUID) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	ix.removeLocked(id)
+}
+
+func (ix *searchIndex) removeLocked(id uuid.UUID) {
+	for _, term := range ix.terms[id] {
+		delete(ix.postings[term], id)
+		if len(ix.postings[term]) == 0 {
+			delete(ix.postings, term)
+		}
+	}
+	delete(ix.terms, id)
+	delete(ix.lengths, id)
+}
+
+// search возвращает документы, содержащие все слова запроса, с их релевантностью: сумма по словам и полям
+// веса поля, умноженного на число вхождений и нормированного на логарифм длины поля
+func (ix *searchIndex) search(query string) map[uuid.UUID]float64 {
+	terms := queryTerms(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	var ranks map[uuid.UUID]float64
+	for term := range terms {
+		docs := ix.postings[term]
+
+		next := make(map[uuid.UUID]float64, len(docs))
+		for id, counts := range docs {
+			rank, ok := ranks[id]
+			if ranks != nil && !ok {
+				continue
+			}
+			for f, count := range counts {
+				rank += ix.weights[f] * float64(count) / (1 + math.Log(1+float64(ix.lengths[id][f])))
+			}
+			next[id] = rank
+		}
+		ranks = next
+	}
+	return ranks
+}
+
+type searchToken struct {
+	term       string
+	start, end int // байтовые границы слова в тексте
+}
+
+// разбиваем текст на слова (последовательности букв и цифр) и приводим их к термам
+func tokenize(text string) []searchToken {
+	var tokens []searchToken
+
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case isWord && start < 0:
+			start = i
+		case !isWord && start >= 0:
+			tokens = append(tokens, searchToken{term: stem(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, searchToken{term: stem(text[start:]), start: start, end: len(text)})
+	}
+	return tokens
+}
+
+func queryTerms(query string) map[string]struct{} {
+	terms := make(map[string]struct{})
+	for _, token := range tokenize(query) {
+		terms[token.term] = struct{}{}
+	}
+	return terms
+}
+
+// отрезаем окончание, оставляя основу не короче трех букв
+func stem(word string) string {
+	word = strings.ReplaceAll(strings.ToLower(word), "ё", "е")
+	if utf8.RuneCountInString(word) <= 4 {
+		return word
+	}
+
+	for _, suffix := range searchSuffixes {
+		if strings.HasSuffix(word, suffix) && utf8.RuneCountInString(word)-utf8.RuneCountInString(suffix) >= 3 {
+			return strings.TrimSuffix(word, suffix)
+		}
+	}
+	return word
+}
+
+// фрагмент текста вокруг первого найденного слова, найденные слова выделяются, как в ts_headline
+func highlight(text, query string) string {
+	tokens := tokenize(text)
+	if len(tokens) == 0 {
+		return ""
+	}
+	terms := queryTerms(query)
+
+	first := 0
+	for i, token := range tokens {
+		if _, ok := terms[token.term]; ok {
+			first = i
+			break
+		}
+	}
+
+	start := max(0, first-consts.SearchSnippetWords/3)
+	end := min(len(tokens), start+consts.SearchSnippetWords)
+	start = max(0, end-consts.SearchSnippetWords)
+
+	var b strings.Builder
+	pos := tokens[start].start
+	for _, token := range tokens[start:end] {
+		if _, ok := terms[token.term]; ok {
+			b.WriteString(text[pos:token.start])
+			b.WriteString(consts.SearchHighlightStart)
+			b.WriteString(text[token.start:token.end])
+			b.WriteString(consts.SearchHighlightStop)
+			pos = token.end
+		}
+	}
+	b.WriteString(text[pos:tokens[end-1].end])
+	return b.String()
+}
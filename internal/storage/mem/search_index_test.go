@@ -0,0 +1,98 @@
+package mem
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStem(t *testing.T) {
+	testCases := []struct {
+		word string
+		stem string
+	}{
+		{word: "Комментарии", stem: "комментар"},
+		{word: "комментарий", stem: "комментар"},
+		{word: "постами", stem: "пост"},
+		{word: "Ёлка", stem: "елка"}, // короткие слова не обрезаются
+		{word: "searching", stem: "search"},
+		{word: "posts", stem: "post"},
+		{word: "go", stem: "go"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.word, func(t *testing.T) {
+			assert.Equal(t, tc.stem, stem(tc.word))
+		})
+	}
+}
+
+func TestSearchIndex(t *testing.T) {
+	index := newSearchIndex(1, 0.4)
+	title, content, both := uuid.New(), uuid.New(), uuid.New()
+
+	index.put(title, "Поиск по постам", "обычный текст")
+	index.put(content, "Заголовок", "Текст про поиск, mixed content")
+	index.put(both, "Поиск", "и еще раз поиск")
+
+	t.Run("ranks by field weights", func(t *testing.T) {
+		ranks := index.search("поиска")
+
+		require.Len(t, ranks, 3)
+		assert.Greater(t, ranks[both], ranks[title])
+		assert.Greater(t, ranks[title], ranks[content])
+	})
+
+	t.Run("all query words must match", func(t *testing.T) {
+		ranks := index.search("поиск content")
+
+		assert.Len(t, ranks, 1)
+		assert.Contains(t, ranks, content)
+	})
+
+	t.Run("no words in query", func(t *testing.T) {
+		assert.Empty(t, index.search("?!"))
+	})
+
+	t.Run("reindex and remove", func(t *testing.T) {
+		index.put(title, "Другой заголовок", "")
+		index.remove(both)
+
+		ranks := index.search("поиск")
+		assert.Len(t, ranks, 1)
+		assert.Contains(t, ranks, content)
+
+		index.remove(content)
+		assert.Empty(t, index.search("поиск"))
+		assert.Len(t, index.search("заголовок"), 1)
+	})
+}
+
+func TestHighlight(t *testing.T) {
+	t.Run("marks matched words", func(t *testing.T) {
+		snippet := highlight("Полнотекстовый Поиск: ищем посты и комментарии.", "поиск комментарий")
+
+		assert.Equal(t, "Полнотекстовый <mark>Поиск</mark>: ищем посты и <mark>комментарии</mark>", snippet)
+	})
+
+	t.Run("window around the first match", func(t *testing.T) {
+		text := ""
+		for range 100 {
+			text += "слово "
+		}
+		text += "находка " + text
+
+		snippet := highlight(text, "находка")
+
+		assert.Contains(t, snippet, "<mark>находка</mark>")
+		assert.Len(t, strings.Fields(snippet), consts.SearchSnippetWords)
+	})
+
+	t.Run("empty text", func(t *testing.T) {
+		assert.Empty(t, highlight("", "поиск"))
+	})
+}
@@ -0,0 +1,84 @@
+package mem
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/internal/storage"
+)
+
+type UserStorageMem struct {
+	users      map[uuid.UUID]*models.User
+	byUsername map[string]uuid.UUID
+	mu         sync.RWMutex
+}
+
+func NewUserStorageMem() *UserStorageMem {
+	return &UserStorageMem{
+		users:      make(map[uuid.UUID]*models.User),
+		byUsername: make(map[string]uuid.UUID),
+	}
+}
+
+func (s *UserStorageMem) CreateUser(_ context.Context, user models.User) (models.User, error) {
+	now := time.Now()
+	user.ID = uuid.New()
+	user.CreatedAt = &now
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.byUsername[user.Username]; ok {
+		return models.User{}, fmt.Errorf("user with username: %s: %w", user.Username, storage.ErrAlreadyExists)
+	}
+
+	s.users[user.ID] = &user
+	s.byUsername[user.Username] = user.ID
+	return user, nil
+}
+
+func (s *UserStorageMem) GetUserByID(_ context.Context, userID uuid.UUID) (*models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.users[userID]
+	if !ok {
+		return nil, fmt.Errorf("user with id: %s not found: %w", userID.String(), sql.ErrNoRows)
+	}
+
+	res := *user
+	return &res, nil
+}
+
+func (s *UserStorageMem) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	s.mu.RLock()
+	userID, ok := s.byUsername[username]
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("user with username: %s not found: %w", username, sql.ErrNoRows)
+	}
+	return s.GetUserByID(ctx, userID)
+}
+
+// назначение роли, вызывается хранилищем модерации
+func (s *UserStorageMem) setRole(username string, role models.Role) (models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	userID, ok := s.byUsername[username]
+	if !ok {
+		return models.User{}, fmt.Errorf("user with username: %s not found: %w", username, sql.ErrNoRows)
+	}
+
+	// подменяем указатель на копию, как и при изменении постов
+	updated := *s.users[userID]
+	updated.Role = role
+	s.users[userID] = &updated
+	return updated, nil
+}
@@ -0,0 +1,69 @@
+package mem
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserStorageMem_CreateUser(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("with auto-generated fields", func(t *testing.T) {
+		store := NewUserStorageMem()
+
+		created, err := store.CreateUser(ctx, models.User{Username: "test_user", PasswordHash: "hash"})
+		require.NoError(t, err)
+
+		assert.NotEqual(t, uuid.Nil, created.ID)
+		assert.NotNil(t, created.CreatedAt)
+		assert.WithinDuration(t, time.Now(), *created.CreatedAt, time.Second)
+		assert.Equal(t, "test_user", created.Username)
+		assert.Equal(t, "hash", created.PasswordHash)
+	})
+
+	t.Run("with taken username", func(t *testing.T) {
+		store := NewUserStorageMem()
+
+		_, err := store.CreateUser(ctx, models.User{Username: "test_user", PasswordHash: "hash"})
+		require.NoError(t, err)
+
+		_, err = store.CreateUser(ctx, models.User{Username: "test_user", PasswordHash: "other_hash"})
+		assert.ErrorIs(t, err, storage.ErrAlreadyExists)
+	})
+}
+
+func TestUserStorageMem_GetUser(t *testing.T) {
+	ctx := context.Background()
+	store := NewUserStorageMem()
+
+	created, err := store.CreateUser(ctx, models.User{Username: "test_user", PasswordHash: "hash"})
+	require.NoError(t, err)
+
+	t.Run("by id", func(t *testing.T) {
+		user, err := store.GetUserByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, created, *user)
+	})
+
+	t.Run("by username", func(t *testing.T) {
+		user, err := store.GetUserByUsername(ctx, "test_user")
+		require.NoError(t, err)
+		assert.Equal(t, created.ID, user.ID)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := store.GetUserByID(ctx, uuid.New())
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+
+		_, err = store.GetUserByUsername(ctx, "unknown_user")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
@@ -0,0 +1,97 @@
+package mem
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/models"
+)
+
+type voteKey struct {
+	id    uuid.UUID
+	voter string
+}
+
+// счетчики голосов хранятся в самих постах и комментариях, поэтому хранилище голосов работает поверх них
+type VoteStorageMem struct {
+	posts        *PostStorageMem
+	comms        *CommentsStorageMem
+	postVotes    map[voteKey]int
+	commentVotes map[voteKey]int
+	mu           sync.RWMutex
+}
+
+func NewVoteStorageMem(posts *PostStorageMem, comms *CommentsStorageMem) *VoteStorageMem {
+	return &VoteStorageMem{
+		posts:        posts,
+		comms:        comms,
+		postVotes:    make(map[voteKey]int),
+		commentVotes: make(map[voteKey]int),
+	}
+}
+
+func (s *VoteStorageMem) VotePost(_ context.Context, postID uuid.UUID, voter string, value int) (models.Post, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := voteKey{id: postID, voter: voter}
+	up, down := models.VoteDelta(s.postVotes[key], value)
+
+	post, err := s.posts.addVotes(postID, up, down)
+	if err != nil {
+		return models.Post{}, err
+	}
+
+	setVote(s.postVotes, key, value)
+	return post, nil
+}
+
+func (s *VoteStorageMem) VoteComment(_ context.Context, commentID uuid.UUID, voter string,
+	value int) (models.Comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := voteKey{id: commentID, voter: voter}
+	up, down := models.VoteDelta(s.commentVotes[key], value)
+
+	comment, err := s.comms.addVotes(commentID, up, down)
+	if err != nil {
+		return models.Comment{}, err
+	}
+
+	setVote(s.commentVotes, key, value)
+	return comment, nil
+}
+
+func (s *VoteStorageMem) GetPostVotes(_ context.Context, postIDs []uuid.UUID,
+	voter string) (map[uuid.UUID]int, error) {
+	return s.getVotes(s.postVotes, postIDs, voter), nil
+}
+
+func (s *VoteStorageMem) GetCommentVotes(_ context.Context, commentIDs []uuid.UUID,
+	voter string) (map[uuid.UUID]int, error) {
+	return s.getVotes(s.commentVotes, commentIDs, voter), nil
+}
+
+func (s *VoteStorageMem) getVotes(votes map[voteKey]int, ids []uuid.UUID, voter string) map[uuid.UUID]int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	res := make(map[uuid.UUID]int, len(ids))
+	for _, id := range ids {
+		if value, ok := votes[voteKey{id: id, voter: voter}]; ok {
+			res[id] = value
+		}
+	}
+	return res
+}
+
+// вызывается под мьютексом, нулевой голос означает отзыв голоса
+func setVote(votes map[voteKey]int, key voteKey, value int) {
+	if value == 0 {
+		delete(votes, key)
+		return
+	}
+	votes[key] = value
+}
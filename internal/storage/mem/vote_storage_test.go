@@ -0,0 +1,125 @@
+package mem
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVoteStorageMem_VotePost(t *testing.T) {
+	ctx := context.Background()
+
+	posts := NewPostStorageMem()
+	comms := NewCommentsStorageMem(posts)
+	storage := NewVoteStorageMem(posts, comms)
+
+	post, err := posts.CreatePost(ctx, models.Post{Title: "Test", Author: "Test_author", Content: "Test_test_test"})
+	require.NoError(t, err)
+
+	t.Run("counts one vote per voter", func(t *testing.T) {
+		voted, err := storage.VotePost(ctx, post.ID, "voter1", 1)
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), voted.Upvotes)
+
+		voted, err = storage.VotePost(ctx, post.ID, "voter1", 1)
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), voted.Upvotes)
+		assert.Equal(t, int32(1), voted.Score())
+
+		voted, err = storage.VotePost(ctx, post.ID, "voter2", -1)
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), voted.Upvotes)
+		assert.Equal(t, int32(1), voted.Downvotes)
+		assert.Equal(t, int32(0), voted.Score())
+	})
+
+	t.Run("changes and retracts vote", func(t *testing.T) {
+		voted, err := storage.VotePost(ctx, post.ID, "voter1", -1)
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), voted.Upvotes)
+		assert.Equal(t, int32(2), voted.Downvotes)
+
+		voted, err = storage.VotePost(ctx, post.ID, "voter2", 0)
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), voted.Upvotes)
+		assert.Equal(t, int32(1), voted.Downvotes)
+
+		stored, err := posts.GetPostByID(ctx, post.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int32(-1), stored.Score())
+
+		votes, err := storage.GetPostVotes(ctx, []uuid.UUID{post.ID}, "voter1")
+		require.NoError(t, err)
+		assert.Equal(t, map[uuid.UUID]int{post.ID: -1}, votes)
+
+		votes, err = storage.GetPostVotes(ctx, []uuid.UUID{post.ID}, "voter2")
+		require.NoError(t, err)
+		assert.Empty(t, votes)
+	})
+
+	t.Run("with non-existent post", func(t *testing.T) {
+		_, err := storage.VotePost(ctx, uuid.New(), "voter1", 1)
+		assert.Error(t, err)
+	})
+
+	t.Run("concurrent votes of one voter", func(t *testing.T) {
+		other, err := posts.CreatePost(ctx, models.Post{Title: "Test", Author: "Test_author", Content: "Test"})
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := storage.VotePost(ctx, other.ID, "voter1", 1)
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		stored, err := posts.GetPostByID(ctx, other.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), stored.Upvotes)
+	})
+}
+
+func TestVoteStorageMem_VoteComment(t *testing.T) {
+	ctx := context.Background()
+
+	posts := NewPostStorageMem()
+	comms := NewCommentsStorageMem(posts)
+	storage := NewVoteStorageMem(posts, comms)
+
+	comment, err := comms.CreateComment(ctx, models.Comment{Author: "Test_author", Content: "Test",
+		PostID: uuid.New()})
+	require.NoError(t, err)
+
+	t.Run("with existing comment", func(t *testing.T) {
+		voted, err := storage.VoteComment(ctx, comment.ID, "voter1", -1)
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), voted.Downvotes)
+		assert.Equal(t, int32(-1), voted.Score())
+
+		stored, err := comms.GetCommentByID(ctx, comment.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), stored.Downvotes)
+
+		votes, err := storage.GetCommentVotes(ctx, []uuid.UUID{comment.ID, uuid.New()}, "voter1")
+		require.NoError(t, err)
+		assert.Equal(t, map[uuid.UUID]int{comment.ID: -1}, votes)
+	})
+
+	t.Run("with non-existent comment", func(t *testing.T) {
+		_, err := storage.VoteComment(ctx, uuid.New(), "voter1", 1)
+		assert.Error(t, err)
+
+		votes, err := storage.GetCommentVotes(ctx, []uuid.UUID{comment.ID}, "voter2")
+		require.NoError(t, err)
+		assert.Empty(t, votes)
+	})
+}
@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	uuid "github.com/google/uuid"
@@ -36,6 +37,36 @@ func (m *MockPostStorage) EXPECT() *MockPostStorageMockRecorder {
 	return m.recorder
 }
 
+// CountPosts mocks base method.
+func (m *MockPostStorage) CountPosts(ctx context.Context, feed models.PostFeed) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountPosts", ctx, feed)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountPosts indicates an expected call of CountPosts.
+func (mr *MockPostStorageMockRecorder) CountPosts(ctx, feed interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountPosts", reflect.TypeOf((*MockPostStorage)(nil).CountPosts), ctx, feed)
+}
+
+// CountSearchPosts mocks base method.
+func (m *MockPostStorage) CountSearchPosts(ctx context.Context, query string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountSearchPosts", ctx, query)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountSearchPosts indicates an expected call of CountSearchPosts.
+func (mr *MockPostStorageMockRecorder) CountSearchPosts(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountSearchPosts", reflect.TypeOf((*MockPostStorage)(nil).CountSearchPosts), ctx, query)
+}
+
 // CreatePost mocks base method.
 func (m *MockPostStorage) CreatePost(ctx context.Context, post models.Post) (models.Post, error) {
 	m.ctrl.T.Helper()
@@ -51,19 +82,48 @@ func (mr *MockPostStorageMockRecorder) CreatePost(ctx, post interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePost", reflect.TypeOf((*MockPostStorage)(nil).CreatePost), ctx, post)
 }
 
+// DeletePost mocks base method.
+func (m *MockPostStorage) DeletePost(ctx context.Context, postId uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePost", ctx, postId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePost indicates an expected call of DeletePost.
+func (mr *MockPostStorageMockRecorder) DeletePost(ctx, postId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePost", reflect.TypeOf((*MockPostStorage)(nil).DeletePost), ctx, postId)
+}
+
 // GetAllPosts mocks base method.
-func (m *MockPostStorage) GetAllPosts(ctx context.Context, offset, limit int) ([]*models.Post, error) {
+func (m *MockPostStorage) GetAllPosts(ctx context.Context, feed models.PostFeed, page models.Page) ([]*models.Post, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllPosts", ctx, offset, limit)
+	ret := m.ctrl.Call(m, "GetAllPosts", ctx, feed, page)
 	ret0, _ := ret[0].([]*models.Post)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAllPosts indicates an expected call of GetAllPosts.
-func (mr *MockPostStorageMockRecorder) GetAllPosts(ctx, offset, limit interface{}) *gomock.Call {
+func (mr *MockPostStorageMockRecorder) GetAllPosts(ctx, feed, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllPosts", reflect.TypeOf((*MockPostStorage)(nil).GetAllPosts), ctx, feed, page)
+}
+
+// GetPopularTags mocks base method.
+func (m *MockPostStorage) GetPopularTags(ctx context.Context, limit int) ([]*models.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPopularTags", ctx, limit)
+	ret0, _ := ret[0].([]*models.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPopularTags indicates an expected call of GetPopularTags.
+func (mr *MockPostStorageMockRecorder) GetPopularTags(ctx, limit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllPosts", reflect.TypeOf((*MockPostStorage)(nil).GetAllPosts), ctx, offset, limit)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPopularTags", reflect.TypeOf((*MockPostStorage)(nil).GetPopularTags), ctx, limit)
 }
 
 // GetPostByID mocks base method.
@@ -81,6 +141,66 @@ func (mr *MockPostStorageMockRecorder) GetPostByID(ctx, postId interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPostByID", reflect.TypeOf((*MockPostStorage)(nil).GetPostByID), ctx, postId)
 }
 
+// HasDuplicate mocks base method.
+func (m *MockPostStorage) HasDuplicate(ctx context.Context, author, contentHash string, since time.Time, excludeID uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasDuplicate", ctx, author, contentHash, since, excludeID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasDuplicate indicates an expected call of HasDuplicate.
+func (mr *MockPostStorageMockRecorder) HasDuplicate(ctx, author, contentHash, since, excludeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasDuplicate", reflect.TypeOf((*MockPostStorage)(nil).HasDuplicate), ctx, author, contentHash, since, excludeID)
+}
+
+// SearchPosts mocks base method.
+func (m *MockPostStorage) SearchPosts(ctx context.Context, query string, page models.Page) ([]*models.PostSearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchPosts", ctx, query, page)
+	ret0, _ := ret[0].([]*models.PostSearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchPosts indicates an expected call of SearchPosts.
+func (mr *MockPostStorageMockRecorder) SearchPosts(ctx, query, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchPosts", reflect.TypeOf((*MockPostStorage)(nil).SearchPosts), ctx, query, page)
+}
+
+// SetCommentsAllowed mocks base method.
+func (m *MockPostStorage) SetCommentsAllowed(ctx context.Context, postId uuid.UUID, allowed bool) (models.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCommentsAllowed", ctx, postId, allowed)
+	ret0, _ := ret[0].(models.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetCommentsAllowed indicates an expected call of SetCommentsAllowed.
+func (mr *MockPostStorageMockRecorder) SetCommentsAllowed(ctx, postId, allowed interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCommentsAllowed", reflect.TypeOf((*MockPostStorage)(nil).SetCommentsAllowed), ctx, postId, allowed)
+}
+
+// UpdatePost mocks base method.
+func (m *MockPostStorage) UpdatePost(ctx context.Context, post models.Post) (models.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePost", ctx, post)
+	ret0, _ := ret[0].(models.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePost indicates an expected call of UpdatePost.
+func (mr *MockPostStorageMockRecorder) UpdatePost(ctx, post interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePost", reflect.TypeOf((*MockPostStorage)(nil).UpdatePost), ctx, post)
+}
+
 // MockCommentStorage is a mock of CommentStorage interface.
 type MockCommentStorage struct {
 	ctrl     *gomock.Controller
@@ -104,6 +224,51 @@ func (m *MockCommentStorage) EXPECT() *MockCommentStorageMockRecorder {
 	return m.recorder
 }
 
+// CountAllCommentsByPostIDs mocks base method.
+func (m *MockCommentStorage) CountAllCommentsByPostIDs(ctx context.Context, postIDs []uuid.UUID, viewer string) (map[uuid.UUID]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountAllCommentsByPostIDs", ctx, postIDs, viewer)
+	ret0, _ := ret[0].(map[uuid.UUID]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountAllCommentsByPostIDs indicates an expected call of CountAllCommentsByPostIDs.
+func (mr *MockCommentStorageMockRecorder) CountAllCommentsByPostIDs(ctx, postIDs, viewer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountAllCommentsByPostIDs", reflect.TypeOf((*MockCommentStorage)(nil).CountAllCommentsByPostIDs), ctx, postIDs, viewer)
+}
+
+// CountCommentsByPostIDs mocks base method.
+func (m *MockCommentStorage) CountCommentsByPostIDs(ctx context.Context, postIDs []uuid.UUID, viewer string) (map[uuid.UUID]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountCommentsByPostIDs", ctx, postIDs, viewer)
+	ret0, _ := ret[0].(map[uuid.UUID]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountCommentsByPostIDs indicates an expected call of CountCommentsByPostIDs.
+func (mr *MockCommentStorageMockRecorder) CountCommentsByPostIDs(ctx, postIDs, viewer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountCommentsByPostIDs", reflect.TypeOf((*MockCommentStorage)(nil).CountCommentsByPostIDs), ctx, postIDs, viewer)
+}
+
+// CountRepliesByParentCommentIDs mocks base method.
+func (m *MockCommentStorage) CountRepliesByParentCommentIDs(ctx context.Context, parentCommentIDs []uuid.UUID, viewer string) (map[uuid.UUID]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountRepliesByParentCommentIDs", ctx, parentCommentIDs, viewer)
+	ret0, _ := ret[0].(map[uuid.UUID]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountRepliesByParentCommentIDs indicates an expected call of CountRepliesByParentCommentIDs.
+func (mr *MockCommentStorageMockRecorder) CountRepliesByParentCommentIDs(ctx, parentCommentIDs, viewer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountRepliesByParentCommentIDs", reflect.TypeOf((*MockCommentStorage)(nil).CountRepliesByParentCommentIDs), ctx, parentCommentIDs, viewer)
+}
+
 // CreateComment mocks base method.
 func (m *MockCommentStorage) CreateComment(ctx context.Context, comment models.Comment) (models.Comment, error) {
 	m.ctrl.T.Helper()
@@ -119,32 +284,732 @@ func (mr *MockCommentStorageMockRecorder) CreateComment(ctx, comment interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateComment", reflect.TypeOf((*MockCommentStorage)(nil).CreateComment), ctx, comment)
 }
 
-// GetCommentsByPostID mocks base method.
-func (m *MockCommentStorage) GetCommentsByPostID(ctx context.Context, postID uuid.UUID, offset, limit int) ([]*models.Comment, error) {
+// DeleteComment mocks base method.
+func (m *MockCommentStorage) DeleteComment(ctx context.Context, commentID uuid.UUID) (models.Comment, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetCommentsByPostID", ctx, postID, offset, limit)
-	ret0, _ := ret[0].([]*models.Comment)
+	ret := m.ctrl.Call(m, "DeleteComment", ctx, commentID)
+	ret0, _ := ret[0].(models.Comment)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetCommentsByPostID indicates an expected call of GetCommentsByPostID.
-func (mr *MockCommentStorageMockRecorder) GetCommentsByPostID(ctx, postID, offset, limit interface{}) *gomock.Call {
+// DeleteComment indicates an expected call of DeleteComment.
+func (mr *MockCommentStorageMockRecorder) DeleteComment(ctx, commentID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommentsByPostID", reflect.TypeOf((*MockCommentStorage)(nil).GetCommentsByPostID), ctx, postID, offset, limit)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteComment", reflect.TypeOf((*MockCommentStorage)(nil).DeleteComment), ctx, commentID)
 }
 
-// GetRepliesByParentCommentID mocks base method.
-func (m *MockCommentStorage) GetRepliesByParentCommentID(ctx context.Context, parentCommentID uuid.UUID) ([]*models.Comment, error) {
+// GetCommentByID mocks base method.
+func (m *MockCommentStorage) GetCommentByID(ctx context.Context, commentID uuid.UUID) (*models.Comment, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetRepliesByParentCommentID", ctx, parentCommentID)
+	ret := m.ctrl.Call(m, "GetCommentByID", ctx, commentID)
+	ret0, _ := ret[0].(*models.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommentByID indicates an expected call of GetCommentByID.
+func (mr *MockCommentStorageMockRecorder) GetCommentByID(ctx, commentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommentByID", reflect.TypeOf((*MockCommentStorage)(nil).GetCommentByID), ctx, commentID)
+}
+
+// GetCommentTree mocks base method.
+func (m *MockCommentStorage) GetCommentTree(ctx context.Context, postID uuid.UUID, maxDepth, offset, limit int, viewer string) ([]*models.CommentTreeNode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommentTree", ctx, postID, maxDepth, offset, limit, viewer)
+	ret0, _ := ret[0].([]*models.CommentTreeNode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommentTree indicates an expected call of GetCommentTree.
+func (mr *MockCommentStorageMockRecorder) GetCommentTree(ctx, postID, maxDepth, offset, limit, viewer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommentTree", reflect.TypeOf((*MockCommentStorage)(nil).GetCommentTree), ctx, postID, maxDepth, offset, limit, viewer)
+}
+
+// GetCommentsAfter mocks base method.
+func (m *MockCommentStorage) GetCommentsAfter(ctx context.Context, postID uuid.UUID, after models.Cursor, limit int, viewer string) ([]*models.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommentsAfter", ctx, postID, after, limit, viewer)
 	ret0, _ := ret[0].([]*models.Comment)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetRepliesByParentCommentID indicates an expected call of GetRepliesByParentCommentID.
-func (mr *MockCommentStorageMockRecorder) GetRepliesByParentCommentID(ctx, parentCommentID interface{}) *gomock.Call {
+// GetCommentsAfter indicates an expected call of GetCommentsAfter.
+func (mr *MockCommentStorageMockRecorder) GetCommentsAfter(ctx, postID, after, limit, viewer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommentsAfter", reflect.TypeOf((*MockCommentStorage)(nil).GetCommentsAfter), ctx, postID, after, limit, viewer)
+}
+
+// GetCommentsByPostIDs mocks base method.
+func (m *MockCommentStorage) GetCommentsByPostIDs(ctx context.Context, postIDs []uuid.UUID, sort models.CommentSort, page models.Page, viewer string) (map[uuid.UUID][]*models.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommentsByPostIDs", ctx, postIDs, sort, page, viewer)
+	ret0, _ := ret[0].(map[uuid.UUID][]*models.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommentsByPostIDs indicates an expected call of GetCommentsByPostIDs.
+func (mr *MockCommentStorageMockRecorder) GetCommentsByPostIDs(ctx, postIDs, sort, page, viewer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommentsByPostIDs", reflect.TypeOf((*MockCommentStorage)(nil).GetCommentsByPostIDs), ctx, postIDs, sort, page, viewer)
+}
+
+// GetRepliesByParentCommentIDs mocks base method.
+func (m *MockCommentStorage) GetRepliesByParentCommentIDs(ctx context.Context, parentCommentIDs []uuid.UUID, sort models.CommentSort, page models.Page, viewer string) (map[uuid.UUID][]*models.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRepliesByParentCommentIDs", ctx, parentCommentIDs, sort, page, viewer)
+	ret0, _ := ret[0].(map[uuid.UUID][]*models.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRepliesByParentCommentIDs indicates an expected call of GetRepliesByParentCommentIDs.
+func (mr *MockCommentStorageMockRecorder) GetRepliesByParentCommentIDs(ctx, parentCommentIDs, sort, page, viewer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRepliesByParentCommentIDs", reflect.TypeOf((*MockCommentStorage)(nil).GetRepliesByParentCommentIDs), ctx, parentCommentIDs, sort, page, viewer)
+}
+
+// HasDuplicate mocks base method.
+func (m *MockCommentStorage) HasDuplicate(ctx context.Context, author, contentHash string, since time.Time, excludeID uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasDuplicate", ctx, author, contentHash, since, excludeID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasDuplicate indicates an expected call of HasDuplicate.
+func (mr *MockCommentStorageMockRecorder) HasDuplicate(ctx, author, contentHash, since, excludeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasDuplicate", reflect.TypeOf((*MockCommentStorage)(nil).HasDuplicate), ctx, author, contentHash, since, excludeID)
+}
+
+// SearchComments mocks base method.
+func (m *MockCommentStorage) SearchComments(ctx context.Context, query string, postID *uuid.UUID, limit int) ([]*models.CommentSearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchComments", ctx, query, postID, limit)
+	ret0, _ := ret[0].([]*models.CommentSearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchComments indicates an expected call of SearchComments.
+func (mr *MockCommentStorageMockRecorder) SearchComments(ctx, query, postID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchComments", reflect.TypeOf((*MockCommentStorage)(nil).SearchComments), ctx, query, postID, limit)
+}
+
+// UpdateComment mocks base method.
+func (m *MockCommentStorage) UpdateComment(ctx context.Context, commentID uuid.UUID, content string, shadow bool) (models.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateComment", ctx, commentID, content, shadow)
+	ret0, _ := ret[0].(models.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateComment indicates an expected call of UpdateComment.
+func (mr *MockCommentStorageMockRecorder) UpdateComment(ctx, commentID, content, shadow interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateComment", reflect.TypeOf((*MockCommentStorage)(nil).UpdateComment), ctx, commentID, content, shadow)
+}
+
+// MockVoteStorage is a mock of VoteStorage interface.
+type MockVoteStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockVoteStorageMockRecorder
+}
+
+// MockVoteStorageMockRecorder is the mock recorder for MockVoteStorage.
+type MockVoteStorageMockRecorder struct {
+	mock *MockVoteStorage
+}
+
+// NewMockVoteStorage creates a new mock instance.
+func NewMockVoteStorage(ctrl *gomock.Controller) *MockVoteStorage {
+	mock := &MockVoteStorage{ctrl: ctrl}
+	mock.recorder = &MockVoteStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVoteStorage) EXPECT() *MockVoteStorageMockRecorder {
+	return m.recorder
+}
+
+// GetCommentVotes mocks base method.
+func (m *MockVoteStorage) GetCommentVotes(ctx context.Context, commentIDs []uuid.UUID, voter string) (map[uuid.UUID]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommentVotes", ctx, commentIDs, voter)
+	ret0, _ := ret[0].(map[uuid.UUID]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommentVotes indicates an expected call of GetCommentVotes.
+func (mr *MockVoteStorageMockRecorder) GetCommentVotes(ctx, commentIDs, voter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommentVotes", reflect.TypeOf((*MockVoteStorage)(nil).GetCommentVotes), ctx, commentIDs, voter)
+}
+
+// GetPostVotes mocks base method.
+func (m *MockVoteStorage) GetPostVotes(ctx context.Context, postIDs []uuid.UUID, voter string) (map[uuid.UUID]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPostVotes", ctx, postIDs, voter)
+	ret0, _ := ret[0].(map[uuid.UUID]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPostVotes indicates an expected call of GetPostVotes.
+func (mr *MockVoteStorageMockRecorder) GetPostVotes(ctx, postIDs, voter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPostVotes", reflect.TypeOf((*MockVoteStorage)(nil).GetPostVotes), ctx, postIDs, voter)
+}
+
+// VoteComment mocks base method.
+func (m *MockVoteStorage) VoteComment(ctx context.Context, commentID uuid.UUID, voter string, value int) (models.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VoteComment", ctx, commentID, voter, value)
+	ret0, _ := ret[0].(models.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VoteComment indicates an expected call of VoteComment.
+func (mr *MockVoteStorageMockRecorder) VoteComment(ctx, commentID, voter, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VoteComment", reflect.TypeOf((*MockVoteStorage)(nil).VoteComment), ctx, commentID, voter, value)
+}
+
+// VotePost mocks base method.
+func (m *MockVoteStorage) VotePost(ctx context.Context, postID uuid.UUID, voter string, value int) (models.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VotePost", ctx, postID, voter, value)
+	ret0, _ := ret[0].(models.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VotePost indicates an expected call of VotePost.
+func (mr *MockVoteStorageMockRecorder) VotePost(ctx, postID, voter, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VotePost", reflect.TypeOf((*MockVoteStorage)(nil).VotePost), ctx, postID, voter, value)
+}
+
+// MockUserStorage is a mock of UserStorage interface.
+type MockUserStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserStorageMockRecorder
+}
+
+// MockUserStorageMockRecorder is the mock recorder for MockUserStorage.
+type MockUserStorageMockRecorder struct {
+	mock *MockUserStorage
+}
+
+// NewMockUserStorage creates a new mock instance.
+func NewMockUserStorage(ctrl *gomock.Controller) *MockUserStorage {
+	mock := &MockUserStorage{ctrl: ctrl}
+	mock.recorder = &MockUserStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserStorage) EXPECT() *MockUserStorageMockRecorder {
+	return m.recorder
+}
+
+// CreateUser mocks base method.
+func (m *MockUserStorage) CreateUser(ctx context.Context, user models.User) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", ctx, user)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockUserStorageMockRecorder) CreateUser(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUserStorage)(nil).CreateUser), ctx, user)
+}
+
+// GetUserByID mocks base method.
+func (m *MockUserStorage) GetUserByID(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", ctx, userID)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockUserStorageMockRecorder) GetUserByID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockUserStorage)(nil).GetUserByID), ctx, userID)
+}
+
+// GetUserByUsername mocks base method.
+func (m *MockUserStorage) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByUsername", ctx, username)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByUsername indicates an expected call of GetUserByUsername.
+func (mr *MockUserStorageMockRecorder) GetUserByUsername(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockUserStorage)(nil).GetUserByUsername), ctx, username)
+}
+
+// MockNotificationStorage is a mock of NotificationStorage interface.
+type MockNotificationStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationStorageMockRecorder
+}
+
+// MockNotificationStorageMockRecorder is the mock recorder for MockNotificationStorage.
+type MockNotificationStorageMockRecorder struct {
+	mock *MockNotificationStorage
+}
+
+// NewMockNotificationStorage creates a new mock instance.
+func NewMockNotificationStorage(ctrl *gomock.Controller) *MockNotificationStorage {
+	mock := &MockNotificationStorage{ctrl: ctrl}
+	mock.recorder = &MockNotificationStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationStorage) EXPECT() *MockNotificationStorageMockRecorder {
+	return m.recorder
+}
+
+// CountUnreadNotifications mocks base method.
+func (m *MockNotificationStorage) CountUnreadNotifications(ctx context.Context, recipient string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnreadNotifications", ctx, recipient)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnreadNotifications indicates an expected call of CountUnreadNotifications.
+func (mr *MockNotificationStorageMockRecorder) CountUnreadNotifications(ctx, recipient interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnreadNotifications", reflect.TypeOf((*MockNotificationStorage)(nil).CountUnreadNotifications), ctx, recipient)
+}
+
+// CreateNotification mocks base method.
+func (m *MockNotificationStorage) CreateNotification(ctx context.Context, notification models.Notification) (models.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateNotification", ctx, notification)
+	ret0, _ := ret[0].(models.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateNotification indicates an expected call of CreateNotification.
+func (mr *MockNotificationStorageMockRecorder) CreateNotification(ctx, notification interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNotification", reflect.TypeOf((*MockNotificationStorage)(nil).CreateNotification), ctx, notification)
+}
+
+// GetNotifications mocks base method.
+func (m *MockNotificationStorage) GetNotifications(ctx context.Context, recipient string, page models.Page) ([]*models.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotifications", ctx, recipient, page)
+	ret0, _ := ret[0].([]*models.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotifications indicates an expected call of GetNotifications.
+func (mr *MockNotificationStorageMockRecorder) GetNotifications(ctx, recipient, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotifications", reflect.TypeOf((*MockNotificationStorage)(nil).GetNotifications), ctx, recipient, page)
+}
+
+// MarkNotificationsRead mocks base method.
+func (m *MockNotificationStorage) MarkNotificationsRead(ctx context.Context, recipient string, ids []uuid.UUID) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkNotificationsRead", ctx, recipient, ids)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkNotificationsRead indicates an expected call of MarkNotificationsRead.
+func (mr *MockNotificationStorageMockRecorder) MarkNotificationsRead(ctx, recipient, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkNotificationsRead", reflect.TypeOf((*MockNotificationStorage)(nil).MarkNotificationsRead), ctx, recipient, ids)
+}
+
+// MockCommunityStorage is a mock of CommunityStorage interface.
+type MockCommunityStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockCommunityStorageMockRecorder
+}
+
+// MockCommunityStorageMockRecorder is the mock recorder for MockCommunityStorage.
+type MockCommunityStorageMockRecorder struct {
+	mock *MockCommunityStorage
+}
+
+// NewMockCommunityStorage creates a new mock instance.
+func NewMockCommunityStorage(ctrl *gomock.Controller) *MockCommunityStorage {
+	mock := &MockCommunityStorage{ctrl: ctrl}
+	mock.recorder = &MockCommunityStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCommunityStorage) EXPECT() *MockCommunityStorageMockRecorder {
+	return m.recorder
+}
+
+// AddMember mocks base method.
+func (m *MockCommunityStorage) AddMember(ctx context.Context, communityID uuid.UUID, username string) (models.Community, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddMember", ctx, communityID, username)
+	ret0, _ := ret[0].(models.Community)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddMember indicates an expected call of AddMember.
+func (mr *MockCommunityStorageMockRecorder) AddMember(ctx, communityID, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMember", reflect.TypeOf((*MockCommunityStorage)(nil).AddMember), ctx, communityID, username)
+}
+
+// CreateCommunity mocks base method.
+func (m *MockCommunityStorage) CreateCommunity(ctx context.Context, community models.Community) (models.Community, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCommunity", ctx, community)
+	ret0, _ := ret[0].(models.Community)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCommunity indicates an expected call of CreateCommunity.
+func (mr *MockCommunityStorageMockRecorder) CreateCommunity(ctx, community interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCommunity", reflect.TypeOf((*MockCommunityStorage)(nil).CreateCommunity), ctx, community)
+}
+
+// GetCommunitiesByIDs mocks base method.
+func (m *MockCommunityStorage) GetCommunitiesByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.Community, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommunitiesByIDs", ctx, ids)
+	ret0, _ := ret[0].(map[uuid.UUID]*models.Community)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommunitiesByIDs indicates an expected call of GetCommunitiesByIDs.
+func (mr *MockCommunityStorageMockRecorder) GetCommunitiesByIDs(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommunitiesByIDs", reflect.TypeOf((*MockCommunityStorage)(nil).GetCommunitiesByIDs), ctx, ids)
+}
+
+// GetCommunityBySlug mocks base method.
+func (m *MockCommunityStorage) GetCommunityBySlug(ctx context.Context, slug string) (*models.Community, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommunityBySlug", ctx, slug)
+	ret0, _ := ret[0].(*models.Community)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommunityBySlug indicates an expected call of GetCommunityBySlug.
+func (mr *MockCommunityStorageMockRecorder) GetCommunityBySlug(ctx, slug interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommunityBySlug", reflect.TypeOf((*MockCommunityStorage)(nil).GetCommunityBySlug), ctx, slug)
+}
+
+// GetMemberships mocks base method.
+func (m *MockCommunityStorage) GetMemberships(ctx context.Context, communityIDs []uuid.UUID, username string) (map[uuid.UUID]bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMemberships", ctx, communityIDs, username)
+	ret0, _ := ret[0].(map[uuid.UUID]bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMemberships indicates an expected call of GetMemberships.
+func (mr *MockCommunityStorageMockRecorder) GetMemberships(ctx, communityIDs, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberships", reflect.TypeOf((*MockCommunityStorage)(nil).GetMemberships), ctx, communityIDs, username)
+}
+
+// IsModerator mocks base method.
+func (m *MockCommunityStorage) IsModerator(ctx context.Context, communityID uuid.UUID, username string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsModerator", ctx, communityID, username)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsModerator indicates an expected call of IsModerator.
+func (mr *MockCommunityStorageMockRecorder) IsModerator(ctx, communityID, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsModerator", reflect.TypeOf((*MockCommunityStorage)(nil).IsModerator), ctx, communityID, username)
+}
+
+// RemoveMember mocks base method.
+func (m *MockCommunityStorage) RemoveMember(ctx context.Context, communityID uuid.UUID, username string) (models.Community, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", ctx, communityID, username)
+	ret0, _ := ret[0].(models.Community)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockCommunityStorageMockRecorder) RemoveMember(ctx, communityID, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockCommunityStorage)(nil).RemoveMember), ctx, communityID, username)
+}
+
+// MockModerationStorage is a mock of ModerationStorage interface.
+type MockModerationStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockModerationStorageMockRecorder
+}
+
+// MockModerationStorageMockRecorder is the mock recorder for MockModerationStorage.
+type MockModerationStorageMockRecorder struct {
+	mock *MockModerationStorage
+}
+
+// NewMockModerationStorage creates a new mock instance.
+func NewMockModerationStorage(ctrl *gomock.Controller) *MockModerationStorage {
+	mock := &MockModerationStorage{ctrl: ctrl}
+	mock.recorder = &MockModerationStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockModerationStorage) EXPECT() *MockModerationStorageMockRecorder {
+	return m.recorder
+}
+
+// AddCommunityModerator mocks base method.
+func (m *MockModerationStorage) AddCommunityModerator(ctx context.Context, communityID uuid.UUID, username string, action models.ModerationAction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCommunityModerator", ctx, communityID, username, action)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddCommunityModerator indicates an expected call of AddCommunityModerator.
+func (mr *MockModerationStorageMockRecorder) AddCommunityModerator(ctx, communityID, username, action interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCommunityModerator", reflect.TypeOf((*MockModerationStorage)(nil).AddCommunityModerator), ctx, communityID, username, action)
+}
+
+// GetModerationLog mocks base method.
+func (m *MockModerationStorage) GetModerationLog(ctx context.Context, communityID *uuid.UUID, page models.Page) ([]*models.ModerationAction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetModerationLog", ctx, communityID, page)
+	ret0, _ := ret[0].([]*models.ModerationAction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetModerationLog indicates an expected call of GetModerationLog.
+func (mr *MockModerationStorageMockRecorder) GetModerationLog(ctx, communityID, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetModerationLog", reflect.TypeOf((*MockModerationStorage)(nil).GetModerationLog), ctx, communityID, page)
+}
+
+// RemoveComment mocks base method.
+func (m *MockModerationStorage) RemoveComment(ctx context.Context, commentID uuid.UUID, action models.ModerationAction) (models.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveComment", ctx, commentID, action)
+	ret0, _ := ret[0].(models.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveComment indicates an expected call of RemoveComment.
+func (mr *MockModerationStorageMockRecorder) RemoveComment(ctx, commentID, action interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveComment", reflect.TypeOf((*MockModerationStorage)(nil).RemoveComment), ctx, commentID, action)
+}
+
+// RemoveCommunityModerator mocks base method.
+func (m *MockModerationStorage) RemoveCommunityModerator(ctx context.Context, communityID uuid.UUID, username string, action models.ModerationAction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveCommunityModerator", ctx, communityID, username, action)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveCommunityModerator indicates an expected call of RemoveCommunityModerator.
+func (mr *MockModerationStorageMockRecorder) RemoveCommunityModerator(ctx, communityID, username, action interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveCommunityModerator", reflect.TypeOf((*MockModerationStorage)(nil).RemoveCommunityModerator), ctx, communityID, username, action)
+}
+
+// RemovePost mocks base method.
+func (m *MockModerationStorage) RemovePost(ctx context.Context, postID uuid.UUID, action models.ModerationAction) (models.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemovePost", ctx, postID, action)
+	ret0, _ := ret[0].(models.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemovePost indicates an expected call of RemovePost.
+func (mr *MockModerationStorageMockRecorder) RemovePost(ctx, postID, action interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePost", reflect.TypeOf((*MockModerationStorage)(nil).RemovePost), ctx, postID, action)
+}
+
+// SetUserRole mocks base method.
+func (m *MockModerationStorage) SetUserRole(ctx context.Context, username string, role models.Role, action models.ModerationAction) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserRole", ctx, username, role, action)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetUserRole indicates an expected call of SetUserRole.
+func (mr *MockModerationStorageMockRecorder) SetUserRole(ctx, username, role, action interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserRole", reflect.TypeOf((*MockModerationStorage)(nil).SetUserRole), ctx, username, role, action)
+}
+
+// MockReportStorage is a mock of ReportStorage interface.
+type MockReportStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockReportStorageMockRecorder
+}
+
+// MockReportStorageMockRecorder is the mock recorder for MockReportStorage.
+type MockReportStorageMockRecorder struct {
+	mock *MockReportStorage
+}
+
+// NewMockReportStorage creates a new mock instance.
+func NewMockReportStorage(ctrl *gomock.Controller) *MockReportStorage {
+	mock := &MockReportStorage{ctrl: ctrl}
+	mock.recorder = &MockReportStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReportStorage) EXPECT() *MockReportStorageMockRecorder {
+	return m.recorder
+}
+
+// CreateReport mocks base method.
+func (m *MockReportStorage) CreateReport(ctx context.Context, report models.Report, hideThreshold int, hide models.ModerationAction) (models.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReport", ctx, report, hideThreshold, hide)
+	ret0, _ := ret[0].(models.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateReport indicates an expected call of CreateReport.
+func (mr *MockReportStorageMockRecorder) CreateReport(ctx, report, hideThreshold, hide interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReport", reflect.TypeOf((*MockReportStorage)(nil).CreateReport), ctx, report, hideThreshold, hide)
+}
+
+// GetReportByID mocks base method.
+func (m *MockReportStorage) GetReportByID(ctx context.Context, reportID uuid.UUID) (*models.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReportByID", ctx, reportID)
+	ret0, _ := ret[0].(*models.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReportByID indicates an expected call of GetReportByID.
+func (mr *MockReportStorageMockRecorder) GetReportByID(ctx, reportID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportByID", reflect.TypeOf((*MockReportStorage)(nil).GetReportByID), ctx, reportID)
+}
+
+// GetReports mocks base method.
+func (m *MockReportStorage) GetReports(ctx context.Context, status models.ReportStatus, page models.Page) ([]*models.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReports", ctx, status, page)
+	ret0, _ := ret[0].([]*models.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReports indicates an expected call of GetReports.
+func (mr *MockReportStorageMockRecorder) GetReports(ctx, status, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReports", reflect.TypeOf((*MockReportStorage)(nil).GetReports), ctx, status, page)
+}
+
+// ResolveReport mocks base method.
+func (m *MockReportStorage) ResolveReport(ctx context.Context, reportID uuid.UUID, status models.ReportStatus, resolver string, action models.ModerationAction) (models.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveReport", ctx, reportID, status, resolver, action)
+	ret0, _ := ret[0].(models.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveReport indicates an expected call of ResolveReport.
+func (mr *MockReportStorageMockRecorder) ResolveReport(ctx, reportID, status, resolver, action interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveReport", reflect.TypeOf((*MockReportStorage)(nil).ResolveReport), ctx, reportID, status, resolver, action)
+}
+
+// MockRateLimitStorage is a mock of RateLimitStorage interface.
+type MockRateLimitStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockRateLimitStorageMockRecorder
+}
+
+// MockRateLimitStorageMockRecorder is the mock recorder for MockRateLimitStorage.
+type MockRateLimitStorageMockRecorder struct {
+	mock *MockRateLimitStorage
+}
+
+// NewMockRateLimitStorage creates a new mock instance.
+func NewMockRateLimitStorage(ctrl *gomock.Controller) *MockRateLimitStorage {
+	mock := &MockRateLimitStorage{ctrl: ctrl}
+	mock.recorder = &MockRateLimitStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRateLimitStorage) EXPECT() *MockRateLimitStorageMockRecorder {
+	return m.recorder
+}
+
+// TakeToken mocks base method.
+func (m *MockRateLimitStorage) TakeToken(ctx context.Context, key string, limit models.RateLimit) (bool, time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeToken", ctx, key, limit)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(time.Duration)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// TakeToken indicates an expected call of TakeToken.
+func (mr *MockRateLimitStorageMockRecorder) TakeToken(ctx, key, limit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRepliesByParentCommentID", reflect.TypeOf((*MockCommentStorage)(nil).GetRepliesByParentCommentID), ctx, parentCommentID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeToken", reflect.TypeOf((*MockRateLimitStorage)(nil).TakeToken), ctx, key, limit)
 }
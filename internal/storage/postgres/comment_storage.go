@@ -2,13 +2,32 @@ package postgres
 
 import (
 	"context"
+	"fmt"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/nedokyrill/posts-service/internal/models"
 )
 
+// у удаленного комментария автор затирается (null), поэтому приводим его к пустой строке
+const commentColumns = `id, coalesce(author, ''), content, post_id, parent_comment_id, is_deleted, upvotes, downvotes,
+	created_at, updated_at, best, is_removed, is_shadowed`
+
+// скрытый фильтром контента комментарий видит только автор ($n - кто смотрит), у удаленного автор null
+const commentVisible = `(NOT is_shadowed OR author = $%d)`
+
+// столбцы ключей сортировки комментариев, у NEW и OLD ключа нет - сортировка только по (created_at, id)
+var commentSortColumns = map[models.CommentSort]string{
+	models.CommentSortTop:  "score",
+	models.CommentSortBest: "best",
+}
+
+// ключ комментария в пути сортировки дерева, время дополнено до фиксированной ширины для сравнения строк
+const treeSortKey = `to_char(c.created_at, 'YYYYMMDDHH24MISSUS') || c.id::text`
+
 type CommentsStorePgx struct {
 	db *pgxpool.Pool
 }
@@ -20,14 +39,14 @@ func NewCommentsStorePgx(db *pgxpool.Pool) *CommentsStorePgx {
 }
 
 func (s *CommentsStorePgx) CreateComment(ctx context.Context, comment models.Comment) (models.Comment, error) {
-	query := `INSERT INTO comments (author, content, post_id, parent_comment_id)
-				VALUES ($1, $2, $3, $4) RETURNING id, created_at;`
+	query := `INSERT INTO comments (author, content, post_id, parent_comment_id, is_shadowed, content_hash)
+				VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at;`
 
 	var id uuid.UUID
 	var createdAt time.Time
 
-	err := s.db.QueryRow(ctx, query, comment.Author, comment.Content, comment.PostID,
-		comment.ParentCommentID).Scan(&id, &createdAt)
+	err := s.db.QueryRow(ctx, query, comment.Author, comment.Content, comment.PostID, comment.ParentCommentID,
+		comment.IsShadowed, models.ContentHash(comment.Content)).Scan(&id, &createdAt)
 	if err != nil {
 		return models.Comment{}, err
 	}
@@ -37,61 +56,245 @@ func (s *CommentsStorePgx) CreateComment(ctx context.Context, comment models.Com
 	return comment, nil
 }
 
-func (s *CommentsStorePgx) GetCommentsByPostID(ctx context.Context, postID uuid.UUID, offset, limit int) ([]*models.Comment, error) {
-	var comments []*models.Comment
-	query := `SELECT * FROM comments WHERE post_id = $1 AND parent_comment_id IS NULL 
-         		ORDER BY created_at DESC LIMIT $2 OFFSET $3;`
+func (s *CommentsStorePgx) GetCommentsByPostIDs(ctx context.Context, postIDs []uuid.UUID, sort models.CommentSort,
+	page models.Page, viewer string) (map[uuid.UUID][]*models.Comment, error) {
+	query, args := paginateGroups(commentColumns, "comments", "post_id", commentSortColumns[sort],
+		[]string{"post_id = ANY($1)", "parent_comment_id IS NULL", fmt.Sprintf(commentVisible, 2)},
+		[]any{postIDs, viewer}, page, sort.Desc())
 
-	rows, err := s.db.Query(ctx, query, postID, limit, offset)
+	comments, err := s.queryComments(ctx, query, args)
 	if err != nil {
 		return nil, err
 	}
 
+	return groupComments(comments, page, func(c *models.Comment) uuid.UUID { return c.PostID }), nil
+}
+
+func (s *CommentsStorePgx) CountCommentsByPostIDs(ctx context.Context, postIDs []uuid.UUID,
+	viewer string) (map[uuid.UUID]int, error) {
+	query := `SELECT post_id, count(*) FROM comments WHERE post_id = ANY($1) AND parent_comment_id IS NULL
+				AND ` + fmt.Sprintf(commentVisible, 2) + ` GROUP BY post_id;`
+
+	return s.queryCounts(ctx, query, postIDs, viewer)
+}
+
+// считается по индексу (post_id, created_at, id), отдельный счетчик в posts не нужен
+func (s *CommentsStorePgx) CountAllCommentsByPostIDs(ctx context.Context, postIDs []uuid.UUID,
+	viewer string) (map[uuid.UUID]int, error) {
+	query := `SELECT post_id, count(*) FROM comments WHERE post_id = ANY($1) AND ` + fmt.Sprintf(commentVisible, 2) +
+		` GROUP BY post_id;`
+
+	return s.queryCounts(ctx, query, postIDs, viewer)
+}
+
+func (s *CommentsStorePgx) GetRepliesByParentCommentIDs(ctx context.Context, parentCommentIDs []uuid.UUID,
+	sort models.CommentSort, page models.Page, viewer string) (map[uuid.UUID][]*models.Comment, error) {
+	query, args := paginateGroups(commentColumns, "comments", "parent_comment_id", commentSortColumns[sort],
+		[]string{"parent_comment_id = ANY($1)", fmt.Sprintf(commentVisible, 2)}, []any{parentCommentIDs, viewer},
+		page, sort.Desc())
+
+	replies, err := s.queryComments(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return groupComments(replies, page, func(c *models.Comment) uuid.UUID { return *c.ParentCommentID }), nil
+}
+
+func (s *CommentsStorePgx) CountRepliesByParentCommentIDs(ctx context.Context, parentCommentIDs []uuid.UUID,
+	viewer string) (map[uuid.UUID]int, error) {
+	query := `SELECT parent_comment_id, count(*) FROM comments WHERE parent_comment_id = ANY($1)
+				AND ` + fmt.Sprintf(commentVisible, 2) + ` GROUP BY parent_comment_id;`
+
+	return s.queryCounts(ctx, query, parentCommentIDs, viewer)
+}
+
+// дерево собирается рекурсивным запросом, sort_key - путь из ключей (created_at, id) от корня до комментария,
+// сортировка по нему дает обход в глубину, где на каждом уровне комментарии идут от старых к новым.
+// Невидимый комментарий отсекается вместе с веткой ответов на него
+func (s *CommentsStorePgx) GetCommentTree(ctx context.Context, postID uuid.UUID, maxDepth, offset, limit int,
+	viewer string) ([]*models.CommentTreeNode, error) {
+	query := `WITH RECURSIVE tree AS (
+					SELECT c.*, 0 AS depth, ARRAY[c.id] AS path, ARRAY[` + treeSortKey + `] AS sort_key
+					FROM comments c WHERE c.post_id = $1 AND c.parent_comment_id IS NULL
+						AND (NOT c.is_shadowed OR c.author = $5)
+					UNION ALL
+					SELECT c.*, t.depth + 1, t.path || c.id, t.sort_key || ` + treeSortKey + `
+					FROM comments c JOIN tree t ON c.parent_comment_id = t.id
+					WHERE c.post_id = $1 AND t.depth < $2 AND (NOT c.is_shadowed OR c.author = $5)
+				)
+				SELECT ` + commentColumns + `, depth, path FROM tree ORDER BY sort_key LIMIT $3 OFFSET $4;`
+
+	rows, err := s.db.Query(ctx, query, postID, maxDepth, limit, offset, viewer)
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
+
+	var nodes []*models.CommentTreeNode
 	for rows.Next() {
-		var id, postId uuid.UUID
-		var parentId *uuid.UUID
-		var author, content string
-		var createdAt *time.Time
+		var comment models.Comment
+		node := models.CommentTreeNode{Comment: &comment}
 
-		if err = rows.Scan(&id, &author, &content, &postId, &parentId, &createdAt); err != nil {
+		err = rows.Scan(&comment.ID, &comment.Author, &comment.Content, &comment.PostID, &comment.ParentCommentID,
+			&comment.IsDeleted, &comment.Upvotes, &comment.Downvotes, &comment.CreatedAt, &comment.UpdatedAt,
+			&comment.Best, &comment.IsRemoved, &comment.IsShadowed, &node.Depth, &node.Path)
+		if err != nil {
 			return nil, err
 		}
-		comments = append(comments, &models.Comment{ID: id, Author: author, Content: content, PostID: postId,
-			ParentCommentID: parentId, CreatedAt: createdAt})
+		nodes = append(nodes, &node)
+	}
+	return nodes, rows.Err()
+}
+
+func (s *CommentsStorePgx) GetCommentByID(ctx context.Context, commentID uuid.UUID) (*models.Comment, error) {
+	query := `SELECT ` + commentColumns + ` FROM comments WHERE id = $1;`
+
+	return scanComment(s.db.QueryRow(ctx, query, commentID))
+}
+
+func (s *CommentsStorePgx) GetCommentsAfter(ctx context.Context, postID uuid.UUID, after models.Cursor, limit int,
+	viewer string) ([]*models.Comment, error) {
+	query := `SELECT ` + commentColumns + ` FROM comments WHERE post_id = $1 AND (created_at, id) > ($2, $3)
+				AND ` + fmt.Sprintf(commentVisible, 5) + ` ORDER BY created_at, id LIMIT $4;`
+
+	return s.queryComments(ctx, query, []any{postID, after.CreatedAt, after.ID, limit, viewer})
+}
+
+func (s *CommentsStorePgx) UpdateComment(ctx context.Context, commentID uuid.UUID, content string,
+	shadow bool) (models.Comment, error) {
+	query := `UPDATE comments SET content = $1, content_hash = $3, is_shadowed = is_shadowed OR $4, updated_at = now()
+				WHERE id = $2 AND NOT is_deleted RETURNING ` + commentColumns + `;`
+
+	comment, err := scanComment(s.db.QueryRow(ctx, query, content, commentID, models.ContentHash(content), shadow))
+	if err != nil {
+		return models.Comment{}, err
 	}
-	return comments, nil
+	return *comment, nil
 }
 
-// слишком много одинакового кода, но решил не выносить в отдельный метод, так как используется всего в двух методах
-func (s *CommentsStorePgx) GetRepliesByParentCommentID(ctx context.Context,
-	parentCommentID uuid.UUID) ([]*models.Comment, error) {
-	var replies []*models.Comment
-	query := `SELECT * FROM comments WHERE parent_comment_id = $1 ORDER BY created_at;`
+// строку не удаляем, так как parent_comment_id ссылается на нее с on delete cascade и удалились бы все ответы
+func (s *CommentsStorePgx) DeleteComment(ctx context.Context, commentID uuid.UUID) (models.Comment, error) {
+	query := `UPDATE comments SET content = '', author = NULL, is_deleted = true, updated_at = now()
+				WHERE id = $1 RETURNING ` + commentColumns + `;`
 
-	rows, err := s.db.Query(ctx, query, parentCommentID)
+	comment, err := scanComment(s.db.QueryRow(ctx, query, commentID))
+	if err != nil {
+		return models.Comment{}, err
+	}
+	return *comment, nil
+}
+
+func (s *CommentsStorePgx) SearchComments(ctx context.Context, query string, postID *uuid.UUID,
+	limit int) ([]*models.CommentSearchResult, error) {
+	// у удаленных комментариев текст пустой, поэтому они и так не находятся, скрытые модераторами и фильтром
+	// контента исключаем, как и комментарии к скрытым постам
+	sql := `SELECT ` + commentColumns + `, rank, ` + headline("content") + ` FROM (
+					SELECT c.*, ts_rank(c.search, tsq)::float8 AS rank, tsq
+					FROM comments c JOIN posts p ON p.id = c.post_id, ` + searchFrom + `
+					WHERE c.search @@ tsq AND NOT c.is_removed AND NOT c.is_shadowed
+						AND NOT p.is_removed AND NOT p.is_shadowed
+						AND ($2::uuid IS NULL OR c.post_id = $2)
+				) AS found ORDER BY rank DESC, created_at DESC, id DESC LIMIT $3;`
+
+	rows, err := s.db.Query(ctx, sql, query, postID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []*models.CommentSearchResult
+	for rows.Next() {
+		var comment models.Comment
+		r := models.CommentSearchResult{Comment: &comment}
+
+		err = rows.Scan(&comment.ID, &comment.Author, &comment.Content, &comment.PostID, &comment.ParentCommentID,
+			&comment.IsDeleted, &comment.Upvotes, &comment.Downvotes, &comment.CreatedAt, &comment.UpdatedAt,
+			&comment.Best, &comment.IsRemoved, &comment.IsShadowed, &r.Rank, &r.Snippet)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, &r)
+	}
+	return res, rows.Err()
+}
+
+func (s *CommentsStorePgx) HasDuplicate(ctx context.Context, author, contentHash string, since time.Time,
+	excludeID uuid.UUID) (bool, error) {
+	var found bool
+
+	err := s.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM comments WHERE author = $1 AND content_hash = $2
+				AND created_at >= $3 AND id <> $4);`, author, contentHash, since, excludeID).Scan(&found)
+	return found, err
+}
+
+func (s *CommentsStorePgx) queryComments(ctx context.Context, query string, args []any) ([]*models.Comment, error) {
+	rows, err := s.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 
+	return scanComments(rows)
+}
+
+func (s *CommentsStorePgx) queryCounts(ctx context.Context, query string, ids []uuid.UUID,
+	viewer string) (map[uuid.UUID]int, error) {
+	rows, err := s.db.Query(ctx, query, ids, viewer)
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
+
+	counts := make(map[uuid.UUID]int, len(ids))
 	for rows.Next() {
-		var id, postId uuid.UUID
-		var parentId *uuid.UUID
-		var author, content string
-		var createdAt *time.Time
+		var id uuid.UUID
+		var count int
+		if err = rows.Scan(&id, &count); err != nil {
+			return nil, err
+		}
+		counts[id] = count
+	}
+	return counts, rows.Err()
+}
+
+// раскладываем страницы по группам; при page.FromEnd строки каждой группы выбраны в обратном порядке
+func groupComments(comments []*models.Comment, page models.Page,
+	groupOf func(c *models.Comment) uuid.UUID) map[uuid.UUID][]*models.Comment {
+	groups := make(map[uuid.UUID][]*models.Comment)
+	for _, comment := range comments {
+		groups[groupOf(comment)] = append(groups[groupOf(comment)], comment)
+	}
+
+	if page.FromEnd {
+		for _, group := range groups {
+			slices.Reverse(group)
+		}
+	}
+	return groups
+}
 
-		if err = rows.Scan(&id, &author, &content, &postId, &parentId, &createdAt); err != nil {
+func scanComment(row pgx.Row) (*models.Comment, error) {
+	var comment models.Comment
+
+	err := row.Scan(&comment.ID, &comment.Author, &comment.Content, &comment.PostID, &comment.ParentCommentID,
+		&comment.IsDeleted, &comment.Upvotes, &comment.Downvotes, &comment.CreatedAt, &comment.UpdatedAt,
+		&comment.Best, &comment.IsRemoved, &comment.IsShadowed)
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+func scanComments(rows pgx.Rows) ([]*models.Comment, error) {
+	var comments []*models.Comment
+
+	defer rows.Close()
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
 			return nil, err
 		}
-		replies = append(replies, &models.Comment{
-			ID:              id,
-			Author:          author,
-			Content:         content,
-			PostID:          postId,
-			ParentCommentID: parentId,
-			CreatedAt:       createdAt,
-		})
-	}
-	return replies, nil
+		comments = append(comments, comment)
+	}
+	return comments, rows.Err()
 }
@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/internal/storage"
+)
+
+const communityColumns = `id, slug, title, description, owner, post_policy, comment_policy, member_count, created_at`
+
+type CommunityStorePgx struct {
+	db *pgxpool.Pool
+}
+
+func NewCommunityStorePgx(db *pgxpool.Pool) *CommunityStorePgx {
+	return &CommunityStorePgx{
+		db: db,
+	}
+}
+
+// сообщество и членство создателя вставляются одним запросом
+func (s *CommunityStorePgx) CreateCommunity(ctx context.Context,
+	community models.Community) (models.Community, error) {
+	query := `WITH created AS (
+					INSERT INTO communities (slug, title, description, owner, post_policy, comment_policy, member_count)
+					VALUES ($1, $2, $3, $4, $5, $6, 1) RETURNING ` + communityColumns + `
+				), owner AS (
+					INSERT INTO community_members (community_id, username) SELECT id, owner FROM created
+				)
+				SELECT ` + communityColumns + ` FROM created;`
+
+	created, err := scanCommunity(s.db.QueryRow(ctx, query, community.Slug, community.Title, community.Description,
+		community.Owner, community.PostPolicy, community.CommentPolicy))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+			return models.Community{}, fmt.Errorf("community with slug: %s: %w", community.Slug,
+				storage.ErrAlreadyExists)
+		}
+		return models.Community{}, err
+	}
+	return *created, nil
+}
+
+func (s *CommunityStorePgx) GetCommunityBySlug(ctx context.Context, slug string) (*models.Community, error) {
+	query := `SELECT ` + communityColumns + ` FROM communities WHERE slug = $1;`
+
+	return scanCommunity(s.db.QueryRow(ctx, query, slug))
+}
+
+func (s *CommunityStorePgx) GetCommunitiesByIDs(ctx context.Context,
+	ids []uuid.UUID) (map[uuid.UUID]*models.Community, error) {
+	query := `SELECT ` + communityColumns + ` FROM communities WHERE id = ANY($1);`
+
+	rows, err := s.db.Query(ctx, query, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	communities := make(map[uuid.UUID]*models.Community, len(ids))
+	for rows.Next() {
+		community, err := scanCommunity(rows)
+		if err != nil {
+			return nil, err
+		}
+		communities[community.ID] = community
+	}
+	return communities, rows.Err()
+}
+
+// счетчик участников меняется в том же запросе, только если членство действительно добавилось
+func (s *CommunityStorePgx) AddMember(ctx context.Context, communityID uuid.UUID,
+	username string) (models.Community, error) {
+	query := `WITH added AS (
+					INSERT INTO community_members (community_id, username) VALUES ($1, $2)
+					ON CONFLICT DO NOTHING RETURNING username
+				)
+				UPDATE communities SET member_count = member_count + (SELECT count(*) FROM added)
+				WHERE id = $1 RETURNING ` + communityColumns + `;`
+
+	return s.changeMembers(ctx, query, communityID, username)
+}
+
+func (s *CommunityStorePgx) RemoveMember(ctx context.Context, communityID uuid.UUID,
+	username string) (models.Community, error) {
+	query := `WITH removed AS (
+					DELETE FROM community_members WHERE community_id = $1 AND username = $2 RETURNING username
+				)
+				UPDATE communities SET member_count = member_count - (SELECT count(*) FROM removed)
+				WHERE id = $1 RETURNING ` + communityColumns + `;`
+
+	return s.changeMembers(ctx, query, communityID, username)
+}
+
+func (s *CommunityStorePgx) GetMemberships(ctx context.Context, communityIDs []uuid.UUID,
+	username string) (map[uuid.UUID]bool, error) {
+	query := `SELECT community_id FROM community_members WHERE community_id = ANY($1) AND username = $2;`
+
+	rows, err := s.db.Query(ctx, query, communityIDs, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	memberships := make(map[uuid.UUID]bool, len(communityIDs))
+	for rows.Next() {
+		var id uuid.UUID
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		memberships[id] = true
+	}
+	return memberships, rows.Err()
+}
+
+func (s *CommunityStorePgx) IsModerator(ctx context.Context, communityID uuid.UUID, username string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM communities WHERE id = $1 AND owner = $2)
+				OR EXISTS (SELECT 1 FROM community_moderators WHERE community_id = $1 AND username = $2);`
+
+	var isModerator bool
+	err := s.db.QueryRow(ctx, query, communityID, username).Scan(&isModerator)
+	return isModerator, err
+}
+
+func (s *CommunityStorePgx) changeMembers(ctx context.Context, query string, communityID uuid.UUID,
+	username string) (models.Community, error) {
+	community, err := scanCommunity(s.db.QueryRow(ctx, query, communityID, username))
+	if err != nil {
+		return models.Community{}, err
+	}
+	return *community, nil
+}
+
+func scanCommunity(row pgx.Row) (*models.Community, error) {
+	var community models.Community
+
+	err := row.Scan(&community.ID, &community.Slug, &community.Title, &community.Description, &community.Owner,
+		&community.PostPolicy, &community.CommentPolicy, &community.MemberCount, &community.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &community, nil
+}
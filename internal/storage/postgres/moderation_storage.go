@@ -0,0 +1,172 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/internal/storage"
+)
+
+const moderationColumns = `id, actor, action, post_id, comment_id, target_user, role, community_id, reason, created_at`
+
+type ModerationStorePgx struct {
+	db *pgxpool.Pool
+}
+
+func NewModerationStorePgx(db *pgxpool.Pool) *ModerationStorePgx {
+	return &ModerationStorePgx{
+		db: db,
+	}
+}
+
+func (s *ModerationStorePgx) RemovePost(ctx context.Context, postID uuid.UUID,
+	action models.ModerationAction) (models.Post, error) {
+	var post *models.Post
+
+	err := s.withAction(ctx, action, func(tx pgx.Tx) (err error) {
+		// если поста нет или он уже скрыт, здесь вернется pgx.ErrNoRows
+		post, err = scanPost(tx.QueryRow(ctx, `UPDATE posts SET is_removed = true WHERE id = $1 AND NOT is_removed
+				RETURNING `+postColumns+`;`, postID))
+		return err
+	})
+	if err != nil {
+		return models.Post{}, err
+	}
+	return *post, nil
+}
+
+func (s *ModerationStorePgx) RemoveComment(ctx context.Context, commentID uuid.UUID,
+	action models.ModerationAction) (models.Comment, error) {
+	var comment *models.Comment
+
+	err := s.withAction(ctx, action, func(tx pgx.Tx) (err error) {
+		comment, err = scanComment(tx.QueryRow(ctx, `UPDATE comments SET is_removed = true
+				WHERE id = $1 AND NOT is_removed RETURNING `+commentColumns+`;`, commentID))
+		return err
+	})
+	if err != nil {
+		return models.Comment{}, err
+	}
+	return *comment, nil
+}
+
+func (s *ModerationStorePgx) SetUserRole(ctx context.Context, username string, role models.Role,
+	action models.ModerationAction) (models.User, error) {
+	var user *models.User
+
+	err := s.withAction(ctx, action, func(tx pgx.Tx) (err error) {
+		user, err = scanUser(tx.QueryRow(ctx, `UPDATE users SET role = $1 WHERE username = $2
+				RETURNING `+userColumns+`;`, role, username))
+		return err
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+	return *user, nil
+}
+
+func (s *ModerationStorePgx) AddCommunityModerator(ctx context.Context, communityID uuid.UUID, username string,
+	action models.ModerationAction) error {
+	return s.withAction(ctx, action, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `INSERT INTO community_moderators (community_id, username) VALUES ($1, $2);`,
+			communityID, username)
+
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+			return fmt.Errorf("moderator %s of community %s: %w", username, communityID.String(),
+				storage.ErrAlreadyExists)
+		}
+		return err
+	})
+}
+
+func (s *ModerationStorePgx) RemoveCommunityModerator(ctx context.Context, communityID uuid.UUID, username string,
+	action models.ModerationAction) error {
+	return s.withAction(ctx, action, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `DELETE FROM community_moderators WHERE community_id = $1 AND username = $2;`,
+			communityID, username)
+		if err != nil {
+			return err
+		}
+
+		if tag.RowsAffected() == 0 {
+			return pgx.ErrNoRows
+		}
+		return nil
+	})
+}
+
+func (s *ModerationStorePgx) GetModerationLog(ctx context.Context, communityID *uuid.UUID,
+	page models.Page) ([]*models.ModerationAction, error) {
+	var conds []string
+	var args []any
+	if communityID != nil {
+		conds, args = []string{"community_id = $1"}, []any{*communityID}
+	}
+
+	query, args := paginate(`SELECT `+moderationColumns+` FROM moderation_log`, "", conds, args, page, true)
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var actions []*models.ModerationAction
+	for rows.Next() {
+		var a models.ModerationAction
+		err = rows.Scan(&a.ID, &a.Actor, &a.Action, &a.PostID, &a.CommentID, &a.TargetUser, &a.Role,
+			&a.CommunityID, &a.Reason, &a.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, &a)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if page.FromEnd {
+		slices.Reverse(actions)
+	}
+	return actions, nil
+}
+
+// изменение и запись о нем в журнале выполняются в одной транзакции
+func (s *ModerationStorePgx) withAction(ctx context.Context, action models.ModerationAction,
+	change func(tx pgx.Tx) error) (err error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	if err = change(tx); err != nil {
+		return err
+	}
+
+	if err = insertAction(ctx, tx, action); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// запись действия в журнал модерации в транзакции tx
+func insertAction(ctx context.Context, tx pgx.Tx, action models.ModerationAction) error {
+	_, err := tx.Exec(ctx, `INSERT INTO moderation_log (actor, action, post_id, comment_id, target_user, role,
+				community_id, reason) VALUES ($1, $2, $3, $4, $5, $6, $7, $8);`, action.Actor, action.Action,
+		action.PostID, action.CommentID, action.TargetUser, action.Role, action.CommunityID, action.Reason)
+	return err
+}
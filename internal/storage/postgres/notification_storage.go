@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nedokyrill/posts-service/internal/models"
+)
+
+const notificationColumns = `id, recipient, type, actor, post_id, comment_id, is_read, created_at`
+
+type NotificationStorePgx struct {
+	db *pgxpool.Pool
+}
+
+func NewNotificationStorePgx(db *pgxpool.Pool) *NotificationStorePgx {
+	return &NotificationStorePgx{
+		db: db,
+	}
+}
+
+func (s *NotificationStorePgx) CreateNotification(ctx context.Context,
+	notification models.Notification) (models.Notification, error) {
+	query := `INSERT INTO notifications (recipient, type, actor, post_id, comment_id)
+				VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at;`
+
+	var id uuid.UUID
+	var createdAt time.Time
+
+	err := s.db.QueryRow(ctx, query, notification.Recipient, notification.Type, notification.Actor,
+		notification.PostID, notification.CommentID).Scan(&id, &createdAt)
+	if err != nil {
+		return models.Notification{}, err
+	}
+
+	notification.ID = id
+	notification.CreatedAt = &createdAt
+	return notification, nil
+}
+
+func (s *NotificationStorePgx) GetNotifications(ctx context.Context, recipient string,
+	page models.Page) ([]*models.Notification, error) {
+	query, args := paginate(`SELECT `+notificationColumns+` FROM notifications`, "",
+		[]string{"recipient = $1"}, []any{recipient}, page, true)
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []*models.Notification
+	for rows.Next() {
+		var n models.Notification
+		err = rows.Scan(&n.ID, &n.Recipient, &n.Type, &n.Actor, &n.PostID, &n.CommentID, &n.IsRead, &n.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, &n)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if page.FromEnd {
+		slices.Reverse(notifications)
+	}
+	return notifications, nil
+}
+
+func (s *NotificationStorePgx) CountUnreadNotifications(ctx context.Context, recipient string) (int, error) {
+	query := `SELECT count(*) FROM notifications WHERE recipient = $1 AND NOT is_read;`
+
+	var count int
+	err := s.db.QueryRow(ctx, query, recipient).Scan(&count)
+	return count, err
+}
+
+func (s *NotificationStorePgx) MarkNotificationsRead(ctx context.Context, recipient string,
+	ids []uuid.UUID) (int, error) {
+	query := `UPDATE notifications SET is_read = true WHERE recipient = $1 AND id = ANY($2) AND NOT is_read;`
+
+	tag, err := s.db.Exec(ctx, query, recipient, ids)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nedokyrill/posts-service/internal/models"
+)
+
+// собираем запрос с keyset-пагинацией по (key, created_at, id): условия курсоров, сортировку и лимит.
+// key - столбец ключа сортировки (рейтинг и т.п.), пустой при сортировке только по времени.
+// desc - порядок выдачи (true - от больших к меньшим), при page.FromEnd строки выбираются в обратном порядке
+// и их нужно развернуть после чтения
+func paginate(query, key string, conds []string, args []any, page models.Page, desc bool) (string, []any) {
+	conds, args = cursorConds(key, conds, args, page, desc)
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	args = append(args, page.Limit)
+	query += fmt.Sprintf(" ORDER BY %s LIMIT $%d;", orderBy(key, page, desc), len(args))
+	return query, args
+}
+
+// то же, что paginate, но страница выбирается отдельно для каждого значения столбца group
+// (например, первые 20 ответов на каждый из нескольких комментариев одним запросом).
+// Строки одной группы идут подряд в том же порядке, что и у paginate
+func paginateGroups(columns, table, group, key string, conds []string, args []any,
+	page models.Page, desc bool) (string, []any) {
+	conds, args = cursorConds(key, conds, args, page, desc)
+
+	args = append(args, page.Limit)
+	query := fmt.Sprintf(`SELECT %s FROM (
+					SELECT *, row_number() OVER (PARTITION BY %s ORDER BY %s) AS rn
+					FROM %s WHERE %s
+				) AS page WHERE rn <= $%d ORDER BY %s, rn;`,
+		columns, group, orderBy(key, page, desc), table, strings.Join(conds, " AND "), len(args), group)
+	return query, args
+}
+
+func cursorConds(key string, conds []string, args []any, page models.Page, desc bool) ([]string, []any) {
+	next, prev := ">", "<"
+	if desc {
+		next, prev = prev, next
+	}
+
+	columns := sortColumns(key)
+	cond := func(c *models.Cursor, op string) {
+		values := []any{c.CreatedAt, c.ID}
+		if key != "" {
+			values = append([]any{c.Key}, values...)
+		}
+
+		placeholders := make([]string, 0, len(values))
+		for _, value := range values {
+			args = append(args, value)
+			placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+		}
+		conds = append(conds, fmt.Sprintf("(%s) %s (%s)", strings.Join(columns, ", "), op,
+			strings.Join(placeholders, ", ")))
+	}
+
+	if page.After != nil {
+		cond(page.After, next)
+	}
+	if page.Before != nil {
+		cond(page.Before, prev)
+	}
+	return conds, args
+}
+
+func orderBy(key string, page models.Page, desc bool) string {
+	order := "ASC"
+	if desc != page.FromEnd {
+		order = "DESC"
+	}
+
+	columns := sortColumns(key)
+	for i := range columns {
+		columns[i] += " " + order
+	}
+	return strings.Join(columns, ", ")
+}
+
+func sortColumns(key string) []string {
+	if key == "" {
+		return []string{"created_at", "id"}
+	}
+	return []string{key, "created_at", "id"}
+}
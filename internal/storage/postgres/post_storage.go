@@ -2,13 +2,28 @@ package postgres
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/nedokyrill/posts-service/internal/models"
 )
 
+// теги выбираются подзапросом по id поста, поэтому столбцы подходят и для RETURNING, и для выборки из подзапроса
+const postColumns = `id, title, content, author, is_comments_allowed, upvotes, downvotes, created_at, hot_score,
+	controversy, array(SELECT tag FROM post_tags WHERE post_id = id ORDER BY tag), community_id, is_removed, is_shadowed`
+
+// столбцы ключей сортировки ленты, у NEW ключа нет - сортировка только по (created_at, id)
+var postSortColumns = map[models.PostSort]string{
+	models.PostSortTop:           "score",
+	models.PostSortHot:           "hot_score",
+	models.PostSortControversial: "controversy",
+}
+
 type PostStorePgx struct {
 	db *pgxpool.Pool
 }
@@ -19,41 +34,55 @@ func NewPostStorePgx(db *pgxpool.Pool) *PostStorePgx {
 	}
 }
 
-func (s *PostStorePgx) GetAllPosts(ctx context.Context, offset, limit int) ([]*models.Post, error) {
-	var posts []*models.Post
+func (s *PostStorePgx) GetAllPosts(ctx context.Context, feed models.PostFeed, page models.Page) ([]*models.Post, error) {
+	conds, args := feedConds(feed)
+	query, args := paginate(`SELECT `+postColumns+` FROM posts`, postSortColumns[feed.Sort], conds, args, page, true)
 
-	query := `SELECT * FROM posts ORDER BY id DESC LIMIT $1 OFFSET $2;`
-
-	rows, err := s.db.Query(ctx, query, limit, offset)
+	rows, err := s.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 
-	defer rows.Close()
-	for rows.Next() {
-		var id uuid.UUID
-		var title, author, content string
-		var isCommentsAllowed bool
-		var createdAt *time.Time
+	posts, err := scanPosts(rows)
+	if err != nil {
+		return nil, err
+	}
 
-		if err = rows.Scan(&id, &title, &content, &author, &isCommentsAllowed, &createdAt); err != nil {
-			return nil, err
-		}
-		posts = append(posts, &models.Post{ID: id, Title: title, Content: content, Author: author,
-			IsCommentsAllowed: isCommentsAllowed, CreatedAt: createdAt})
+	if page.FromEnd {
+		slices.Reverse(posts)
 	}
 	return posts, nil
 }
 
+func (s *PostStorePgx) CountPosts(ctx context.Context, feed models.PostFeed) (int, error) {
+	var count int
+
+	query := `SELECT count(*) FROM posts`
+	conds, args := feedConds(feed)
+	if len(conds) > 0 {
+		query += ` WHERE ` + strings.Join(conds, " AND ")
+	}
+
+	err := s.db.QueryRow(ctx, query+`;`, args...).Scan(&count)
+	return count, err
+}
+
 func (s *PostStorePgx) CreatePost(ctx context.Context, post models.Post) (models.Post, error) {
-	query := `INSERT INTO posts (title, content, author, is_comments_allowed) 
-				VALUES ($1, $2, $3, $4) RETURNING id, created_at;`
+	query := `WITH created AS (
+					INSERT INTO posts (title, content, author, is_comments_allowed, community_id, is_shadowed,
+						content_hash)
+					VALUES ($1, $2, $3, $4, $6, $7, $8) RETURNING id, created_at
+				), tags AS (
+					INSERT INTO post_tags (post_id, tag) SELECT id, unnest($5::text[]) FROM created
+				)
+				SELECT id, created_at FROM created;`
 
 	var id uuid.UUID
 	var createdAt time.Time
 
 	err := s.db.QueryRow(ctx, query, post.Title, post.Content, post.Author,
-		post.IsCommentsAllowed).Scan(&id, &createdAt)
+		post.IsCommentsAllowed, post.Tags, post.CommunityID, post.IsShadowed,
+		models.ContentHash(post.Content)).Scan(&id, &createdAt)
 	if err != nil {
 		return models.Post{}, err
 	}
@@ -64,13 +93,207 @@ func (s *PostStorePgx) CreatePost(ctx context.Context, post models.Post) (models
 }
 
 func (s *PostStorePgx) GetPostByID(ctx context.Context, postId uuid.UUID) (*models.Post, error) {
+	query := `SELECT ` + postColumns + ` FROM posts WHERE id = $1;`
+
+	return scanPost(s.db.QueryRow(ctx, query, postId))
+}
+
+// пост и его теги меняются в одной транзакции, строка поста блокируется до замены тегов
+func (s *PostStorePgx) UpdatePost(ctx context.Context, post models.Post) (_ models.Post, err error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return models.Post{}, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	// если поста нет, здесь вернется pgx.ErrNoRows. Скрытый фильтром контента пост остается скрытым,
+	// комментарии закрываются и открываются только через SetCommentsAllowed
+	err = tx.QueryRow(ctx, `UPDATE posts SET title = $1, content = $2, is_shadowed = is_shadowed OR $4,
+				content_hash = $5 WHERE id = $3 RETURNING id;`, post.Title, post.Content, post.ID, post.IsShadowed,
+		models.ContentHash(post.Content)).Scan(&post.ID)
+	if err != nil {
+		return models.Post{}, err
+	}
+
+	if _, err = tx.Exec(ctx, `DELETE FROM post_tags WHERE post_id = $1;`, post.ID); err != nil {
+		return models.Post{}, err
+	}
+	_, err = tx.Exec(ctx, `INSERT INTO post_tags (post_id, tag) SELECT $1, unnest($2::text[]);`, post.ID,
+		post.Tags)
+	if err != nil {
+		return models.Post{}, err
+	}
+
+	updated, err := scanPost(tx.QueryRow(ctx, `SELECT `+postColumns+` FROM posts WHERE id = $1;`, post.ID))
+	if err != nil {
+		return models.Post{}, err
+	}
+
+	return *updated, tx.Commit(ctx)
+}
+
+func (s *PostStorePgx) DeletePost(ctx context.Context, postId uuid.UUID) error {
+	query := `DELETE FROM posts WHERE id = $1;`
+
+	tag, err := s.db.Exec(ctx, query, postId)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (s *PostStorePgx) SetCommentsAllowed(ctx context.Context, postId uuid.UUID, allowed bool) (models.Post, error) {
+	query := `UPDATE posts SET is_comments_allowed = $1 WHERE id = $2 RETURNING ` + postColumns + `;`
+
+	post, err := scanPost(s.db.QueryRow(ctx, query, allowed, postId))
+	if err != nil {
+		return models.Post{}, err
+	}
+	return *post, nil
+}
+
+func (s *PostStorePgx) SearchPosts(ctx context.Context, query string,
+	page models.Page) ([]*models.PostSearchResult, error) {
+	// ранг считается во вложенном запросе, чтобы пагинировать по нему как по обычному столбцу
+	sql, args := paginate(`SELECT `+postColumns+`, rank, `+headline("content")+` FROM (
+					SELECT posts.*, ts_rank(search, tsq)::float8 AS rank, tsq
+					FROM posts, `+searchFrom+` WHERE search @@ tsq AND NOT is_removed AND NOT is_shadowed
+				) AS found`, "rank", nil, []any{query}, page, true)
+
+	rows, err := s.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []*models.PostSearchResult
+	for rows.Next() {
+		var post models.Post
+		r := models.PostSearchResult{Post: &post}
+
+		err = rows.Scan(&post.ID, &post.Title, &post.Content, &post.Author, &post.IsCommentsAllowed, &post.Upvotes,
+			&post.Downvotes, &post.CreatedAt, &post.HotScore, &post.Controversy, &post.Tags,
+			&post.CommunityID, &post.IsRemoved, &post.IsShadowed, &r.Rank, &r.Snippet)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, &r)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if page.FromEnd {
+		slices.Reverse(res)
+	}
+	return res, nil
+}
+
+func (s *PostStorePgx) CountSearchPosts(ctx context.Context, query string) (int, error) {
+	var count int
+
+	err := s.db.QueryRow(ctx, `SELECT count(*) FROM posts WHERE search @@ (`+searchTsQuery+`) AND NOT is_removed
+				AND NOT is_shadowed;`, query).Scan(&count)
+	return count, err
+}
+
+func (s *PostStorePgx) GetPopularTags(ctx context.Context, limit int) ([]*models.Tag, error) {
+	query := `SELECT tag, count(*) FROM post_tags JOIN posts ON posts.id = post_id WHERE NOT is_removed
+				AND NOT is_shadowed GROUP BY tag ORDER BY count(*) DESC, tag LIMIT $1;`
+
+	rows, err := s.db.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []*models.Tag
+	for rows.Next() {
+		var tag models.Tag
+		if err = rows.Scan(&tag.Name, &tag.PostCount); err != nil {
+			return nil, err
+		}
+		tags = append(tags, &tag)
+	}
+	return tags, rows.Err()
+}
+
+func (s *PostStorePgx) HasDuplicate(ctx context.Context, author, contentHash string, since time.Time,
+	excludeID uuid.UUID) (bool, error) {
+	var found bool
+
+	err := s.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM posts WHERE author = $1 AND content_hash = $2
+				AND created_at >= $3 AND id <> $4);`, author, contentHash, since, excludeID).Scan(&found)
+	return found, err
+}
+
+func feedConds(feed models.PostFeed) ([]string, []any) {
+	var conds []string
+	var args []any
+
+	if !feed.IncludeRemoved {
+		conds = append(conds, "NOT is_removed")
+	}
+
+	// скрытые фильтром контента посты видит только автор
+	args = append(args, feed.Viewer)
+	conds = append(conds, fmt.Sprintf("(NOT is_shadowed OR author = $%d)", len(args)))
+
+	if feed.Since != nil {
+		args = append(args, *feed.Since)
+		conds = append(conds, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+
+	if feed.CommunityID != nil {
+		args = append(args, *feed.CommunityID)
+		conds = append(conds, fmt.Sprintf("community_id = $%d", len(args)))
+	}
+
+	if len(feed.Tags) > 0 {
+		args = append(args, feed.Tags)
+		tagged := fmt.Sprintf("SELECT count(*) FROM post_tags WHERE post_id = posts.id AND tag = ANY($%d)", len(args))
+
+		// теги в фильтре без повторов, поэтому у поста со всеми тегами совпадений столько же, сколько тегов
+		if feed.Match == models.TagMatchAll {
+			args = append(args, len(feed.Tags))
+			conds = append(conds, fmt.Sprintf("(%s) = $%d", tagged, len(args)))
+		} else {
+			conds = append(conds, fmt.Sprintf("EXISTS (%s)", tagged))
+		}
+	}
+	return conds, args
+}
+
+func scanPost(row pgx.Row) (*models.Post, error) {
 	var post models.Post
 
-	query := `SELECT * FROM posts WHERE id = $1;`
-	err := s.db.QueryRow(ctx, query, postId).Scan(&post.ID, &post.Title, &post.Content, &post.Author,
-		&post.IsCommentsAllowed, &post.CreatedAt)
+	err := row.Scan(&post.ID, &post.Title, &post.Content, &post.Author, &post.IsCommentsAllowed, &post.Upvotes,
+		&post.Downvotes, &post.CreatedAt, &post.HotScore, &post.Controversy, &post.Tags,
+		&post.CommunityID, &post.IsRemoved, &post.IsShadowed)
 	if err != nil {
 		return nil, err
 	}
 	return &post, nil
 }
+
+func scanPosts(rows pgx.Rows) ([]*models.Post, error) {
+	var posts []*models.Post
+
+	defer rows.Close()
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+	return posts, rows.Err()
+}
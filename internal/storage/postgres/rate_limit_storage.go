@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/pkg/consts"
+)
+
+// состояние корзин общее для всех инстансов, поэтому время берется из базы, а не у инстанса
+type RateLimitStorePgx struct {
+	db    *pgxpool.Pool
+	swept time.Time // когда последний раз удалялись полные корзины
+	mu    sync.Mutex
+}
+
+func NewRateLimitStorePgx(db *pgxpool.Pool) *RateLimitStorePgx {
+	return &RateLimitStorePgx{
+		db:    db,
+		swept: time.Now(),
+	}
+}
+
+// корзина восполняется и списывается одним upsert под блокировкой строки, поэтому одновременные запросы не
+// заберут лишних токенов. Если токенов нет, строка не меняется, и из нее же считается время ожидания
+func (s *RateLimitStorePgx) TakeToken(ctx context.Context, key string, limit models.RateLimit) (bool,
+	time.Duration, error) {
+	if err := s.sweep(ctx); err != nil {
+		return false, 0, err
+	}
+
+	var taken bool
+	var wait float64
+	err := s.db.QueryRow(ctx, `WITH taken AS (
+				INSERT INTO rate_limits AS b (key, tokens, burst, rate) VALUES ($1, $2::integer - 1, $2::integer, $3::float8)
+				ON CONFLICT (key) DO UPDATE
+				SET tokens = LEAST(EXCLUDED.burst, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at) * EXCLUDED.rate) - 1,
+					burst = EXCLUDED.burst, rate = EXCLUDED.rate, updated_at = now()
+				WHERE LEAST(EXCLUDED.burst, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at) * EXCLUDED.rate) >= 1
+				RETURNING key
+			)
+			SELECT EXISTS (SELECT 1 FROM taken), COALESCE((SELECT (1 - LEAST($2::integer,
+				tokens + EXTRACT(EPOCH FROM now() - updated_at) * $3::float8)) / $3::float8
+				FROM rate_limits WHERE key = $1), 0);`, key, limit.Burst, limit.Rate()).Scan(&taken, &wait)
+	if err != nil || taken {
+		return taken, 0, err
+	}
+	return false, time.Duration(wait * float64(time.Second)), nil
+}
+
+// удаляет восполнившиеся корзины не чаще раза в RateLimitSweepInterval
+func (s *RateLimitStorePgx) sweep(ctx context.Context) error {
+	s.mu.Lock()
+	if time.Since(s.swept) < consts.RateLimitSweepInterval {
+		s.mu.Unlock()
+		return nil
+	}
+	s.swept = time.Now()
+	s.mu.Unlock()
+
+	_, err := s.db.Exec(ctx, `DELETE FROM rate_limits
+				WHERE tokens + EXTRACT(EPOCH FROM now() - updated_at) * rate >= burst;`)
+	return err
+}
@@ -0,0 +1,209 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/internal/storage"
+)
+
+const reportColumns = `id, target_type, target_id, post_id, reporter, reason, note, status, resolved_by, resolved_at,
+	created_at`
+
+type reportTarget struct {
+	table   string // posts или comments
+	hidden  string // условие скрытия контента: скрыт модератором, а комментарий еще и удален автором
+	logCond string // условие на записи журнала модерации о контенте ($1 - его id)
+}
+
+var reportTargets = map[models.ReportTargetType]reportTarget{
+	models.ReportTargetTypePost:    {table: "posts", hidden: "is_removed", logCond: "post_id = $1 AND comment_id IS NULL"},
+	models.ReportTargetTypeComment: {table: "comments", hidden: "is_removed OR is_deleted", logCond: "comment_id = $1"},
+}
+
+type ReportStorePgx struct {
+	db *pgxpool.Pool
+}
+
+func NewReportStorePgx(db *pgxpool.Pool) *ReportStorePgx {
+	return &ReportStorePgx{
+		db: db,
+	}
+}
+
+func (s *ReportStorePgx) CreateReport(ctx context.Context, report models.Report, hideThreshold int,
+	hide models.ModerationAction) (_ models.Report, err error) {
+	target := reportTargets[report.TargetType]
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return models.Report{}, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	// блокируем контент, чтобы одновременные жалобы считались по очереди и порог срабатывал один раз.
+	// Если контента нет, здесь вернется pgx.ErrNoRows
+	var hidden bool
+	err = tx.QueryRow(ctx, fmt.Sprintf(`SELECT %s FROM %s WHERE id = $1 FOR UPDATE;`, target.hidden, target.table),
+		report.TargetID).Scan(&hidden)
+	if err != nil {
+		return models.Report{}, err
+	}
+	if hidden {
+		return models.Report{}, pgx.ErrNoRows
+	}
+
+	created, err := scanReport(tx.QueryRow(ctx, `INSERT INTO reports (target_type, target_id, post_id, reporter,
+				reason, note) VALUES ($1, $2, $3, $4, $5, $6) RETURNING `+reportColumns+`;`, report.TargetType,
+		report.TargetID, report.PostID, report.Reporter, report.Reason, report.Note))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+			return models.Report{}, fmt.Errorf("report of %s by %s: %w", report.TargetID.String(), report.Reporter,
+				storage.ErrAlreadyExists)
+		}
+		return models.Report{}, err
+	}
+
+	if hideThreshold > 0 {
+		var open int
+		err = tx.QueryRow(ctx, `SELECT count(*) FROM reports WHERE target_type = $1 AND target_id = $2
+				AND status = $3;`, report.TargetType, report.TargetID, models.ReportStatusOpen).Scan(&open)
+		if err != nil {
+			return models.Report{}, err
+		}
+
+		if open >= hideThreshold {
+			_, err = tx.Exec(ctx, fmt.Sprintf(`UPDATE %s SET is_removed = true WHERE id = $1;`, target.table),
+				report.TargetID)
+			if err != nil {
+				return models.Report{}, err
+			}
+			if err = insertAction(ctx, tx, hide); err != nil {
+				return models.Report{}, err
+			}
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return models.Report{}, err
+	}
+	return *created, nil
+}
+
+func (s *ReportStorePgx) GetReportByID(ctx context.Context, reportID uuid.UUID) (*models.Report, error) {
+	return scanReport(s.db.QueryRow(ctx, `SELECT `+reportColumns+` FROM reports WHERE id = $1;`, reportID))
+}
+
+func (s *ReportStorePgx) GetReports(ctx context.Context, status models.ReportStatus,
+	page models.Page) ([]*models.Report, error) {
+	query, args := paginate(`SELECT `+reportColumns+` FROM reports`, "", []string{"status = $1"},
+		[]any{status}, page, false)
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reports []*models.Report
+	for rows.Next() {
+		report, err := scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if page.FromEnd {
+		slices.Reverse(reports)
+	}
+	return reports, nil
+}
+
+// решение принимается по контенту, поэтому в одной транзакции закрываются все открытые жалобы на него
+func (s *ReportStorePgx) ResolveReport(ctx context.Context, reportID uuid.UUID, status models.ReportStatus,
+	resolver string, action models.ModerationAction) (_ models.Report, err error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return models.Report{}, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	// если жалобы нет или она уже решена, здесь вернется pgx.ErrNoRows
+	report, err := scanReport(tx.QueryRow(ctx, `SELECT `+reportColumns+` FROM reports
+				WHERE id = $1 AND status = $2 FOR UPDATE;`, reportID, models.ReportStatusOpen))
+	if err != nil {
+		return models.Report{}, err
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE reports SET status = $1, resolved_by = $2, resolved_at = now()
+				WHERE target_type = $3 AND target_id = $4 AND status = $5;`, status, resolver, report.TargetType,
+		report.TargetID, models.ReportStatusOpen)
+	if err != nil {
+		return models.Report{}, err
+	}
+
+	target := reportTargets[report.TargetType]
+	changed := true
+	if status == models.ReportStatusRemoved {
+		_, err = tx.Exec(ctx, fmt.Sprintf(`UPDATE %s SET is_removed = true WHERE id = $1;`, target.table),
+			report.TargetID)
+	} else {
+		// восстанавливаем только контент, скрытый по жалобам, а не модератором
+		var tag pgconn.CommandTag
+		tag, err = tx.Exec(ctx, fmt.Sprintf(`UPDATE %s SET is_removed = false WHERE id = $1 AND is_removed
+					AND (SELECT action FROM moderation_log WHERE %s ORDER BY created_at DESC, id DESC LIMIT 1)
+						IN ($2, $3);`, target.table, target.logCond), report.TargetID,
+			models.ModerationActionTypeAutoHidePost, models.ModerationActionTypeAutoHideComment)
+		changed = tag.RowsAffected() > 0
+	}
+	if err != nil {
+		return models.Report{}, err
+	}
+
+	if changed {
+		if err = insertAction(ctx, tx, action); err != nil {
+			return models.Report{}, err
+		}
+	}
+
+	resolved, err := scanReport(tx.QueryRow(ctx, `SELECT `+reportColumns+` FROM reports WHERE id = $1;`, reportID))
+	if err != nil {
+		return models.Report{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return models.Report{}, err
+	}
+	return *resolved, nil
+}
+
+func scanReport(row pgx.Row) (*models.Report, error) {
+	var report models.Report
+
+	err := row.Scan(&report.ID, &report.TargetType, &report.TargetID, &report.PostID, &report.Reporter,
+		&report.Reason, &report.Note, &report.Status, &report.ResolvedBy, &report.ResolvedAt, &report.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
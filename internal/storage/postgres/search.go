@@ -0,0 +1,21 @@
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/nedokyrill/posts-service/pkg/consts"
+)
+
+// запрос пользователя в синтаксисе веб-поиска ("фраза в кавычках", or, -исключение), тексты смешанные,
+// поэтому запрос разбирается и русской, и английской конфигурацией, как и столбцы search
+const searchTsQuery = `websearch_to_tsquery('russian', $1) || websearch_to_tsquery('english', $1)`
+
+// подзапрос для FROM с единственным столбцом tsq - разобранным запросом
+const searchFrom = `(SELECT ` + searchTsQuery + ` AS tsq) AS q`
+
+// фрагмент текста column с выделенными найденными словами, tsq - столбец с разобранным запросом
+func headline(column string) string {
+	return fmt.Sprintf(`ts_headline('russian', %s, tsq, 'StartSel=%s, StopSel=%s, MaxWords=%d, MinWords=%d')`,
+		column, consts.SearchHighlightStart, consts.SearchHighlightStop, consts.SearchSnippetWords,
+		consts.SearchSnippetWords/2)
+}
@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nedokyrill/posts-service/internal/models"
+	"github.com/nedokyrill/posts-service/internal/storage"
+)
+
+// код ошибки postgres при нарушении ограничения уникальности
+const uniqueViolationCode = "23505"
+
+const userColumns = `id, username, password_hash, role, created_at`
+
+type UserStorePgx struct {
+	db *pgxpool.Pool
+}
+
+func NewUserStorePgx(db *pgxpool.Pool) *UserStorePgx {
+	return &UserStorePgx{
+		db: db,
+	}
+}
+
+func (s *UserStorePgx) CreateUser(ctx context.Context, user models.User) (models.User, error) {
+	query := `INSERT INTO users (username, password_hash, role) VALUES ($1, $2, $3) RETURNING id, created_at;`
+
+	var id uuid.UUID
+	var createdAt time.Time
+
+	err := s.db.QueryRow(ctx, query, user.Username, user.PasswordHash, user.Role).Scan(&id, &createdAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+			return models.User{}, fmt.Errorf("user with username: %s: %w", user.Username, storage.ErrAlreadyExists)
+		}
+		return models.User{}, err
+	}
+
+	user.ID = id
+	user.CreatedAt = &createdAt
+	return user, nil
+}
+
+func (s *UserStorePgx) GetUserByID(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1;`
+
+	return scanUser(s.db.QueryRow(ctx, query, userID))
+}
+
+func (s *UserStorePgx) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE username = $1;`
+
+	return scanUser(s.db.QueryRow(ctx, query, username))
+}
+
+func scanUser(row pgx.Row) (*models.User, error) {
+	var user models.User
+
+	err := row.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Role, &user.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
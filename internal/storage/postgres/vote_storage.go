@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nedokyrill/posts-service/internal/models"
+)
+
+// таблица голосов и столбец с id оцениваемой записи
+type voteTarget struct {
+	table    string // posts или comments
+	votes    string // post_votes или comment_votes
+	idColumn string // post_id или comment_id
+	columns  string
+}
+
+var (
+	postVotes    = voteTarget{table: "posts", votes: "post_votes", idColumn: "post_id", columns: postColumns}
+	commentVotes = voteTarget{table: "comments", votes: "comment_votes", idColumn: "comment_id",
+		columns: commentColumns}
+)
+
+type VoteStorePgx struct {
+	db *pgxpool.Pool
+}
+
+func NewVoteStorePgx(db *pgxpool.Pool) *VoteStorePgx {
+	return &VoteStorePgx{
+		db: db,
+	}
+}
+
+func (s *VoteStorePgx) VotePost(ctx context.Context, postID uuid.UUID, voter string, value int) (models.Post, error) {
+	var post *models.Post
+
+	err := s.vote(ctx, postVotes, postID, voter, value, func(row pgx.Row) (err error) {
+		post, err = scanPost(row)
+		return err
+	})
+	if err != nil {
+		return models.Post{}, err
+	}
+	return *post, nil
+}
+
+func (s *VoteStorePgx) VoteComment(ctx context.Context, commentID uuid.UUID, voter string,
+	value int) (models.Comment, error) {
+	var comment *models.Comment
+
+	err := s.vote(ctx, commentVotes, commentID, voter, value, func(row pgx.Row) (err error) {
+		comment, err = scanComment(row)
+		return err
+	})
+	if err != nil {
+		return models.Comment{}, err
+	}
+	return *comment, nil
+}
+
+func (s *VoteStorePgx) GetPostVotes(ctx context.Context, postIDs []uuid.UUID,
+	voter string) (map[uuid.UUID]int, error) {
+	return s.getVotes(ctx, postVotes, postIDs, voter)
+}
+
+func (s *VoteStorePgx) GetCommentVotes(ctx context.Context, commentIDs []uuid.UUID,
+	voter string) (map[uuid.UUID]int, error) {
+	return s.getVotes(ctx, commentVotes, commentIDs, voter)
+}
+
+// голос и счетчики меняются в одной транзакции, строка оцениваемой записи блокируется,
+// чтобы одновременные голоса одного пользователя не посчитались дважды
+func (s *VoteStorePgx) vote(ctx context.Context, target voteTarget, id uuid.UUID, voter string, value int,
+	scan func(row pgx.Row) error) (err error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	// если записи нет, здесь вернется pgx.ErrNoRows
+	err = tx.QueryRow(ctx, fmt.Sprintf(`SELECT id FROM %s WHERE id = $1 FOR UPDATE;`, target.table), id).Scan(&id)
+	if err != nil {
+		return err
+	}
+
+	var prev int
+	err = tx.QueryRow(ctx, fmt.Sprintf(`SELECT value FROM %s WHERE %s = $1 AND voter = $2;`,
+		target.votes, target.idColumn), id, voter).Scan(&prev)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+
+	if value == 0 {
+		_, err = tx.Exec(ctx, fmt.Sprintf(`DELETE FROM %s WHERE %s = $1 AND voter = $2;`,
+			target.votes, target.idColumn), id, voter)
+	} else {
+		_, err = tx.Exec(ctx, fmt.Sprintf(`INSERT INTO %s (%s, voter, value) VALUES ($1, $2, $3)
+				ON CONFLICT (%s, voter) DO UPDATE SET value = excluded.value, created_at = now();`,
+			target.votes, target.idColumn, target.idColumn), id, voter, value)
+	}
+	if err != nil {
+		return err
+	}
+
+	up, down := models.VoteDelta(prev, value)
+	err = scan(tx.QueryRow(ctx, fmt.Sprintf(`UPDATE %s SET upvotes = upvotes + $1, downvotes = downvotes + $2
+				WHERE id = $3 RETURNING %s;`, target.table, target.columns), up, down, id))
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (s *VoteStorePgx) getVotes(ctx context.Context, target voteTarget, ids []uuid.UUID,
+	voter string) (map[uuid.UUID]int, error) {
+	query := fmt.Sprintf(`SELECT %s, value FROM %s WHERE %s = ANY($1) AND voter = $2;`,
+		target.idColumn, target.votes, target.idColumn)
+
+	rows, err := s.db.Query(ctx, query, ids, voter)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	votes := make(map[uuid.UUID]int, len(ids))
+	for rows.Next() {
+		var id uuid.UUID
+		var value int
+		if err = rows.Scan(&id, &value); err != nil {
+			return nil, err
+		}
+		votes[id] = value
+	}
+	return votes, rows.Err()
+}
@@ -2,19 +2,99 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/nedokyrill/posts-service/internal/models"
 )
 
+// ErrAlreadyExists - нарушено ограничение уникальности (например, имя пользователя уже занято)
+var ErrAlreadyExists = errors.New("already exists")
+
 type PostStorage interface {
-	GetAllPosts(ctx context.Context, offset, limit int) ([]*models.Post, error) // получение списка всех постов
-	GetPostByID(ctx context.Context, postId uuid.UUID) (*models.Post, error)    // получение поста по его id
-	CreatePost(ctx context.Context, post models.Post) (models.Post, error)      // создание поста
+	GetAllPosts(ctx context.Context, feed models.PostFeed, page models.Page) ([]*models.Post, error)                  // получение страницы ленты постов
+	CountPosts(ctx context.Context, feed models.PostFeed) (int, error)                                                // получение количества постов в ленте
+	GetPostByID(ctx context.Context, postId uuid.UUID) (*models.Post, error)                                          // получение поста по его id
+	CreatePost(ctx context.Context, post models.Post) (models.Post, error)                                            // создание поста
+	UpdatePost(ctx context.Context, post models.Post) (models.Post, error)                                            // обновление поста
+	DeletePost(ctx context.Context, postId uuid.UUID) error                                                           // удаление поста
+	SetCommentsAllowed(ctx context.Context, postId uuid.UUID, allowed bool) (models.Post, error)                      // запрет/разрешение комментариев к посту
+	SearchPosts(ctx context.Context, query string, page models.Page) ([]*models.PostSearchResult, error)              // полнотекстовый поиск постов, от более релевантных к менее
+	CountSearchPosts(ctx context.Context, query string) (int, error)                                                  // получение количества найденных постов
+	GetPopularTags(ctx context.Context, limit int) ([]*models.Tag, error)                                             // самые популярные теги по количеству постов
+	HasDuplicate(ctx context.Context, author, contentHash string, since time.Time, excludeID uuid.UUID) (bool, error) // есть ли у автора пост с тем же текстом (models.ContentHash), созданный не раньше since
 }
 
+// CommentStorage - комментарии и ответы. viewer - кто их смотрит: скрытые фильтром контента комментарии
+// отдаются только автору, для остальных их (и их веток ответов) нет
 type CommentStorage interface {
-	CreateComment(ctx context.Context, comment models.Comment) (models.Comment, error)                       // создание комментария
-	GetCommentsByPostID(ctx context.Context, postID uuid.UUID, offset, limit int) ([]*models.Comment, error) // получение комментариев по id поста
-	GetRepliesByParentCommentID(ctx context.Context, parentCommentID uuid.UUID) ([]*models.Comment, error)   // получение ответов на комментарий по его id
+	CreateComment(ctx context.Context, comment models.Comment) (models.Comment, error)                                                                                                 // создание комментария
+	GetCommentsByPostIDs(ctx context.Context, postIDs []uuid.UUID, sort models.CommentSort, page models.Page, viewer string) (map[uuid.UUID][]*models.Comment, error)                  // получение страниц комментариев сразу для нескольких постов
+	CountCommentsByPostIDs(ctx context.Context, postIDs []uuid.UUID, viewer string) (map[uuid.UUID]int, error)                                                                         // получение количества комментариев к нескольким постам
+	CountAllCommentsByPostIDs(ctx context.Context, postIDs []uuid.UUID, viewer string) (map[uuid.UUID]int, error)                                                                      // получение количества всех комментариев и ответов к нескольким постам
+	GetRepliesByParentCommentIDs(ctx context.Context, parentCommentIDs []uuid.UUID, sort models.CommentSort, page models.Page, viewer string) (map[uuid.UUID][]*models.Comment, error) // получение страниц ответов сразу для нескольких комментариев
+	CountRepliesByParentCommentIDs(ctx context.Context, parentCommentIDs []uuid.UUID, viewer string) (map[uuid.UUID]int, error)                                                        // получение количества ответов на несколько комментариев
+	GetCommentTree(ctx context.Context, postID uuid.UUID, maxDepth, offset, limit int, viewer string) ([]*models.CommentTreeNode, error)                                               // получение страницы дерева комментариев к посту в порядке обхода в глубину
+	GetCommentByID(ctx context.Context, commentID uuid.UUID) (*models.Comment, error)                                                                                                  // получение комментария по его id
+	GetCommentsAfter(ctx context.Context, postID uuid.UUID, after models.Cursor, limit int, viewer string) ([]*models.Comment, error)                                                  // комментарии и ответы к посту после курсора (created_at, id), от старых к новым
+	UpdateComment(ctx context.Context, commentID uuid.UUID, content string, shadow bool) (models.Comment, error)                                                                       // изменение текста комментария (shadow - скрыть его фильтром контента, скрытый остается скрытым)
+	DeleteComment(ctx context.Context, commentID uuid.UUID) (models.Comment, error)                                                                                                    // мягкое удаление комментария (ответы сохраняются)
+	SearchComments(ctx context.Context, query string, postID *uuid.UUID, limit int) ([]*models.CommentSearchResult, error)                                                             // полнотекстовый поиск комментариев (во всех постах, если postID не задан)
+	HasDuplicate(ctx context.Context, author, contentHash string, since time.Time, excludeID uuid.UUID) (bool, error)                                                                  // есть ли у автора комментарий с тем же текстом (models.ContentHash), созданный не раньше since
+}
+
+type VoteStorage interface {
+	VotePost(ctx context.Context, postID uuid.UUID, voter string, value int) (models.Post, error)          // голос за пост (1 - за, -1 - против, 0 - отзыв голоса)
+	VoteComment(ctx context.Context, commentID uuid.UUID, voter string, value int) (models.Comment, error) // голос за комментарий
+	GetPostVotes(ctx context.Context, postIDs []uuid.UUID, voter string) (map[uuid.UUID]int, error)        // голоса пользователя за посты
+	GetCommentVotes(ctx context.Context, commentIDs []uuid.UUID, voter string) (map[uuid.UUID]int, error)  // голоса пользователя за комментарии
+}
+
+type UserStorage interface {
+	CreateUser(ctx context.Context, user models.User) (models.User, error)        // создание пользователя (ErrAlreadyExists, если имя занято)
+	GetUserByID(ctx context.Context, userID uuid.UUID) (*models.User, error)      // получение пользователя по его id
+	GetUserByUsername(ctx context.Context, username string) (*models.User, error) // получение пользователя по имени
+}
+
+type NotificationStorage interface {
+	CreateNotification(ctx context.Context, notification models.Notification) (models.Notification, error)    // создание уведомления
+	GetNotifications(ctx context.Context, recipient string, page models.Page) ([]*models.Notification, error) // получение страницы уведомлений пользователя от новых к старым
+	CountUnreadNotifications(ctx context.Context, recipient string) (int, error)                              // получение количества непрочитанных уведомлений пользователя
+	MarkNotificationsRead(ctx context.Context, recipient string, ids []uuid.UUID) (int, error)                // отметка уведомлений пользователя прочитанными, возвращает количество отмеченных
+}
+
+type CommunityStorage interface {
+	CreateCommunity(ctx context.Context, community models.Community) (models.Community, error)                 // создание сообщества вместе с членством создателя (ErrAlreadyExists, если slug занят)
+	GetCommunityBySlug(ctx context.Context, slug string) (*models.Community, error)                            // получение сообщества по slug
+	GetCommunitiesByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.Community, error)         // получение нескольких сообществ по id
+	AddMember(ctx context.Context, communityID uuid.UUID, username string) (models.Community, error)           // вступление в сообщество (повторное ничего не меняет)
+	RemoveMember(ctx context.Context, communityID uuid.UUID, username string) (models.Community, error)        // выход из сообщества
+	GetMemberships(ctx context.Context, communityIDs []uuid.UUID, username string) (map[uuid.UUID]bool, error) // в каких из сообществ состоит пользователь
+	IsModerator(ctx context.Context, communityID uuid.UUID, username string) (bool, error)                     // модерирует ли пользователь сообщество (создатель модерирует всегда)
+}
+
+// ModerationStorage - действия модераторов, каждое изменение записывается в журнал вместе с ним (в одной транзакции)
+type ModerationStorage interface {
+	RemovePost(ctx context.Context, postID uuid.UUID, action models.ModerationAction) (models.Post, error)                      // скрытие поста (sql.ErrNoRows, если его нет или он уже скрыт)
+	RemoveComment(ctx context.Context, commentID uuid.UUID, action models.ModerationAction) (models.Comment, error)             // скрытие комментария (sql.ErrNoRows, если его нет или он уже скрыт)
+	SetUserRole(ctx context.Context, username string, role models.Role, action models.ModerationAction) (models.User, error)    // назначение роли пользователю
+	AddCommunityModerator(ctx context.Context, communityID uuid.UUID, username string, action models.ModerationAction) error    // назначение модератора сообщества (ErrAlreadyExists, если он уже назначен)
+	RemoveCommunityModerator(ctx context.Context, communityID uuid.UUID, username string, action models.ModerationAction) error // снятие модератора сообщества (sql.ErrNoRows, если он не назначен)
+	GetModerationLog(ctx context.Context, communityID *uuid.UUID, page models.Page) ([]*models.ModerationAction, error)         // страница журнала от новых записей к старым (всего или по сообществу)
+}
+
+// ReportStorage - жалобы на посты и комментарии. Контент, скрытый по жалобам автоматически (запись hide в журнале),
+// восстанавливается при отклонении жалоб (запись action), при решении REMOVED контент скрывается (запись action).
+// Изменения контента и записи журнала делаются в одной транзакции с жалобами
+type ReportStorage interface {
+	CreateReport(ctx context.Context, report models.Report, hideThreshold int, hide models.ModerationAction) (models.Report, error)                            // создание жалобы (ErrAlreadyExists - повторная, sql.ErrNoRows - контента нет или он скрыт), при hideThreshold открытых жалоб контент скрывается
+	GetReportByID(ctx context.Context, reportID uuid.UUID) (*models.Report, error)                                                                             // получение жалобы по ее id
+	GetReports(ctx context.Context, status models.ReportStatus, page models.Page) ([]*models.Report, error)                                                    // страница жалоб с заданным статусом от старых к новым
+	ResolveReport(ctx context.Context, reportID uuid.UUID, status models.ReportStatus, resolver string, action models.ModerationAction) (models.Report, error) // решение по открытой жалобе (sql.ErrNoRows, если ее нет или она уже решена) и по всем открытым жалобам на тот же контент
+}
+
+// RateLimitStorage - состояние корзин ограничителя частоты запросов
+type RateLimitStorage interface {
+	TakeToken(ctx context.Context, key string, limit models.RateLimit) (bool, time.Duration, error) // забирает токен из корзины key, если токенов нет - возвращает false и через сколько появится следующий
 }
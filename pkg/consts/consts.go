@@ -3,12 +3,79 @@ package consts
 import "time"
 
 const PageSize = 20
+const MaxPageSize = 100
 const ContentMaxLen = 2000
+const MaxCommentTreeDepth = 50
 
 const InitPostsSizeInMem = 200
 const InitCommentsSizeInMem = 50
 
 const BadRequestType = "Bad Request"
+const UnauthorizedType = "Unauthorized"
+const ForbiddenType = "Forbidden"
+const TooManyRequestsType = "Too Many Requests"
+const RateLimitedType = "RATE_LIMITED"
+const BannedWordsType = "BANNED_WORDS"
+const TooManyLinksType = "TOO_MANY_LINKS"
+const DuplicateContentType = "DUPLICATE_CONTENT"
 const InternalServerErrorType = "Internal Server Error"
 
 const PgxTimeout = 5 * time.Second
+
+const LoaderWait = 2 * time.Millisecond
+const LoaderMaxBatch = 100
+
+const AccessTokenTTL = 15 * time.Minute
+const RefreshTokenTTL = 30 * 24 * time.Hour
+
+const UsernameMinLen = 3
+const UsernameMaxLen = 32
+const PasswordMinLen = 8
+const PasswordMaxLen = 72 // bcrypt учитывает только первые 72 байта пароля
+
+const MaxSubscriptionsPerUser = 10
+const ViewerBufferSize = 32
+const SSEKeepAliveInterval = 15 * time.Second
+const MaxReplayComments = 500 // сколько пропущенных комментариев можно получить при переподключении подписки
+
+const PostEventsChannel = "post_events"
+const NotificationsChannel = "user_notifications"
+const MaxNotifyPayload = 7900 // postgres ограничивает payload NOTIFY 8000 байт
+const ListenMinBackoff = time.Second
+const ListenMaxBackoff = 30 * time.Second
+
+const WebsocketInitTimeout = 10 * time.Second
+const WebsocketPingInterval = 15 * time.Second
+
+const SearchQueryMaxLen = 200
+const SearchCommentsLimit = 50
+const SearchSnippetWords = 35   // длина фрагмента текста в результатах поиска
+const SearchTitleWeight = 1.0   // вес названия поста в поисковом индексе mem (как setweight 'A' в postgres)
+const SearchContentWeight = 0.4 // вес текста поста и комментария ('B')
+const SearchHighlightStart = "<mark>"
+const SearchHighlightStop = "</mark>"
+
+const MaxTagsPerPost = 5
+const MaxTagLen = 32
+const MaxFilterTags = 10 // сколько тегов можно передать в фильтр ленты
+const PopularTagsSize = 10
+const MaxPopularTagsSize = 50
+
+const CommunitySlugMinLen = 3
+const CommunitySlugMaxLen = 32
+const CommunityTitleMaxLen = 100
+const CommunityDescriptionMaxLen = 1000
+
+const ModerationReasonMaxLen = 500
+const SystemActor = "system" // автор записей журнала модерации, сделанных самим сервисом (назначение админов, скрытие по жалобам)
+
+const ReportNoteMaxLen = 500
+const ReportHideThreshold = 5 // после скольких открытых жалоб контент скрывается автоматически (если не задан REPORT_HIDE_THRESHOLD)
+
+const RateLimitSweepInterval = time.Minute                           // как часто удаляются восполнившиеся корзины ограничителя частоты
+const DefaultRateLimits = "CreatePost=5/1m,AddComment=20/1m,*=60/1m" // ограничения частоты операций, если не заданы в RATE_LIMITS
+
+const MaxPostLinks = 5                 // сколько ссылок может быть в посте (если не задан MAX_POST_LINKS)
+const MaxCommentLinks = 2              // сколько ссылок может быть в комментарии (если не задан MAX_COMMENT_LINKS)
+const DuplicateWindow = 24 * time.Hour // за какое время ищутся повторы контента автора
+const DuplicateMinLen = 20             // более короткие тексты ("спасибо!") повторами не считаются
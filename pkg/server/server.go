@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"os"
 	"time"
@@ -15,14 +16,23 @@ type APIServer struct {
 }
 
 func NewAPIServer(router *gin.Engine) *APIServer {
-	return &APIServer{
-		httpServer: &http.Server{
-			Addr:         ":" + os.Getenv("API_PORT"),
-			Handler:      router.Handler(),
-			ReadTimeout:  5 * time.Second,
-			WriteTimeout: 10 * time.Second,
+	// контекст всех запросов отменяется при остановке сервера, чтобы завершились потоки подписок (SSE)
+	baseCtx, cancel := context.WithCancel(context.Background())
+
+	httpServer := &http.Server{
+		Addr:         ":" + os.Getenv("API_PORT"),
+		Handler:      router.Handler(),
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 10 * time.Second,
+		BaseContext: func(net.Listener) context.Context {
+			return baseCtx
 		},
 	}
+	httpServer.RegisterOnShutdown(cancel)
+
+	return &APIServer{
+		httpServer: httpServer,
+	}
 }
 
 func (s *APIServer) Start() {
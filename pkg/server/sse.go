@@ -0,0 +1,56 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nedokyrill/posts-service/pkg/logger"
+)
+
+// SSEMiddleware готовит ответ для подписки через Server-Sent Events (запрос с "Accept: text/event-stream"):
+// снимает WriteTimeout сервера, который оборвал бы поток, отключает буферизацию в nginx и сериализует запись
+// в ответ, так как gqlgen пишет keep-alive пинги из отдельной горутины
+func SSEMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !strings.Contains(c.GetHeader("Accept"), "text/event-stream") {
+			c.Next()
+			return
+		}
+
+		err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+		if err != nil && !errors.Is(err, http.ErrNotSupported) {
+			logger.Logger.Error("error resetting write deadline for event stream: ", err)
+		}
+		c.Header("X-Accel-Buffering", "no")
+
+		c.Writer = &lockedWriter{ResponseWriter: c.Writer}
+		c.Next()
+	}
+}
+
+type lockedWriter struct {
+	gin.ResponseWriter
+	mu sync.Mutex
+}
+
+func (w *lockedWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *lockedWriter) WriteString(s string) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *lockedWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.ResponseWriter.Flush()
+}
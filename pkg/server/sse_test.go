@@ -0,0 +1,59 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSEMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.POST("/query", SSEMiddleware(), func(c *gin.Context) {
+		_, locked := c.Writer.(*lockedWriter)
+		if !locked {
+			c.String(http.StatusOK, "plain")
+			return
+		}
+
+		// события и пинги пишутся из разных горутин, как в transport.SSE
+		var wg sync.WaitGroup
+		for i := range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				fmt.Fprintf(c.Writer, ": ping %d\n\n", i)
+				c.Writer.Flush()
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("event stream", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/query", nil)
+		req.Header.Set("Accept", "text/event-stream")
+		rec := httptest.NewRecorder()
+
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, "no", rec.Header().Get("X-Accel-Buffering"))
+		assert.Contains(t, rec.Body.String(), ": ping 0\n\n")
+		assert.True(t, rec.Flushed)
+	})
+
+	t.Run("regular request", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/query", nil)
+		rec := httptest.NewRecorder()
+
+		router.ServeHTTP(rec, req)
+
+		assert.Empty(t, rec.Header().Get("X-Accel-Buffering"))
+		assert.Equal(t, "plain", rec.Body.String())
+	})
+}
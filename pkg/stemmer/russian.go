@@ -0,0 +1,142 @@
+package stemmer
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// окончания по классам алгоритма Snowball для русского языка. В группах с "после а/я" окончание
+// отрезается, только если перед ним стоит а или я (сама буква остается)
+var (
+	perfectiveGerund      = endings("в", "вши", "вшись")
+	perfectiveGerundOther = endings("ив", "ивши", "ившись", "ыв", "ывши", "ывшись")
+	adjective             = endings("ее", "ие", "ые", "ое", "ими", "ыми", "ей", "ий", "ый", "ой", "ем", "им", "ым",
+		"ом", "его", "ого", "ему", "ому", "их", "ых", "ую", "юю", "ая", "яя", "ою", "ею")
+	participle      = endings("ем", "нн", "вш", "ющ", "щ")
+	participleOther = endings("ивш", "ывш", "ующ")
+	reflexive       = endings("ся", "сь")
+	verb            = endings("ла", "на", "ете", "йте", "ли", "й", "л", "ем", "н", "ло", "но", "ет", "ют", "ны", "ть",
+		"ешь", "нно")
+	verbOther = endings("ила", "ыла", "ена", "ейте", "уйте", "ите", "или", "ыли", "ей", "уй", "ил", "ыл", "им", "ым",
+		"ен", "ило", "ыло", "ено", "ят", "ует", "уют", "ит", "ыт", "ены", "ить", "ыть", "ишь", "ую", "ю")
+	noun = endings("а", "ев", "ов", "ие", "ье", "е", "иями", "ями", "ами", "еи", "ии", "и", "ией", "ей", "ой", "ий",
+		"й", "иям", "ям", "ием", "ем", "ам", "ом", "о", "у", "ах", "иях", "ях", "ы", "ь", "ию", "ью", "ю", "ия", "ья",
+		"я")
+	superlative   = endings("ейше", "ейш")
+	derivational  = endings("ость", "ост")
+	russianVowels = "аеиоуыэюя"
+)
+
+// Russian возвращает основу русского слова по алгоритму Snowball: разные формы одного слова ("спамер", "спамера",
+// "спамерами") сводятся к одной основе. Слово приводится к нижнему регистру, ё заменяется на е
+func Russian(word string) string {
+	w := []rune(strings.ReplaceAll(strings.ToLower(word), "ё", "е"))
+	rv, r2 := regions(w)
+
+	// шаг 1: деепричастие, иначе возвратная частица и затем прилагательное, глагол или существительное
+	if cut, ok := trim(w, rv, perfectiveGerund, true); ok {
+		w = cut
+	} else if cut, ok = trim(w, rv, perfectiveGerundOther, false); ok {
+		w = cut
+	} else {
+		if cut, ok = trim(w, rv, reflexive, false); ok {
+			w = cut
+		}
+
+		if cut, ok = trim(w, rv, adjective, false); ok {
+			w = cut
+			if cut, ok = trim(w, rv, participle, true); ok {
+				w = cut
+			} else if cut, ok = trim(w, rv, participleOther, false); ok {
+				w = cut
+			}
+		} else if cut, ok = trim(w, rv, verb, true); ok {
+			w = cut
+		} else if cut, ok = trim(w, rv, verbOther, false); ok {
+			w = cut
+		} else if cut, ok = trim(w, rv, noun, false); ok {
+			w = cut
+		}
+	}
+
+	// шаг 2: и на конце
+	if cut, ok := trim(w, rv, endings("и"), false); ok {
+		w = cut
+	}
+
+	// шаг 3: словообразовательное окончание целиком в R2
+	if cut, ok := trim(w, r2, derivational, false); ok {
+		w = cut
+	}
+
+	// шаг 4: двойное н, превосходная степень (и затем двойное н) или мягкий знак
+	if cut, ok := trim(w, rv, endings("нн"), false); ok {
+		w = append(cut, 'н')
+	} else if cut, ok = trim(w, rv, superlative, false); ok {
+		w = cut
+		if cut, ok = trim(w, rv, endings("нн"), false); ok {
+			w = append(cut, 'н')
+		}
+	} else if cut, ok = trim(w, rv, endings("ь"), false); ok {
+		w = cut
+	}
+
+	return string(w)
+}
+
+// RV - часть слова после первой гласной, R1 - после первой согласной, идущей за гласной, R2 - то же внутри R1
+func regions(w []rune) (rv, r2 int) {
+	rv = len(w)
+	for i, r := range w {
+		if isVowel(r) {
+			rv = i + 1
+			break
+		}
+	}
+
+	r1 := region(w, 0)
+	return rv, region(w, r1)
+}
+
+func region(w []rune, from int) int {
+	for i := from + 1; i < len(w); i++ {
+		if !isVowel(w[i]) && isVowel(w[i-1]) {
+			return i + 1
+		}
+	}
+	return len(w)
+}
+
+func isVowel(r rune) bool {
+	return strings.ContainsRune(russianVowels, r)
+}
+
+// отрезает самое длинное из окончаний, целиком попадающее в область слова с индекса from.
+// afterA - окончание должно идти после а или я из той же области
+func trim(w []rune, from int, ends [][]rune, afterA bool) ([]rune, bool) {
+	for _, end := range ends {
+		start := len(w) - len(end)
+		if start < from || !slices.Equal(w[start:], end) {
+			continue
+		}
+		if afterA && (start-1 < from || (w[start-1] != 'а' && w[start-1] != 'я')) {
+			continue
+		}
+		return w[:start:start], true
+	}
+	return w, false
+}
+
+// окончания от длинных к коротким, чтобы отрезалось самое длинное подходящее
+func endings(ends ...string) [][]rune {
+	slices.SortStableFunc(ends, func(a, b string) int {
+		return utf8.RuneCountInString(b) - utf8.RuneCountInString(a)
+	})
+
+	res := make([][]rune, 0, len(ends))
+	for _, end := range ends {
+		res = append(res, []rune(end))
+	}
+	return res
+}
@@ -0,0 +1,33 @@
+package stemmer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRussian(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		{word: "спамер", want: "спамер"},
+		{word: "Спамерами", want: "спамер"},
+		{word: "рекламой", want: "реклам"},
+		{word: "рекламировать", want: "рекламирова"},
+		{word: "наркотиков", want: "наркотик"},
+		{word: "вероятность", want: "вероятн"},
+		{word: "красивейший", want: "красив"},
+		{word: "каменный", want: "камен"},
+		{word: "бегавшая", want: "бега"},
+		{word: "прыгнувшись", want: "прыгнувш"},
+		{word: "ёлки", want: "елк"},
+		{word: "я", want: "я"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			assert.Equal(t, tt.want, Russian(tt.word))
+		})
+	}
+}
@@ -1,8 +1,14 @@
 package utils
 
+import (
+	"math"
+	"time"
+)
+
 type GqlError struct {
-	Msg  string
-	Type string
+	Msg        string
+	Type       string
+	RetryAfter time.Duration // через сколько можно повторить запрос, если он отклонен ограничителем частоты
 }
 
 func (g GqlError) Error() string {
@@ -10,8 +16,13 @@ func (g GqlError) Error() string {
 }
 
 func (g GqlError) Extensions() map[string]interface{} {
-	return map[string]interface{}{
+	ext := map[string]interface{}{
 		"msg":  g.Msg,
 		"type": g.Type,
 	}
+	// в секундах с округлением вверх, как в заголовке Retry-After
+	if g.RetryAfter > 0 {
+		ext["retryAfter"] = int(math.Ceil(g.RetryAfter.Seconds()))
+	}
+	return ext
 }